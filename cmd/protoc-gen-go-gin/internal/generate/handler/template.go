@@ -325,7 +325,7 @@ import (
 var (
 	{{.LowerName}}NO       = {{.RandNumber}}
 	{{.LowerName}}Name     = "{{.LowerName}}"
-	{{.LowerName}}BaseCode = errcode.HCode({{.LowerName}}NO)
+	{{.LowerName}}BaseCode = errcode.HCode({{.LowerName}}NO, {{.LowerName}}Name)
 // --blank line--
 {{- range $i, $v := .Methods}}
 	{{if eq .InvokeType 0}}{{if .Path}}Err{{.MethodName}}{{.ServiceName}}   = errcode.NewError({{.LowerServiceName}}BaseCode+{{$v.AddOne $i}}, "failed to {{.MethodName}} "+{{.LowerServiceName}}Name){{end}}{{end}}
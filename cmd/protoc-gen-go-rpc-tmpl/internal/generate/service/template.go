@@ -549,7 +549,7 @@ import (
 var (
 	_{{.LowerName}}NO       = {{.RandNumber}}
 	_{{.LowerName}}Name     = "{{.LowerName}}"
-	_{{.LowerName}}BaseCode = errcode.RCode(_{{.LowerName}}NO)
+	_{{.LowerName}}BaseCode = errcode.RCode(_{{.LowerName}}NO, _{{.LowerName}}Name)
 // --blank line--
 {{- range $i, $v := .Methods}}
 	Status{{.MethodName}}{{.ServiceName}}   = errcode.NewRPCStatus(_{{.LowerServiceName}}BaseCode+{{$v.AddOne $i}}, "failed to {{.MethodName}} "+_{{.LowerServiceName}}Name)
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/18721889353/sunshine/pkg/app"
+	"github.com/18721889353/sunshine/pkg/logger"
 	"github.com/18721889353/sunshine/pkg/tracer"
 
 	"github.com/18721889353/sunshine/internal/config"
@@ -40,5 +41,10 @@ func Close(servers []app.IServer) []app.Close {
 		})
 	}
 
+	// flush and close the log sinks
+	closes = append(closes, func() error {
+		return logger.CloseSinks()
+	})
+
 	return closes
 }
@@ -9,13 +9,16 @@ import (
 	"github.com/18721889353/sunshine/pkg/jwt"
 	v5 "github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap/zapcore"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jinzhu/copier"
 
 	"github.com/18721889353/sunshine/pkg/conf"
+	"github.com/18721889353/sunshine/pkg/consulcli"
+	"github.com/18721889353/sunshine/pkg/etcdcli"
 	"github.com/18721889353/sunshine/pkg/logger"
+	"github.com/18721889353/sunshine/pkg/metrics"
 	"github.com/18721889353/sunshine/pkg/nacoscli"
 	"github.com/18721889353/sunshine/pkg/stat"
 	"github.com/18721889353/sunshine/pkg/tracer"
@@ -62,6 +65,9 @@ func InitApp() {
 		logger.WithLevel(cfg.Logger.Level),
 		logger.WithFormat(cfg.Logger.Format),
 		logger.WithHooks(ZapLogHandler),
+		logger.WithSampling(cfg.Logger.Sampling.First, cfg.Logger.Sampling.Thereafter),
+		logger.WithNamedLevels(cfg.Logger.NamedLevels),
+		logger.WithSinks(newLogSinks(cfg.Logger.Sinks)...),
 		logger.WithSave(
 			cfg.Logger.IsSave,
 			logger.WithFileName(cfg.Logger.LogFileConfig.Filename),
@@ -80,6 +86,7 @@ func InitApp() {
 	model.GetDB()
 	logger.Infof("[%s] was initialized", cfg.Database.Driver)
 
+	model.SetTracing(cfg.App.EnableTrace)
 	model.InitCache(cfg.App.CacheType)
 	logger.Info("init " + cfg.App.CacheType + " succeeded")
 
@@ -105,34 +112,24 @@ func InitApp() {
 	}
 	// initializing tracing
 	if cfg.App.EnableTrace {
-		tracer.InitWithConfig(
-			cfg.App.Name,
-			cfg.App.Env,
-			cfg.App.Version,
-			cfg.Jaeger.AgentHost,
-			strconv.Itoa(cfg.Jaeger.AgentPort),
-			cfg.App.TracingSamplingRate,
-		)
+		err = tracer.InitWithTracingConfig(cfg.App.Name, cfg.App.Env, cfg.App.Version, newTracingConfig(&cfg.Tracing))
+		if err != nil {
+			panic("init tracer error: " + err.Error())
+		}
 		logger.Info("[tracer] was initialized")
 	}
-	// initializing tracing
-	if cfg.App.EnableTrace {
-		tracer.InitWithConfig(
-			cfg.App.Name,
-			cfg.App.Env,
-			cfg.App.Version,
-			cfg.Jaeger.AgentHost,
-			strconv.Itoa(cfg.Jaeger.AgentPort),
-			cfg.App.TracingSamplingRate,
-		)
-		logger.Info("[tracer] was initialized")
+
+	// initializing the standard labels of metrics, all metrics are served by one /metrics endpoint
+	if cfg.App.EnableMetrics {
+		metrics.Init(cfg.App.Name, cfg.App.Version, metrics.WithConstLabels(map[string]string{"env": cfg.App.Env}))
+		logger.Info("[metrics] was initialized")
 	}
 
 	// initializing the print system and process resources
 	if cfg.App.EnableStat {
 		stat.Init(
 			stat.WithLog(logger.Get()),
-			stat.WithAlarm(), // invalid if it is windows, the default threshold for cpu and memory is 0.8, you can modify themstat.WithPrintField(logger.String("service_name", cfg.App.Name), logger.String("host", cfg.App.Host)),
+			stat.WithAlarm(stat.WithServiceName(cfg.App.Name)), // invalid if it is windows, the default threshold for cpu and memory is 0.8, you can modify themstat.WithPrintField(logger.String("service_name", cfg.App.Name), logger.String("host", cfg.App.Host)),
 			stat.WithPrintField(logger.String("service_name", cfg.App.Name), logger.String("host", cfg.App.Host)),
 		)
 		logger.Info("[resource statistics] was initialized")
@@ -148,30 +145,31 @@ func initConfig() {
 	flag.Parse()
 
 	if enableConfigCenter {
-		// get the configuration from the configuration center (first get the nacos configuration,
-		// then read the service configuration according to the nacos configuration center)
+		// get the configuration from the configuration center (first get the configuration center settings,
+		// then read the service configuration from the configuration center nacos, etcd or consul)
 		if configFile == "" {
 			configFile = configs.Path("serverNameExample_cc.yml")
 		}
-		nacosConfig, err := config.NewCenter(configFile)
+		centerConfig, err := config.NewCenter(configFile)
 		if err != nil {
 			panic(err)
 		}
-		appConfig := &config.Config{}
-		params := &nacoscli.Params{}
-		_ = copier.Copy(params, &nacosConfig.Nacos)
-		format, data, err := nacoscli.GetConfig(params)
+		source, err := newCenterSource(centerConfig)
 		if err != nil {
 			panic(fmt.Sprintf("connect to configuration center err, %v", err))
 		}
-		err = conf.ParseConfigData(data, format, appConfig)
+		// watch the configuration center, the changed configuration replaces the current configuration
+		err = config.InitWithSources([]conf.Source{source},
+			conf.WithOnChange(func(e *conf.Event) {
+				logger.Info("configuration changed", logger.String("source", e.Source), logger.Any("keys", e.ChangedKeys))
+			}),
+		)
 		if err != nil {
 			panic(fmt.Sprintf("parse configuration data err, %v", err))
 		}
-		if appConfig.App.Name == "" {
+		if config.Get().App.Name == "" {
 			panic("read the config from center error, config data is empty")
 		}
-		config.Set(appConfig)
 	} else {
 		// get configuration from local configuration file
 		if configFile == "" {
@@ -184,6 +182,83 @@ func initConfig() {
 	}
 
 	if version != "" {
-		config.Get().App.Version = version
+		config.Override(func(c *config.Config) {
+			c.App.Version = version
+		})
+	}
+}
+
+// create the sinks that the logs are shipped to
+func newLogSinks(sinkCfgs []config.LogSink) []*logger.AsyncSink {
+	var sinks []*logger.AsyncSink
+	for _, c := range sinkCfgs {
+		sink, err := logger.NewSink(&logger.SinkConfig{
+			Type:          c.Type,
+			Addrs:         c.Addrs,
+			Topic:         c.Topic,
+			Network:       c.Network,
+			Addr:          c.Addr,
+			Tag:           c.Tag,
+			URL:           c.URL,
+			Index:         c.Index,
+			Labels:        c.Labels,
+			Headers:       c.Headers,
+			BufferSize:    c.BufferSize,
+			BatchSize:     c.BatchSize,
+			FlushInterval: time.Millisecond * time.Duration(c.FlushInterval),
+		})
+		if err != nil {
+			panic(fmt.Sprintf("create log sink '%s' error: %v", c.Type, err))
+		}
+		sinks = append(sinks, sink)
 	}
+	return sinks
+}
+
+func newTracingConfig(c *config.Tracing) *tracer.Config {
+	rules := make([]tracer.SamplingRule, 0, len(c.SamplingRules))
+	for _, rule := range c.SamplingRules {
+		rules = append(rules, tracer.SamplingRule{Name: rule.Name, Rate: rule.Rate})
+	}
+	return &tracer.Config{
+		Exporter:           c.Exporter,
+		Protocol:           c.Protocol,
+		Endpoint:           c.Endpoint,
+		Insecure:           c.Insecure,
+		Headers:            c.Headers,
+		SamplingRate:       c.SamplingRate,
+		ParentBased:        c.ParentBased,
+		SamplingRules:      rules,
+		Propagators:        c.Propagators,
+		BatchTimeout:       time.Millisecond * time.Duration(c.BatchTimeout),
+		ExportTimeout:      time.Millisecond * time.Duration(c.ExportTimeout),
+		MaxExportBatchSize: c.MaxExportBatchSize,
+		MaxQueueSize:       c.MaxQueueSize,
+	}
+}
+
+// create the source of configuration center
+func newCenterSource(c *config.Center) (conf.Source, error) {
+	switch strings.ToLower(c.Provider) {
+	case "", "nacos":
+		params := &nacoscli.Params{}
+		_ = copier.Copy(params, &c.Nacos)
+		return nacoscli.NewSource(params)
+
+	case "etcd":
+		cli, err := etcdcli.Init(c.Etcd.Addrs)
+		if err != nil {
+			return nil, err
+		}
+		return etcdcli.NewSource(cli, c.Etcd.Key, c.Etcd.Format), nil
+
+	case "consul":
+		cli, err := consulcli.Init(c.Consul.Addr)
+		if err != nil {
+			return nil, err
+		}
+		return consulcli.NewSource(cli.KV(), c.Consul.Key, c.Consul.Format), nil
+	}
+
+	return nil, fmt.Errorf("unsupported configuration center provider '%s'", c.Provider)
 }
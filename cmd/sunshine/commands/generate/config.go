@@ -15,6 +15,14 @@ import (
 	"github.com/18721889353/sunshine/pkg/jy2struct"
 )
 
+// the validation rules of configuration fields, the configuration is checked by conf when parsing
+var configValidateTags = map[string]string{
+	"app.name":      `validate:"required"`,
+	"app.cacheType": `validate:"omitempty,oneof=memory redis"`,
+	"logger.format": `validate:"omitempty,oneof=console json"`,
+	"logger.level":  `validate:"omitempty,oneof=debug info warn error"`,
+}
+
 // ConfigCommand convert yaml to struct command
 func ConfigCommand() *cobra.Command {
 	var (
@@ -22,6 +30,7 @@ func ConfigCommand() *cobra.Command {
 			Format:    "yaml",
 			Tags:      "json",
 			SubStruct: true,
+			FieldTags: configValidateTags,
 		}
 		serverDir = ""
 		outPath   string // output directory
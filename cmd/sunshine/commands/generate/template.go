@@ -155,7 +155,7 @@ fi`
           readinessProbe:
             httpGet:
               port: http-port
-              path: /health
+              path: /health/ready
             initialDelaySeconds: 10
             timeoutSeconds: 2
             periodSeconds: 10
@@ -164,7 +164,7 @@ fi`
           livenessProbe:
             httpGet:
               port: http-port
-              path: /health`
+              path: /health/live`
 
 	k8sDeploymentFileGrpcCode = `
           ports:
@@ -205,18 +205,38 @@ import (
 	"github.com/18721889353/sunshine/pkg/conf"
 )
 
-var config *Config
+var loader = conf.NewLoader(&Config{})
 
 func Init(configFile string, fs ...func()) error {
-	config = &Config{}
-	return conf.Parse(configFile, config, fs...)
+	loader.Close()
+	loader = conf.NewLoader(&Config{}, conf.WithReloads(fs...))
+	err := loader.LoadFile(configFile)
+	if err != nil {
+		return err
+	}
+	if len(fs) > 0 {
+		return loader.Watch()
+	}
+	return nil
+}
+
+// InitWithSources load the configuration from sources, e.g. configuration center, and watch the changes
+func InitWithSources(sources []conf.Source, opts ...conf.SourceOption) error {
+	loader.Close()
+	loader = conf.NewLoader(&Config{}, opts...)
+	err := loader.Load(sources...)
+	if err != nil {
+		return err
+	}
+	return loader.Watch()
 }
 
 func Show(hiddenFields ...string) string {
-	return conf.Show(config, hiddenFields...)
+	return conf.Show(loader.Get(), hiddenFields...)
 }
 
 func Get() *Config {
+	config, _ := loader.Get().(*Config)
 	if config == nil {
 		panic("config is nil, please call config.Init() first")
 	}
@@ -224,7 +244,16 @@ func Get() *Config {
 }
 
 func Set(conf *Config) {
-	config = conf
+	loader.Set(conf)
+}
+
+// Override modify the configuration, e.g. by command line flags, the modification is kept after reloading
+func Override(fn func(c *Config)) {
+	loader.Override(func(obj interface{}) {
+		if c, ok := obj.(*Config); ok {
+			fn(c)
+		}
+	})
 }
 `
 
@@ -237,9 +266,12 @@ import (
 )
 
 func NewCenter(configFile string) (*Center, error) {
-	nacosConf := &Center{}
-	err := conf.Parse(configFile, nacosConf)
-	return nacosConf, err
+	loader := conf.NewLoader(&Center{})
+	err := loader.LoadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	return loader.Get().(*Center), nil
 }
 `
 
@@ -377,6 +409,9 @@ grpc:
   port: 8282                # listen port
   httpPort: 8283            # profile and metrics ports
   enableToken: false        # whether to enable server-side token authentication, default appID=grpc, appKey=123456
+  enableReflection: false   # whether to register grpc reflection service, used by tools such as grpcurl to list and call services
+  enableChannelz: false     # whether to register grpc channelz service, used for debugging connections and calls
+  enableAdmin: false        # whether to enable admin endpoints on httpPort: /admin/services, /admin/interceptors, /admin/config, /admin/connections, /admin/loglevel
   # serverSecure parameter setting
   # if type="", it means no secure connection, no need to fill in any parameters
   # if type="one-way", it means server-side certification, only the fields 'certFile' and 'keyFile' should be filled in
@@ -394,8 +429,20 @@ grpcClient:
     host: "127.0.0.1"            # grpc service address, used for direct connection
     port: 8282                   # grpc service port
     timeout: 0                   # request timeout, unit(second), if 0 means not set, if greater than 0 means set timeout, valid only for unary grpc type
-    registryDiscoveryType: ""    # registration and discovery types: consul, etcd, nacos, if empty, connecting to server using host and port
+    registryDiscoveryType: ""    # registration and discovery types: consul, etcd, nacos, file, dns, if empty, connecting to server using host and port
     enableLoadBalance: true      # whether to turn on the load balancer
+    # per-method policies, method is the full method name, e.g. /api.serverNameExample.v1.UserExample/GetByID,
+    # /api.serverNameExample.v1.UserExample/* matches all methods of the service, * matches all methods, valid only for unary grpc type
+    methodPolicies:
+      - method: "*"
+        timeout: 0                 # request timeout, unit(millisecond), if 0 means using the timeout above
+        retryTimes: 0              # number of retries, if 0 means no retry, retries are stopped when about 1 in 10 requests fails
+        retryCodes: ["UNAVAILABLE"] # grpc codes triggering a retry
+        retryBackoff: 100          # backoff of the first retry, unit(millisecond), doubled each retry with random jitter
+        retryMaxBackoff: 1000      # max backoff, unit(millisecond)
+        hedgingTimes: 0            # number of hedged requests, if 0 means no hedging, valid only for idempotent methods, retry is ignored if set
+        hedgingDelay: 0            # delay before sending each hedged request, unit(millisecond)
+        breaker: false             # whether to turn on circuit breaker per method and instance
     # clientSecure parameter setting
     # if type="", it means no secure connection, no need to fill in any parameters
     # if type="one-way", it means server-side certification, only the fields 'serverName' and 'certFile' should be filled in
@@ -423,8 +470,20 @@ grpcClient:
     host: "127.0.0.1"            # grpc service address, used for direct connection
     port: 8282                   # grpc service port
     timeout: 0                   # request timeout, unit(second), if 0 means not set, if greater than 0 means set timeout, valid only for unary grpc type
-    registryDiscoveryType: ""    # registration and discovery types: consul, etcd, nacos, if empty, connecting to server using host and port
+    registryDiscoveryType: ""    # registration and discovery types: consul, etcd, nacos, file, dns, if empty, connecting to server using host and port
     enableLoadBalance: true      # whether to turn on the load balancer
+    # per-method policies, method is the full method name, e.g. /api.serverNameExample.v1.UserExample/GetByID,
+    # /api.serverNameExample.v1.UserExample/* matches all methods of the service, * matches all methods, valid only for unary grpc type
+    methodPolicies:
+      - method: "*"
+        timeout: 0                 # request timeout, unit(millisecond), if 0 means using the timeout above
+        retryTimes: 0              # number of retries, if 0 means no retry, retries are stopped when about 1 in 10 requests fails
+        retryCodes: ["UNAVAILABLE"] # grpc codes triggering a retry
+        retryBackoff: 100          # backoff of the first retry, unit(millisecond), doubled each retry with random jitter
+        retryMaxBackoff: 1000      # max backoff, unit(millisecond)
+        hedgingTimes: 0            # number of hedged requests, if 0 means no hedging, valid only for idempotent methods, retry is ignored if set
+        hedgingDelay: 0            # delay before sending each hedged request, unit(millisecond)
+        breaker: false             # whether to turn on circuit breaker per method and instance
     # clientSecure parameter setting
     # if type="", it means no secure connection, no need to fill in any parameters
     # if type="one-way", it means server-side certification, only the fields 'serverName' and 'certFile' should be filled in
@@ -451,6 +510,9 @@ grpc:
   port: 8282                # listen port
   httpPort: 8283            # profile and metrics ports
   enableToken: false        # whether to enable server-side token authentication, default appID=grpc, appKey=123456
+  enableReflection: false   # whether to register grpc reflection service, used by tools such as grpcurl to list and call services
+  enableChannelz: false     # whether to register grpc channelz service, used for debugging connections and calls
+  enableAdmin: false        # whether to enable admin endpoints on httpPort: /admin/services, /admin/interceptors, /admin/config, /admin/connections, /admin/loglevel
   # serverSecure parameter setting
   # if type="", it means no secure connection, no need to fill in any parameters
   # if type="one-way", it means server-side certification, only the fields 'certFile' and 'keyFile' should be filled in
@@ -468,8 +530,20 @@ grpcClient:
     host: "127.0.0.1"            # grpc service address, used for direct connection
     port: 8282                   # grpc service port
     timeout: 0                   # request timeout, unit(second), if 0 means not set, if greater than 0 means set timeout, valid only for unary grpc type
-    registryDiscoveryType: ""    # registration and discovery types: consul, etcd, nacos, if empty, connecting to server using host and port
+    registryDiscoveryType: ""    # registration and discovery types: consul, etcd, nacos, file, dns, if empty, connecting to server using host and port
     enableLoadBalance: true      # whether to turn on the load balancer
+    # per-method policies, method is the full method name, e.g. /api.serverNameExample.v1.UserExample/GetByID,
+    # /api.serverNameExample.v1.UserExample/* matches all methods of the service, * matches all methods, valid only for unary grpc type
+    methodPolicies:
+      - method: "*"
+        timeout: 0                 # request timeout, unit(millisecond), if 0 means using the timeout above
+        retryTimes: 0              # number of retries, if 0 means no retry, retries are stopped when about 1 in 10 requests fails
+        retryCodes: ["UNAVAILABLE"] # grpc codes triggering a retry
+        retryBackoff: 100          # backoff of the first retry, unit(millisecond), doubled each retry with random jitter
+        retryMaxBackoff: 1000      # max backoff, unit(millisecond)
+        hedgingTimes: 0            # number of hedged requests, if 0 means no hedging, valid only for idempotent methods, retry is ignored if set
+        hedgingDelay: 0            # delay before sending each hedged request, unit(millisecond)
+        breaker: false             # whether to turn on circuit breaker per method and instance
     # clientSecure parameter setting
     # if type="", it means no secure connection, no need to fill in any parameters
     # if type="one-way", it means server-side certification, only the fields 'serverName' and 'certFile' should be filled in
@@ -26,6 +26,7 @@ func PatchCommand() *cobra.Command {
 		patch.CopyGOModCommand(),
 		patch.ModifyDuplicateNumCommand(),
 		patch.ModifyDuplicateErrCodeCommand(),
+		patch.ExportErrCodeCommand(),
 		patch.AdaptMonoRepoCommand(),
 		patch.ModifyProtoPackageCommand(),
 	)
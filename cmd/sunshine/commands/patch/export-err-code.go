@@ -0,0 +1,138 @@
+package patch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// ExportErrCodeCommand export error codes
+func ExportErrCodeCommand() *cobra.Command {
+	var (
+		serverDir string
+		ecodeDir  string
+		format    string
+		outFile   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export-err-code",
+		Short: "Export error codes as markdown, json or openapi components",
+		Long: color.HiBlackString(`export the error codes registered by the service as markdown, json or openapi components,
+it runs the error codes package of service, duplicate error codes will cause failure.
+
+Examples:
+  # export error codes as markdown to stdout
+  sunshine patch export-err-code --format=markdown
+
+  # export error codes as openapi components to file
+  sunshine patch export-err-code --dir=./yourServerDir --format=openapi --out=docs/error_codes.json
+`),
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch format {
+			case "json", "markdown", "md", "openapi":
+			default:
+				return fmt.Errorf("unsupported format '%s', only supports json, markdown and openapi", format)
+			}
+
+			data, err := exportErrCodes(serverDir, ecodeDir, format)
+			if err != nil {
+				return err
+			}
+
+			if outFile == "" {
+				fmt.Print(string(data))
+				return nil
+			}
+			if dir := filepath.Dir(outFile); dir != "." {
+				if err = os.MkdirAll(dir, 0766); err != nil {
+					return err
+				}
+			}
+			if err = os.WriteFile(outFile, data, 0666); err != nil {
+				return err
+			}
+			fmt.Printf("export error codes to %s successfully.\n", outFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&serverDir, "dir", "d", ".", "server directory")
+	cmd.Flags().StringVarP(&ecodeDir, "ecode-dir", "e", "internal/ecode", "directory of error codes package, relative to server directory")
+	cmd.Flags().StringVarP(&format, "format", "f", "markdown", "export format, json, markdown or openapi")
+	cmd.Flags().StringVarP(&outFile, "out", "o", "", "output file, default is stdout")
+
+	return cmd
+}
+
+const exportErrCodeMainTmpl = `// Code generated by sunshine patch export-err-code. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/18721889353/sunshine/pkg/errcode"
+
+	_ "%s"
+)
+
+func main() {
+	if err := errcode.ExportCodes(os.Stdout, %q); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`
+
+// the registry of error codes is filled at init of the error codes package, so generate a temporary
+// main package in the server directory that imports it and exports the registry.
+func exportErrCodes(serverDir string, ecodeDir string, format string) ([]byte, error) {
+	ecodePkg, err := runGoCommand(serverDir, "list", "./"+filepath.ToSlash(filepath.Clean(ecodeDir)))
+	if err != nil {
+		return nil, fmt.Errorf("get error codes package error: %v", err)
+	}
+
+	tmpDir, err := os.MkdirTemp(serverDir, "export_err_code_")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir) //nolint
+
+	mainFile := filepath.Join(tmpDir, "main.go")
+	content := fmt.Sprintf(exportErrCodeMainTmpl, strings.TrimSpace(string(ecodePkg)), format)
+	if err = os.WriteFile(mainFile, []byte(content), 0666); err != nil {
+		return nil, err
+	}
+
+	data, err := runGoCommand(serverDir, "run", "./"+filepath.Base(tmpDir))
+	if err != nil {
+		return nil, fmt.Errorf("export error codes error: %v", err)
+	}
+	return data, nil
+}
+
+func runGoCommand(dir string, args ...string) ([]byte, error) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
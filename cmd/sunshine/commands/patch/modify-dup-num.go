@@ -63,8 +63,8 @@ type coreInfo struct {
 var (
 	httpNumMark = "errcode.HCode"
 	grpcNumMark = "errcode.RCode"
-	httpPattern = `errcode\.HCode\(([^)]+)\)`
-	grpcPattern = `errcode\.RCode\(([^)]+)\)`
+	httpPattern = `errcode\.HCode\(([^),]+)[,)]`
+	grpcPattern = `errcode\.RCode\(([^),]+)[,)]`
 )
 
 func getVariableName(data []byte, pattern string) string {
@@ -54,7 +54,7 @@ func NewCacheNameExampleCache(cacheType *model.CacheType) CacheNameExampleCache
 	switch cType {
 	case "redis":
 		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, newObject)
-		return &cacheNameExampleCache{cache: c}
+		return &cacheNameExampleCache{cache: model.WrapCache(c)}
 	}
 
 	panic(fmt.Sprintf("unsupported cache type='%s'", cacheType.CType))
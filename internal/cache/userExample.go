@@ -51,7 +51,7 @@ func NewUserExampleCache(cacheType *model.CacheType) UserExampleCache {
 		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
 			return &model.UserExample{}
 		})
-		return &userExampleCache{cache: c}
+		return &userExampleCache{cache: model.WrapCache(c)}
 	}
 
 	return nil // no cache
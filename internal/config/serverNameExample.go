@@ -6,18 +6,38 @@ import (
 	"github.com/18721889353/sunshine/pkg/conf"
 )
 
-var config *Config
+var loader = conf.NewLoader(&Config{})
 
 func Init(configFile string, fs ...func()) error {
-	config = &Config{}
-	return conf.Parse(configFile, config, fs...)
+	loader.Close()
+	loader = conf.NewLoader(&Config{}, conf.WithReloads(fs...))
+	err := loader.LoadFile(configFile)
+	if err != nil {
+		return err
+	}
+	if len(fs) > 0 {
+		return loader.Watch()
+	}
+	return nil
+}
+
+// InitWithSources load the configuration from sources, e.g. configuration center, and watch the changes
+func InitWithSources(sources []conf.Source, opts ...conf.SourceOption) error {
+	loader.Close()
+	loader = conf.NewLoader(&Config{}, opts...)
+	err := loader.Load(sources...)
+	if err != nil {
+		return err
+	}
+	return loader.Watch()
 }
 
 func Show(hiddenFields ...string) string {
-	return conf.Show(config, hiddenFields...)
+	return conf.Show(loader.Get(), hiddenFields...)
 }
 
 func Get() *Config {
+	config, _ := loader.Get().(*Config)
 	if config == nil {
 		panic("config is nil, please call config.Init() first")
 	}
@@ -25,30 +45,60 @@ func Get() *Config {
 }
 
 func Set(conf *Config) {
-	config = conf
+	loader.Set(conf)
+}
+
+// Override modify the configuration, e.g. by command line flags, the modification is kept after reloading
+func Override(fn func(c *Config)) {
+	loader.Override(func(obj interface{}) {
+		if c, ok := obj.(*Config); ok {
+			fn(c)
+		}
+	})
 }
 
 type Config struct {
 	App        App          `yaml:"app" json:"app"`
 	Database   Database     `yaml:"database" json:"database"`
 	Etcd       Etcd         `yaml:"etcd" json:"etcd"`
+	FileRd     FileRd       `yaml:"fileRd" json:"fileRd"`
 	Grpc       Grpc         `yaml:"grpc" json:"grpc"`
 	GrpcClient []GrpcClient `yaml:"grpcClient" json:"grpcClient"`
 	HTTP       HTTP         `yaml:"http" json:"http"`
-	Jaeger     Jaeger       `yaml:"jaeger" json:"jaeger"`
 	Jwt        Jwt          `yaml:"jwt" json:"jwt"`
 	Logger     Logger       `yaml:"logger" json:"logger"`
 	Redis      Redis        `yaml:"redis" json:"redis"`
 	Sign       Sign         `yaml:"sign" json:"sign"`
+	Tracing    Tracing      `yaml:"tracing" json:"tracing"`
 }
 
 type Etcd struct {
 	Addrs []string `yaml:"addrs" json:"addrs"`
 }
 
-type Jaeger struct {
-	AgentHost string `yaml:"agentHost" json:"agentHost"`
-	AgentPort int    `yaml:"agentPort" json:"agentPort"`
+type FileRd struct {
+	Path string `yaml:"path" json:"path"`
+}
+
+type Tracing struct {
+	BatchTimeout       int                   `yaml:"batchTimeout" json:"batchTimeout"`
+	Endpoint           string                `yaml:"endpoint" json:"endpoint"`
+	ExportTimeout      int                   `yaml:"exportTimeout" json:"exportTimeout"`
+	Exporter           string                `yaml:"exporter" json:"exporter"`
+	Headers            map[string]string     `yaml:"headers" json:"headers"`
+	Insecure           bool                  `yaml:"insecure" json:"insecure"`
+	MaxExportBatchSize int                   `yaml:"maxExportBatchSize" json:"maxExportBatchSize"`
+	MaxQueueSize       int                   `yaml:"maxQueueSize" json:"maxQueueSize"`
+	ParentBased        bool                  `yaml:"parentBased" json:"parentBased"`
+	Propagators        []string              `yaml:"propagators" json:"propagators"`
+	Protocol           string                `yaml:"protocol" json:"protocol"`
+	SamplingRate       float64               `yaml:"samplingRate" json:"samplingRate"`
+	SamplingRules      []TracingSamplingRule `yaml:"samplingRules" json:"samplingRules"`
+}
+
+type TracingSamplingRule struct {
+	Name string  `yaml:"name" json:"name"`
+	Rate float64 `yaml:"rate" json:"rate"`
 }
 
 type ClientToken struct {
@@ -73,35 +123,47 @@ type ServerSecure struct {
 }
 
 type App struct {
-	CacheType             string  `yaml:"cacheType" json:"cacheType"`
-	EnableCircuitBreaker  bool    `yaml:"enableCircuitBreaker" json:"enableCircuitBreaker"`
-	EnableHTTPProfile     bool    `yaml:"enableHTTPProfile" json:"enableHTTPProfile"`
-	EnableLimit           bool    `yaml:"enableLimit" json:"enableLimit"`
-	EnableMetrics         bool    `yaml:"enableMetrics" json:"enableMetrics"`
-	EnableStat            bool    `yaml:"enableStat" json:"enableStat"`
-	EnableTrace           bool    `yaml:"enableTrace" json:"enableTrace"`
-	Env                   string  `yaml:"env" json:"env"`
-	Host                  string  `yaml:"host" json:"host"`
-	MachineID             int     `yaml:"machineId" json:"machineId"`
-	Name                  string  `yaml:"name" json:"name"`
-	OpenHTTP              bool    `yaml:"openHttp" json:"openHttp"`
-	OpenJwt               bool    `yaml:"openJwt" json:"openJwt"`
-	OpenSign              bool    `yaml:"openSign" json:"openSign"`
-	OpenXSS               bool    `yaml:"openXSS" json:"openXSS"`
-	RegistryDiscoveryType string  `yaml:"registryDiscoveryType" json:"registryDiscoveryType"`
-	TracingSamplingRate   float64 `yaml:"tracingSamplingRate" json:"tracingSamplingRate"`
-	Version               string  `yaml:"version" json:"version"`
+	CacheType             string `yaml:"cacheType" json:"cacheType" validate:"omitempty,oneof=memory redis"`
+	EnableCircuitBreaker  bool   `yaml:"enableCircuitBreaker" json:"enableCircuitBreaker"`
+	EnableHTTPProfile     bool   `yaml:"enableHTTPProfile" json:"enableHTTPProfile"`
+	EnableLimit           bool   `yaml:"enableLimit" json:"enableLimit"`
+	EnableMetrics         bool   `yaml:"enableMetrics" json:"enableMetrics"`
+	EnableStat            bool   `yaml:"enableStat" json:"enableStat"`
+	EnableTrace           bool   `yaml:"enableTrace" json:"enableTrace"`
+	Env                   string `yaml:"env" json:"env"`
+	Host                  string `yaml:"host" json:"host"`
+	MachineID             int    `yaml:"machineId" json:"machineId"`
+	Name                  string `yaml:"name" json:"name" validate:"required"`
+	OpenHTTP              bool   `yaml:"openHttp" json:"openHttp"`
+	OpenJwt               bool   `yaml:"openJwt" json:"openJwt"`
+	OpenSign              bool   `yaml:"openSign" json:"openSign"`
+	OpenXSS               bool   `yaml:"openXSS" json:"openXSS"`
+	RegistryDiscoveryType string `yaml:"registryDiscoveryType" json:"registryDiscoveryType"`
+	Version               string `yaml:"version" json:"version"`
 }
 
 type GrpcClient struct {
-	ClientSecure          ClientSecure `yaml:"clientSecure" json:"clientSecure"`
-	ClientToken           ClientToken  `yaml:"clientToken" json:"clientToken"`
-	EnableLoadBalance     bool         `yaml:"enableLoadBalance" json:"enableLoadBalance"`
-	Host                  string       `yaml:"host" json:"host"`
-	Name                  string       `yaml:"name" json:"name"`
-	Port                  int          `yaml:"port" json:"port"`
-	RegistryDiscoveryType string       `yaml:"registryDiscoveryType" json:"registryDiscoveryType"`
-	Timeout               int          `yaml:"timeout" json:"timeout"`
+	ClientSecure          ClientSecure   `yaml:"clientSecure" json:"clientSecure"`
+	ClientToken           ClientToken    `yaml:"clientToken" json:"clientToken"`
+	EnableLoadBalance     bool           `yaml:"enableLoadBalance" json:"enableLoadBalance"`
+	Host                  string         `yaml:"host" json:"host"`
+	MethodPolicies        []MethodPolicy `yaml:"methodPolicies" json:"methodPolicies"`
+	Name                  string         `yaml:"name" json:"name"`
+	Port                  int            `yaml:"port" json:"port"`
+	RegistryDiscoveryType string         `yaml:"registryDiscoveryType" json:"registryDiscoveryType"`
+	Timeout               int            `yaml:"timeout" json:"timeout"`
+}
+
+type MethodPolicy struct {
+	Breaker         bool     `yaml:"breaker" json:"breaker"`
+	HedgingDelay    int      `yaml:"hedgingDelay" json:"hedgingDelay"`
+	HedgingTimes    int      `yaml:"hedgingTimes" json:"hedgingTimes"`
+	Method          string   `yaml:"method" json:"method"`
+	RetryBackoff    int      `yaml:"retryBackoff" json:"retryBackoff"`
+	RetryCodes      []string `yaml:"retryCodes" json:"retryCodes"`
+	RetryMaxBackoff int      `yaml:"retryMaxBackoff" json:"retryMaxBackoff"`
+	RetryTimes      int      `yaml:"retryTimes" json:"retryTimes"`
+	Timeout         int      `yaml:"timeout" json:"timeout"`
 }
 
 type Sqlite struct {
@@ -150,10 +212,13 @@ type Mongodb struct {
 }
 
 type Grpc struct {
-	EnableToken  bool         `yaml:"enableToken" json:"enableToken"`
-	HTTPPort     int          `yaml:"httpPort" json:"httpPort"`
-	Port         int          `yaml:"port" json:"port"`
-	ServerSecure ServerSecure `yaml:"serverSecure" json:"serverSecure"`
+	EnableAdmin      bool         `yaml:"enableAdmin" json:"enableAdmin"`
+	EnableChannelz   bool         `yaml:"enableChannelz" json:"enableChannelz"`
+	EnableReflection bool         `yaml:"enableReflection" json:"enableReflection"`
+	EnableToken      bool         `yaml:"enableToken" json:"enableToken"`
+	HTTPPort         int          `yaml:"httpPort" json:"httpPort"`
+	Port             int          `yaml:"port" json:"port"`
+	ServerSecure     ServerSecure `yaml:"serverSecure" json:"serverSecure"`
 }
 
 type Jwt struct {
@@ -173,11 +238,42 @@ type LogFileConfig struct {
 }
 
 type Logger struct {
-	Format        string        `yaml:"format" json:"format"`
-	IsSave        bool          `yaml:"isSave" json:"isSave"`
-	Level         string        `yaml:"level" json:"level"`
-	LogFileConfig LogFileConfig `yaml:"logFileConfig" json:"logFileConfig"`
-	MaxLen        int           `yaml:"maxLen" json:"maxLen"`
+	Format        string            `yaml:"format" json:"format" validate:"omitempty,oneof=console json"`
+	IsSave        bool              `yaml:"isSave" json:"isSave"`
+	Level         string            `yaml:"level" json:"level" validate:"omitempty,oneof=debug info warn error"`
+	LogFileConfig LogFileConfig     `yaml:"logFileConfig" json:"logFileConfig"`
+	MaxLen        int               `yaml:"maxLen" json:"maxLen"`
+	NamedLevels   map[string]string `yaml:"namedLevels" json:"namedLevels"`
+	Redact        LogRedact         `yaml:"redact" json:"redact"`
+	Sampling      Sampling          `yaml:"sampling" json:"sampling"`
+	Sinks         []LogSink         `yaml:"sinks" json:"sinks"`
+}
+
+type LogRedact struct {
+	Fields   []string `yaml:"fields" json:"fields"`
+	Paths    []string `yaml:"paths" json:"paths"`
+	Patterns []string `yaml:"patterns" json:"patterns"`
+}
+
+type LogSink struct {
+	Addr          string            `yaml:"addr" json:"addr"`
+	Addrs         []string          `yaml:"addrs" json:"addrs"`
+	BatchSize     int               `yaml:"batchSize" json:"batchSize"`
+	BufferSize    int               `yaml:"bufferSize" json:"bufferSize"`
+	FlushInterval int               `yaml:"flushInterval" json:"flushInterval"`
+	Headers       map[string]string `yaml:"headers" json:"headers"`
+	Index         string            `yaml:"index" json:"index"`
+	Labels        map[string]string `yaml:"labels" json:"labels"`
+	Network       string            `yaml:"network" json:"network"`
+	Tag           string            `yaml:"tag" json:"tag"`
+	Topic         string            `yaml:"topic" json:"topic"`
+	Type          string            `yaml:"type" json:"type"`
+	URL           string            `yaml:"url" json:"url"`
+}
+
+type Sampling struct {
+	First      int `yaml:"first" json:"first"`
+	Thereafter int `yaml:"thereafter" json:"thereafter"`
 }
 
 type HTTP struct {
@@ -187,4 +283,4 @@ type HTTP struct {
 
 type Sign struct {
 	SignKey string `yaml:"signKey" json:"signKey"`
-}
\ No newline at end of file
+}
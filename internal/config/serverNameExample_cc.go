@@ -7,13 +7,31 @@ import (
 )
 
 func NewCenter(configFile string) (*Center, error) {
-	nacosConf := &Center{}
-	err := conf.Parse(configFile, nacosConf)
-	return nacosConf, err
+	loader := conf.NewLoader(&Center{})
+	err := loader.LoadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	return loader.Get().(*Center), nil
 }
 
 type Center struct {
-	Nacos Nacos `yaml:"nacos" json:"nacos"`
+	Consul   CenterConsul `yaml:"consul" json:"consul"`
+	Etcd     CenterEtcd   `yaml:"etcd" json:"etcd"`
+	Nacos    Nacos        `yaml:"nacos" json:"nacos"`
+	Provider string       `yaml:"provider" json:"provider"`
+}
+
+type CenterConsul struct {
+	Addr   string `yaml:"addr" json:"addr"`
+	Format string `yaml:"format" json:"format"`
+	Key    string `yaml:"key" json:"key"`
+}
+
+type CenterEtcd struct {
+	Addrs  []string `yaml:"addrs" json:"addrs"`
+	Format string   `yaml:"format" json:"format"`
+	Key    string   `yaml:"key" json:"key"`
 }
 
 type Nacos struct {
@@ -25,4 +43,4 @@ type Nacos struct {
 	NamespaceID string `yaml:"namespaceID" json:"namespaceID"`
 	Port        int    `yaml:"port" json:"port"`
 	Scheme      string `yaml:"scheme" json:"scheme"`
-}
\ No newline at end of file
+}
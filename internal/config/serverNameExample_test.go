@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/18721889353/sunshine/pkg/conf"
 	"github.com/18721889353/sunshine/pkg/gofile"
 
 	"github.com/18721889353/sunshine/configs"
@@ -43,3 +44,27 @@ func TestInitNacos(t *testing.T) {
 		assert.Error(t, err)
 	}
 }
+
+func TestInitWithSources(t *testing.T) {
+	configFile := configs.Path("serverNameExample.yml")
+	err := InitWithSources([]conf.Source{conf.NewFileSource(configFile)})
+	defer loader.Close()
+	if gofile.IsExists(configFile) {
+		assert.NoError(t, err)
+		assert.NotEmpty(t, Get().App.Name)
+	} else {
+		assert.Error(t, err)
+	}
+}
+
+func TestOverride(t *testing.T) {
+	err := Init(configs.Path("serverNameExample.yml"))
+	if err != nil {
+		t.Skip(err)
+	}
+	c := Get()
+	version := c.App.Version
+	Override(func(c *Config) { c.App.Version = "v9.9.9" })
+	assert.Equal(t, "v9.9.9", Get().App.Version)
+	assert.Equal(t, version, c.App.Version)
+}
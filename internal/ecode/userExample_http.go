@@ -9,7 +9,7 @@ import (
 var (
 	userExampleNO       = 1
 	userExampleName     = "userExample"
-	userExampleBaseCode = errcode.HCode(userExampleNO)
+	userExampleBaseCode = errcode.HCode(userExampleNO, userExampleName)
 
 	ErrCreateUserExample     = errcode.NewError(userExampleBaseCode+1, "failed to create "+userExampleName)
 	ErrDeleteByIDUserExample = errcode.NewError(userExampleBaseCode+2, "failed to delete "+userExampleName)
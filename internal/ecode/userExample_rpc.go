@@ -9,7 +9,7 @@ import (
 var (
 	_userExampleNO       = 2
 	_userExampleName     = "userExample"
-	_userExampleBaseCode = errcode.RCode(_userExampleNO)
+	_userExampleBaseCode = errcode.RCode(_userExampleNO, _userExampleName)
 
 	StatusCreateUserExample     = errcode.NewRPCStatus(_userExampleBaseCode+1, "failed to create "+_userExampleName)
 	StatusDeleteByIDUserExample = errcode.NewRPCStatus(_userExampleBaseCode+2, "failed to delete "+_userExampleName)
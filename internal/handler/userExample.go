@@ -58,7 +58,7 @@ func (h *userExampleHandler) Create(c *gin.Context) {
 	err := c.ShouldBindJSON(form)
 	if err != nil {
 		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
-		response.Error(c, ecode.InvalidParams)
+		response.ParamError(c, err)
 		return
 	}
 
@@ -131,7 +131,7 @@ func (h *userExampleHandler) UpdateByID(c *gin.Context) {
 	err := c.ShouldBindJSON(form)
 	if err != nil {
 		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
-		response.Error(c, ecode.InvalidParams)
+		response.ParamError(c, err)
 		return
 	}
 	form.ID = id
@@ -211,7 +211,7 @@ func (h *userExampleHandler) List(c *gin.Context) {
 	err := c.ShouldBindJSON(form)
 	if err != nil {
 		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
-		response.Error(c, ecode.InvalidParams)
+		response.ParamError(c, err)
 		return
 	}
 
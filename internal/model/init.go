@@ -11,9 +11,13 @@ import (
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 
+	"github.com/18721889353/sunshine/pkg/cache"
+	"github.com/18721889353/sunshine/pkg/dlock"
 	"github.com/18721889353/sunshine/pkg/ggorm"
 	"github.com/18721889353/sunshine/pkg/goredis"
+	"github.com/18721889353/sunshine/pkg/health"
 	"github.com/18721889353/sunshine/pkg/logger"
+	"github.com/18721889353/sunshine/pkg/metrics"
 	"github.com/18721889353/sunshine/pkg/tracer"
 	"github.com/18721889353/sunshine/pkg/utils"
 
@@ -40,6 +44,9 @@ var (
 
 	snowNode *snowflake.Node
 	once4    sync.Once
+
+	// trace the cache and lock operations, set by SetTracing
+	isTracing bool
 )
 
 // CacheType cache type
@@ -70,6 +77,32 @@ func GetCacheType() *CacheType {
 	return cacheType
 }
 
+// SetTracing set whether to trace the cache and lock operations created by WrapCache and NewLocker,
+// it is called by the init code after the configuration is loaded, default is false.
+func SetTracing(enable bool) {
+	isTracing = enable
+}
+
+// WrapCache wraps the cache with tracing if tracing is set, each cache operation is recorded in a span.
+func WrapCache(c cache.Cache) cache.Cache {
+	if isTracing {
+		return cache.NewTracingCache(c)
+	}
+	return c
+}
+
+// NewLocker create a distributed lock based on redis, the lock operations are traced if tracing is set.
+func NewLocker(key string) (dlock.Locker, error) {
+	locker, err := dlock.NewRedisLock(GetRedisCli(), key)
+	if err != nil {
+		return nil, err
+	}
+	if isTracing {
+		locker = dlock.NewTracingLocker(locker)
+	}
+	return locker, nil
+}
+
 // InitRedis connect redis
 func InitRedis() {
 	opts := []goredis.Option{
@@ -86,6 +119,10 @@ func InitRedis() {
 	if err != nil {
 		panic("goredis.Init error: " + err.Error())
 	}
+	health.AddReadiness("redis", health.RedisCheck(redisCli))
+	if config.Get().App.EnableMetrics {
+		_ = metrics.RegisterRedisPool("redis", redisCli)
+	}
 }
 
 // GetRedisCli get redis client
@@ -230,6 +267,12 @@ func GetDB() *gorm.DB {
 	if db == nil {
 		once1.Do(func() {
 			InitDB()
+			if sqlDB, err := db.DB(); err == nil {
+				health.AddReadiness("database", health.DBCheck(sqlDB))
+				if config.Get().App.EnableMetrics {
+					_ = metrics.RegisterDBStats(config.Get().Database.Driver, sqlDB)
+				}
+			}
 		})
 	}
 
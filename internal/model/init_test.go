@@ -5,11 +5,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 
 	"github.com/18721889353/sunshine/configs"
 	"github.com/18721889353/sunshine/internal/config"
+	"github.com/18721889353/sunshine/pkg/cache"
+	"github.com/18721889353/sunshine/pkg/encoding"
 	"github.com/18721889353/sunshine/pkg/utils"
 )
 
@@ -131,3 +134,12 @@ func TestGetCacheType(t *testing.T) {
 	ct = GetCacheType()
 	assert.NotNil(t, ct)
 }
+
+func TestWrapCache(t *testing.T) {
+	defer SetTracing(false)
+
+	c := cache.NewRedisCache(redis.NewClient(&redis.Options{}), "", encoding.JSONEncoding{}, nil)
+	assert.Equal(t, c, WrapCache(c))
+	SetTracing(true)
+	assert.NotEqual(t, c, WrapCache(c))
+}
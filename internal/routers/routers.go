@@ -17,6 +17,7 @@ import (
 	"github.com/18721889353/sunshine/pkg/gin/middleware/metrics"
 	"github.com/18721889353/sunshine/pkg/gin/prof"
 	"github.com/18721889353/sunshine/pkg/gin/validator"
+	"github.com/18721889353/sunshine/pkg/health"
 	"github.com/18721889353/sunshine/pkg/jwt"
 	"github.com/18721889353/sunshine/pkg/logger"
 
@@ -46,11 +47,21 @@ func NewRouter() *gin.Engine {
 	// request id middleware
 	r.Use(middleware.RequestID())
 
+	// trace middleware, before the logger middleware so that the logs carry trace id and span id
+	if config.Get().App.EnableTrace {
+		r.Use(middleware.Tracing(config.Get().App.Name))
+	}
+
 	// logger middleware, to print simple messages, replace middleware.Logging with middleware.SimpleLog
 	r.Use(middleware.Logging(
 		middleware.WithLog(logger.Get()),
 		middleware.WithRequestIDFromContext(),
 		middleware.WithIgnoreRoutes("/metrics"), // ignore path
+		middleware.WithRedactor(logger.NewRedactor( // mask the sensitive values in request and response
+			logger.WithRedactFields(config.Get().Logger.Redact.Fields...),
+			logger.WithRedactPaths(config.Get().Logger.Redact.Paths...),
+			logger.WithRedactPatterns(config.Get().Logger.Redact.Patterns...),
+		)),
 	))
 
 	// init jwt middleware
@@ -78,11 +89,6 @@ func NewRouter() *gin.Engine {
 		r.Use(middleware.CircuitBreaker())
 	}
 
-	// trace middleware
-	if config.Get().App.EnableTrace {
-		r.Use(middleware.Tracing(config.Get().App.Name))
-	}
-
 	// profile performance analysis
 	if config.Get().App.EnableHTTPProfile {
 		prof.Register(r, prof.WithIOWaitTime())
@@ -91,11 +97,20 @@ func NewRouter() *gin.Engine {
 	// validator
 	binding.Validator = validator.Init()
 
-	r.GET("/health", handlerfunc.CheckHealth)
+	r.GET("/health", gin.WrapF(health.Default().LiveHandler())) // compatible with the previous health check
+	r.GET("/health/live", gin.WrapF(health.Default().LiveHandler()))
+	r.GET("/health/ready", gin.WrapF(health.Default().ReadyHandler()))
 	r.GET("/ping", handlerfunc.Ping)
 	r.GET("/codes", handlerfunc.ListCodes)
 	r.GET("/config", gin.WrapF(errcode.ShowConfig([]byte(config.Show()))))
 
+	// change the log level at runtime, requires jwt authorization, e.g. PUT {"level":"debug"} or {"name":"kafka","level":"debug"},
+	// DELETE ?name=kafka resets the level of named logger
+	adminGroup := r.Group("/admin", middleware.Auth())
+	adminGroup.GET("/loglevel", gin.WrapF(logger.LevelHandler()))
+	adminGroup.PUT("/loglevel", gin.WrapF(logger.LevelHandler()))
+	adminGroup.DELETE("/loglevel", gin.WrapF(logger.LevelHandler()))
+
 	// register swagger routes, generate code via swag init
 	docs.SwaggerInfo.BasePath = ""
 	// access path /swagger/index.html
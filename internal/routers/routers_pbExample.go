@@ -20,6 +20,7 @@ import (
 	"github.com/18721889353/sunshine/pkg/gin/prof"
 	"github.com/18721889353/sunshine/pkg/gin/swagger"
 	"github.com/18721889353/sunshine/pkg/gin/validator"
+	"github.com/18721889353/sunshine/pkg/health"
 	"github.com/18721889353/sunshine/pkg/logger"
 
 	"github.com/18721889353/sunshine/docs"
@@ -53,6 +54,11 @@ func NewRouter_pbExample() *gin.Engine { //nolint
 	// request id middleware
 	r.Use(middleware.RequestID(middleware.WithSnow(model.GetSnowNode())))
 
+	// trace middleware, before the logger middleware so that the logs carry trace id and span id
+	if config.Get().App.EnableTrace {
+		r.Use(middleware.Tracing(config.Get().App.Name))
+	}
+
 	// logger middleware, to print simple messages, replace middleware.Logging with middleware.SimpleLog
 	r.Use(middleware.Logging(
 		middleware.WithLog(logger.Get()),
@@ -60,6 +66,11 @@ func NewRouter_pbExample() *gin.Engine { //nolint
 		middleware.WithRequestIDFromContext(),
 		middleware.WithLogFrom(config.Get().App.Name+strconv.Itoa(config.Get().App.MachineID)),
 		middleware.WithIgnoreRoutes("/metrics"), // ignore path
+		middleware.WithRedactor(logger.NewRedactor( // mask the sensitive values in request and response
+			logger.WithRedactFields(config.Get().Logger.Redact.Fields...),
+			logger.WithRedactPaths(config.Get().Logger.Redact.Paths...),
+			logger.WithRedactPatterns(config.Get().Logger.Redact.Patterns...),
+		)),
 	))
 	// 将签名添加为全局中间件
 	if config.Get().App.OpenSign {
@@ -92,11 +103,6 @@ func NewRouter_pbExample() *gin.Engine { //nolint
 		))
 	}
 
-	// trace middleware
-	if config.Get().App.EnableTrace {
-		r.Use(middleware.Tracing(config.Get().App.Name))
-	}
-
 	// profile performance analysis
 	if config.Get().App.EnableHTTPProfile {
 		prof.Register(r, prof.WithIOWaitTime())
@@ -105,11 +111,20 @@ func NewRouter_pbExample() *gin.Engine { //nolint
 	// validator
 	binding.Validator = validator.Init()
 
-	r.GET("/health", handlerfunc.CheckHealth)
+	r.GET("/health", gin.WrapF(health.Default().LiveHandler())) // compatible with the previous health check
+	r.GET("/health/live", gin.WrapF(health.Default().LiveHandler()))
+	r.GET("/health/ready", gin.WrapF(health.Default().ReadyHandler()))
 	r.GET("/ping", handlerfunc.Ping)
 	r.GET("/codes", handlerfunc.ListCodes)
 	r.GET("/config", gin.WrapF(errcode.ShowConfig([]byte(config.Show()))))
 
+	// change the log level at runtime, requires jwt authorization, e.g. PUT {"level":"debug"} or {"name":"kafka","level":"debug"},
+	// DELETE ?name=kafka resets the level of named logger
+	adminGroup := r.Group("/admin", middleware.Auth())
+	adminGroup.GET("/loglevel", gin.WrapF(logger.LevelHandler()))
+	adminGroup.PUT("/loglevel", gin.WrapF(logger.LevelHandler()))
+	adminGroup.DELETE("/loglevel", gin.WrapF(logger.LevelHandler()))
+
 	c := newMiddlewareConfig()
 
 	// set up all middlewares
@@ -16,7 +16,9 @@ import (
 	"github.com/18721889353/sunshine/pkg/etcdcli"
 	"github.com/18721889353/sunshine/pkg/grpc/grpccli"
 	"github.com/18721889353/sunshine/pkg/logger"
+	"github.com/18721889353/sunshine/pkg/servicerd/registry/dns"
 	"github.com/18721889353/sunshine/pkg/servicerd/registry/etcd"
+	"github.com/18721889353/sunshine/pkg/servicerd/registry/file"
 )
 
 var (
@@ -59,6 +61,38 @@ func NewServerNameExampleRPCConn() {
 		cliOptions = append(cliOptions, grpccli.WithTimeout(time.Second*time.Duration(grpcClientCfg.Timeout)))
 	}
 
+	// per-method policies of timeout, retry, hedging and circuit breaker
+	if len(grpcClientCfg.MethodPolicies) > 0 {
+		var policies []*interceptor.MethodPolicy
+		for _, p := range grpcClientCfg.MethodPolicies {
+			policy := &interceptor.MethodPolicy{
+				Method:  p.Method,
+				Timeout: time.Millisecond * time.Duration(p.Timeout),
+				Breaker: p.Breaker,
+			}
+			if p.RetryTimes > 0 {
+				retryCodes, err := interceptor.ParseCodes(p.RetryCodes...)
+				if err != nil {
+					panic(fmt.Sprintf("invalid retryCodes of method '%s': %v", p.Method, err))
+				}
+				policy.Retry = &interceptor.RetryPolicy{
+					Times:          uint(p.RetryTimes),
+					Codes:          retryCodes,
+					InitialBackoff: time.Millisecond * time.Duration(p.RetryBackoff),
+					MaxBackoff:     time.Millisecond * time.Duration(p.RetryMaxBackoff),
+				}
+			}
+			if p.HedgingTimes > 0 {
+				policy.Hedging = &interceptor.HedgingPolicy{
+					Times: uint(p.HedgingTimes),
+					Delay: time.Millisecond * time.Duration(p.HedgingDelay),
+				}
+			}
+			policies = append(policies, policy)
+		}
+		cliOptions = append(cliOptions, grpccli.WithMethodPolicies(policies))
+	}
+
 	// load balance
 	if grpcClientCfg.EnableLoadBalance {
 		cliOptions = append(cliOptions, grpccli.WithEnableLoadBalance())
@@ -120,6 +154,21 @@ func NewServerNameExampleRPCConn() {
 		//	iDiscovery := nacos.New(cli)
 		//	cliOptions = append(cliOptions, grpccli.WithDiscovery(iDiscovery))
 		//	isUseDiscover = true
+	// discovering services from the instances in local file
+	case "file":
+		endpoint = "discovery:///" + grpcClientCfg.Name // Connecting to grpc services by service name
+		iDiscovery, err := file.New(cfg.FileRd.Path)
+		if err != nil {
+			panic(fmt.Sprintf("file.New error: %v, path: %s", err, cfg.FileRd.Path))
+		}
+		cliOptions = append(cliOptions, grpccli.WithDiscovery(iDiscovery))
+		isUseDiscover = true
+	// discovering services using dns, host is a domain name, e.g. kubernetes headless service
+	case "dns":
+		endpoint = "discovery:///" + endpoint // Connecting to grpc services by domain name and port
+		iDiscovery := dns.New()
+		cliOptions = append(cliOptions, grpccli.WithDiscovery(iDiscovery))
+		isUseDiscover = true
 	}
 
 	if cfg.App.EnableTrace {
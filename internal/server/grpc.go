@@ -11,14 +11,18 @@ import (
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"google.golang.org/grpc"
+	channelzservice "google.golang.org/grpc/channelz/service"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 
 	"github.com/18721889353/sunshine/pkg/app"
 	"github.com/18721889353/sunshine/pkg/errcode"
+	"github.com/18721889353/sunshine/pkg/grpc/admin"
 	"github.com/18721889353/sunshine/pkg/grpc/gtls"
 	"github.com/18721889353/sunshine/pkg/grpc/interceptor"
 	"github.com/18721889353/sunshine/pkg/grpc/metrics"
+	"github.com/18721889353/sunshine/pkg/health"
 	"github.com/18721889353/sunshine/pkg/logger"
 	"github.com/18721889353/sunshine/pkg/prof"
 	"github.com/18721889353/sunshine/pkg/servicerd/registry"
@@ -46,31 +50,21 @@ type grpcServer struct {
 
 	iRegistry registry.Registry
 	instance  *registry.ServiceInstance
+	registrar *health.Registrar
+
+	// used by admin endpoints
+	connStats          *admin.ConnStats
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
 }
 
 // Start grpc service
 func (s *grpcServer) Start() error {
-	// registration Services
-	if s.iRegistry != nil {
-		ctx, _ := context.WithTimeout(context.Background(), 5*time.Second) //nolint
-		if err := s.iRegistry.Register(ctx, s.instance); err != nil {
+	// registration services, the instance is deregistered when readiness fails and registered again on recovery
+	if s.registrar != nil {
+		if err := s.registrar.Start(); err != nil {
 			return err
 		}
-		go func() {
-			ticker := time.NewTicker(15 * time.Second) // 每15秒检查一次
-			defer ticker.Stop()
-			for {
-				select {
-				case <-ticker.C:
-					ctx, _ := context.WithTimeout(context.Background(), 5*time.Second) //nolint
-					if err := s.iRegistry.Register(ctx, s.instance); err != nil {
-						//logger.Warn("s.iRegistry.Register error", logger.Err(err))
-					} else {
-						logger.Warn("s.iRegistry.Register")
-					}
-				}
-			}
-		}()
 	}
 
 	if s.registerMetricsMuxAndMethodFunc != nil {
@@ -87,7 +81,7 @@ func (s *grpcServer) Start() error {
 			Handler: s.mux,
 		}
 		go func() {
-			fmt.Printf("http address of pprof and metrics %s\n", addr)
+			fmt.Printf("http address of pprof, metrics and admin %s\n", addr)
 			if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 				panic("listen and serve error: " + err.Error())
 			}
@@ -103,14 +97,10 @@ func (s *grpcServer) Start() error {
 
 // Stop grpc service
 func (s *grpcServer) Stop() error {
-	if s.iRegistry != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		go func() {
-			_ = s.iRegistry.Deregister(ctx, s.instance)
-			cancel()
-		}()
-		<-ctx.Done()
+	if s.registrar != nil {
+		_ = s.registrar.Stop()
 	}
+	health.Default().Shutdown() // grpc health status changes to NOT_SERVING
 
 	s.server.GracefulStop()
 
@@ -167,12 +157,22 @@ func (s *grpcServer) unaryServerOptions() grpc.ServerOption {
 		interceptor.UnaryServerRequestID(),
 	}
 
+	// trace interceptor, before the logger interceptor so that the logs carry trace id and span id
+	if config.Get().App.EnableTrace {
+		unaryServerInterceptors = append(unaryServerInterceptors, interceptor.UnaryServerTracing())
+	}
+
 	// logger interceptor, to print simple messages, replace interceptor.UnaryServerLog with interceptor.UnaryServerSimpleLog
 	unaryServerInterceptors = append(unaryServerInterceptors, interceptor.UnaryServerLog(
 		logger.Get(),
 		interceptor.WithMaxLen(config.Get().Logger.MaxLen),
 		interceptor.WithLogFrom(config.Get().App.Name+strconv.Itoa(config.Get().App.MachineID)),
 		interceptor.WithReplaceGRPCLogger(),
+		interceptor.WithLogRedactor(logger.NewRedactor( // mask the sensitive values in request and response
+			logger.WithRedactFields(config.Get().Logger.Redact.Fields...),
+			logger.WithRedactPaths(config.Get().Logger.Redact.Paths...),
+			logger.WithRedactPatterns(config.Get().Logger.Redact.Patterns...),
+		)),
 	))
 
 	// token interceptor
@@ -218,11 +218,7 @@ func (s *grpcServer) unaryServerOptions() grpc.ServerOption {
 		))
 	}
 
-	// trace interceptor
-	if config.Get().App.EnableTrace {
-		unaryServerInterceptors = append(unaryServerInterceptors, interceptor.UnaryServerTracing())
-	}
-
+	s.unaryInterceptors = unaryServerInterceptors
 	return grpc_middleware.WithUnaryServerChain(unaryServerInterceptors...)
 }
 
@@ -284,6 +280,7 @@ func (s *grpcServer) streamServerOptions() grpc.ServerOption {
 		streamServerInterceptors = append(streamServerInterceptors, interceptor.StreamServerTracing())
 	}
 
+	s.streamInterceptors = streamServerInterceptors
 	return grpc_middleware.WithStreamServerChain(streamServerInterceptors...)
 }
 
@@ -298,6 +295,12 @@ func (s *grpcServer) getOptions() []grpc.ServerOption {
 	options = append(options, s.unaryServerOptions())
 	options = append(options, s.streamServerOptions())
 
+	// connection stats for admin endpoints
+	if config.Get().Grpc.EnableAdmin {
+		s.connStats = admin.NewConnStats()
+		options = append(options, grpc.StatsHandler(s.connStats))
+	}
+
 	return options
 }
 
@@ -318,11 +321,25 @@ func (s *grpcServer) registerProfMux() {
 	prof.Register(s.mux, prof.WithIOWaitTime())
 }
 
+// admin endpoints, /admin/services, /admin/interceptors, /admin/config, /admin/connections, /admin/loglevel
+func (s *grpcServer) registerAdminMux() {
+	if s.mux == nil {
+		s.mux = http.NewServeMux()
+	}
+	admin.Register(s.mux, s.server,
+		admin.WithConnStats(s.connStats),
+		admin.WithUnaryInterceptors(s.unaryInterceptors...),
+		admin.WithStreamInterceptors(s.streamInterceptors...),
+		admin.WithConfig(func() string { return config.Show() }),
+	)
+}
+
 func (s *grpcServer) addHTTPRouter() {
 	if s.mux == nil {
 		s.mux = http.NewServeMux()
 	}
-	s.mux.HandleFunc("/codes", errcode.ListGRPCErrCodes) // error codes router
+	s.mux.HandleFunc("/codes", errcode.CodesHandler) // error codes router
+	health.Default().Register(s.mux)                 // health check router, /health/live and /health/ready
 
 	cfgStr := config.Show()
 	s.mux.HandleFunc("/config", errcode.ShowConfig([]byte(cfgStr))) // config router
@@ -350,5 +367,20 @@ func NewGRPCServer(addr string, opts ...GrpcOption) app.IServer {
 
 	s.server = grpc.NewServer(s.getOptions()...)
 	service.RegisterAllService(s.server) // register for all services
+
+	// register reflection service, used by tools such as grpcurl to list and call services
+	if config.Get().Grpc.EnableReflection {
+		reflection.Register(s.server)
+	}
+	// register channelz service, used for debugging connections and calls
+	if config.Get().Grpc.EnableChannelz {
+		channelzservice.RegisterChannelzServiceToServer(s.server)
+	}
+	if config.Get().Grpc.EnableAdmin {
+		s.registerAdminMux()
+	}
+	if s.iRegistry != nil {
+		s.registrar = health.NewRegistrar(health.Default(), s.iRegistry, s.instance)
+	}
 	return s
 }
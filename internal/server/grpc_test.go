@@ -30,6 +30,9 @@ func TestGRPCServer(t *testing.T) {
 	config.Get().App.EnableLimit = true
 	config.Get().App.EnableCircuitBreaker = true
 	config.Get().Grpc.EnableToken = true
+	config.Get().Grpc.EnableReflection = true
+	config.Get().Grpc.EnableChannelz = true
+	config.Get().Grpc.EnableAdmin = true
 
 	port, _ := utils.GetAvailablePort()
 	addr := fmt.Sprintf(":%d", port)
@@ -6,11 +6,10 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/18721889353/sunshine/pkg/logger"
-
 	"github.com/gin-gonic/gin"
 
 	"github.com/18721889353/sunshine/pkg/app"
+	"github.com/18721889353/sunshine/pkg/health"
 	"github.com/18721889353/sunshine/pkg/servicerd/registry"
 
 	"github.com/18721889353/sunshine/internal/routers"
@@ -24,30 +23,16 @@ type httpServer struct {
 
 	instance  *registry.ServiceInstance
 	iRegistry registry.Registry
+	registrar *health.Registrar
 }
 
 // Start http service
 func (s *httpServer) Start() error {
-	if s.iRegistry != nil {
-		ctx, _ := context.WithTimeout(context.Background(), 5*time.Second) //nolint
-		if err := s.iRegistry.Register(ctx, s.instance); err != nil {
+	// registration services, the instance is deregistered when readiness fails and registered again on recovery
+	if s.registrar != nil {
+		if err := s.registrar.Start(); err != nil {
 			return err
 		}
-		go func() {
-			ticker := time.NewTicker(15 * time.Second) // 每15秒检查一次
-			defer ticker.Stop()
-			for {
-				select {
-				case <-ticker.C:
-					ctx, _ := context.WithTimeout(context.Background(), 5*time.Second) //nolint
-					if err := s.iRegistry.Register(ctx, s.instance); err != nil {
-						//logger.Warn("s.iRegistry.Register error", logger.Err(err))
-					} else {
-						logger.Warn("s.iRegistry.Register")
-					}
-				}
-			}
-		}()
 	}
 
 	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -58,14 +43,10 @@ func (s *httpServer) Start() error {
 
 // Stop http service
 func (s *httpServer) Stop() error {
-	if s.iRegistry != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		go func() {
-			_ = s.iRegistry.Deregister(ctx, s.instance)
-			cancel()
-		}()
-		<-ctx.Done()
+	if s.registrar != nil {
+		_ = s.registrar.Stop()
 	}
+	health.Default().Shutdown() // readiness changes to DOWN
 
 	ctx, _ := context.WithTimeout(context.Background(), 3*time.Second) //nolint
 	return s.server.Shutdown(ctx)
@@ -96,12 +77,20 @@ func NewHTTPServer(addr string, opts ...HTTPOption) app.IServer {
 		MaxHeaderBytes: 1 << 20,
 	}
 
-	return &httpServer{
+	return newHTTPServer(addr, server, o)
+}
+
+func newHTTPServer(addr string, server *http.Server, o *httpOptions) *httpServer {
+	s := &httpServer{
 		addr:      addr,
 		server:    server,
 		iRegistry: o.iRegistry,
 		instance:  o.instance,
 	}
+	if s.iRegistry != nil {
+		s.registrar = health.NewRegistrar(health.Default(), s.iRegistry, s.instance)
+	}
+	return s
 }
 
 // delete the templates code start
@@ -126,12 +115,7 @@ func NewHTTPServer_pbExample(addr string, opts ...HTTPOption) app.IServer { //no
 		MaxHeaderBytes: 1 << 20,
 	}
 
-	return &httpServer{
-		addr:      addr,
-		server:    server,
-		iRegistry: o.iRegistry,
-		instance:  o.instance,
-	}
+	return newHTTPServer(addr, server, o)
 }
 
 // delete the templates code end
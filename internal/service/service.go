@@ -3,8 +3,8 @@ package service
 
 import (
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/health"
-	healthPB "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/18721889353/sunshine/pkg/health"
 )
 
 var (
@@ -14,7 +14,7 @@ var (
 
 // RegisterAllService register all services to the service
 func RegisterAllService(server *grpc.Server) {
-	healthPB.RegisterHealthServer(server, health.NewServer()) // Register for Health Screening
+	health.Default().RegisterGRPC(server) // Register for Health Screening, serving status follows the readiness
 
 	for _, fn := range registerFns {
 		fn(server)
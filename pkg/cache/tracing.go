@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redsync/redsync/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/18721889353/sunshine/pkg/cache"
+
+var _ Cache = (*tracingCache)(nil)
+
+// TracingOption set the tracing cache options.
+type TracingOption func(*tracingOptions)
+
+type tracingOptions struct {
+	dbSystem string
+}
+
+func (o *tracingOptions) apply(opts ...TracingOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithTracingDBSystem set the attribute db.system of spans, e.g. redis, memcached,
+// default is redis if the cache is created by NewRedisCache or NewRedisClusterCache, otherwise not set.
+func WithTracingDBSystem(system string) TracingOption {
+	return func(o *tracingOptions) {
+		o.dbSystem = system
+	}
+}
+
+type tracingCache struct {
+	next  Cache
+	attrs []attribute.KeyValue
+}
+
+// NewTracingCache wraps the cache, each cache operation is recorded in a span,
+// the miss of Get is not regarded as an error.
+func NewTracingCache(c Cache, opts ...TracingOption) Cache {
+	o := &tracingOptions{}
+	switch c.(type) {
+	case *redisCache, *redisClusterCache:
+		o.dbSystem = semconv.DBSystemRedis.Value.AsString()
+	}
+	o.apply(opts...)
+
+	var attrs []attribute.KeyValue
+	if o.dbSystem != "" {
+		attrs = append(attrs, semconv.DBSystemKey.String(o.dbSystem))
+	}
+	return &tracingCache{next: c, attrs: attrs}
+}
+
+func (c *tracingCache) start(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, "cache."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(c.attrs...),
+		trace.WithAttributes(semconv.DBOperationName(operation)),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func (c *tracingCache) GetLoopLock(ctx context.Context, key string, expireTime, loopWaitTime time.Duration, loopNum int) (*redsync.Mutex, error) {
+	ctx, span := c.start(ctx, "GetLoopLock", attribute.String("cache.key", key))
+	mutex, err := c.next.GetLoopLock(ctx, key, expireTime, loopWaitTime, loopNum)
+	endSpan(span, err)
+	return mutex, err
+}
+
+func (c *tracingCache) GetLock(ctx context.Context, key string, expireTime time.Duration) (*redsync.Mutex, error) {
+	ctx, span := c.start(ctx, "GetLock", attribute.String("cache.key", key))
+	mutex, err := c.next.GetLock(ctx, key, expireTime)
+	endSpan(span, err)
+	return mutex, err
+}
+
+func (c *tracingCache) ReleaseLock(ctx context.Context, mutex *redsync.Mutex) error {
+	var attrs []attribute.KeyValue
+	if mutex != nil {
+		attrs = append(attrs, attribute.String("cache.key", mutex.Name()))
+	}
+	ctx, span := c.start(ctx, "ReleaseLock", attrs...)
+	err := c.next.ReleaseLock(ctx, mutex)
+	endSpan(span, err)
+	return err
+}
+
+func (c *tracingCache) Set(ctx context.Context, key string, val interface{}, expireTime time.Duration) error {
+	ctx, span := c.start(ctx, "Set", attribute.String("cache.key", key))
+	err := c.next.Set(ctx, key, val, expireTime)
+	endSpan(span, err)
+	return err
+}
+
+func (c *tracingCache) Get(ctx context.Context, key string, val interface{}) error {
+	ctx, span := c.start(ctx, "Get", attribute.String("cache.key", key))
+	err := c.next.Get(ctx, key, val)
+	span.SetAttributes(attribute.Bool("cache.hit", !errors.Is(err, CacheNotFound) && (err == nil || errors.Is(err, ErrPlaceholder))))
+	if errors.Is(err, CacheNotFound) || errors.Is(err, ErrPlaceholder) {
+		endSpan(span, nil)
+	} else {
+		endSpan(span, err)
+	}
+	return err
+}
+
+func (c *tracingCache) MultiSet(ctx context.Context, valMap map[string]interface{}, expireTime time.Duration) error {
+	ctx, span := c.start(ctx, "MultiSet", attribute.Int("cache.key_count", len(valMap)))
+	err := c.next.MultiSet(ctx, valMap, expireTime)
+	endSpan(span, err)
+	return err
+}
+
+func (c *tracingCache) MultiGet(ctx context.Context, keys []string, valueMap interface{}) error {
+	ctx, span := c.start(ctx, "MultiGet", attribute.Int("cache.key_count", len(keys)))
+	err := c.next.MultiGet(ctx, keys, valueMap)
+	endSpan(span, err)
+	return err
+}
+
+func (c *tracingCache) Del(ctx context.Context, keys ...string) error {
+	ctx, span := c.start(ctx, "Del", attribute.Int("cache.key_count", len(keys)))
+	err := c.next.Del(ctx, keys...)
+	endSpan(span, err)
+	return err
+}
+
+func (c *tracingCache) SetCacheWithNotFound(ctx context.Context, key string) error {
+	ctx, span := c.start(ctx, "SetCacheWithNotFound", attribute.String("cache.key", key))
+	err := c.next.SetCacheWithNotFound(ctx, key)
+	endSpan(span, err)
+	return err
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
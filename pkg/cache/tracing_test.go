@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewTracingCache(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(tp)
+
+	c := newRedisCache()
+	defer c.Close()
+	cache := NewTracingCache(c.ICache.(Cache))
+	ctx := context.Background()
+
+	user := &redisUser{ID: 1, Name: "foo"}
+	assert.NoError(t, cache.Set(ctx, "1", user, time.Minute))
+	assert.NoError(t, cache.Get(ctx, "1", &redisUser{}))
+	assert.ErrorIs(t, cache.Get(ctx, "not_found", &redisUser{}), CacheNotFound)
+	assert.NoError(t, cache.SetCacheWithNotFound(ctx, "2"))
+	assert.ErrorIs(t, cache.Get(ctx, "2", &redisUser{}), ErrPlaceholder)
+	assert.NoError(t, cache.MultiSet(ctx, map[string]interface{}{"3": user, "4": user}, time.Minute))
+	assert.NoError(t, cache.MultiGet(ctx, []string{"3", "4"}, map[string]*redisUser{}))
+	assert.NoError(t, cache.Del(ctx, "1", "3"))
+	mutex, err := cache.GetLock(ctx, "5", time.Second)
+	require.NoError(t, err)
+	assert.NoError(t, cache.ReleaseLock(ctx, mutex))
+	mutex, err = cache.GetLoopLock(ctx, "6", time.Second, time.Millisecond, 1)
+	require.NoError(t, err)
+	assert.NoError(t, cache.ReleaseLock(ctx, mutex))
+	assert.Error(t, cache.Set(ctx, "", user, time.Minute))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 13)
+	assert.Equal(t, "cache.Set", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("db.system", "redis"))
+	assert.Contains(t, spans[0].Attributes(), attribute.String("cache.key", "1"))
+	assert.Contains(t, spans[1].Attributes(), attribute.Bool("cache.hit", true))
+	assert.Contains(t, spans[2].Attributes(), attribute.Bool("cache.hit", false))
+	assert.Equal(t, codes.Unset, spans[2].Status().Code) // miss is not an error
+	assert.Contains(t, spans[4].Attributes(), attribute.Bool("cache.hit", true))
+	assert.Contains(t, spans[6].Attributes(), attribute.Int("cache.key_count", 2))
+	assert.Equal(t, codes.Error, spans[12].Status().Code)
+}
+
+func TestNewTracingCache_dbSystem(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(tp)
+
+	c := newRedisCache()
+	defer c.Close()
+	ctx := context.Background()
+
+	// not a redis cache, db.system is not set by default
+	cache := NewTracingCache(&wrappedCache{Cache: c.ICache.(Cache)})
+	_ = cache.Del(ctx, "1")
+	cache = NewTracingCache(&wrappedCache{Cache: c.ICache.(Cache)}, WithTracingDBSystem("memcached"))
+	_ = cache.Del(ctx, "1")
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	for _, attr := range spans[0].Attributes() {
+		assert.NotEqual(t, "db.system", string(attr.Key))
+	}
+	assert.Contains(t, spans[1].Attributes(), attribute.String("db.system", "memcached"))
+}
+
+type wrappedCache struct {
+	Cache
+}
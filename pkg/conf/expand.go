@@ -0,0 +1,145 @@
+package conf
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+
+	"github.com/18721889353/sunshine/pkg/gocrypto"
+)
+
+const (
+	// DefaultEnvPrefix is the default prefix of environment variables that override the configuration,
+	// e.g. APP_DATABASE_MYSQL_DSN overrides database.mysql.dsn.
+	DefaultEnvPrefix = "APP"
+
+	// AesKeyEnv is the environment variable of the aes key that decrypts the encrypted values,
+	// the length of key must be 16, 24 or 32.
+	AesKeyEnv = "CONF_AES_KEY"
+
+	// the version of encrypted value format, v1 is ENC(v1:base64(nonce + ciphertext)) encrypted by AES-GCM
+	encryptVersion1 = "v1"
+)
+
+var (
+	placeholderRegexp = regexp.MustCompile(`\$\{([^{}]+)\}`)
+	encryptedRegexp   = regexp.MustCompile(`^ENC\((.*)\)$`)
+)
+
+// bind the environment variables with prefix to the configuration keys, nested keys are joined by '_'
+func bindEnv(v *viper.Viper, prefix string) {
+	if prefix == "" {
+		return
+	}
+	v.SetEnvPrefix(prefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+}
+
+// the decode hook of string values, expand placeholders ${ENV} and ${file:/path}, then decrypt the value ENC(ciphertext)
+func expandHook(from reflect.Type, _ reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String {
+		return data, nil
+	}
+	value, err := Expand(data.(string))
+	if err != nil {
+		return nil, err
+	}
+	return decrypt(value)
+}
+
+func decodeHook() viper.DecoderConfigOption {
+	return viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		expandHook,
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	))
+}
+
+// Expand replaces the placeholders ${ENV} with the value of environment variable,
+// and ${file:/path} with the content of file, the trailing newline of file content is removed.
+func Expand(value string) (string, error) {
+	var err error
+	result := placeholderRegexp.ReplaceAllStringFunc(value, func(placeholder string) string {
+		name := strings.TrimSpace(placeholder[2 : len(placeholder)-1])
+		if strings.HasPrefix(name, "file:") {
+			data, e := os.ReadFile(strings.TrimPrefix(name, "file:"))
+			if e != nil {
+				err = fmt.Errorf("expand %s error: %v", placeholder, e)
+				return placeholder
+			}
+			return strings.TrimRight(string(data), "\r\n")
+		}
+
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			err = fmt.Errorf("expand %s error: environment variable %s is not set", placeholder, name)
+			return placeholder
+		}
+		return env
+	})
+	return result, err
+}
+
+// the value in the format ENC(version:ciphertext) is decrypted by the aes key from environment variable
+func decrypt(value string) (string, error) {
+	matches := encryptedRegexp.FindStringSubmatch(value)
+	if len(matches) != 2 {
+		return value, nil
+	}
+
+	key, ok := os.LookupEnv(AesKeyEnv)
+	if !ok {
+		return "", fmt.Errorf("decrypt value error: environment variable %s is not set", AesKeyEnv)
+	}
+	version, cipherStr, ok := strings.Cut(matches[1], ":")
+	if !ok || version != encryptVersion1 {
+		return "", fmt.Errorf("decrypt value error: unsupported format, expected ENC(%s:ciphertext)", encryptVersion1)
+	}
+	rawData, err := aesGCMDecrypt(cipherStr, []byte(key))
+	if err != nil {
+		return "", fmt.Errorf("decrypt value error: %v", err)
+	}
+	return rawData, nil
+}
+
+func aesGCMDecrypt(cipherStr string, key []byte) (string, error) {
+	cipherData, err := base64.StdEncoding.DecodeString(cipherStr)
+	if err != nil {
+		return "", err
+	}
+	rawData, err := gocrypto.AesDecrypt(cipherData, gocrypto.WithAesKey(key), gocrypto.WithAesModeGCM())
+	if err != nil {
+		return "", err
+	}
+	return string(rawData), nil
+}
+
+func aesGCMEncrypt(value string, key []byte) (string, error) {
+	cipherData, err := gocrypto.AesEncrypt([]byte(value), gocrypto.WithAesKey(key), gocrypto.WithAesModeGCM())
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(cipherData), nil
+}
+
+// Encrypt encrypts the value by AES-GCM with the key from environment variable CONF_AES_KEY, returns the format
+// ENC(v1:ciphertext) that can be written in the configuration file instead of plaintext, the ciphertext is different
+// each time because of the random nonce.
+func Encrypt(value string) (string, error) {
+	key, ok := os.LookupEnv(AesKeyEnv)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", AesKeyEnv)
+	}
+	cipherStr, err := aesGCMEncrypt(value, []byte(key))
+	if err != nil {
+		return "", err
+	}
+	return "ENC(" + encryptVersion1 + ":" + cipherStr + ")", nil
+}
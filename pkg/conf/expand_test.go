@@ -0,0 +1,112 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("TEST_DB_USER", "root")
+	file := filepath.Join(t.TempDir(), "password")
+	err := os.WriteFile(file, []byte("123456\n"), 0666)
+	require.NoError(t, err)
+
+	value, err := Expand("${TEST_DB_USER}:${file:" + file + "}@(127.0.0.1:3306)/account")
+	assert.NoError(t, err)
+	assert.Equal(t, "root:123456@(127.0.0.1:3306)/account", value)
+
+	value, err = Expand("no placeholder")
+	assert.NoError(t, err)
+	assert.Equal(t, "no placeholder", value)
+
+	_, err = Expand("${TEST_NOT_EXIST_ENV}")
+	assert.Error(t, err)
+	_, err = Expand("${file:/not/exist/file}")
+	assert.Error(t, err)
+}
+
+func TestEncrypt(t *testing.T) {
+	t.Setenv(AesKeyEnv, "0123456789abcdef")
+	value, err := Encrypt("123456")
+	require.NoError(t, err)
+	assert.Regexp(t, `^ENC\(v1:[A-Za-z0-9+/=]+\)$`, value)
+	value2, err := Encrypt("123456")
+	require.NoError(t, err)
+	assert.NotEqual(t, value, value2) // random nonce
+
+	rawData, err := decrypt(value)
+	assert.NoError(t, err)
+	assert.Equal(t, "123456", rawData)
+	rawData, err = decrypt(value2)
+	assert.NoError(t, err)
+	assert.Equal(t, "123456", rawData)
+
+	rawData, err = decrypt("plaintext")
+	assert.NoError(t, err)
+	assert.Equal(t, "plaintext", rawData)
+
+	_, err = decrypt("ENC(0123)") // no version
+	assert.Error(t, err)
+	_, err = decrypt("ENC(v2:" + value[7:])
+	assert.Error(t, err)
+	_, err = decrypt("ENC(v1:xyz)")
+	assert.Error(t, err)
+	_, err = decrypt("ENC(v1:AAAA)")
+	assert.Error(t, err)
+	_, err = decrypt(value[:len(value)-5] + "AAAA)") // tampered
+	assert.Error(t, err)
+
+	t.Setenv(AesKeyEnv, "fedcba9876543210")
+	_, err = decrypt(value) // wrong key
+	assert.Error(t, err)
+	t.Setenv(AesKeyEnv, "0123456789abcdef")
+
+	os.Unsetenv(AesKeyEnv) //nolint
+	_, err = decrypt(value)
+	assert.Error(t, err)
+	_, err = Encrypt("123456")
+	assert.Error(t, err)
+}
+
+func TestParseConfigDataWithEnv(t *testing.T) {
+	t.Setenv(AesKeyEnv, "0123456789abcdef")
+	password, err := Encrypt("123456")
+	require.NoError(t, err)
+	t.Setenv("TEST_DB_USER", "root")
+	t.Setenv("APP_DATABASE_MYSQL_MAXIDLECONNS", "20")
+	t.Setenv("APP_DATABASE_MYSQL_TIMEOUT", "5s")
+
+	type config struct {
+		Database struct {
+			Mysql struct {
+				User         string        `yaml:"user"`
+				Password     string        `yaml:"password"`
+				MaxIdleConns int           `yaml:"maxIdleConns"`
+				Timeout      time.Duration `yaml:"timeout"`
+			} `yaml:"mysql"`
+		} `yaml:"database"`
+	}
+	data := []byte(`
+database:
+  mysql:
+    user: "${TEST_DB_USER}"
+    password: "` + password + `"
+    maxIdleConns: 10
+    timeout: 3s
+`)
+	cfg := &config{}
+	err = ParseConfigData(data, "yaml", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "root", cfg.Database.Mysql.User)
+	assert.Equal(t, "123456", cfg.Database.Mysql.Password)
+	assert.Equal(t, 20, cfg.Database.Mysql.MaxIdleConns)
+	assert.Equal(t, time.Second*5, cfg.Database.Mysql.Timeout)
+
+	err = ParseConfigData([]byte(`database: {mysql: {user: "${TEST_NOT_EXIST_ENV}"}}`), "yaml", cfg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,171 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/spf13/viper"
+)
+
+// Loader loads the configuration to struct, unlike Parse, it does not use the global viper state,
+// it owns its viper instance, its watcher and its reload subscribers.
+// The reloaded configuration is a new struct that replaces the old one atomically, the struct
+// got by Get is never modified by the loader, so it is safe to read it concurrently during a reload.
+type Loader struct {
+	typ     reflect.Type
+	options *sourceOptions
+	value   atomic.Value
+
+	mu          sync.Mutex
+	merger      *merger
+	cancel      context.CancelFunc
+	subscribers []func(e *Event)
+	overrides   []func(obj interface{})
+}
+
+// NewLoader creates a loader, obj is a pointer to configuration struct, e.g. &Config{}, which is
+// used to determine the type of the configuration loaded.
+// The options WithEnvPrefix, WithOnChange and WithReloads are supported.
+func NewLoader(obj interface{}, opts ...SourceOption) *Loader {
+	o := defaultSourceOptions()
+	o.apply(opts...)
+
+	typ := reflect.TypeOf(obj)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return &Loader{
+		typ:     typ,
+		options: o,
+	}
+}
+
+// Load loads the configuration from sources, the later sources override the earlier ones,
+// the sources of the previous Load are no longer watched.
+func (l *Loader) Load(sources ...Source) error {
+	l.Close()
+
+	m := newMerger(sources, l.options, l.apply, l.notify)
+	err := m.load(context.Background())
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.merger = m
+	l.mu.Unlock()
+	return nil
+}
+
+// LoadFile loads the configuration from local file, including yaml, toml, json, etc.
+func (l *Loader) LoadFile(configFile string) error {
+	return l.Load(NewFileSource(configFile))
+}
+
+// LoadData loads the configuration from data, format is the format of data, e.g. yaml, json, toml.
+func (l *Loader) LoadData(data []byte, format string) error {
+	return l.Load(NewMemorySource("data", format, data))
+}
+
+// Get returns the current configuration, it is a pointer to the struct of the type passed to NewLoader,
+// returns nil if the configuration is not loaded. Don't modify the returned configuration.
+func (l *Loader) Get() interface{} {
+	return l.value.Load()
+}
+
+// Set replaces the current configuration, obj must be a pointer to the struct of the type passed to NewLoader.
+func (l *Loader) Set(obj interface{}) {
+	l.value.Store(obj)
+}
+
+// Subscribe adds a function notified after the configuration is reloaded, the subscribers are called
+// after the functions set by WithOnChange and WithReloads.
+func (l *Loader) Subscribe(fn func(e *Event)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.subscribers = append(l.subscribers, fn)
+}
+
+// Override adds a function that modifies the configuration, e.g. the values set by command line flags,
+// it is applied to a copy of the current configuration immediately and to every reloaded configuration,
+// so the override is not lost after a reload and the struct got by Get is not modified.
+func (l *Loader) Override(fn func(obj interface{})) {
+	l.mu.Lock()
+	l.overrides = append(l.overrides, fn)
+	l.mu.Unlock()
+
+	cur := l.value.Load()
+	if cur == nil {
+		return
+	}
+	obj := reflect.New(l.typ)
+	obj.Elem().Set(reflect.ValueOf(cur).Elem())
+	fn(obj.Interface())
+	l.value.Store(obj.Interface())
+}
+
+// Watch starts watching the sources, the configuration is reloaded when a source changes,
+// until Close is called.
+func (l *Loader) Watch() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.merger == nil {
+		return errors.New("the configuration is not loaded")
+	}
+	if l.cancel != nil {
+		return nil // already watching
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.merger.watchAll(ctx)
+	return nil
+}
+
+// Close stops watching the sources.
+func (l *Loader) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.cancel != nil {
+		l.cancel()
+		l.cancel = nil
+	}
+}
+
+// decode the configuration to a new struct and replace the current configuration
+func (l *Loader) apply(v *viper.Viper) error {
+	obj := reflect.New(l.typ).Interface()
+	err := decode(v, obj)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	overrides := make([]func(obj interface{}), len(l.overrides))
+	copy(overrides, l.overrides)
+	l.mu.Unlock()
+	for _, fn := range overrides {
+		fn(obj)
+	}
+
+	l.value.Store(obj)
+	return nil
+}
+
+func (l *Loader) notify(e *Event) {
+	l.options.notify(e)
+
+	l.mu.Lock()
+	subscribers := make([]func(e *Event), len(l.subscribers))
+	copy(subscribers, l.subscribers)
+	l.mu.Unlock()
+	for _, fn := range subscribers {
+		fn(e)
+	}
+}
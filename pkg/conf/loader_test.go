@@ -0,0 +1,117 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "app1.yml")
+	file2 := filepath.Join(dir, "app2.json")
+	require.NoError(t, os.WriteFile(file1, []byte("app:\n  name: app1\n  level: info\n"), 0666))
+	require.NoError(t, os.WriteFile(file2, []byte(`{"app":{"name":"app2","port":8080}}`), 0666))
+
+	reloaded := make(chan struct{}, 10)
+	loader1 := NewLoader(&testConfig{}, WithReloads(func() { reloaded <- struct{}{} }))
+	defer loader1.Close()
+	assert.Nil(t, loader1.Get())
+	assert.Error(t, loader1.Watch())
+	require.NoError(t, loader1.LoadFile(file1))
+
+	loader2 := NewLoader(testConfig{})
+	require.NoError(t, loader2.LoadFile(file2))
+
+	// the loaders do not affect each other
+	cfg1 := loader1.Get().(*testConfig)
+	cfg2 := loader2.Get().(*testConfig)
+	assert.Equal(t, "app1", cfg1.App.Name)
+	assert.Equal(t, 0, cfg1.App.Port)
+	assert.Equal(t, "app2", cfg2.App.Name)
+	assert.Equal(t, "", cfg2.App.Level)
+
+	events := make(chan *Event, 10)
+	loader1.Subscribe(func(e *Event) { events <- e })
+	require.NoError(t, loader1.Watch())
+	require.NoError(t, loader1.Watch())
+
+	// read concurrently during reloading
+	done := make(chan struct{})
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				cfg := loader1.Get().(*testConfig)
+				_ = cfg.App.Name + cfg.App.Level
+			}
+		}
+	}()
+
+	require.NoError(t, os.WriteFile(file1, []byte("app:\n  name: app1\n  level: debug\n"), 0666))
+	select {
+	case e := <-events:
+		assert.Equal(t, "file", e.Source)
+		assert.Equal(t, []string{"app.level"}, e.ChangedKeys)
+	case <-time.After(time.Second * 3):
+		t.Fatal("timeout")
+	}
+	<-reloaded
+	close(done)
+	wg.Wait()
+
+	// the old configuration is not modified
+	assert.Equal(t, "info", cfg1.App.Level)
+	assert.Equal(t, "debug", loader1.Get().(*testConfig).App.Level)
+	assert.Equal(t, "app2", loader2.Get().(*testConfig).App.Name)
+
+	loader2.Set(&testConfig{})
+	assert.Equal(t, "", loader2.Get().(*testConfig).App.Name)
+}
+
+func TestLoader_LoadData(t *testing.T) {
+	loader := NewLoader(&validateConfig{})
+	err := loader.LoadData([]byte("app: {name: demo, level: info, port: 8080}"), "yaml")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, loader.Get().(*validateConfig).App.Port)
+
+	// invalid configuration
+	err = loader.LoadData([]byte("app: {name: demo, level: info}"), "yaml")
+	assert.Error(t, err)
+	assert.Equal(t, 8080, loader.Get().(*validateConfig).App.Port)
+
+	err = loader.Load()
+	assert.Error(t, err)
+	err = loader.LoadFile("not_found.yml")
+	assert.Error(t, err)
+}
+
+func TestLoader_Override(t *testing.T) {
+	loader := NewLoader(&testConfig{})
+	loader.Override(func(obj interface{}) {}) // not loaded yet
+	assert.Nil(t, loader.Get())
+
+	require.NoError(t, loader.LoadData([]byte("app: {name: app1, level: info}"), "yaml"))
+	cfg := loader.Get().(*testConfig)
+	loader.Override(func(obj interface{}) { obj.(*testConfig).App.Name = "override" })
+
+	// the current configuration is replaced by a modified copy
+	assert.Equal(t, "app1", cfg.App.Name)
+	assert.Equal(t, "override", loader.Get().(*testConfig).App.Name)
+	assert.Equal(t, "info", loader.Get().(*testConfig).App.Level)
+
+	// applied to the reloaded configuration
+	require.NoError(t, loader.LoadData([]byte("app: {name: app2, level: debug}"), "yaml"))
+	assert.Equal(t, "override", loader.Get().(*testConfig).App.Name)
+	assert.Equal(t, "debug", loader.Get().(*testConfig).App.Level)
+}
@@ -0,0 +1,55 @@
+package conf
+
+// SourceOption set the options of ParseSources and Loader.
+type SourceOption func(*sourceOptions)
+
+type sourceOptions struct {
+	envPrefix string
+	reloads   []func()
+	onChanges []func(e *Event)
+}
+
+func defaultSourceOptions() *sourceOptions {
+	return &sourceOptions{
+		envPrefix: DefaultEnvPrefix,
+	}
+}
+
+func (o *sourceOptions) apply(opts ...SourceOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithEnvPrefix set the prefix of environment variables that override the configuration, default is APP,
+// nested keys are joined by '_', e.g. APP_DATABASE_MYSQL_DSN overrides database.mysql.dsn, empty prefix disables it.
+func WithEnvPrefix(prefix string) SourceOption {
+	return func(o *sourceOptions) {
+		o.envPrefix = prefix
+	}
+}
+
+// WithReloads set the functions called after the configuration changes, and turn on watching the sources.
+func WithReloads(reloads ...func()) SourceOption {
+	return func(o *sourceOptions) {
+		o.reloads = append(o.reloads, reloads...)
+	}
+}
+
+// WithOnChange set the function notified of configuration changes, and turn on watching the sources,
+// it is called before the reloads.
+func WithOnChange(fn func(e *Event)) SourceOption {
+	return func(o *sourceOptions) {
+		o.onChanges = append(o.onChanges, fn)
+	}
+}
+
+// call the change notifications, then the reloads
+func (o *sourceOptions) notify(e *Event) {
+	for _, onChange := range o.onChanges {
+		onChange(e)
+	}
+	for _, reload := range o.reloads {
+		reload()
+	}
+}
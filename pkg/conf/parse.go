@@ -12,9 +12,14 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
+
+	"github.com/18721889353/sunshine/pkg/utils"
 )
 
-// Parse configuration files to struct, including yaml, toml, json, etc., and turn on listening for configuration file changes if fs is not empty
+// Parse configuration files to struct, including yaml, toml, json, etc., and turn on listening for configuration file changes if fs is not empty.
+// The environment variables with prefix APP_ override the configuration, the placeholders ${ENV} and ${file:/path} are expanded,
+// the values ENC(v1:ciphertext) are decrypted by the aes key from environment variable CONF_AES_KEY, and the struct is checked by `validate` tags.
+// Note: Parse uses the global viper instance, use Loader to load multiple configuration files in one process.
 func Parse(configFile string, obj interface{}, reloads ...func()) error {
 	confFileAbs, err := filepath.Abs(configFile)
 	if err != nil {
@@ -28,12 +33,13 @@ func Parse(configFile string, obj interface{}, reloads ...func()) error {
 	viper.AddConfigPath(filePathStr) // path
 	viper.SetConfigName(filename)    // file name
 	viper.SetConfigType(ext)         // get the configuration type from the file name
+	bindEnv(viper.GetViper(), DefaultEnvPrefix)
 	err = viper.ReadInConfig()
 	if err != nil {
 		return err
 	}
 
-	err = viper.Unmarshal(obj)
+	err = decode(viper.GetViper(), obj)
 	if err != nil {
 		return err
 	}
@@ -45,15 +51,16 @@ func Parse(configFile string, obj interface{}, reloads ...func()) error {
 	return nil
 }
 
-// ParseConfigData parse data to struct
+// ParseConfigData parse data to struct, the same processing as Parse
 func ParseConfigData(data []byte, format string, obj interface{}, reloads ...func()) error {
 	viper.SetConfigType(format)
+	bindEnv(viper.GetViper(), DefaultEnvPrefix)
 	err := viper.ReadConfig(bytes.NewBuffer(data))
 	if err != nil {
 		return err
 	}
 
-	err = viper.Unmarshal(obj)
+	err = decode(viper.GetViper(), obj)
 	if err != nil {
 		return err
 	}
@@ -71,9 +78,9 @@ func watchConfig(obj interface{}, reloads ...func()) {
 
 	// Note: OnConfigChange is called twice on Windows
 	viper.OnConfigChange(func(e fsnotify.Event) {
-		err := viper.Unmarshal(obj)
+		err := decodeAndApply(viper.GetViper(), obj)
 		if err != nil {
-			fmt.Println("viper.Unmarshal error: ", err)
+			fmt.Println("conf.decode error: ", err)
 		} else {
 			for _, reload := range reloads {
 				reload()
@@ -92,13 +99,17 @@ func Show(obj interface{}, fields ...string) string {
 		return ""
 	}
 
+	// the same fields are masked in the logs by logger redactor
+	for _, field := range utils.SensitiveFields() {
+		fields = append(fields, `"`+field+`"`)
+	}
+
 	buf := bufio.NewReader(bytes.NewReader(data))
 	for {
 		line, err := buf.ReadString('\n')
 		if err != nil {
 			break
 		}
-		fields = append(fields, `"dsn"`, `"password"`, `"pwd"`)
 
 		out += hideSensitiveFields(line, fields...)
 	}
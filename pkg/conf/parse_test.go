@@ -5,6 +5,8 @@ import (
 	"os"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 var c = make(map[string]interface{})
@@ -14,6 +16,18 @@ func TestShow(t *testing.T) {
 	t.Log(Show(make(chan string)))
 }
 
+func TestShow_sensitiveFields(t *testing.T) {
+	str := Show(map[string]interface{}{
+		"name":     "user",
+		"password": "123456",
+		"token":    "abcdef",
+		"dsn":      "root:123456@(127.0.0.1:3306)/account",
+	})
+	assert.Contains(t, str, `"user"`)
+	assert.NotContains(t, str, "123456")
+	assert.NotContains(t, str, "abcdef")
+}
+
 func Test_replaceDSN(t *testing.T) {
 	dsn := "default:123456@192.168.3.37:6379/0"
 	t.Log(replaceDSN(dsn))
@@ -0,0 +1,366 @@
+package conf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+var watchRetryInterval = time.Second * 3
+
+// Source is a provider of configuration data, such as local file, nacos, etcd, consul.
+type Source interface {
+	// Name returns the name of the source, e.g. file, nacos, etcd, consul.
+	Name() string
+	// Format returns the format of the configuration data, e.g. yaml, json, toml.
+	Format() string
+	// Load reads the configuration data.
+	Load(ctx context.Context) ([]byte, error)
+	// Watch blocks until ctx is done, onChange is called with the latest data when the configuration changes,
+	// calling onChange with unchanged data is allowed.
+	Watch(ctx context.Context, onChange func(data []byte)) error
+}
+
+// Event is the notification of configuration changes.
+type Event struct {
+	Source      string   // name of the source that changed
+	ChangedKeys []string // keys whose values changed, nested keys are joined by '.'
+}
+
+// ParseSources loads the configuration from sources and parses it to obj, the later sources override
+// the earlier ones, e.g. local file defaults overridden by configuration center, environment variables
+// with prefix APP_ override all sources, the prefix can be changed by WithEnvPrefix. The values are
+// expanded, decrypted and validated in the same way as Parse.
+// If reloads or change notifications are set, watch the sources until ctx is done, obj is updated
+// and the reloads are called when the configuration changes.
+func ParseSources(ctx context.Context, obj interface{}, sources []Source, opts ...SourceOption) error {
+	o := defaultSourceOptions()
+	o.apply(opts...)
+
+	m := newMerger(sources, o, func(v *viper.Viper) error {
+		return decodeAndApply(v, obj)
+	}, o.notify)
+	err := m.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(o.reloads) > 0 || len(o.onChanges) > 0 {
+		m.watchAll(ctx)
+	}
+
+	return nil
+}
+
+// merger merges the configuration of sources, and applies the merged configuration when a source changes.
+type merger struct {
+	mu      sync.Mutex
+	sources []Source
+	data    [][]byte
+	values  map[string]interface{}
+	options *sourceOptions
+
+	apply  func(v *viper.Viper) error // apply the merged configuration
+	notify func(e *Event)             // called after the changed configuration is applied
+}
+
+func newMerger(sources []Source, o *sourceOptions, apply func(v *viper.Viper) error, notify func(e *Event)) *merger {
+	return &merger{
+		sources: sources,
+		data:    make([][]byte, len(sources)),
+		options: o,
+		apply:   apply,
+		notify:  notify,
+	}
+}
+
+// load the data of all sources and apply the merged configuration
+func (m *merger) load(ctx context.Context) error {
+	if len(m.sources) == 0 {
+		return fmt.Errorf("no configuration source")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data := make([][]byte, len(m.sources))
+	for i, s := range m.sources {
+		d, err := s.Load(ctx)
+		if err != nil {
+			return fmt.Errorf("load config from %s error: %v", s.Name(), err)
+		}
+		data[i] = d
+	}
+
+	v, err := m.merge(data)
+	if err != nil {
+		return err
+	}
+	err = m.apply(v)
+	if err != nil {
+		return err
+	}
+	m.data = data
+	m.values = settings(v)
+
+	return nil
+}
+
+// watch all sources until ctx is done
+func (m *merger) watchAll(ctx context.Context) {
+	for i, s := range m.sources {
+		go m.watch(ctx, i, s)
+	}
+}
+
+// merge the data of sources in order, the later sources override the earlier ones
+func (m *merger) merge(data [][]byte) (*viper.Viper, error) {
+	v := viper.New()
+	bindEnv(v, m.options.envPrefix)
+
+	for i, s := range m.sources {
+		if len(data[i]) == 0 {
+			continue
+		}
+		v.SetConfigType(s.Format())
+		err := v.MergeConfig(bytes.NewReader(data[i]))
+		if err != nil {
+			return nil, fmt.Errorf("merge config from %s error: %v", s.Name(), err)
+		}
+	}
+
+	return v, nil
+}
+
+// watch the source until ctx is done, retry if the watching stops unexpectedly
+func (m *merger) watch(ctx context.Context, index int, s Source) {
+	for {
+		err := s.Watch(ctx, func(data []byte) {
+			m.update(index, data)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		fmt.Printf("watch config from %s stopped: %v, retry after %s\n", s.Name(), err, watchRetryInterval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchRetryInterval):
+		}
+	}
+}
+
+func (m *merger) update(index int, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if bytes.Equal(m.data[index], data) {
+		return
+	}
+
+	newData := make([][]byte, len(m.data))
+	copy(newData, m.data)
+	newData[index] = data
+	v, err := m.merge(newData)
+	if err != nil {
+		fmt.Println("conf.merge error: ", err)
+		return
+	}
+	m.data = newData
+
+	values := settings(v)
+	changedKeys := diffKeys(m.values, values)
+	if len(changedKeys) == 0 {
+		return
+	}
+	err = m.apply(v)
+	if err != nil {
+		fmt.Println("conf.apply error: ", err)
+		return
+	}
+	m.values = values
+
+	m.notify(&Event{Source: m.sources[index].Name(), ChangedKeys: changedKeys})
+}
+
+func settings(v *viper.Viper) map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, key := range v.AllKeys() {
+		values[key] = v.Get(key)
+	}
+	return values
+}
+
+func diffKeys(oldValues map[string]interface{}, newValues map[string]interface{}) []string {
+	var keys []string
+	for key, value := range newValues {
+		if oldValue, ok := oldValues[key]; !ok || !reflect.DeepEqual(oldValue, value) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range oldValues {
+		if _, ok := newValues[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ------------------------------------------------------------------------------------------
+
+type fileSource struct {
+	file   string
+	format string
+}
+
+// NewFileSource creates a source of local configuration file, the format is got from the file suffix.
+func NewFileSource(configFile string) Source {
+	file, err := filepath.Abs(configFile)
+	if err != nil {
+		file = configFile
+	}
+	return &fileSource{
+		file:   file,
+		format: strings.TrimLeft(path.Ext(file), "."),
+	}
+}
+
+func (s *fileSource) Name() string {
+	return "file"
+}
+
+func (s *fileSource) Format() string {
+	return s.format
+}
+
+func (s *fileSource) Load(_ context.Context) ([]byte, error) {
+	return os.ReadFile(s.file)
+}
+
+// Watch the directory of file, compatible with editors that replace the file and the
+// configmap of kubernetes that changes the symbolic link.
+func (s *fileSource) Watch(ctx context.Context, onChange func(data []byte)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close() //nolint
+
+	err = watcher.Add(filepath.Dir(s.file))
+	if err != nil {
+		return err
+	}
+	// the file may be changed between Load and Watch
+	if data, err := os.ReadFile(s.file); err == nil && len(data) > 0 {
+		onChange(data)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			data, err := os.ReadFile(s.file)
+			if err != nil || len(data) == 0 {
+				continue // the file is being replaced or written
+			}
+			onChange(data)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// ------------------------------------------------------------------------------------------
+
+// MemorySource is a source that holds configuration data in memory, it can be used as a fake
+// configuration center in local testing, call Update to simulate the remote changes.
+type MemorySource struct {
+	name   string
+	format string
+
+	mu       sync.Mutex
+	data     []byte
+	watchers map[int]func(data []byte)
+	nextID   int
+}
+
+// NewMemorySource creates a source of configuration data in memory.
+func NewMemorySource(name string, format string, data []byte) *MemorySource {
+	return &MemorySource{
+		name:     name,
+		format:   format,
+		data:     data,
+		watchers: make(map[int]func(data []byte)),
+	}
+}
+
+// Name returns the name of source.
+func (s *MemorySource) Name() string {
+	return s.name
+}
+
+// Format returns the format of data.
+func (s *MemorySource) Format() string {
+	return s.format
+}
+
+// Load returns the current data.
+func (s *MemorySource) Load(_ context.Context) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data, nil
+}
+
+// Watch calls onChange with the current data, and calls it again every time Update is called.
+func (s *MemorySource) Watch(ctx context.Context, onChange func(data []byte)) error {
+	s.mu.Lock()
+	id := s.nextID
+	s.nextID++
+	s.watchers[id] = onChange
+	data := s.data
+	s.mu.Unlock()
+
+	// the data may be updated between Load and Watch
+	onChange(data)
+
+	<-ctx.Done()
+	s.mu.Lock()
+	delete(s.watchers, id)
+	s.mu.Unlock()
+	return nil
+}
+
+// Update replaces the data and notifies the watchers.
+func (s *MemorySource) Update(data []byte) {
+	s.mu.Lock()
+	s.data = data
+	watchers := make([]func(data []byte), 0, len(s.watchers))
+	for _, w := range s.watchers {
+		watchers = append(watchers, w)
+	}
+	s.mu.Unlock()
+
+	for _, w := range watchers {
+		w(data)
+	}
+}
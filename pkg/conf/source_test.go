@@ -0,0 +1,97 @@
+package conf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	App struct {
+		Name  string `yaml:"name" json:"name"`
+		Level string `yaml:"level" json:"level"`
+		Port  int    `yaml:"port" json:"port"`
+	} `yaml:"app" json:"app"`
+}
+
+func TestParseSources(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.yml")
+	err := os.WriteFile(file, []byte("app:\n  name: demo\n  level: info\n  port: 8080\n"), 0666)
+	require.NoError(t, err)
+	remote := NewMemorySource("nacos", "json", []byte(`{"app":{"level":"debug"}}`))
+	t.Setenv("TEST_APP_PORT", "9090")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan *Event, 10)
+	reloaded := make(chan struct{}, 10)
+	cfg := &testConfig{}
+	err = ParseSources(ctx, cfg, []Source{NewFileSource(file), remote},
+		WithEnvPrefix("TEST"),
+		WithOnChange(func(e *Event) { events <- e }),
+		WithReloads(func() { reloaded <- struct{}{} }),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "demo", cfg.App.Name)
+	assert.Equal(t, "debug", cfg.App.Level) // overridden by remote
+	assert.Equal(t, 9090, cfg.App.Port)     // overridden by env
+
+	// remote changes
+	remote.Update([]byte(`{"app":{"level":"warn"}}`))
+	e := waitEvent(t, events)
+	assert.Equal(t, "nacos", e.Source)
+	assert.Equal(t, []string{"app.level"}, e.ChangedKeys)
+	assert.Equal(t, "warn", cfg.App.Level)
+	<-reloaded
+
+	// local file changes
+	err = os.WriteFile(file, []byte("app:\n  name: demo2\n  level: info\n  port: 8080\n"), 0666)
+	require.NoError(t, err)
+	e = waitEvent(t, events)
+	assert.Equal(t, "file", e.Source)
+	assert.Equal(t, []string{"app.name"}, e.ChangedKeys)
+	assert.Equal(t, "demo2", cfg.App.Name)
+
+	// invalid data is ignored, the values overridden by env do not change
+	remote.Update([]byte(`{"app":`))
+	remote.Update([]byte(`{"app":{"level":"warn","port":1000}}`))
+	select {
+	case e = <-events:
+		t.Fatalf("unexpected event %+v", e)
+	case <-time.After(time.Millisecond * 100):
+	}
+	assert.Equal(t, 9090, cfg.App.Port)
+
+	// remote config is deleted
+	remote.Update(nil)
+	e = waitEvent(t, events)
+	assert.Equal(t, []string{"app.level"}, e.ChangedKeys)
+	assert.Equal(t, "info", cfg.App.Level)
+}
+
+func TestParseSourcesError(t *testing.T) {
+	cfg := &testConfig{}
+	err := ParseSources(context.Background(), cfg, nil)
+	assert.Error(t, err)
+
+	err = ParseSources(context.Background(), cfg, []Source{NewFileSource("not_found.yml")})
+	assert.Error(t, err)
+
+	err = ParseSources(context.Background(), cfg, []Source{NewMemorySource("etcd", "yaml", []byte("app: [\n"))})
+	assert.Error(t, err)
+}
+
+func waitEvent(t *testing.T, events chan *Event) *Event {
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(time.Second * 3):
+		t.Fatal("timeout waiting for change event")
+	}
+	return nil
+}
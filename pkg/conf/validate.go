@@ -0,0 +1,98 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/viper"
+)
+
+var (
+	validate     *validator.Validate
+	validateOnce sync.Once
+)
+
+func getValidate() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New()
+		// use the name of configuration key in error message
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"yaml", "json", "mapstructure"} {
+				name := strings.Split(field.Tag.Get(tag), ",")[0]
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	})
+	return validate
+}
+
+// Validate checks the fields of configuration struct according to the `validate` tags,
+// e.g. `validate:"required"`, `validate:"oneof=debug info warn error"`, `validate:"min=1,max=65535"`.
+// obj that is not a struct or pointer to struct is not checked.
+func Validate(obj interface{}) error {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	err := getValidate().Struct(obj)
+	if err == nil {
+		return nil
+	}
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	msgs := make([]string, 0, len(validationErrs))
+	for _, e := range validationErrs {
+		// remove the name of root struct, e.g. Config.database.mysql.dsn --> database.mysql.dsn
+		key := e.Namespace()
+		if i := strings.Index(key, "."); i >= 0 {
+			key = key[i+1:]
+		}
+		msg := fmt.Sprintf("'%s' failed on the '%s' rule", key, e.Tag())
+		if e.Param() != "" {
+			msg = fmt.Sprintf("'%s' failed on the '%s=%s' rule", key, e.Tag(), e.Param())
+		}
+		msgs = append(msgs, msg)
+	}
+	return fmt.Errorf("invalid config: %s", strings.Join(msgs, "; "))
+}
+
+// unmarshal the configuration to obj, expand the placeholders, decrypt the encrypted values and validate obj
+func decode(v *viper.Viper, obj interface{}) error {
+	err := v.Unmarshal(obj, decodeHook())
+	if err != nil {
+		return err
+	}
+	return Validate(obj)
+}
+
+// decode the configuration to a new object of the same type, make sure obj is not modified by invalid configuration
+func decodeAndApply(v *viper.Viper, obj interface{}) error {
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Ptr {
+		err := decode(v, reflect.New(t.Elem()).Interface())
+		if err != nil {
+			return err
+		}
+	}
+	return v.Unmarshal(obj, decodeHook())
+}
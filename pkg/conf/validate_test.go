@@ -0,0 +1,59 @@
+package conf
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validateConfig struct {
+	App struct {
+		Name  string `yaml:"name" validate:"required"`
+		Level string `yaml:"level" validate:"oneof=debug info warn error"`
+		Port  int    `yaml:"port" validate:"min=1,max=65535"`
+	} `yaml:"app"`
+}
+
+func TestValidate(t *testing.T) {
+	cfg := &validateConfig{}
+	cfg.App.Name = "demo"
+	cfg.App.Level = "info"
+	cfg.App.Port = 8080
+	assert.NoError(t, Validate(cfg))
+
+	cfg.App.Name = ""
+	cfg.App.Port = 0
+	err := Validate(cfg)
+	assert.EqualError(t, err, "invalid config: 'app.name' failed on the 'required' rule; 'app.port' failed on the 'min=1' rule")
+
+	// not struct
+	assert.NoError(t, Validate(map[string]interface{}{}))
+	assert.NoError(t, Validate((*validateConfig)(nil)))
+}
+
+func TestParseSourcesValidate(t *testing.T) {
+	remote := NewMemorySource("etcd", "yaml", []byte("app: {name: demo, level: info, port: 8080}"))
+	cfg := &validateConfig{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan *Event, 1)
+	err := ParseSources(ctx, cfg, []Source{remote}, WithOnChange(func(e *Event) { events <- e }))
+	require.NoError(t, err)
+
+	// invalid config is not applied
+	remote.Update([]byte("app: {name: demo, level: trace, port: 8080}"))
+	remote.Update([]byte("app: {name: demo, level: debug, port: 8080}"))
+	select {
+	case e := <-events:
+		assert.Equal(t, []string{"app.level"}, e.ChangedKeys)
+	case <-time.After(time.Second * 3):
+		t.Fatal("timeout")
+	}
+	assert.Equal(t, "debug", cfg.App.Level)
+
+	err = ParseSources(ctx, &validateConfig{}, []Source{NewMemorySource("etcd", "yaml", []byte("app: {name: demo}"))})
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+package consulcli
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+
+	"github.com/18721889353/sunshine/pkg/conf"
+)
+
+// KV is the consul key/value client used by Source, *api.KV implements it.
+type KV interface {
+	Get(key string, q *api.QueryOptions) (*api.KVPair, *api.QueryMeta, error)
+}
+
+// Source is the configuration source of a key in consul, it implements conf.Source.
+type Source struct {
+	kv       KV
+	key      string
+	format   string
+	waitTime time.Duration
+}
+
+var _ conf.Source = (*Source)(nil)
+
+// NewSource create a configuration source of consul, kv is got by client.KV(), the value of key is
+// the configuration data, format is the format of configuration data, e.g. yaml, json, toml.
+func NewSource(kv KV, key string, format string) *Source {
+	return &Source{
+		kv:       kv,
+		key:      key,
+		format:   format,
+		waitTime: time.Minute * 5,
+	}
+}
+
+// Name returns the name of source.
+func (s *Source) Name() string {
+	return "consul"
+}
+
+// Format returns the format of configuration data.
+func (s *Source) Format() string {
+	return s.format
+}
+
+// Load read the configuration data from consul, returns empty data if the key does not exist.
+func (s *Source) Load(ctx context.Context) ([]byte, error) {
+	pair, _, err := s.kv.Get(s.key, (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if pair == nil {
+		return nil, nil
+	}
+	return pair.Value, nil
+}
+
+// Watch the key by blocking queries until ctx is done, the deleted key is notified as empty data.
+func (s *Source) Watch(ctx context.Context, onChange func(data []byte)) error {
+	var index uint64
+	for {
+		opts := &api.QueryOptions{WaitIndex: index, WaitTime: s.waitTime}
+		pair, meta, err := s.kv.Get(s.key, opts.WithContext(ctx))
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		// the index may go backwards, e.g. the consul server is restored from snapshot
+		if meta.LastIndex < index {
+			index = 0
+			continue
+		}
+		if meta.LastIndex == index {
+			continue // timeout, no changes
+		}
+		index = meta.LastIndex
+
+		if pair == nil {
+			onChange(nil)
+		} else {
+			onChange(pair.Value)
+		}
+	}
+}
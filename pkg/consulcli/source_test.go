@@ -0,0 +1,94 @@
+package consulcli
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/18721889353/sunshine/pkg/conf"
+)
+
+// fake kv supports blocking queries
+type fakeKV struct {
+	mu      sync.Mutex
+	value   []byte
+	index   uint64
+	changed chan struct{}
+}
+
+func newFakeKV(value []byte) *fakeKV {
+	return &fakeKV{value: value, index: 1, changed: make(chan struct{})}
+}
+
+func (kv *fakeKV) Get(key string, q *api.QueryOptions) (*api.KVPair, *api.QueryMeta, error) {
+	kv.mu.Lock()
+	if q.WaitIndex > 0 && q.WaitIndex >= kv.index {
+		changed := kv.changed
+		kv.mu.Unlock()
+		select {
+		case <-q.Context().Done():
+			return nil, nil, q.Context().Err()
+		case <-changed:
+		}
+		kv.mu.Lock()
+	}
+	defer kv.mu.Unlock()
+
+	var pair *api.KVPair
+	if kv.value != nil {
+		pair = &api.KVPair{Key: key, Value: kv.value}
+	}
+	return pair, &api.QueryMeta{LastIndex: kv.index}, nil
+}
+
+func (kv *fakeKV) put(value []byte) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	kv.value = value
+	kv.index++
+	close(kv.changed)
+	kv.changed = make(chan struct{})
+}
+
+func TestSource(t *testing.T) {
+	kv := newFakeKV([]byte("app:\n  name: foo\n"))
+	source := NewSource(kv, "config/app.yml", "yaml")
+	assert.Equal(t, "consul", source.Name())
+	assert.Equal(t, "yaml", source.Format())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan *conf.Event, 1)
+	cfg := make(map[string]interface{})
+	err := conf.ParseSources(ctx, &cfg, []conf.Source{source}, conf.WithOnChange(func(e *conf.Event) {
+		events <- e
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "foo"}, cfg["app"])
+
+	kv.put([]byte("app:\n  name: bar\n"))
+	select {
+	case e := <-events:
+		assert.Equal(t, "consul", e.Source)
+		assert.Equal(t, []string{"app.name"}, e.ChangedKeys)
+	case <-time.After(time.Second * 3):
+		t.Fatal("timeout")
+	}
+
+	kv.put(nil)
+	select {
+	case e := <-events:
+		assert.Equal(t, []string{"app.name"}, e.ChangedKeys)
+	case <-time.After(time.Second * 3):
+		t.Fatal("timeout")
+	}
+
+	data, err := source.Load(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, data)
+}
@@ -45,6 +45,22 @@ func (g *Group) Get(key string) interface{} {
 	return v
 }
 
+// Range calls fn for each existing object, stops if fn returns false.
+func (g *Group) Range(fn func(key string, value interface{}) bool) {
+	g.RLock()
+	vals := make(map[string]interface{}, len(g.vals))
+	for k, v := range g.vals {
+		vals[k] = v
+	}
+	g.RUnlock()
+
+	for k, v := range vals {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
 // Reset resets the new function and deletes all existing objects.
 func (g *Group) Reset(new func() interface{}) {
 	if new == nil {
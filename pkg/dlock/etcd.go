@@ -14,6 +14,7 @@ var defaultTTL = 15 // seconds
 type EtcdLock struct {
 	session *concurrency.Session
 	mutex   *concurrency.Mutex
+	key     string
 }
 
 // NewEtcd creates a new etcd locker with the given key and ttl.
@@ -45,6 +46,7 @@ func NewEtcd(client *clientv3.Client, key string, ttl int) (Locker, error) {
 	locker := &EtcdLock{
 		session: session,
 		mutex:   mutex,
+		key:     key,
 	}
 
 	return locker, nil
@@ -72,6 +74,10 @@ func (l *EtcdLock) TryLock(ctx context.Context) (bool, error) {
 	return false, err
 }
 
+func (l *EtcdLock) describe() (string, string) {
+	return "etcd", l.key
+}
+
 // Close releases the lock and the etcd session.
 func (l *EtcdLock) Close() error {
 	if l.session != nil {
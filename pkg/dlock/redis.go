@@ -12,6 +12,7 @@ import (
 // RedisLock implements Locker using Redis.
 type RedisLock struct {
 	mutex *redsync.Mutex
+	key   string
 }
 
 // NewRedisLock creates a new RedisLock.
@@ -43,6 +44,7 @@ func newLocker(delegate redis.UniversalClient, key string, options ...redsync.Op
 
 	return &RedisLock{
 		mutex: mutex,
+		key:   key,
 	}
 }
 
@@ -66,6 +68,10 @@ func (l *RedisLock) Unlock(ctx context.Context) error {
 	return err
 }
 
+func (l *RedisLock) describe() (string, string) {
+	return "redis", l.key
+}
+
 // Close no-op for RedisLock.
 func (l *RedisLock) Close() error {
 	return nil
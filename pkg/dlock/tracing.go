@@ -0,0 +1,74 @@
+package dlock
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/18721889353/sunshine/pkg/dlock"
+
+// returns the backend system and the key of lock
+type describer interface {
+	describe() (system string, key string)
+}
+
+type tracingLocker struct {
+	Locker
+	attrs []attribute.KeyValue
+}
+
+// NewTracingLocker wraps the locker, each lock operation is recorded in a span.
+func NewTracingLocker(locker Locker) Locker {
+	var attrs []attribute.KeyValue
+	if d, ok := locker.(describer); ok {
+		system, key := d.describe()
+		attrs = append(attrs, semconv.DBSystemKey.String(system), attribute.String("dlock.key", key))
+	}
+	return &tracingLocker{Locker: locker, attrs: attrs}
+}
+
+func (l *tracingLocker) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, "dlock."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(l.attrs...),
+		trace.WithAttributes(semconv.DBOperationName(operation)),
+	)
+}
+
+// Lock blocks until the lock is acquired or the context is canceled.
+func (l *tracingLocker) Lock(ctx context.Context) error {
+	ctx, span := l.start(ctx, "Lock")
+	err := l.Locker.Lock(ctx)
+	endSpan(span, err)
+	return err
+}
+
+// Unlock releases the lock.
+func (l *tracingLocker) Unlock(ctx context.Context) error {
+	ctx, span := l.start(ctx, "Unlock")
+	err := l.Locker.Unlock(ctx)
+	endSpan(span, err)
+	return err
+}
+
+// TryLock tries to acquire the lock without blocking.
+func (l *tracingLocker) TryLock(ctx context.Context) (bool, error) {
+	ctx, span := l.start(ctx, "TryLock")
+	ok, err := l.Locker.TryLock(ctx)
+	span.SetAttributes(attribute.Bool("dlock.acquired", ok))
+	endSpan(span, err)
+	return ok, err
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,73 @@
+package dlock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type fakeLocker struct {
+	locked bool
+}
+
+func (l *fakeLocker) Lock(ctx context.Context) error {
+	if l.locked {
+		return errors.New("locked")
+	}
+	l.locked = true
+	return nil
+}
+
+func (l *fakeLocker) Unlock(ctx context.Context) error {
+	l.locked = false
+	return nil
+}
+
+func (l *fakeLocker) TryLock(ctx context.Context) (bool, error) {
+	if l.locked {
+		return false, nil
+	}
+	l.locked = true
+	return true, nil
+}
+
+func (l *fakeLocker) Close() error { return nil }
+
+func (l *fakeLocker) describe() (string, string) { return "redis", "test_lock" }
+
+func TestNewTracingLocker(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(tp)
+
+	ctx := context.Background()
+	locker := NewTracingLocker(&fakeLocker{})
+	ok, err := locker.TryLock(ctx)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	ok, _ = locker.TryLock(ctx)
+	assert.False(t, ok)
+	assert.Error(t, locker.Lock(ctx))
+	assert.NoError(t, locker.Unlock(ctx))
+	assert.NoError(t, locker.Close())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+	names := []string{}
+	for _, span := range spans {
+		names = append(names, span.Name())
+	}
+	assert.Equal(t, []string{"dlock.TryLock", "dlock.TryLock", "dlock.Lock", "dlock.Unlock"}, names)
+	assert.Contains(t, spans[0].Attributes(), attribute.String("db.system", "redis"))
+	assert.Contains(t, spans[0].Attributes(), attribute.String("dlock.key", "test_lock"))
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+
+	"google.golang.org/protobuf/protoadapt"
 )
 
 // ToHTTPCodeLabel need to convert to standard http code label
@@ -20,22 +22,21 @@ type Error struct {
 	msg     string
 	details []string
 
+	// typed error details of rpc status, they are kept when converting to rpc status
+	rpcDetails []protoadapt.MessageV1
+
 	// if true, need to convert to standard http code
 	// use ErrToHTTP and ParseError will set this to true
 	needHTTPCode bool
 }
 
-// NewError create a new error message
+// NewError create a new error message, the error code is registered to the registry,
+// if the same error code is used, it will cause panic.
 func NewError(code int, msg string, details ...string) *Error {
-	if v, ok := errCodes[code]; ok {
-		panic(fmt.Sprintf(`http error code = %d already exists, please define a new error code,
-msg1 = %s
-msg2 = %s
-`, code, v.Msg(), msg))
-	}
+	e := &Error{code: code, msg: msg, details: details}
+	registry.register(KindHTTP, &codeEntry{code: code, desc: msg, httpError: e})
 
 	httpErrCodes[code] = msg
-	e := &Error{code: code, msg: msg, details: details}
 	errCodes[code] = e
 	return e
 }
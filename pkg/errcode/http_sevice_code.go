@@ -1,18 +1,24 @@
 package errcode
 
-// HCode Generate an error code between 20000 and 30000 according to the number
+// HCode Generate an error code between 20000 and 30000 according to the number,
+// the optional module name is recorded in the registry, if the same number is used by
+// different modules, it will cause panic.
 //
 // http service level error code, Err prefix, example.
 //
 // var (
-// ErrUserCreate = NewError(HCode(1)+1, "failed to create user")		// 20101
-// ErrUserDelete = NewError(HCode(1)+2, "failed to delete user")		// 20102
-// ErrUserUpdate = NewError(HCode(1)+3, "failed to update user")		// 20103
-// ErrUserGet    = NewError(HCode(1)+4, "failed to get user details")	// 20104
+// ErrUserCreate = NewError(HCode(1, "user")+1, "failed to create user")		// 20101
+// ErrUserDelete = NewError(HCode(1, "user")+2, "failed to delete user")		// 20102
+// ErrUserUpdate = NewError(HCode(1, "user")+3, "failed to update user")		// 20103
+// ErrUserGet    = NewError(HCode(1, "user")+4, "failed to get user details")	// 20104
 // )
-func HCode(num int) int {
+func HCode(num int, module ...string) int {
 	if num > 99 || num < 1 {
 		panic("num range must be between 0 to 100")
 	}
-	return 20000 + num*100
+	code := 20000 + num*100
+	if len(module) > 0 && module[0] != "" {
+		registry.registerModule(code, module[0])
+	}
+	return code
 }
@@ -0,0 +1,209 @@
+package errcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog is the message catalog of error codes in multiple languages, the messages of each language
+// are loaded from a yaml or json file, e.g. en-US.yml, zh-CN.json, the content is a map of code to message:
+//
+//	10001: "Invalid Parameter"
+//	20010: "login failed"
+type Catalog struct {
+	defaultLang string
+
+	mu    sync.RWMutex
+	langs map[string]map[int]string // lower case language tag -> code -> message
+	names map[string]string         // lower case language tag -> original language tag
+}
+
+// NewCatalog creates a message catalog, defaultLang is used if Accept-Language does not match any language.
+func NewCatalog(defaultLang string) *Catalog {
+	return &Catalog{
+		defaultLang: defaultLang,
+		langs:       make(map[string]map[int]string),
+		names:       make(map[string]string),
+	}
+}
+
+// Add adds the messages of language, the existing messages of the same code are replaced.
+func (c *Catalog) Add(lang string, msgs map[int]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := strings.ToLower(lang)
+	m, ok := c.langs[key]
+	if !ok {
+		m = make(map[int]string, len(msgs))
+		c.langs[key] = m
+		c.names[key] = lang
+	}
+	for code, msg := range msgs {
+		m[code] = msg
+	}
+}
+
+// LoadFile loads the messages of language from yaml or json file, the language is the file name
+// without extension, e.g. locales/zh-CN.yml is zh-CN.
+func (c *Catalog) LoadFile(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	ext := filepath.Ext(file)
+	lang := strings.TrimSuffix(filepath.Base(file), ext)
+	raw := map[string]string{}
+	switch strings.ToLower(ext) {
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	default:
+		return fmt.Errorf("unsupported message file format '%s'", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("parse message file '%s' error: %v", file, err)
+	}
+
+	msgs := make(map[int]string, len(raw))
+	for k, v := range raw {
+		code, err := strconv.Atoi(k)
+		if err != nil {
+			return fmt.Errorf("invalid error code '%s' in message file '%s'", k, file)
+		}
+		msgs[code] = v
+	}
+	c.Add(lang, msgs)
+	return nil
+}
+
+// LoadDir loads all yaml and json files in dir, each file is a language.
+func (c *Catalog) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yml", ".yaml", ".json":
+			if err = c.LoadFile(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Languages returns the languages loaded.
+func (c *Catalog) Languages() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	langs := make([]string, 0, len(c.names))
+	for _, name := range c.names {
+		langs = append(langs, name)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Message returns the message of code in language, returns false if not found.
+func (c *Catalog) Message(lang string, code int) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	msg, ok := c.langs[strings.ToLower(lang)][code]
+	return msg, ok
+}
+
+// Localize returns a new error with the message in language, the original error is returned if not found.
+func (c *Catalog) Localize(e *Error, lang string) *Error {
+	if msg, ok := c.Message(lang, e.Code()); ok {
+		return e.RewriteMsg(msg)
+	}
+	return e
+}
+
+// Match returns the language negotiated by the value of Accept-Language header, e.g. zh-CN,zh;q=0.9,en;q=0.8,
+// the base language matches the region language, e.g. zh matches zh-CN, the default language is returned if not matched.
+func (c *Catalog) Match(acceptLanguage string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if tag == "*" {
+			break
+		}
+		if _, ok := c.langs[tag]; ok {
+			return c.names[tag]
+		}
+		base := strings.SplitN(tag, "-", 2)[0]
+		if _, ok := c.langs[base]; ok {
+			return c.names[base]
+		}
+		// the first language with the same base, sorted for stable result
+		var matched []string
+		for key := range c.langs {
+			if strings.HasPrefix(key, base+"-") {
+				matched = append(matched, key)
+			}
+		}
+		if len(matched) > 0 {
+			sort.Strings(matched)
+			return c.names[matched[0]]
+		}
+	}
+
+	return c.defaultLang
+}
+
+type langQuality struct {
+	tag string
+	q   float64
+}
+
+// parse Accept-Language to lower case tags sorted by quality
+func parseAcceptLanguage(value string) []string {
+	var lqs []langQuality
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lq := langQuality{tag: part, q: 1}
+		if i := strings.Index(part, ";"); i >= 0 {
+			lq.tag = strings.TrimSpace(part[:i])
+			param := strings.TrimSpace(part[i+1:])
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					lq.q = q
+				}
+			}
+		}
+		if lq.q <= 0 {
+			continue
+		}
+		lq.tag = strings.ToLower(strings.ReplaceAll(lq.tag, "_", "-"))
+		lqs = append(lqs, lq)
+	}
+
+	sort.SliceStable(lqs, func(i, j int) bool { return lqs[i].q > lqs[j].q })
+	tags := make([]string, 0, len(lqs))
+	for _, lq := range lqs {
+		tags = append(tags, lq.tag)
+	}
+	return tags
+}
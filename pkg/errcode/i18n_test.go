@@ -0,0 +1,76 @@
+package errcode
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCatalog(t *testing.T) *Catalog {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "zh-CN.yml"), []byte("10001: 参数错误\n0: 成功\n"), 0666))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "en-US.json"), []byte(`{"10001": "Invalid Parameter"}`), 0666))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0666))
+
+	c := NewCatalog("en-US")
+	require.NoError(t, c.LoadDir(dir))
+	return c
+}
+
+func TestCatalog(t *testing.T) {
+	c := newTestCatalog(t)
+	assert.Equal(t, []string{"en-US", "zh-CN"}, c.Languages())
+
+	msg, ok := c.Message("zh-cn", 10001)
+	assert.True(t, ok)
+	assert.Equal(t, "参数错误", msg)
+	_, ok = c.Message("zh-CN", 99999)
+	assert.False(t, ok)
+
+	c.Add("ja", map[int]string{10001: "無効なパラメータ"})
+	assert.Equal(t, "無効なパラメータ", c.Localize(InvalidParams, "ja").Msg())
+	assert.Equal(t, InvalidParams.Msg(), c.Localize(InvalidParams, "fr").Msg())
+}
+
+func TestCatalog_Match(t *testing.T) {
+	c := newTestCatalog(t)
+	tests := map[string]string{
+		"":                             "en-US",
+		"zh-CN":                        "zh-CN",
+		"zh":                           "zh-CN",
+		"zh-TW,zh;q=0.9":               "zh-CN",
+		"fr-FR,en;q=0.8,zh-CN;q=0.9":   "zh-CN",
+		"en-GB,en;q=0.9":               "en-US",
+		"fr,*;q=0.5":                   "en-US",
+		"zh_CN":                        "zh-CN",
+		"zh-CN;q=0,en-US;q=0.1":        "en-US",
+		"de, fr;q=invalid, zh-CN;q=.5": "zh-CN",
+	}
+	for acceptLanguage, want := range tests {
+		assert.Equal(t, want, c.Match(acceptLanguage), acceptLanguage)
+	}
+}
+
+func TestCatalog_LoadFileError(t *testing.T) {
+	dir := t.TempDir()
+	c := NewCatalog("en-US")
+
+	assert.Error(t, c.LoadFile(filepath.Join(dir, "not_exist.yml")))
+
+	file := filepath.Join(dir, "en-US.toml")
+	require.NoError(t, os.WriteFile(file, []byte(`10001 = "x"`), 0666))
+	assert.Error(t, c.LoadFile(file))
+
+	file = filepath.Join(dir, "en-US.yml")
+	require.NoError(t, os.WriteFile(file, []byte(`abc: x`), 0666))
+	assert.Error(t, c.LoadFile(file))
+
+	file = filepath.Join(dir, "en-US.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{`), 0666))
+	assert.Error(t, c.LoadFile(file))
+	assert.Error(t, c.LoadDir(dir))
+	assert.Error(t, c.LoadDir(filepath.Join(dir, "not_exist")))
+}
@@ -0,0 +1,111 @@
+package errcode
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// ProblemContentType is the content type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// Problem is the RFC 7807 problem details, the extension members code and errors are added.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     int          `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+
+	// typed error details of rpc status in json format of google.rpc.Status
+	Details []json.RawMessage `json:"details,omitempty"`
+}
+
+// ResponserOption set the responser options.
+type ResponserOption func(*responserOptions)
+
+type responserOptions struct {
+	problemJSON bool
+	typeURI     string
+	catalog     *Catalog
+}
+
+func defaultResponserOptions() *responserOptions {
+	return &responserOptions{
+		typeURI: "about:blank",
+	}
+}
+
+func (o *responserOptions) apply(opts ...ResponserOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithProblemJSON render the errors as RFC 7807 application/problem+json, the response status is always
+// the http status of the error code, typeURI is the prefix of problem type, e.g. https://example.com/errors/,
+// the type is typeURI + code, default is about:blank.
+func WithProblemJSON(typeURI ...string) ResponserOption {
+	return func(o *responserOptions) {
+		o.problemJSON = true
+		if len(typeURI) > 0 && typeURI[0] != "" {
+			o.typeURI = typeURI[0]
+		}
+	}
+}
+
+// WithCatalog set the message catalog, the message of error code is translated to the language
+// negotiated by the Accept-Language header.
+func WithCatalog(catalog *Catalog) ResponserOption {
+	return func(o *responserOptions) {
+		o.catalog = catalog
+	}
+}
+
+func problemStatus(respStatus int, code int) int {
+	if respStatus != http.StatusOK {
+		return respStatus
+	}
+	if code < 0 || code == InternalServerError.Code() {
+		return http.StatusInternalServerError
+	}
+	if e, ok := errCodes[code]; ok {
+		if status := e.ToHTTPCode(); status != http.StatusInternalServerError {
+			return status
+		}
+	}
+	return http.StatusBadRequest
+}
+
+// NewProblem creates the problem details, typeURI is the prefix of problem type, empty means about:blank,
+// respStatus is the http status, if it is 200, the http status is got from the error code, the business
+// error codes not mapped to http status are regarded as 400.
+func NewProblem(c *gin.Context, typeURI string, respStatus int, code int, msg string, fieldErrors []FieldError) *Problem {
+	status := problemStatus(respStatus, code)
+	p := &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: msg,
+		Code:   code,
+		Errors: fieldErrors,
+	}
+	if typeURI != "" && typeURI != "about:blank" {
+		p.Type = typeURI + strconv.Itoa(code)
+	}
+	if c.Request != nil && c.Request.URL != nil {
+		p.Instance = c.Request.URL.Path
+	}
+	return p
+}
+
+// RenderProblem writes the problem details as application/problem+json.
+func RenderProblem(c *gin.Context, p *Problem) {
+	c.Header("Content-Type", ProblemContentType)
+	c.Render(p.Status, render.JSON{Data: p})
+}
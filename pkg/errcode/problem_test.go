@@ -0,0 +1,148 @@
+package errcode
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type createForm struct {
+	Name string `json:"name" binding:"required"`
+	Age  int    `json:"age" binding:"gte=0,lte=150"`
+}
+
+func newResponserEngine(resp Responser) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/success", func(c *gin.Context) { resp.Success(c, "ok") })
+	r.GET("/notfound", func(c *gin.Context) { resp.Error(c, NotFound.Err()) })
+	r.GET("/internal", func(c *gin.Context) { resp.Error(c, InternalServerError.Err()) })
+	r.GET("/custom", func(c *gin.Context) { resp.Error(c, InvalidParams.Err("id is required")) })
+	r.GET("/unknown", func(c *gin.Context) { resp.Error(c, errors.New("unknown")) })
+	r.POST("/users", func(c *gin.Context) {
+		form := &createForm{}
+		if err := c.ShouldBindJSON(form); err != nil {
+			resp.ParamError(c, err)
+			return
+		}
+		resp.Success(c, form)
+	})
+	return r
+}
+
+func doRequest(r http.Handler, method string, path string, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestResponser_ProblemJSON(t *testing.T) {
+	r := newResponserEngine(NewResponser(false, nil, nil, WithProblemJSON("https://example.com/errors/")))
+
+	w := doRequest(r, http.MethodGet, "/success", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":0`)
+
+	w = doRequest(r, http.MethodGet, "/notfound", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	p := &Problem{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), p))
+	assert.Equal(t, "https://example.com/errors/10004", p.Type)
+	assert.Equal(t, "Not Found", p.Title)
+	assert.Equal(t, NotFound.Code(), p.Code)
+	assert.Equal(t, "/notfound", p.Instance)
+
+	w = doRequest(r, http.MethodGet, "/internal", "", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	w = doRequest(r, http.MethodGet, "/unknown", "", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	w = doRequest(r, http.MethodPost, "/users", `{"age":200}`, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	p = &Problem{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), p))
+	assert.Equal(t, InvalidParams.Code(), p.Code)
+	require.Len(t, p.Errors, 2)
+	assert.Equal(t, "required", p.Errors[0].Rule)
+	assert.Equal(t, "lte", p.Errors[1].Rule)
+	assert.Equal(t, "150", p.Errors[1].Param)
+
+	// business error code that is not mapped to http status
+	p = NewProblem(&gin.Context{}, "", http.StatusOK, 20001, "login failed", nil)
+	assert.Equal(t, http.StatusBadRequest, p.Status)
+	assert.Equal(t, "about:blank", p.Type)
+}
+
+func TestResponser_ParamError(t *testing.T) {
+	r := newResponserEngine(NewResponser(false, nil, nil))
+
+	w := doRequest(r, http.MethodPost, "/users", `{"age":-1}`, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	result := struct {
+		Code int
+		Data struct{ Errors []FieldError }
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, InvalidParams.Code(), result.Code)
+	assert.Len(t, result.Data.Errors, 2)
+
+	w = doRequest(r, http.MethodPost, "/users", `{"name":1}`, nil)
+	assert.Contains(t, w.Body.String(), `"rule":"type"`)
+
+	w = doRequest(r, http.MethodPost, "/users", `{`, nil)
+	assert.Equal(t, `{"code":10001,"data":{},"msg":"Invalid Parameter"}`, w.Body.String())
+}
+
+func TestResponser_Catalog(t *testing.T) {
+	c := NewCatalog("en-US")
+	c.Add("zh-CN", map[int]string{0: "成功", 10001: "参数错误", 10004: "资源不存在", 500: "服务器内部错误"})
+	r := newResponserEngine(NewResponser(false, nil, nil, WithCatalog(c)))
+	zh := map[string]string{"Accept-Language": "zh-CN,zh;q=0.9"}
+
+	w := doRequest(r, http.MethodGet, "/notfound", "", zh)
+	assert.Contains(t, w.Body.String(), "资源不存在")
+
+	w = doRequest(r, http.MethodGet, "/internal", "", zh)
+	assert.Contains(t, w.Body.String(), "服务器内部错误")
+
+	// the custom message is not translated
+	w = doRequest(r, http.MethodGet, "/custom", "", zh)
+	assert.Contains(t, w.Body.String(), "id is required")
+
+	w = doRequest(r, http.MethodGet, "/notfound", "", map[string]string{"Accept-Language": "en"})
+	assert.Contains(t, w.Body.String(), NotFound.Msg())
+}
+
+func TestValidationErrors(t *testing.T) {
+	assert.Nil(t, ValidationErrors(nil))
+	assert.Nil(t, ValidationErrors(errors.New("other error")))
+
+	err := binding.Validator.ValidateStruct(&createForm{Age: 151})
+	fes := ValidationErrors(err)
+	require.Len(t, fes, 2)
+	assert.Equal(t, "field 'Name' failed on the 'required' rule", fes[0].Message)
+	assert.Equal(t, "field 'Age' failed on the 'lte=150' rule", fes[1].Message)
+
+	form := &struct {
+		ID int `form:"id"`
+	}{}
+	err = binding.Query.Bind(httptest.NewRequest(http.MethodGet, "/?id=abc", nil), form)
+	fes = ValidationErrors(err)
+	require.Len(t, fes, 1)
+	assert.Equal(t, "type", fes[0].Rule)
+}
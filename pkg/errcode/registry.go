@@ -0,0 +1,293 @@
+package errcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
+// error code kinds
+const (
+	KindHTTP = "http"
+	KindGRPC = "grpc"
+)
+
+// export formats of error codes
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+	FormatOpenAPI  = "openapi"
+)
+
+// SystemModule is the module name of system level error codes
+const SystemModule = "system"
+
+// CodeInfo is the registered error code
+type CodeInfo struct {
+	Code        int    `json:"code"`
+	Kind        string `json:"kind"`       // http or grpc
+	Module      string `json:"module"`     // module name, system level error codes are system
+	HTTPStatus  int    `json:"httpStatus"` // standard http status code
+	GRPCCode    string `json:"grpcCode"`   // standard grpc code, e.g. NotFound
+	Description string `json:"description"`
+}
+
+type codeEntry struct {
+	code      int
+	desc      string
+	httpError *Error
+	rpcStatus *RPCStatus
+}
+
+// registry of all error codes, the duplicate code of the same kind causes panic at init
+type codeRegistry struct {
+	mu      sync.RWMutex
+	codes   map[string]map[int]*codeEntry // kind -> code -> entry
+	modules map[int]string                // base code -> module name
+}
+
+var registry = &codeRegistry{
+	codes:   map[string]map[int]*codeEntry{KindHTTP: {}, KindGRPC: {}},
+	modules: map[int]string{},
+}
+
+func (r *codeRegistry) register(kind string, entry *codeEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v, ok := r.codes[kind][entry.code]; ok {
+		panic(fmt.Sprintf(`%s error code = %d already exists, please define a new error code,
+msg1 = %s
+msg2 = %s
+`, kind, entry.code, v.desc, entry.desc))
+	}
+	r.codes[kind][entry.code] = entry
+}
+
+func (r *codeRegistry) registerModule(baseCode int, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v, ok := r.modules[baseCode]; ok && v != name {
+		panic(fmt.Sprintf(`module number of base code %d is used by both '%s' and '%s', please use a new module number`,
+			baseCode, v, name))
+	}
+	r.modules[baseCode] = name
+}
+
+// module name of code, the business level error codes without module name are named by module number, e.g. module1
+func (r *codeRegistry) module(code int) string {
+	switch {
+	case code == 0, code >= 10000 && code < 20000, code >= 30000 && code < 40000:
+		return SystemModule
+	}
+	if name, ok := r.modules[code/100*100]; ok {
+		return name
+	}
+	if (code >= 20000 && code < 30000) || (code >= 40000 && code < 50000) {
+		return "module" + strconv.Itoa(code/100%100)
+	}
+	return ""
+}
+
+func (r *codeRegistry) list() []CodeInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// the standard grpc code of http error is got from the system level rpc status converted to it
+	rpcCodes := map[int]codes.Code{}
+	for _, entry := range r.codes[KindGRPC] {
+		if r.module(entry.code) == SystemModule {
+			rpcCodes[ToHTTPErr(entry.rpcStatus.status).Code()] = entry.rpcStatus.ToRPCCode()
+		}
+	}
+
+	infos := make([]CodeInfo, 0, len(r.codes[KindHTTP])+len(r.codes[KindGRPC]))
+	for _, entry := range r.codes[KindHTTP] {
+		httpStatus := entry.httpError.ToHTTPCode()
+		grpcCode, ok := rpcCodes[entry.code]
+		if !ok {
+			grpcCode = httpStatusToRPCCode(httpStatus)
+		}
+		infos = append(infos, CodeInfo{
+			Code:        entry.code,
+			Kind:        KindHTTP,
+			Module:      r.module(entry.code),
+			HTTPStatus:  httpStatus,
+			GRPCCode:    grpcCode.String(),
+			Description: entry.desc,
+		})
+	}
+	for _, entry := range r.codes[KindGRPC] {
+		infos = append(infos, CodeInfo{
+			Code:        entry.code,
+			Kind:        KindGRPC,
+			Module:      r.module(entry.code),
+			HTTPStatus:  convertToHTTPCode(entry.rpcStatus.Code()),
+			GRPCCode:    entry.rpcStatus.ToRPCCode().String(),
+			Description: entry.desc,
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Kind != infos[j].Kind {
+			return infos[i].Kind == KindHTTP
+		}
+		return infos[i].Code < infos[j].Code
+	})
+	return infos
+}
+
+func httpStatusToRPCCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusRequestTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
+
+// ListCodes list all registered http and grpc error codes, sorted by kind and code.
+func ListCodes() []CodeInfo {
+	return registry.list()
+}
+
+// ExportCodes writes the registered error codes in format json, markdown or openapi,
+// the openapi format is the components of OpenAPI 3 document, which can be merged into api document.
+func ExportCodes(w io.Writer, format string) error {
+	infos := ListCodes()
+	switch strings.ToLower(format) {
+	case FormatJSON, "":
+		return writeJSON(w, infos)
+	case FormatMarkdown, "md":
+		return writeMarkdown(w, infos)
+	case FormatOpenAPI:
+		return writeJSON(w, openAPIComponents(infos))
+	}
+	return fmt.Errorf("unsupported format '%s', only supports json, markdown and openapi", format)
+}
+
+// CodesHandler is the http handler func of listing error codes, the query parameter format is
+// json (default), markdown or openapi, e.g. /codes?format=markdown
+func CodesHandler(w http.ResponseWriter, r *http.Request) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	contentType := "application/json; charset=utf-8"
+	if format == FormatMarkdown || format == "md" {
+		contentType = "text/markdown; charset=utf-8"
+	}
+
+	buf := &strings.Builder{}
+	if err := ExportCodes(buf, format); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, buf.String())
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func writeMarkdown(w io.Writer, infos []CodeInfo) error {
+	sb := &strings.Builder{}
+	sb.WriteString("# Error Codes\n")
+	for _, kind := range []string{KindHTTP, KindGRPC} {
+		fmt.Fprintf(sb, "\n## %s error codes\n\n", kind)
+		sb.WriteString("| Code | Module | HTTP Status | gRPC Code | Description |\n")
+		sb.WriteString("|:-----|:-------|:------------|:----------|:------------|\n")
+		for _, info := range infos {
+			if info.Kind != kind {
+				continue
+			}
+			fmt.Fprintf(sb, "| %d | %s | %d | %s | %s |\n", info.Code, info.Module, info.HTTPStatus,
+				info.GRPCCode, strings.ReplaceAll(info.Description, "|", `\|`))
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// the components of OpenAPI 3 document, including the schemas of error codes and error response,
+// and the error responses grouped by http status
+func openAPIComponents(infos []CodeInfo) map[string]interface{} {
+	schemas := map[string]interface{}{}
+	for _, kind := range []string{KindHTTP, KindGRPC} {
+		var enum []int
+		var descriptions []string
+		for _, info := range infos {
+			if info.Kind == kind {
+				enum = append(enum, info.Code)
+				descriptions = append(descriptions, info.Description)
+			}
+		}
+		schemas[strings.ToUpper(kind)+"ErrorCode"] = map[string]interface{}{
+			"type":                "integer",
+			"description":         kind + " error codes",
+			"enum":                enum,
+			"x-enum-descriptions": descriptions,
+		}
+	}
+	schemas["ErrorResponse"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"code": map[string]interface{}{"$ref": "#/components/schemas/HTTPErrorCode"},
+			"msg":  map[string]interface{}{"type": "string"},
+			"data": map[string]interface{}{"type": "object"},
+		},
+	}
+
+	statusCodes := map[int][]string{}
+	for _, info := range infos {
+		if info.Kind == KindHTTP && info.HTTPStatus != http.StatusOK {
+			statusCodes[info.HTTPStatus] = append(statusCodes[info.HTTPStatus], fmt.Sprintf("%d: %s", info.Code, info.Description))
+		}
+	}
+	responses := map[string]interface{}{}
+	for status, lines := range statusCodes {
+		responses["Error"+strconv.Itoa(status)] = map[string]interface{}{
+			"description": http.StatusText(status) + ", error codes:\n" + strings.Join(lines, "\n"),
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{"$ref": "#/components/schemas/ErrorResponse"},
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"components": map[string]interface{}{
+			"schemas":   schemas,
+			"responses": responses,
+		},
+	}
+}
@@ -0,0 +1,104 @@
+package errcode
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testOrderBaseCode = HCode(95, "order")
+	errTestOrderPaid  = NewError(testOrderBaseCode+1, "order is paid")
+
+	testPayBaseCode = RCode(95, "pay")
+	statusTestPay   = NewRPCStatus(testPayBaseCode+1, "failed to pay | retry later")
+)
+
+func findCode(infos []CodeInfo, kind string, code int) CodeInfo {
+	for _, info := range infos {
+		if info.Kind == kind && info.Code == code {
+			return info
+		}
+	}
+	return CodeInfo{}
+}
+
+func TestListCodes(t *testing.T) {
+	infos := ListCodes()
+	require.NotEmpty(t, infos)
+	assert.Equal(t, KindHTTP, infos[0].Kind)
+	assert.Equal(t, KindGRPC, infos[len(infos)-1].Kind)
+
+	assert.Equal(t, CodeInfo{Code: 10004, Kind: KindHTTP, Module: SystemModule, HTTPStatus: http.StatusNotFound,
+		GRPCCode: "NotFound", Description: "Not Found"}, findCode(infos, KindHTTP, NotFound.Code()))
+	assert.Equal(t, "Canceled", findCode(infos, KindHTTP, Canceled.Code()).GRPCCode)
+	assert.Equal(t, CodeInfo{Code: 29501, Kind: KindHTTP, Module: "order", HTTPStatus: http.StatusInternalServerError,
+		GRPCCode: "Internal", Description: "order is paid"}, findCode(infos, KindHTTP, errTestOrderPaid.Code()))
+
+	info := findCode(infos, KindGRPC, int(StatusNotFound.Code()))
+	assert.Equal(t, http.StatusNotFound, info.HTTPStatus)
+	assert.Equal(t, "NotFound", info.GRPCCode)
+	assert.Equal(t, "pay", findCode(infos, KindGRPC, int(statusTestPay.Code())).Module)
+
+	assert.Equal(t, "module94", registry.module(29401))
+	assert.Equal(t, "", registry.module(60001))
+}
+
+func TestRegistryCollision(t *testing.T) {
+	assert.Panics(t, func() { NewError(errTestOrderPaid.Code(), "order is closed") })
+	assert.Panics(t, func() { NewRPCStatus(statusTestPay.Code(), "failed to refund") })
+	assert.Panics(t, func() { HCode(95, "user") })
+	assert.NotPanics(t, func() { HCode(95, "order") })
+	assert.NotPanics(t, func() { RCode(95) })
+}
+
+func TestExportCodes(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, ExportCodes(buf, FormatJSON))
+	var infos []CodeInfo
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &infos))
+	assert.Equal(t, ListCodes(), infos)
+
+	buf.Reset()
+	require.NoError(t, ExportCodes(buf, FormatMarkdown))
+	assert.Contains(t, buf.String(), "| 10001 | system | 400 | InvalidArgument | Invalid Parameter |")
+	assert.Contains(t, buf.String(), `failed to pay \| retry later`)
+
+	buf.Reset()
+	require.NoError(t, ExportCodes(buf, FormatOpenAPI))
+	doc := struct {
+		Components struct {
+			Schemas   map[string]map[string]interface{}
+			Responses map[string]map[string]interface{}
+		}
+	}{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Contains(t, doc.Components.Schemas, "HTTPErrorCode")
+	assert.Contains(t, doc.Components.Schemas, "GRPCErrorCode")
+	assert.Contains(t, doc.Components.Schemas, "ErrorResponse")
+	assert.Contains(t, doc.Components.Responses["Error404"]["description"], "10004: Not Found")
+	assert.NotContains(t, doc.Components.Responses, "Error200")
+
+	assert.Error(t, ExportCodes(buf, "xml"))
+}
+
+func TestCodesHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	CodesHandler(w, httptest.NewRequest(http.MethodGet, "/codes", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	w = httptest.NewRecorder()
+	CodesHandler(w, httptest.NewRequest(http.MethodGet, "/codes?format=markdown", nil))
+	assert.Equal(t, "text/markdown; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "## grpc error codes")
+
+	w = httptest.NewRecorder()
+	CodesHandler(w, httptest.NewRequest(http.MethodGet, "/codes?format=xml", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -1,7 +1,9 @@
 package errcode
 
 import (
+	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -21,8 +23,12 @@ type Responser interface {
 	Error(ctx *gin.Context, err error) bool
 }
 
-// NewResponser creates a new responser, if isFromRPC=true, it means return from rpc, otherwise default return from http
-func NewResponser(isFromRPC bool, httpErrors []*Error, rpcStatus []*RPCStatus) Responser {
+// NewResponser creates a new responser, if isFromRPC=true, it means return from rpc, otherwise default return from http,
+// the options WithProblemJSON and WithCatalog are supported, e.g. use different responser for each router.
+func NewResponser(isFromRPC bool, httpErrors []*Error, rpcStatus []*RPCStatus, opts ...ResponserOption) Responser {
+	o := defaultResponserOptions()
+	o.apply(opts...)
+
 	httpErrorsMap := make(map[int]*Error)
 	rpcStatusMap := make(map[int]*RPCStatus)
 
@@ -44,6 +50,7 @@ func NewResponser(isFromRPC bool, httpErrors []*Error, rpcStatus []*RPCStatus) R
 		isFromRPC:  isFromRPC,
 		httpErrors: httpErrorsMap,
 		rpcStatus:  rpcStatusMap,
+		opts:       o,
 	}
 }
 
@@ -51,9 +58,32 @@ type defaultResponse struct {
 	isFromRPC  bool // error comes from grpc, if not, default is from http
 	httpErrors map[int]*Error
 	rpcStatus  map[int]*RPCStatus
+	opts       *responserOptions
 }
 
 func (resp *defaultResponse) response(c *gin.Context, respStatus, code int, msg string, data interface{}) {
+	resp.render(c, respStatus, code, msg, data, nil)
+}
+
+func (resp *defaultResponse) render(c *gin.Context, respStatus, code int, msg string, data interface{}, fieldErrors []FieldError) {
+	msg = resp.localize(c, code, msg)
+
+	if resp.opts.problemJSON && code != Success.Code() {
+		p := NewProblem(c, resp.opts.typeURI, respStatus, code, msg, fieldErrors)
+		if m, ok := data.(map[string]interface{}); ok {
+			p.Details, _ = m["details"].([]json.RawMessage)
+		}
+		RenderProblem(c, p)
+		return
+	}
+
+	if len(fieldErrors) > 0 {
+		if m, ok := data.(map[string]interface{}); ok {
+			m["errors"] = fieldErrors
+		} else {
+			data = map[string]interface{}{"errors": fieldErrors}
+		}
+	}
 	c.JSON(respStatus, map[string]interface{}{
 		"code": code,
 		"msg":  msg,
@@ -61,14 +91,39 @@ func (resp *defaultResponse) response(c *gin.Context, respStatus, code int, msg
 	})
 }
 
+// translate the default message of code to the language negotiated by Accept-Language,
+// the custom message is not translated
+func (resp *defaultResponse) localize(c *gin.Context, code int, msg string) string {
+	catalog := resp.opts.catalog
+	if catalog == nil || !isDefaultMsg(code, msg) {
+		return msg
+	}
+	lang := catalog.Match(c.GetHeader("Accept-Language"))
+	if m, ok := catalog.Message(lang, code); ok {
+		return m
+	}
+	return msg
+}
+
+func isDefaultMsg(code int, msg string) bool {
+	if e, ok := errCodes[code]; ok && e.Msg() == msg {
+		return true
+	}
+	if s, ok := grpcErrCodes[code]; ok && s == msg {
+		return true
+	}
+	return msg == http.StatusText(code)
+}
+
 // Success response success information
 func (resp *defaultResponse) Success(c *gin.Context, data interface{}) {
 	resp.response(c, http.StatusOK, 0, "ok", data)
 }
 
-// ParamError response parameter error information, does not return an error message
-func (resp *defaultResponse) ParamError(c *gin.Context, _ error) {
-	resp.response(c, http.StatusOK, InvalidParams.Code(), InvalidParams.Msg(), struct{}{})
+// ParamError response parameter error information, the field errors of validation are returned in errors,
+// other error messages are not returned
+func (resp *defaultResponse) ParamError(c *gin.Context, err error) {
+	resp.render(c, http.StatusOK, InvalidParams.Code(), InvalidParams.Msg(), struct{}{}, ValidationErrors(err))
 }
 
 // Error response error information, if return true, means that the error code is converted to a standard http code,
@@ -83,19 +138,30 @@ func (resp *defaultResponse) Error(c *gin.Context, err error) bool {
 	return resp.handleHTTPError(c, err)
 }
 
-// error from grpc
+// error from grpc, the typed error details of rpc status are returned in data.details as the json format
+// of google.rpc.Status, the field violations of BadRequest are returned in errors
 func (resp *defaultResponse) handleRPCError(c *gin.Context, err error) bool {
 	st, _ := status.FromError(err)
+	sd := parseStatusDetails(st)
+	if sd.retryDelay > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(sd.retryDelay.Seconds()))))
+	}
 
 	// user defined err, response 200
 	if st.Code() == codes.Unknown {
+		if errcodeErrorInfo(st) != nil {
+			// err converted from Error by ToRPCErr
+			e := statusToError(st)
+			resp.render(c, http.StatusOK, e.Code(), sd.msg(e.Msg()), sd.data, sd.fieldErrors)
+			return false
+		}
 		code, msg := parseCodeAndMsg(st.String())
 		if code == -1 {
 			// non-conforming err
-			resp.response(c, http.StatusOK, -1, "unknown error", struct{}{})
+			resp.render(c, http.StatusOK, -1, "unknown error", sd.data, sd.fieldErrors)
 		} else {
 			// err created using NewRPCStatus
-			resp.response(c, http.StatusOK, code, msg, struct{}{})
+			resp.render(c, http.StatusOK, code, sd.msg(msg), sd.data, sd.fieldErrors)
 		}
 		return false
 	}
@@ -103,10 +169,10 @@ func (resp *defaultResponse) handleRPCError(c *gin.Context, err error) bool {
 	// default error code to http
 	switch st.Code() {
 	case codes.Internal, StatusInternalServerError.status.Code():
-		resp.response(c, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), struct{}{})
+		resp.render(c, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), sd.data, sd.fieldErrors)
 		return true
 	case codes.Unavailable, StatusServiceUnavailable.status.Code():
-		resp.response(c, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable), struct{}{})
+		resp.render(c, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable), sd.data, sd.fieldErrors)
 		return true
 	}
 
@@ -114,17 +180,17 @@ func (resp *defaultResponse) handleRPCError(c *gin.Context, err error) bool {
 	if strings.Contains(st.Message(), ToHTTPCodeLabel) {
 		code := convertToHTTPCode(st.Code())
 		msg := strings.ReplaceAll(st.Message(), ToHTTPCodeLabel, "")
-		resp.response(c, code, int(st.Code()), msg, struct{}{})
+		resp.render(c, code, int(st.Code()), sd.msg(msg), sd.data, sd.fieldErrors)
 		return true
 	}
 
 	// user defined error code to http
-	if resp.isUserDefinedRPCErrorCode(c, int(st.Code())) {
+	if resp.isUserDefinedRPCErrorCode(c, int(st.Code()), sd) {
 		return true
 	}
 
 	// response 200
-	resp.response(c, http.StatusOK, int(st.Code()), st.Message(), struct{}{})
+	resp.render(c, http.StatusOK, int(st.Code()), sd.msg(st.Message()), sd.data, sd.fieldErrors)
 
 	return false
 }
@@ -160,14 +226,14 @@ func (resp *defaultResponse) handleHTTPError(c *gin.Context, err error) bool {
 	return false
 }
 
-func (resp *defaultResponse) isUserDefinedRPCErrorCode(c *gin.Context, errCode int) bool {
+func (resp *defaultResponse) isUserDefinedRPCErrorCode(c *gin.Context, errCode int, sd *statusDetails) bool {
 	if v, ok := resp.rpcStatus[errCode]; ok {
 		httpCode := ToHTTPErr(v.status).ToHTTPCode()
 		msg := http.StatusText(httpCode)
 		if msg == "" {
 			msg = "unknown error"
 		}
-		resp.response(c, httpCode, httpCode, msg, struct{}{})
+		resp.render(c, httpCode, httpCode, msg, sd.data, sd.fieldErrors)
 		return true
 	}
 	return false
@@ -186,8 +252,13 @@ func (resp *defaultResponse) isUserDefinedHTTPErrorCode(c *gin.Context, errCode
 	return false
 }
 
-// ToHTTPErr converted to http error
+// ToHTTPErr converted to http error, if the status is converted from Error by ToRPCErr,
+// the Error is restored from the ErrorInfo detail
 func ToHTTPErr(st *status.Status) *Error { //nolint
+	if errcodeErrorInfo(st) != nil {
+		return statusToError(st)
+	}
+
 	switch st.Code() {
 	case StatusSuccess.status.Code(), codes.OK:
 		return Success
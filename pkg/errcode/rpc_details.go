@@ -0,0 +1,201 @@
+package errcode
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ErrorDomain is the domain of the ErrorInfo detail added by Error.ToRPCStatus, the reason is the error code
+// and the metadata contains the details of Error, it is used to restore the Error from rpc status losslessly.
+const ErrorDomain = "errcode"
+
+const detailsMetadataKey = "details"
+
+// NewErrorInfo creates the ErrorInfo detail, reason is the cause of the error, domain is the logical
+// grouping to which the reason belongs, e.g. the service name.
+func NewErrorInfo(reason string, domain string, metadata map[string]string) *errdetails.ErrorInfo {
+	return &errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   domain,
+		Metadata: metadata,
+	}
+}
+
+// NewBadRequest creates the BadRequest detail from field errors, e.g. NewBadRequest(ValidationErrors(err)...)
+func NewBadRequest(fieldErrors ...FieldError) *errdetails.BadRequest {
+	br := &errdetails.BadRequest{}
+	for _, fe := range fieldErrors {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fe.Field,
+			Description: fe.Message,
+		})
+	}
+	return br
+}
+
+// NewRetryInfo creates the RetryInfo detail, the client should wait at least delay before retrying.
+func NewRetryInfo(delay time.Duration) *errdetails.RetryInfo {
+	return &errdetails.RetryInfo{RetryDelay: durationpb.New(delay)}
+}
+
+// NewLocalizedMessage creates the LocalizedMessage detail, locale is BCP 47 code, e.g. en-US, zh-CN.
+func NewLocalizedMessage(locale string, msg string) *errdetails.LocalizedMessage {
+	return &errdetails.LocalizedMessage{Locale: locale, Message: msg}
+}
+
+// WithDetails returns a new rpc status with the typed error details, e.g. ErrorInfo, BadRequest,
+// RetryInfo, LocalizedMessage, the details are kept by Err, ErrToHTTP and ToRPCErr,
+// the details of success status are ignored.
+func (s *RPCStatus) WithDetails(details ...protoadapt.MessageV1) *RPCStatus {
+	st, err := s.status.WithDetails(details...)
+	if err != nil {
+		return s
+	}
+	return &RPCStatus{status: st}
+}
+
+// Details get the typed error details
+func (s *RPCStatus) Details() []interface{} {
+	return s.status.Details()
+}
+
+// ToError converts to Error, if the rpc status is converted from Error, the code and details of Error are restored
+func (s *RPCStatus) ToError() *Error {
+	return statusToError(s.status)
+}
+
+// the new status with code and message, the details of s are kept
+func (s *RPCStatus) newStatus(code codes.Code, msg string) *status.Status {
+	p := s.status.Proto()
+	p.Code = int32(code)
+	p.Message = msg
+	return status.FromProto(p)
+}
+
+// ToRPCStatus converts to rpc status, the code and details of Error are carried by the ErrorInfo detail
+// of domain ErrorDomain, use FromRPCErr or RPCStatus.ToError to restore the Error.
+func (e *Error) ToRPCStatus() *RPCStatus {
+	if e.code == Success.Code() {
+		return &RPCStatus{status: status.New(codes.OK, e.msg)}
+	}
+
+	code := codes.Code(e.code)
+	if e.code < 0 {
+		code = codes.Unknown
+	}
+	info := NewErrorInfo(strconv.Itoa(e.code), ErrorDomain, nil)
+	if len(e.details) > 0 {
+		data, _ := json.Marshal(e.details)
+		info.Metadata = map[string]string{detailsMetadataKey: string(data)}
+	}
+
+	st, err := status.New(code, e.msg).WithDetails(append([]protoadapt.MessageV1{info}, e.rpcDetails...)...)
+	if err != nil {
+		return &RPCStatus{status: status.New(code, e.msg)}
+	}
+	return &RPCStatus{status: st}
+}
+
+// ToRPCErr converts to rpc error, the code, message and details are kept, the client can use FromRPCErr to restore the Error.
+func (e *Error) ToRPCErr() error {
+	return e.ToRPCStatus().status.Err()
+}
+
+// RPCDetails get the typed error details from rpc status, they are kept when converting to rpc status again
+func (e *Error) RPCDetails() []protoadapt.MessageV1 {
+	return e.rpcDetails
+}
+
+// FromRPCErr converts the error returned by rpc invoke to Error, returns Success if err is nil,
+// if the error is converted from Error by ToRPCErr, the code and details of Error are restored.
+func FromRPCErr(err error) *Error {
+	if err == nil {
+		return Success
+	}
+	st, _ := status.FromError(err)
+	return statusToError(st)
+}
+
+// the ErrorInfo detail added by Error.ToRPCStatus, returns nil if not found
+func errcodeErrorInfo(st *status.Status) *errdetails.ErrorInfo {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetDomain() == ErrorDomain {
+			return info
+		}
+	}
+	return nil
+}
+
+func statusToError(st *status.Status) *Error {
+	if st.Code() == codes.OK {
+		return Success
+	}
+
+	e := &Error{code: int(st.Code()), msg: st.Message()}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetDomain() == ErrorDomain {
+			if code, err := strconv.Atoi(info.GetReason()); err == nil {
+				e.code = code
+			}
+			if v, ok := info.GetMetadata()[detailsMetadataKey]; ok {
+				_ = json.Unmarshal([]byte(v), &e.details)
+			}
+			continue
+		}
+		if msg, ok := detail.(protoadapt.MessageV1); ok {
+			e.rpcDetails = append(e.rpcDetails, msg)
+		}
+	}
+	return e
+}
+
+// the details of rpc status surfaced in http response
+type statusDetails struct {
+	fieldErrors  []FieldError // field violations of BadRequest
+	localizedMsg string
+	retryDelay   time.Duration
+	data         interface{} // {"details": [...]}, the details in json format of google.rpc.Status, or empty struct
+}
+
+func parseStatusDetails(st *status.Status) *statusDetails {
+	sd := &statusDetails{data: struct{}{}}
+	var jsonDetails []json.RawMessage
+	for _, a := range st.Proto().GetDetails() {
+		if data, err := protojson.Marshal(a); err == nil {
+			jsonDetails = append(jsonDetails, data)
+		}
+	}
+	if len(jsonDetails) > 0 {
+		sd.data = map[string]interface{}{"details": jsonDetails}
+	}
+
+	for _, detail := range st.Details() {
+		switch v := detail.(type) {
+		case *errdetails.BadRequest:
+			for _, fv := range v.GetFieldViolations() {
+				sd.fieldErrors = append(sd.fieldErrors, FieldError{Field: fv.GetField(), Message: fv.GetDescription()})
+			}
+		case *errdetails.LocalizedMessage:
+			sd.localizedMsg = v.GetMessage()
+		case *errdetails.RetryInfo:
+			sd.retryDelay = v.GetRetryDelay().AsDuration()
+		}
+	}
+	return sd
+}
+
+// msg returns the localized message if there is LocalizedMessage detail
+func (sd *statusDetails) msg(msg string) string {
+	if sd.localizedMsg != "" {
+		return sd.localizedMsg
+	}
+	return msg
+}
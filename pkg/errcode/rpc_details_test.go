@@ -0,0 +1,125 @@
+package errcode
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRPCStatus_WithDetails(t *testing.T) {
+	s := StatusInvalidParams.WithDetails(
+		NewErrorInfo("INVALID_NAME", "user.service", map[string]string{"name": "foo"}),
+		NewBadRequest(FieldError{Field: "name", Message: "name is required"}),
+	)
+	assert.Len(t, s.Details(), 2)
+	assert.Empty(t, StatusInvalidParams.Details()) // the original status is not changed
+
+	for _, err := range []error{s.Err(), s.Err("name is invalid"), s.ErrToHTTP(), s.ToRPCErr(), s.ToRPCErr("name is invalid")} {
+		st, _ := status.FromError(err)
+		require.Len(t, st.Details(), 2)
+		info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		assert.Equal(t, "INVALID_NAME", info.Reason)
+	}
+	st, _ := status.FromError(s.ToRPCErr("name is invalid"))
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "name is invalid", st.Message())
+
+	// the details of success status are ignored
+	assert.Empty(t, StatusSuccess.WithDetails(NewRetryInfo(time.Second)).Details())
+}
+
+func TestError_ToRPCStatus(t *testing.T) {
+	e := NewError(20901, "order not found", "id=1", "user=2")
+	s := e.ToRPCStatus()
+	assert.Equal(t, codes.Code(20901), s.Code())
+	assert.Equal(t, "order not found", s.Msg())
+
+	restored := FromRPCErr(e.ToRPCErr())
+	assert.Equal(t, e.Code(), restored.Code())
+	assert.Equal(t, e.Msg(), restored.Msg())
+	assert.Equal(t, e.Details(), restored.Details())
+
+	// the typed details are kept in round trip
+	s = StatusNotFound.WithDetails(NewLocalizedMessage("zh-CN", "资源不存在"))
+	e2 := s.ToError()
+	assert.Equal(t, int(StatusNotFound.Code()), e2.Code())
+	require.Len(t, e2.RPCDetails(), 1)
+	s2 := e2.ToRPCStatus().ToError().ToRPCStatus()
+	assert.Equal(t, StatusNotFound.Code(), s2.Code())
+	assert.Len(t, s2.Details(), 2) // ErrorInfo of ErrorDomain and LocalizedMessage
+
+	// unknown error
+	e3 := FromRPCErr((&Error{code: -1, msg: "unknown error"}).ToRPCErr())
+	assert.Equal(t, -1, e3.Code())
+
+	assert.Equal(t, Success, FromRPCErr(nil))
+	assert.Nil(t, Success.ToRPCErr())
+	assert.Equal(t, codes.Unknown, GetStatusCode(errors.New("foo")))
+	assert.Equal(t, -1, ToHTTPErr(status.Convert((&Error{code: -1, msg: "x"}).ToRPCErr())).Code())
+}
+
+func TestResponser_RPCDetails(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	handle := func(path string, err error, opts ...ResponserOption) {
+		resp := NewResponser(true, nil, nil, opts...)
+		r.GET(path, func(c *gin.Context) { resp.Error(c, err) })
+	}
+	handle("/bad_request", StatusInvalidParams.WithDetails(
+		NewBadRequest(FieldError{Field: "name", Message: "name is required"}),
+		NewLocalizedMessage("zh-CN", "参数错误"),
+	).Err())
+	handle("/retry", StatusLimitExceed.WithDetails(NewRetryInfo(time.Millisecond*1500)).ErrToHTTP())
+	handle("/error", NewError(20902, "order is closed").ToRPCErr())
+	handle("/unknown", (&RPCStatus{status: status.New(codes.Unknown, "order is paid")}).WithDetails(
+		NewErrorInfo("20903", ErrorDomain, nil), NewErrorInfo("PAID", "order.service", nil)).Err())
+	handle("/problem", StatusInternalServerError.WithDetails(NewErrorInfo("DB_DOWN", "user.service", nil)).ToRPCErr(),
+		WithProblemJSON())
+
+	w := doRequest(r, http.MethodGet, "/bad_request", "", nil)
+	result := struct {
+		Code int
+		Msg  string
+		Data struct {
+			Details []map[string]interface{}
+			Errors  []FieldError
+		}
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, int(StatusInvalidParams.Code()), result.Code)
+	assert.Equal(t, "参数错误", result.Msg)
+	require.Len(t, result.Data.Details, 2)
+	assert.Equal(t, "type.googleapis.com/google.rpc.BadRequest", result.Data.Details[0]["@type"])
+	assert.Equal(t, []FieldError{{Field: "name", Message: "name is required"}}, result.Data.Errors)
+
+	w = doRequest(r, http.MethodGet, "/retry", "", nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"retryDelay":"1.500s"`)
+
+	w = doRequest(r, http.MethodGet, "/error", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":20902`)
+	assert.Contains(t, w.Body.String(), `"msg":"order is closed"`)
+
+	w = doRequest(r, http.MethodGet, "/unknown", "", nil)
+	assert.Contains(t, w.Body.String(), `"code":20903`)
+	assert.Contains(t, w.Body.String(), `"reason":"PAID"`)
+
+	w = doRequest(r, http.MethodGet, "/problem", "", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	p := &Problem{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), p))
+	require.Len(t, p.Details, 1)
+	assert.Contains(t, string(p.Details[0]), "DB_DOWN")
+}
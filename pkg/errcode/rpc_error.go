@@ -18,25 +18,21 @@ type RPCStatus struct {
 	status *status.Status
 }
 
-var statusCodes = map[codes.Code]string{}
-
-// NewRPCStatus create a new rpc status
+// NewRPCStatus create a new rpc status, the error code is registered to the registry,
+// if the same error code is used, it will cause panic.
 func NewRPCStatus(code codes.Code, msg string) *RPCStatus {
-	if v, ok := statusCodes[code]; ok {
-		panic(fmt.Sprintf(`grpc status code = %d already exists, please define a new error code,
-msg1 = %s
-msg2 = %s
-`, code, v, msg))
+	s := &RPCStatus{
+		status: status.New(code, msg),
 	}
+	registry.register(KindGRPC, &codeEntry{code: int(code), desc: msg, rpcStatus: s})
 
 	grpcErrCodes[int(code)] = msg
-	statusCodes[code] = msg
-	return &RPCStatus{
-		status: status.New(code, msg),
-	}
+	return s
 }
 
 // Detail error details
+//
+// Deprecated: use WithDetails with typed error details instead, e.g. NewErrorInfo, NewBadRequest.
 type Detail struct {
 	key string
 	val interface{}
@@ -48,6 +44,8 @@ func (d *Detail) String() string {
 }
 
 // Any type key value
+//
+// Deprecated: use WithDetails with typed error details instead, e.g. NewErrorInfo, NewBadRequest.
 func Any(key string, val interface{}) Detail {
 	return Detail{
 		key: key,
@@ -65,13 +63,13 @@ func (s *RPCStatus) Msg() string {
 	return s.status.Message()
 }
 
-// Err return error
+// Err return error, the details added by WithDetails are kept,
 // if there is a parameter 'desc', it will replace the original message
 func (s *RPCStatus) Err(desc ...string) error {
 	if len(desc) > 0 {
-		return status.Errorf(s.status.Code(), "%s", strings.Join(desc, ", "))
+		return s.newStatus(s.status.Code(), strings.Join(desc, ", ")).Err()
 	}
-	return status.Errorf(s.status.Code(), "%s", s.status.Message())
+	return s.status.Err()
 }
 
 // ErrToHTTP convert to standard error add ToHTTPCodeLabel to error message,
@@ -82,68 +80,68 @@ func (s *RPCStatus) ErrToHTTP(desc ...string) error {
 	if len(desc) > 0 {
 		message = strings.Join(desc, ", ")
 	}
-	return status.Errorf(s.status.Code(), "%s%s", message, ToHTTPCodeLabel)
+	return s.newStatus(s.status.Code(), message+ToHTTPCodeLabel).Err()
 }
 
-// ToRPCErr converted to standard RPC error,
+// ToRPCErr converted to standard RPC error, the details added by WithDetails are kept,
 // use it if you need to convert to standard RPC errors,
 // if there is a parameter 'desc', it will replace the original message.
 func (s *RPCStatus) ToRPCErr(desc ...string) error {
 	switch s.status.Code() {
 	case StatusInvalidParams.status.Code():
-		return toRPCErr(codes.InvalidArgument, desc...)
+		return s.toRPCErr(codes.InvalidArgument, desc...)
 	case StatusInternalServerError.status.Code():
-		return toRPCErr(codes.Internal, desc...)
+		return s.toRPCErr(codes.Internal, desc...)
 	}
 
 	switch s.status.Code() {
 	case StatusCanceled.status.Code():
-		return toRPCErr(codes.Canceled, desc...)
+		return s.toRPCErr(codes.Canceled, desc...)
 	case StatusUnknown.status.Code():
-		return toRPCErr(codes.Unknown, desc...)
+		return s.toRPCErr(codes.Unknown, desc...)
 	case StatusDeadlineExceeded.status.Code():
-		return toRPCErr(codes.DeadlineExceeded, desc...)
+		return s.toRPCErr(codes.DeadlineExceeded, desc...)
 	case StatusNotFound.status.Code():
-		return toRPCErr(codes.NotFound, desc...)
+		return s.toRPCErr(codes.NotFound, desc...)
 	case StatusAlreadyExists.status.Code(), StatusConflict.status.Code():
-		return toRPCErr(codes.AlreadyExists, desc...)
+		return s.toRPCErr(codes.AlreadyExists, desc...)
 	case StatusPermissionDenied.status.Code():
-		return toRPCErr(codes.PermissionDenied, desc...)
+		return s.toRPCErr(codes.PermissionDenied, desc...)
 	case StatusResourceExhausted.status.Code():
-		return toRPCErr(codes.ResourceExhausted, desc...)
+		return s.toRPCErr(codes.ResourceExhausted, desc...)
 	case StatusFailedPrecondition.status.Code():
-		return toRPCErr(codes.FailedPrecondition, desc...)
+		return s.toRPCErr(codes.FailedPrecondition, desc...)
 	case StatusAborted.status.Code():
-		return toRPCErr(codes.Aborted, desc...)
+		return s.toRPCErr(codes.Aborted, desc...)
 	case StatusOutOfRange.status.Code():
-		return toRPCErr(codes.OutOfRange, desc...)
+		return s.toRPCErr(codes.OutOfRange, desc...)
 	case StatusUnimplemented.status.Code():
-		return toRPCErr(codes.Unimplemented, desc...)
+		return s.toRPCErr(codes.Unimplemented, desc...)
 	case StatusServiceUnavailable.status.Code():
-		return toRPCErr(codes.Unavailable, desc...)
+		return s.toRPCErr(codes.Unavailable, desc...)
 	case StatusDataLoss.status.Code():
-		return toRPCErr(codes.DataLoss, desc...)
+		return s.toRPCErr(codes.DataLoss, desc...)
 	case StatusUnauthorized.status.Code():
-		return toRPCErr(codes.Unauthenticated, desc...)
+		return s.toRPCErr(codes.Unauthenticated, desc...)
 	case StatusAccessDenied.status.Code():
-		return toRPCErr(codes.PermissionDenied, desc...)
+		return s.toRPCErr(codes.PermissionDenied, desc...)
 	case StatusLimitExceed.status.Code():
-		return toRPCErr(codes.ResourceExhausted, desc...)
+		return s.toRPCErr(codes.ResourceExhausted, desc...)
 	case StatusMethodNotAllowed.status.Code():
-		return toRPCErr(codes.Unimplemented, desc...)
+		return s.toRPCErr(codes.Unimplemented, desc...)
 	}
 
 	return s.status.Err()
 }
 
-func toRPCErr(code codes.Code, descs ...string) error {
+func (s *RPCStatus) toRPCErr(code codes.Code, descs ...string) error {
 	var desc string
 	if len(descs) > 0 {
 		desc = strings.Join(descs, ", ")
 	} else {
 		desc = code.String()
 	}
-	return status.New(code, desc).Err()
+	return s.newStatus(code, desc).Err()
 }
 
 // ToRPCCode converted to standard RPC error code
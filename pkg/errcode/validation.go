@@ -0,0 +1,67 @@
+package errcode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	valid "github.com/go-playground/validator/v10"
+)
+
+// FieldError is the validation error of a request field.
+type FieldError struct {
+	Field   string `json:"field"`           // field name, the json name if the validator registers tag name func
+	Rule    string `json:"rule"`            // validation rule, e.g. required, min, email
+	Param   string `json:"param,omitempty"` // parameter of rule, e.g. 1 of min=1
+	Message string `json:"message"`
+}
+
+// ValidationErrors extracts the field errors from the error returned by gin binding,
+// including the errors of validator and json decoding, returns nil if there is no field error.
+func ValidationErrors(err error) []FieldError {
+	if err == nil {
+		return nil
+	}
+
+	var ves valid.ValidationErrors
+	if errors.As(err, &ves) {
+		fes := make([]FieldError, 0, len(ves))
+		for _, fe := range ves {
+			fes = append(fes, FieldError{
+				Field:   fe.Field(),
+				Rule:    fe.Tag(),
+				Param:   fe.Param(),
+				Message: fieldErrorMessage(fe.Field(), fe.Tag(), fe.Param()),
+			})
+		}
+		return fes
+	}
+
+	var ute *json.UnmarshalTypeError
+	if errors.As(err, &ute) {
+		return []FieldError{{
+			Field:   ute.Field,
+			Rule:    "type",
+			Param:   ute.Type.String(),
+			Message: fmt.Sprintf("field '%s' must be %s, got %s", ute.Field, ute.Type.String(), ute.Value),
+		}}
+	}
+
+	var ne *strconv.NumError
+	if errors.As(err, &ne) {
+		return []FieldError{{
+			Rule:    "type",
+			Message: fmt.Sprintf("invalid value '%s'", ne.Num),
+		}}
+	}
+
+	return nil
+}
+
+func fieldErrorMessage(field string, rule string, param string) string {
+	if param == "" {
+		return fmt.Sprintf("field '%s' failed on the '%s' rule", field, rule)
+	}
+	return fmt.Sprintf("field '%s' failed on the '%s=%s' rule", field, rule, param)
+}
@@ -0,0 +1,88 @@
+package etcdcli
+
+import (
+	"context"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/18721889353/sunshine/pkg/conf"
+)
+
+// SourceClient is the etcd client used by Source, *clientv3.Client implements it.
+type SourceClient interface {
+	clientv3.KV
+	clientv3.Watcher
+}
+
+// Source is the configuration source of a key in etcd, it implements conf.Source.
+type Source struct {
+	client SourceClient
+	key    string
+	format string
+}
+
+var _ conf.Source = (*Source)(nil)
+
+// NewSource create a configuration source of etcd, the value of key is the configuration data,
+// format is the format of configuration data, e.g. yaml, json, toml.
+func NewSource(client SourceClient, key string, format string) *Source {
+	return &Source{
+		client: client,
+		key:    key,
+		format: format,
+	}
+}
+
+// Name returns the name of source.
+func (s *Source) Name() string {
+	return "etcd"
+}
+
+// Format returns the format of configuration data.
+func (s *Source) Format() string {
+	return s.format
+}
+
+// Load read the configuration data from etcd, returns empty data if the key does not exist.
+func (s *Source) Load(ctx context.Context) ([]byte, error) {
+	data, _, err := s.get(ctx)
+	return data, err
+}
+
+func (s *Source) get(ctx context.Context) ([]byte, int64, error) {
+	resp, err := s.client.Get(ctx, s.key)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, resp.Header.GetRevision(), nil
+	}
+	return resp.Kvs[0].Value, resp.Header.GetRevision(), nil
+}
+
+// Watch the key until ctx is done, the deleted key is notified as empty data.
+func (s *Source) Watch(ctx context.Context, onChange func(data []byte)) error {
+	data, revision, err := s.get(ctx)
+	if err != nil {
+		return err
+	}
+	// the configuration may be changed between Load and Watch
+	onChange(data)
+
+	ctx = clientv3.WithRequireLeader(ctx)
+	for resp := range s.client.Watch(ctx, s.key, clientv3.WithRev(revision+1)) {
+		if err = resp.Err(); err != nil {
+			return err
+		}
+		for _, event := range resp.Events {
+			switch event.Type {
+			case clientv3.EventTypePut:
+				onChange(event.Kv.Value)
+			case clientv3.EventTypeDelete:
+				onChange(nil)
+			}
+		}
+	}
+
+	return nil
+}
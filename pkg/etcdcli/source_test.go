@@ -0,0 +1,91 @@
+package etcdcli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/18721889353/sunshine/pkg/conf"
+)
+
+type fakeSourceClient struct {
+	clientv3.KV
+	clientv3.Watcher
+	value  []byte
+	events chan clientv3.WatchResponse
+}
+
+func (c *fakeSourceClient) Get(_ context.Context, key string, _ ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	resp := &clientv3.GetResponse{Header: &pb.ResponseHeader{Revision: 1}}
+	if c.value != nil {
+		resp.Kvs = []*mvccpb.KeyValue{{Key: []byte(key), Value: c.value}}
+	}
+	return resp, nil
+}
+
+func (c *fakeSourceClient) Watch(ctx context.Context, _ string, _ ...clientv3.OpOption) clientv3.WatchChan {
+	ch := make(chan clientv3.WatchResponse)
+	go func() {
+		defer close(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case resp := <-c.events:
+				ch <- resp
+			}
+		}
+	}()
+	return ch
+}
+
+func TestSource(t *testing.T) {
+	client := &fakeSourceClient{value: []byte(`{"app":{"name":"foo"}}`), events: make(chan clientv3.WatchResponse)}
+	source := NewSource(client, "/config/app.json", "json")
+	assert.Equal(t, "etcd", source.Name())
+	assert.Equal(t, "json", source.Format())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan *conf.Event, 1)
+	cfg := make(map[string]interface{})
+	err := conf.ParseSources(ctx, &cfg, []conf.Source{source}, conf.WithOnChange(func(e *conf.Event) {
+		events <- e
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "foo"}, cfg["app"])
+
+	client.events <- clientv3.WatchResponse{Events: []*clientv3.Event{
+		{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Value: []byte(`{"app":{"name":"bar"}}`)}},
+	}}
+	select {
+	case e := <-events:
+		assert.Equal(t, []string{"app.name"}, e.ChangedKeys)
+	case <-time.After(time.Second * 3):
+		t.Fatal("timeout")
+	}
+
+	client.events <- clientv3.WatchResponse{Events: []*clientv3.Event{{Type: mvccpb.DELETE, Kv: &mvccpb.KeyValue{}}}}
+	select {
+	case e := <-events:
+		assert.Equal(t, []string{"app.name"}, e.ChangedKeys)
+	case <-time.After(time.Second * 3):
+		t.Fatal("timeout")
+	}
+
+	// watch error
+	cancel()
+	time.Sleep(time.Millisecond * 100)
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- source.Watch(context.Background(), func(data []byte) {})
+	}()
+	client.events <- clientv3.WatchResponse{Canceled: true}
+	assert.Error(t, <-watchErr)
+}
@@ -20,6 +20,9 @@ type CheckHealthReply struct {
 }
 
 // CheckHealth check healthy.
+//
+// Deprecated: use the liveness and readiness handlers of pkg/health instead, e.g.
+// r.GET("/health/live", gin.WrapF(health.Default().LiveHandler())).
 // @Summary check health
 // @Description check health
 // @Tags system
@@ -42,15 +45,16 @@ func Ping(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{})
 }
 
-// ListCodes list error codes info
+// ListCodes list the registered http and grpc error codes, the query parameter format is json (default), markdown or openapi
 // @Summary list error codes info
-// @Description list error codes info
+// @Description list the registered http and grpc error codes, including module, http status, grpc code and description
 // @Tags system
 // @Accept  json
 // @Produce  json
+// @Param format query string false "json (default), markdown or openapi"
 // @Router /codes [get]
 func ListCodes(c *gin.Context) {
-	c.JSON(http.StatusOK, errcode.ListHTTPErrCodes())
+	errcode.CodesHandler(c.Writer, c.Request)
 }
 
 // BrowserRefresh solve vue using history route 404 problem, for system file
@@ -7,6 +7,7 @@ import (
 
 	"github.com/18721889353/sunshine/pkg/container/group"
 	"github.com/18721889353/sunshine/pkg/gin/response"
+	"github.com/18721889353/sunshine/pkg/metrics"
 	"github.com/18721889353/sunshine/pkg/shield/circuitbreaker"
 )
 
@@ -22,6 +23,8 @@ type circuitBreakerOptions struct {
 	validCodes map[int]struct{}
 	// degrade func
 	degradeHandler func(c *gin.Context)
+	// label name of the breaker metrics
+	metricsName string
 }
 
 func defaultCircuitBreakerOptions() *circuitBreakerOptions {
@@ -33,6 +36,7 @@ func defaultCircuitBreakerOptions() *circuitBreakerOptions {
 			http.StatusInternalServerError: {},
 			http.StatusServiceUnavailable:  {},
 		},
+		metricsName: "http",
 	}
 }
 
@@ -68,10 +72,21 @@ func WithDegradeHandler(handler func(c *gin.Context)) CircuitBreakerOption {
 	}
 }
 
+// WithBreakerMetricsName set the label name of the breaker metrics, default is http,
+// the name must be unique if more than one group is used, empty means the metrics are not registered.
+func WithBreakerMetricsName(name string) CircuitBreakerOption {
+	return func(o *circuitBreakerOptions) {
+		o.metricsName = name
+	}
+}
+
 // CircuitBreaker a circuit breaker middleware
 func CircuitBreaker(opts ...CircuitBreakerOption) gin.HandlerFunc {
 	o := defaultCircuitBreakerOptions()
 	o.apply(opts...)
+	if o.metricsName != "" {
+		_ = metrics.RegisterBreakers(o.metricsName, o.group)
+	}
 
 	return func(c *gin.Context) {
 		breaker := o.group.Get(c.FullPath()).(circuitbreaker.CircuitBreaker)
@@ -42,6 +42,7 @@ func defaultOptions() *options {
 		ignoreRoutes:  defaultIgnoreRoutes,
 		requestIDFrom: 0,
 		logFrom:       defaultLogFrom,
+		redactor:      zapLog.DefaultRedactor(),
 	}
 }
 
@@ -51,6 +52,7 @@ type options struct {
 	ignoreRoutes  map[string]struct{}
 	requestIDFrom int // 0: ignore, 1: from context, 2: from header
 	logFrom       string
+	redactor      *zapLog.Redactor
 }
 
 func (o *options) apply(opts ...Option) {
@@ -106,6 +108,14 @@ func WithRequestIDFromHeader() Option {
 	}
 }
 
+// WithRedactor set the redactor that masks the sensitive values in request and response body,
+// default is logger.DefaultRedactor(), nil means not masking.
+func WithRedactor(redactor *zapLog.Redactor) Option {
+	return func(o *options) {
+		o.redactor = redactor
+	}
+}
+
 // ------------------------------------------------------------------------------------------
 
 type bodyLogWriter struct {
@@ -172,14 +182,15 @@ func Logging(opts ...Option) gin.HandlerFunc {
 		fields := []zap.Field{
 			zap.String("current_time", time.Now().Format("2006-01-02 15:04:05.000000000")),
 			zap.String("method", c.Request.Method),
-			zap.String("url", c.Request.URL.String()),
+			zap.String("url", o.redactor.RedactString(c.Request.URL.String())),
 		}
 		if c.Request.Method == http.MethodPost || c.Request.Method == http.MethodPut || c.Request.Method == http.MethodPatch || c.Request.Method == http.MethodDelete {
 			fields = append(fields,
 				zap.Int("size", buf.Len()),
-				zap.ByteString("body", getRequestBody(&buf, o.maxLength)),
+				zap.ByteString("body", getRequestBody(bytes.NewBuffer(o.redactor.Redact(buf.Bytes())), o.maxLength)),
 			)
 		}
+		fields = append(fields, zapLog.TraceFields(c.Request.Context())...)
 
 		reqID := ""
 		if o.requestIDFrom == 1 {
@@ -214,12 +225,13 @@ func Logging(opts ...Option) gin.HandlerFunc {
 			zap.String("url", c.Request.URL.Path),
 			zap.String("ms", fmt.Sprintf("%v", float64(time.Since(start).Nanoseconds())/1e6)),
 			zap.Int("size", newWriter.body.Len()),
-			zap.ByteString("response", getResponseBody(newWriter.body, o.maxLength)),
+			zap.ByteString("response", getResponseBody(bytes.NewBuffer(o.redactor.Redact(newWriter.body.Bytes())), o.maxLength)),
 			//zap.String("response", strings.TrimRight(getBodyData(newWriter.body, o.maxLength), "\n")),
 		}
 		if reqID != "" {
 			fields = append(fields, zap.String(ContextRequestIDKey, reqID))
 		}
+		fields = append(fields, zapLog.TraceFields(c.Request.Context())...)
 		fields = append(fields, zap.String("log_from", o.logFrom+` >>>>`))
 		zapLog.Info(`>>>>`, fields...)
 	}
@@ -1,10 +1,18 @@
 package middleware
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/18721889353/sunshine/pkg/gin/response"
 	"github.com/18721889353/sunshine/pkg/httpcli"
@@ -33,6 +41,7 @@ func runLogHTTPServer() string {
 		WithRequestIDFromHeader(),
 		WithRequestIDFromContext(),
 		WithIgnoreRoutes("/ping"), // ignore path /ping
+		WithRedactor(logger.NewRedactor(logger.WithRedactFields("name"))),
 	))
 
 	// custom zap log
@@ -197,3 +206,53 @@ func TestRequest2(t *testing.T) {
 		}
 	})
 }
+
+// the fields of the log entry written by logger.Info, the fields are encoded as json in message
+func logFields(t *testing.T, logs *observer.ObservedLogs, msg string) map[string]string {
+	for _, entry := range logs.All() {
+		fields := map[string]string{}
+		if err := json.Unmarshal([]byte(entry.Message), &fields); err == nil && fields["log_msg"] == msg {
+			return fields
+		}
+	}
+	t.Fatalf("log '%s' not found", msg)
+	return nil
+}
+
+func TestLogging_redact(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	_, err := logger.Init(logger.WithCores(core))
+	require.NoError(t, err)
+	defer func() { _, _ = logger.Init() }()
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(Logging(WithRedactor(logger.NewRedactor(
+		logger.WithRedactFields("phone"),
+		logger.WithRedactPaths("$.data.cardNo"),
+		logger.WithRedactPatterns(`(?i)bearer\s+([\w.-]+)`),
+	))))
+	r.POST("/hello", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"cardNo": "6222020000000000", "name": "foo"}})
+	})
+
+	body := `{"name":"foo","password":"123456","phone":"13800000000","remark":"Bearer abcdef"}`
+	req := httptest.NewRequest(http.MethodPost, "/hello?id=1&token=abcdef", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	reqFields := logFields(t, logs, "<<<<")
+	assert.Contains(t, reqFields["url"], "id=1")
+	assert.NotContains(t, reqFields["url"], "abcdef")
+	assert.Contains(t, reqFields["body"], `"name":"foo"`)
+	assert.Contains(t, reqFields["body"], `"password":"******"`)
+	assert.Contains(t, reqFields["body"], `"phone":"******"`)
+	assert.Contains(t, reqFields["body"], `"remark":"Bearer ******"`)
+
+	respFields := logFields(t, logs, ">>>>")
+	assert.Equal(t, "200", respFields["code"])
+	assert.Contains(t, respFields["response"], `"cardNo":"******"`)
+	assert.Contains(t, respFields["response"], `"name":"foo"`)
+	assert.NotContains(t, respFields["response"], "6222020000000000")
+}
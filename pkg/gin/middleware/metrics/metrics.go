@@ -9,7 +9,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	pkgMetrics "github.com/18721889353/sunshine/pkg/metrics"
 )
 
 var (
@@ -60,7 +61,7 @@ var (
 
 // init registers the prometheus metrics
 func initPrometheus() {
-	prometheus.MustRegister(uptime, reqCount, reqDuration, reqSizeBytes, respSizeBytes)
+	pkgMetrics.MustRegister(uptime, reqCount, reqDuration, reqSizeBytes, respSizeBytes)
 	go recordUptime()
 }
 
@@ -101,7 +102,7 @@ func calcRequestSize(r *http.Request) float64 {
 // metricsHandler wrappers the standard http.Handler to gin.HandlerFunc
 func metricsHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		handler := promhttp.Handler()
+		handler := pkgMetrics.Handler()
 		handler.ServeHTTP(c.Writer, c.Request)
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/18721889353/sunshine/pkg/gin/response"
+	"github.com/18721889353/sunshine/pkg/metrics"
 	rl "github.com/18721889353/sunshine/pkg/shield/ratelimit"
 )
 
@@ -23,6 +24,7 @@ type rateLimitOptions struct {
 	bucket       int
 	cpuThreshold int64
 	cpuQuota     float64
+	metricsName  string
 }
 
 func defaultRatelimitOptions() *rateLimitOptions {
@@ -30,6 +32,7 @@ func defaultRatelimitOptions() *rateLimitOptions {
 		window:       time.Second * 10,
 		bucket:       100,
 		cpuThreshold: 800,
+		metricsName:  "http",
 	}
 }
 
@@ -67,6 +70,14 @@ func WithCPUQuota(quota float64) RateLimitOption {
 	}
 }
 
+// WithLimiterMetricsName set the label name of the limiter metrics, default is http,
+// the name must be unique if more than one limiter is used, empty means the metrics are not registered.
+func WithLimiterMetricsName(name string) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.metricsName = name
+	}
+}
+
 // RateLimit an adaptive rate limiter middleware
 func RateLimit(opts ...RateLimitOption) gin.HandlerFunc {
 	o := defaultRatelimitOptions()
@@ -77,6 +88,9 @@ func RateLimit(opts ...RateLimitOption) gin.HandlerFunc {
 		rl.WithCPUThreshold(o.cpuThreshold),
 		rl.WithCPUQuota(o.cpuQuota),
 	)
+	if o.metricsName != "" {
+		_ = metrics.RegisterLimiter(o.metricsName, limiter)
+	}
 
 	return func(c *gin.Context) {
 		done, err := limiter.Allow()
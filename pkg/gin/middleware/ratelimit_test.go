@@ -9,9 +9,11 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
 
 	"github.com/18721889353/sunshine/pkg/gin/response"
 	"github.com/18721889353/sunshine/pkg/httpcli"
+	"github.com/18721889353/sunshine/pkg/metrics"
 	"github.com/18721889353/sunshine/pkg/utils"
 )
 
@@ -77,3 +79,26 @@ func TestRateLimiter(t *testing.T) {
 			time.Now().Format(time.RFC3339Nano), success, failures)
 	}
 }
+
+func TestRateLimit_metrics(t *testing.T) {
+	_ = RateLimit(WithLimiterMetricsName("test_limiter"))
+	_ = RateLimit(WithLimiterMetricsName(""))
+
+	mfs, err := metrics.Registry().Gather()
+	assert.NoError(t, err)
+	names := map[string]bool{}
+	for _, mf := range mfs {
+		if mf.GetName() != "ratelimit_in_flight" {
+			continue
+		}
+		for _, m := range mf.Metric {
+			for _, l := range m.Label {
+				if l.GetName() == "name" {
+					names[l.GetValue()] = true
+				}
+			}
+		}
+	}
+	assert.True(t, names["test_limiter"])
+	assert.False(t, names[""])
+}
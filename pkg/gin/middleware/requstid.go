@@ -10,6 +10,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/18721889353/sunshine/pkg/krand"
+	zapLog "github.com/18721889353/sunshine/pkg/logger"
 )
 
 var (
@@ -45,6 +46,7 @@ func (o *requestIDOptions) apply(opts ...RequestIDOption) {
 func (o *requestIDOptions) setRequestIDKey() {
 	if o.contextRequestIDKey != ContextRequestIDKey {
 		ContextRequestIDKey = o.contextRequestIDKey
+		zapLog.SetContextRequestIDKey(o.contextRequestIDKey)
 	}
 	if o.headerXRequestIDKey != HeaderXRequestIDKey {
 		HeaderXRequestIDKey = o.headerXRequestIDKey
@@ -52,7 +52,37 @@ func writeJSON(c *gin.Context, code int, res interface{}) {
 	}
 }
 
+// the context key of the problem type uri, it is set by ProblemJSON
+const problemTypeKey = "_response_problem_type"
+
+// ProblemJSON is a middleware that switches the error responses of the router or router group to
+// RFC 7807 application/problem+json, typeURI is the prefix of problem type, default is about:blank.
+func ProblemJSON(typeURI ...string) gin.HandlerFunc {
+	uri := "about:blank"
+	if len(typeURI) > 0 && typeURI[0] != "" {
+		uri = typeURI[0]
+	}
+	return func(c *gin.Context) {
+		c.Set(problemTypeKey, uri)
+		c.Next()
+	}
+}
+
+// render the error as problem details if ProblemJSON is used, returns false if not
+func respProblem(c *gin.Context, status int, code int, msg string, fieldErrors []errcode.FieldError) bool {
+	typeURI := c.GetString(problemTypeKey)
+	if typeURI == "" || code == errcode.Success.Code() {
+		return false
+	}
+	errcode.RenderProblem(c, errcode.NewProblem(c, typeURI, status, code, msg, fieldErrors))
+	return true
+}
+
 func respJSONWithStatusCode(c *gin.Context, code int, msg string, data ...interface{}) {
+	if code >= http.StatusBadRequest && respProblem(c, code, code, msg, nil) {
+		return
+	}
+
 	var firstData interface{}
 	if len(data) > 0 {
 		firstData = data[0]
@@ -123,6 +153,10 @@ func Out(c *gin.Context, err *errcode.Error, data ...interface{}) {
 
 // status code flat 200, custom error codes in data.code
 func respJSONWith200(c *gin.Context, code int, msg string, data ...interface{}) {
+	if respProblem(c, http.StatusOK, code, msg, nil) {
+		return
+	}
+
 	var firstData interface{}
 	if len(data) > 0 {
 		firstData = data[0]
@@ -141,3 +175,18 @@ func Success(c *gin.Context, data ...interface{}) {
 func Error(c *gin.Context, err *errcode.Error, data ...interface{}) {
 	respJSONWith200(c, err.Code(), err.Msg(), data...)
 }
+
+// ParamError return parameter error, the field errors of validation are returned in data.errors,
+// or in errors of problem details if ProblemJSON is used
+func ParamError(c *gin.Context, err error) {
+	fieldErrors := errcode.ValidationErrors(err)
+	if respProblem(c, http.StatusOK, errcode.InvalidParams.Code(), errcode.InvalidParams.Msg(), fieldErrors) {
+		return
+	}
+
+	var data interface{}
+	if len(fieldErrors) > 0 {
+		data = map[string]interface{}{"errors": fieldErrors}
+	}
+	respJSONWith200(c, errcode.InvalidParams.Code(), errcode.InvalidParams.Msg(), data)
+}
@@ -3,13 +3,17 @@ package response
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/18721889353/sunshine/pkg/errcode"
+	"github.com/18721889353/sunshine/pkg/gin/validator"
 	"github.com/18721889353/sunshine/pkg/httpcli"
 	"github.com/18721889353/sunshine/pkg/utils"
 )
@@ -89,3 +93,55 @@ func TestRespond(t *testing.T) {
 		assert.Error(t, err)
 	}
 }
+
+func TestProblemJSON(t *testing.T) {
+	type form struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	binding.Validator = validator.Init()
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	paramHandler := func(c *gin.Context) {
+		if err := c.ShouldBindJSON(&form{}); err != nil {
+			ParamError(c, err)
+			return
+		}
+		Success(c)
+	}
+	r.POST("/param", paramHandler)
+	g := r.Group("/api", ProblemJSON("https://example.com/errors/"))
+	g.GET("/success", func(c *gin.Context) { Success(c, gin.H{"foo": "bar"}) })
+	g.GET("/error", func(c *gin.Context) { Error(c, errcode.NotFound) })
+	g.GET("/output", func(c *gin.Context) { Output(c, http.StatusUnauthorized) })
+	g.POST("/param", paramHandler)
+
+	do := func(method string, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "/api/success", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"foo":"bar"`)
+
+	w = do(http.MethodGet, "/api/error", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, errcode.ProblemContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"type":"https://example.com/errors/10004"`)
+
+	w = do(http.MethodGet, "/api/output", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), `"instance":"/api/output"`)
+
+	w = do(http.MethodPost, "/api/param", "{}")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"errors":[{"field":"name","rule":"required"`)
+
+	w = do(http.MethodPost, "/param", "{}")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"data":{"errors":[{"field":"name"`)
+}
@@ -3,6 +3,7 @@ package validator
 
 import (
 	"reflect"
+	"strings"
 	"sync"
 
 	valid "github.com/go-playground/validator/v10"
@@ -48,9 +49,21 @@ func (v *CustomValidator) lazyinit() {
 	v.Once.Do(func() {
 		v.Validate = valid.New()
 		v.Validate.SetTagName("binding")
+		v.Validate.RegisterTagNameFunc(fieldName)
 	})
 }
 
+// the field name in validation errors is the name of json or form tag, e.g. the field UserName `json:"userName"` is userName
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
 func kindOfData(data interface{}) reflect.Kind {
 	value := reflect.ValueOf(data)
 	valueType := value.Kind()
@@ -15,6 +15,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
+	valid "github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/18721889353/sunshine/pkg/utils"
@@ -456,3 +457,19 @@ func Test_kindOfData(t *testing.T) {
 	kind := kindOfData(new(st))
 	assert.Equal(t, reflect.Struct, kind)
 }
+
+func Test_fieldName(t *testing.T) {
+	type form struct {
+		UserName string `json:"userName,omitempty" binding:"required"`
+		Page     int    `form:"page" binding:"required"`
+		Ignored  string `json:"-" binding:"required"`
+	}
+
+	validator := NewCustomValidator()
+	err := validator.ValidateStruct(&form{})
+	var ves valid.ValidationErrors
+	assert.True(t, errors.As(err, &ves))
+	assert.Equal(t, "userName", ves[0].Field())
+	assert.Equal(t, "page", ves[1].Field())
+	assert.Equal(t, "Ignored", ves[2].Field())
+}
@@ -0,0 +1,334 @@
+package gocron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-redsync/redsync/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/robfig/cron/v3"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/18721889353/sunshine/pkg/dlock"
+)
+
+// MisfirePolicy how to handle the runs that were missed while no replica was running
+type MisfirePolicy int
+
+const (
+	// MisfireSkip ignore the missed runs, it is the default policy
+	MisfireSkip MisfirePolicy = iota
+	// MisfireFireOnce run once immediately no matter how many runs were missed
+	MisfireFireOnce
+	// MisfireCatchUp run every missed run in order, up to maxCatchUpRuns times
+	MisfireCatchUp
+)
+
+var maxCatchUpRuns = 100
+
+// the window to look back for the scheduled time of a run, cron fires a run shortly after its scheduled time
+const scheduleLookback = time.Minute
+
+// LockerFactory create a distributed locker by key, a task run is executed only by the replica that holds the lock.
+type LockerFactory func(key string) (dlock.Locker, error)
+
+// NewRedisLockerFactory create locker factory based on redis, expiry is the lock expiration time, default 8s.
+func NewRedisLockerFactory(client *redis.Client, expiry ...time.Duration) LockerFactory {
+	var opts []redsync.Option
+	if len(expiry) > 0 && expiry[0] > 0 {
+		opts = append(opts, redsync.WithExpiry(expiry[0]))
+	}
+	return func(key string) (dlock.Locker, error) {
+		return dlock.NewRedisLock(client, key, opts...)
+	}
+}
+
+// NewEtcdLockerFactory create locker factory based on etcd, ttl unit(s), default 15s.
+func NewEtcdLockerFactory(client *clientv3.Client, ttl int) LockerFactory {
+	return func(key string) (dlock.Locker, error) {
+		return dlock.NewEtcd(client, key, ttl)
+	}
+}
+
+func defaultNodeID() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// taskRunner implements cron.Job, it takes care of locking, timeout, persisting state and execution log.
+type taskRunner struct {
+	task     *Task
+	schedule cron.Schedule
+	s        *Scheduler
+}
+
+// Run is called by cron
+func (r *taskRunner) Run() {
+	r.runAt(r.scheduledTime(time.Now()), false)
+}
+
+// scheduledTime returns the latest scheduled time at or before now, it is used as the key of a run,
+// so the replicas with slightly skewed clocks agree on the same run.
+func (r *taskRunner) scheduledTime(now time.Time) time.Time {
+	// the runs of @every are relative to the start time of each replica, the key is truncated
+	// to the delay, so the replicas agree on one run per delay
+	if cds, ok := r.schedule.(cron.ConstantDelaySchedule); ok {
+		return now.Truncate(cds.Delay)
+	}
+
+	t := r.schedule.Next(now.Add(-scheduleLookback))
+	if t.IsZero() || t.After(now) {
+		return now.Truncate(time.Second)
+	}
+	for {
+		next := r.schedule.Next(t)
+		if next.IsZero() || next.After(now) {
+			return t
+		}
+		t = next
+	}
+}
+
+// runAt executes the task scheduled at scheduledAt, if force is true, skip checking whether
+// the scheduled time has already been executed by another replica.
+func (r *taskRunner) runAt(scheduledAt time.Time, force bool) {
+	name := r.task.Name
+	ctx := context.Background()
+	if r.task.IsRunOnce {
+		// deleted on all replicas, no matter which replica runs it
+		defer r.s.DeleteTask(name)
+	}
+
+	if r.s.opts.lockerFactory != nil {
+		locker, err := r.s.opts.lockerFactory(r.s.opts.lockPrefix + name)
+		if err != nil {
+			r.s.log.Error(err, "new_locker", "task", name)
+			return
+		}
+		defer func() { _ = locker.Close() }()
+		ok, err := locker.TryLock(ctx)
+		if !ok || err != nil {
+			// held by another replica
+			return
+		}
+		defer func() { _ = locker.Unlock(ctx) }()
+	}
+
+	startAt := time.Now()
+	var timeSpec string
+	ok, err := r.modifyRecord(func(record *TaskRecord) bool {
+		if !force {
+			if record.Paused {
+				return false // paused by this or another replica
+			}
+			if record.TimeSpec != "" && record.TimeSpec != r.task.TimeSpec {
+				timeSpec = record.TimeSpec // rescheduled by another replica
+				return false
+			}
+			if r.s.opts.lockerFactory != nil && !record.LastScheduledAt.Before(scheduledAt) {
+				return false // already executed by another replica
+			}
+		}
+		record.LastScheduledAt = scheduledAt
+		record.LastRunAt = startAt
+		return true
+	})
+	if err != nil {
+		r.s.log.Error(err, "save_task_record", "task", name)
+		return
+	}
+	if timeSpec != "" {
+		if _, err = r.s.reschedule(name, timeSpec); err != nil {
+			r.s.log.Error(err, "reschedule_task", "task", name)
+		}
+		return
+	}
+	if !ok {
+		return
+	}
+
+	status, runErr := r.execute()
+	endAt := time.Now()
+
+	err = r.updateRecord(func(record *TaskRecord) {
+		record.LastRunStatus = status
+		if !record.Paused && record.TimeSpec == r.task.TimeSpec {
+			record.NextRunAt = r.schedule.Next(endAt)
+		}
+	})
+	if err != nil {
+		r.s.log.Error(err, "save_task_record", "task", name)
+	}
+
+	runLog := &RunLog{
+		Name:        name,
+		NodeID:      r.s.opts.nodeID,
+		ScheduledAt: scheduledAt,
+		StartAt:     startAt,
+		EndAt:       endAt,
+		Status:      status,
+	}
+	if runErr != nil {
+		runLog.Error = runErr.Error()
+		r.s.log.Error(runErr, "run_task", "task", name, "status", status)
+	}
+	if err = r.s.opts.store.AddRunLog(ctx, runLog, r.s.opts.runLogLimit); err != nil {
+		r.s.log.Error(err, "add_run_log", "task", name)
+	}
+}
+
+func (r *taskRunner) execute() (string, error) {
+	fn := r.task.FnWithContext
+	if fn == nil {
+		fn = func(ctx context.Context) error {
+			r.task.Fn()
+			return nil
+		}
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if r.task.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(r.s.ctx, r.task.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(r.s.ctx)
+	}
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if e := recover(); e != nil {
+				done <- fmt.Errorf("panic: %v", e)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return StatusFailed, err
+		}
+		return StatusSuccess, nil
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return StatusTimeout, fmt.Errorf("run timeout %s", r.task.Timeout)
+		}
+		return StatusFailed, ctx.Err()
+	}
+}
+
+func (r *taskRunner) newRecord() *TaskRecord {
+	return &TaskRecord{
+		Name:      r.task.Name,
+		TimeSpec:  r.task.TimeSpec,
+		IsRunOnce: r.task.IsRunOnce,
+		Timeout:   r.task.Timeout,
+		Misfire:   r.task.Misfire,
+	}
+}
+
+// register save the task definition to store and handle the missed runs according to the misfire policy.
+func (r *taskRunner) register() {
+	now := time.Now()
+
+	var missedAt time.Time
+	_, err := r.modifyRecord(func(record *TaskRecord) bool {
+		if !record.NextRunAt.IsZero() && record.NextRunAt.Before(now) {
+			missedAt = record.NextRunAt
+		}
+		record.TimeSpec = r.task.TimeSpec
+		record.IsRunOnce = r.task.IsRunOnce
+		record.Timeout = r.task.Timeout
+		record.Misfire = r.task.Misfire
+		if !record.Paused {
+			record.NextRunAt = r.schedule.Next(now)
+		}
+		return true
+	})
+	if err != nil {
+		r.s.log.Error(err, "save_task_record", "task", r.task.Name)
+		return
+	}
+
+	if !missedAt.IsZero() && r.task.Misfire != MisfireSkip {
+		go r.misfire(missedAt, now)
+	}
+}
+
+// updateRecord update the persisted record of the task
+func (r *taskRunner) updateRecord(fn func(record *TaskRecord)) error {
+	_, err := r.modifyRecord(func(record *TaskRecord) bool {
+		fn(record)
+		return true
+	})
+	return err
+}
+
+// modifyRecord read, modify and save the persisted record of the task under the record lock,
+// the record is not saved if fn returns false, returns whether the record is saved.
+func (r *taskRunner) modifyRecord(fn func(record *TaskRecord) bool) (bool, error) {
+	ctx := context.Background()
+	unlock, err := r.lockRecord(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	record, err := r.s.opts.store.GetTask(ctx, r.task.Name)
+	if err != nil {
+		if !errors.Is(err, ErrTaskNotFound) {
+			return false, err
+		}
+		record = r.newRecord()
+	}
+	if !fn(record) {
+		return false, nil
+	}
+	record.UpdatedAt = time.Now()
+	return true, r.s.opts.store.SaveTask(ctx, record)
+}
+
+// lockRecord lock the record of the task, it is shared by the replicas in cluster mode, so the
+// concurrent updates, e.g. Pause and the end of a run, do not overwrite each other.
+func (r *taskRunner) lockRecord(ctx context.Context) (func(), error) {
+	r.s.recordMu.Lock()
+	if r.s.opts.lockerFactory == nil {
+		return r.s.recordMu.Unlock, nil
+	}
+
+	locker, err := r.s.opts.lockerFactory(r.s.opts.lockPrefix + r.task.Name + ":record")
+	if err != nil {
+		r.s.recordMu.Unlock()
+		return nil, err
+	}
+	if err = locker.Lock(ctx); err != nil {
+		_ = locker.Close()
+		r.s.recordMu.Unlock()
+		return nil, err
+	}
+	return func() {
+		_ = locker.Unlock(ctx)
+		_ = locker.Close()
+		r.s.recordMu.Unlock()
+	}, nil
+}
+
+func (r *taskRunner) misfire(missedAt time.Time, now time.Time) {
+	switch r.task.Misfire {
+	case MisfireFireOnce:
+		r.runAt(missedAt, false)
+	case MisfireCatchUp:
+		for i := 0; i < maxCatchUpRuns && missedAt.Before(now); i++ {
+			if r.s.ctx.Err() != nil {
+				return
+			}
+			r.runAt(missedAt, false)
+			missedAt = r.schedule.Next(missedAt)
+		}
+	}
+}
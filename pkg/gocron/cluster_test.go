@@ -0,0 +1,261 @@
+package gocron
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func newTestRunner(t *testing.T, task *Task, s *Scheduler) *taskRunner {
+	schedule, err := s.parser.Parse(task.TimeSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &taskRunner{
+		task:     task,
+		schedule: schedule,
+		s:        s,
+	}
+}
+
+func TestTaskRunner_cluster(t *testing.T) {
+	redisServer, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redisServer.Close()
+	client := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+
+	var count int32
+	task := &Task{
+		Name:     "cluster_task",
+		TimeSpec: "@every 1s",
+		Fn: func() {
+			atomic.AddInt32(&count, 1)
+		},
+	}
+	store := NewRedisStore(client)
+	s1 := NewScheduler(WithLog(zap.NewNop()), WithLocker(NewRedisLockerFactory(client, time.Second*5), "test:lock:"),
+		WithStore(store), WithNodeID("node1"))
+	defer s1.Stop()
+	s2 := NewScheduler(WithLog(zap.NewNop()), WithLocker(NewRedisLockerFactory(client), "test:lock:"),
+		WithStore(store), WithNodeID("node2"))
+	defer s2.Stop()
+
+	// two replicas fire at the same scheduled time, only one of them runs the task
+	scheduledAt := time.Now().Truncate(time.Second)
+	r1 := newTestRunner(t, task, s1)
+	r2 := newTestRunner(t, task, s2)
+	r1.runAt(scheduledAt, false)
+	r2.runAt(scheduledAt, false)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+
+	r2.runAt(scheduledAt.Add(time.Second), false)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+
+	record, err := store.GetTask(context.Background(), task.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, record.LastRunStatus)
+	assert.True(t, record.NextRunAt.After(record.LastRunAt))
+
+	logs, err := store.ListRunLogs(context.Background(), task.Name, 10)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 2)
+	assert.Equal(t, "node2", logs[0].NodeID)
+	assert.Equal(t, "node1", logs[1].NodeID)
+}
+
+// the replicas are started at different times, the runs of @every are fired at different times
+func TestScheduler_clusterEvery(t *testing.T) {
+	redisServer, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redisServer.Close()
+	client := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	store := NewRedisStore(client)
+
+	var count int32
+	newTask := func() *Task {
+		return &Task{
+			Name:     "cluster_every_task",
+			TimeSpec: "@every 2s",
+			Fn: func() {
+				atomic.AddInt32(&count, 1)
+			},
+		}
+	}
+	runOnce := &Task{Name: "cluster_once_task", TimeSpec: "@every 1s", IsRunOnce: true, Fn: func() {}}
+
+	var schedulers []*Scheduler
+	for _, nodeID := range []string{"node1", "node2"} {
+		s := NewScheduler(WithLog(zap.NewNop()), WithLocker(NewRedisLockerFactory(client), "test:lock:"),
+			WithStore(store), WithNodeID(nodeID))
+		defer s.Stop()
+		task := *runOnce
+		assert.NoError(t, s.Run(newTask(), &task))
+		schedulers = append(schedulers, s)
+		time.Sleep(time.Second)
+	}
+	time.Sleep(time.Millisecond * 4500)
+
+	logs, err := store.ListRunLogs(context.Background(), "cluster_every_task", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int(atomic.LoadInt32(&count)), len(logs))
+	assert.GreaterOrEqual(t, len(logs), 2)
+	for i, l := range logs {
+		assert.Equal(t, l.ScheduledAt, l.ScheduledAt.Truncate(time.Second*2))
+		if i > 0 {
+			assert.GreaterOrEqual(t, logs[i-1].ScheduledAt.Sub(l.ScheduledAt), time.Second*2)
+		}
+	}
+
+	// the run once task is deleted on all replicas
+	for _, s := range schedulers {
+		assert.False(t, s.IsRunningTask(runOnce.Name))
+	}
+}
+
+func TestTaskRunner_scheduledTime(t *testing.T) {
+	s := NewScheduler(WithLog(zap.NewNop()), WithGranularity(SecondType))
+	defer s.Stop()
+
+	r := newTestRunner(t, &Task{Name: "key_task", TimeSpec: "*/5 * * * * *", Fn: func() {}}, s)
+	fireAt := time.Date(2024, 1, 1, 12, 0, 5, 0, time.Local)
+	// replicas fire the same run at different times because of clock skew and scheduling delay
+	for _, now := range []time.Time{fireAt, fireAt.Add(time.Millisecond * 300), fireAt.Add(time.Millisecond * 1200)} {
+		assert.Equal(t, fireAt, r.scheduledTime(now), now)
+	}
+	assert.Equal(t, fireAt.Add(time.Second*5), r.scheduledTime(fireAt.Add(time.Second*5)))
+
+	// the schedule of next run is after the lookback window
+	r = newTestRunner(t, &Task{Name: "key_task", TimeSpec: "0 0 0 1 1 *", Fn: func() {}}, s)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 300, time.Local)
+	assert.Equal(t, now.Truncate(time.Second), r.scheduledTime(now))
+
+	// @every is truncated to the delay
+	r = newTestRunner(t, &Task{Name: "key_task", TimeSpec: "@every 10s", Fn: func() {}}, s)
+	fireAt = time.Date(2024, 1, 1, 12, 0, 10, 0, time.UTC)
+	for _, now := range []time.Time{fireAt, fireAt.Add(time.Second * 3), fireAt.Add(time.Millisecond * 9900)} {
+		assert.Equal(t, fireAt, r.scheduledTime(now), now)
+	}
+}
+
+func TestTaskRunner_timeout(t *testing.T) {
+	s := NewScheduler(WithLog(zap.NewNop()))
+	defer s.Stop()
+
+	tasks := []*Task{
+		{
+			Name:     "timeout_task",
+			TimeSpec: "@every 1h",
+			Timeout:  time.Millisecond * 100,
+			FnWithContext: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		},
+		{
+			Name:     "failed_task",
+			TimeSpec: "@every 1h",
+			FnWithContext: func(ctx context.Context) error {
+				return errors.New("mock error")
+			},
+		},
+		{
+			Name:     "panic_task",
+			TimeSpec: "@every 1h",
+			Fn: func() {
+				panic("mock panic")
+			},
+		},
+	}
+	wantStatus := []string{StatusTimeout, StatusFailed, StatusFailed}
+
+	for i, task := range tasks {
+		newTestRunner(t, task, s).Run()
+		logs, err := s.GetRunLogs(task.Name, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, wantStatus[i], logs[0].Status)
+		assert.NotEmpty(t, logs[0].Error)
+	}
+}
+
+func TestTaskRunner_misfire(t *testing.T) {
+	policies := []MisfirePolicy{MisfireSkip, MisfireFireOnce, MisfireCatchUp}
+	wantCounts := []int32{0, 1, 3}
+
+	for i, policy := range policies {
+		var count int32
+		s := NewScheduler(WithLog(zap.NewNop()))
+		task := &Task{
+			Name:     "misfire_task",
+			TimeSpec: "@every 1m",
+			Misfire:  policy,
+			Fn: func() {
+				atomic.AddInt32(&count, 1)
+			},
+		}
+		// the service was down for 3 minutes
+		_ = s.opts.store.SaveTask(context.Background(), &TaskRecord{
+			Name:      task.Name,
+			TimeSpec:  task.TimeSpec,
+			NextRunAt: time.Now().Add(-time.Minute*3 + time.Second),
+		})
+
+		newTestRunner(t, task, s).register()
+		time.Sleep(time.Millisecond * 200)
+		assert.Equal(t, wantCounts[i], atomic.LoadInt32(&count), policy)
+		s.Stop()
+	}
+}
+
+func TestRunWithContext(t *testing.T) {
+	err := Init(WithLog(zap.NewNop()), WithNodeID("node"), WithRunLogLimit(5), WithStore(NewMemoryStore()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var count int32
+	ran := make(chan struct{}, 1)
+	err = Run(&Task{
+		Name:     "ctx_task",
+		TimeSpec: "@every 1s",
+		Timeout:  time.Second,
+		FnWithContext: func(ctx context.Context) error {
+			atomic.AddInt32(&count, 1)
+			select {
+			case ran <- struct{}{}:
+			default:
+			}
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+	select {
+	case <-ran:
+	case <-time.After(time.Second * 3):
+		t.Fatal("timeout")
+	}
+	assert.Eventually(t, func() bool {
+		logs, _ := GetRunLogs("ctx_task", 5)
+		return len(logs) >= 1
+	}, time.Second, time.Millisecond*10)
+	Stop()
+
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&count), int32(1))
+	records, err := GetTaskRecords()
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	logs, err := GetRunLogs("ctx_task", 5)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, len(logs), 1)
+	DeleteTask("ctx_task")
+}
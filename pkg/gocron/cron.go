@@ -2,21 +2,14 @@
 package gocron
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"strings"
-	"sync"
-
-	"github.com/robfig/cron/v3"
+	"time"
 )
 
-var (
-	c *cron.Cron
-	// task name and id mapping, used to add, delete, modify and query tasks
-	nameID = sync.Map{}
-	// id and task name mapping, used in log printing
-	idName = sync.Map{}
-)
+// default scheduler used by the package-level functions
+var defaultScheduler *Scheduler
 
 // Task scheduled task
 type Task struct {
@@ -28,112 +21,79 @@ type Task struct {
 	Name      string // task name
 	Fn        func() // task function
 	IsRunOnce bool   // if the task is only run once
+
+	// task function with context, used instead of Fn if not nil, ctx is canceled when Timeout is reached or cron stops
+	FnWithContext func(ctx context.Context) error
+	Timeout       time.Duration // run timeout, 0 means no timeout
+	Misfire       MisfirePolicy // how to handle the runs missed while no replica was running, default MisfireSkip
 }
 
-// Init initialize and start timed tasks
+// Init initialize and start the default scheduler
 func Init(opts ...Option) error {
-	o := defaultOptions()
-	o.apply(opts...)
-
-	log := &zapLog{zapLog: o.zapLog, isOnlyPrintError: o.isOnlyPrintError}
-	cronOpts := []cron.Option{
-		cron.WithLogger(log),
-		cron.WithChain(
-			cron.Recover(log),
-		),
-	}
-	if o.granularity == SecondType {
-		cronOpts = append(cronOpts, cron.WithSeconds()) // second-level granularity, default is minute-level granularity
+	if defaultScheduler != nil {
+		defaultScheduler.Stop()
 	}
-
-	c = cron.New(cronOpts...)
-	c.Start()
-
+	defaultScheduler = NewScheduler(opts...)
 	return nil
 }
 
+// GetScheduler gets the default scheduler, returns nil if it is not initialized
+func GetScheduler() *Scheduler {
+	return defaultScheduler
+}
+
 // Run the tasks
 func Run(tasks ...*Task) error {
-	if c == nil {
+	if defaultScheduler == nil {
 		return errors.New("cron is not initialized")
 	}
-
-	var errs []string
-	for _, task := range tasks {
-		if IsRunningTask(task.Name) {
-			errs = append(errs, fmt.Sprintf("task '%s' is already exists", task.Name))
-			continue
-		}
-
-		if err := checkRunOnce(task); err != nil {
-			errs = append(errs, err.Error())
-			continue
-		}
-
-		id, err := c.AddFunc(task.TimeSpec, task.Fn)
-		if err != nil {
-			errs = append(errs, fmt.Sprintf("run task '%s' error: %v", task.Name, err))
-			continue
-		}
-		idName.Store(id, task.Name)
-		nameID.Store(task.Name, id)
-	}
-
-	if len(errs) > 0 {
-		return errors.New(strings.Join(errs, " || "))
-	}
-
-	return nil
-}
-
-func checkRunOnce(task *Task) error {
-	if task.Fn == nil {
-		return fmt.Errorf("task '%s' is nil", task.Name)
-	}
-	if task.IsRunOnce {
-		job := task.Fn
-		task.Fn = func() {
-			job()
-			DeleteTask(task.Name)
-		}
-	}
-	return nil
+	return defaultScheduler.Run(tasks...)
 }
 
 // IsRunningTask determine if the task is running
 func IsRunningTask(name string) bool {
-	_, ok := nameID.Load(name)
-	return ok
+	if defaultScheduler == nil {
+		return false
+	}
+	return defaultScheduler.IsRunningTask(name)
 }
 
 // GetRunningTasks gets a list of running task names
 func GetRunningTasks() []string {
-	var names []string
-	nameID.Range(func(key, value interface{}) bool {
-		names = append(names, key.(string))
-		return true
-	})
-	return names
+	if defaultScheduler == nil {
+		return nil
+	}
+	return defaultScheduler.GetRunningTasks()
 }
 
 // DeleteTask stop and delete the specified task
 func DeleteTask(name string) {
-	if id, ok := nameID.Load(name); ok {
-		entryID, isOk := id.(cron.EntryID)
-		if !isOk {
-			return
-		}
-		c.Remove(entryID)
-		nameID.Delete(name)
-		idName.Delete(entryID)
+	if defaultScheduler != nil {
+		defaultScheduler.DeleteTask(name)
 	}
 }
 
-// Stop all scheduled tasks
+// Stop all scheduled tasks, the context of running tasks is canceled
 func Stop() {
-	if c != nil {
-		c.Stop()
+	if defaultScheduler != nil {
+		defaultScheduler.Stop()
+	}
+}
+
+// GetTaskRecords gets the persisted definition and run state of all tasks
+func GetTaskRecords() ([]*TaskRecord, error) {
+	if defaultScheduler == nil {
+		return nil, errors.New("cron is not initialized")
+	}
+	return defaultScheduler.GetTaskRecords()
+}
+
+// GetRunLogs gets the latest execution logs of the specified task
+func GetRunLogs(name string, limit int) ([]*RunLog, error) {
+	if defaultScheduler == nil {
+		return nil, errors.New("cron is not initialized")
 	}
+	return defaultScheduler.GetRunLogs(name, limit)
 }
 
 // EverySecond every second size (1~59)
@@ -1,6 +1,8 @@
 package gocron
 
 import (
+	"sync"
+
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
 )
@@ -17,6 +19,12 @@ type options struct {
 	isOnlyPrintError bool // default false
 
 	granularity int // 0: second, 1: minute
+
+	lockerFactory LockerFactory // default nil, cluster mode is enabled if not nil
+	lockPrefix    string        // default "gocron:lock:"
+	store         Store         // default memory store
+	nodeID        string        // default hostname-pid
+	runLogLimit   int           // default 100
 }
 
 func defaultOptions() *options {
@@ -25,6 +33,11 @@ func defaultOptions() *options {
 		isOnlyPrintError: false,
 
 		granularity: SecondType,
+
+		lockPrefix:  "gocron:lock:",
+		store:       NewMemoryStore(),
+		nodeID:      defaultNodeID(),
+		runLogLimit: 100,
 	}
 }
 
@@ -59,9 +72,48 @@ func WithLog(log *zap.Logger, isOnlyPrintError ...bool) Option {
 	}
 }
 
+// WithLocker enable cluster mode, each run of a task is executed by only one replica that holds the lock,
+// it should be used with a shared store, e.g. NewRedisStore.
+func WithLocker(factory LockerFactory, keyPrefix ...string) Option {
+	return func(o *options) {
+		o.lockerFactory = factory
+		if len(keyPrefix) > 0 && keyPrefix[0] != "" {
+			o.lockPrefix = keyPrefix[0]
+		}
+	}
+}
+
+// WithStore set store for persisting task definitions, run state and execution logs
+func WithStore(store Store) Option {
+	return func(o *options) {
+		if store != nil {
+			o.store = store
+		}
+	}
+}
+
+// WithNodeID set node id, recorded in the execution log
+func WithNodeID(nodeID string) Option {
+	return func(o *options) {
+		if nodeID != "" {
+			o.nodeID = nodeID
+		}
+	}
+}
+
+// WithRunLogLimit set the maximum number of execution logs kept for each task
+func WithRunLogLimit(limit int) Option {
+	return func(o *options) {
+		if limit > 0 {
+			o.runLogLimit = limit
+		}
+	}
+}
+
 type zapLog struct {
 	zapLog           *zap.Logger
 	isOnlyPrintError bool
+	idName           *sync.Map // id and task name mapping
 }
 
 // Info print info
@@ -73,7 +125,7 @@ func (l *zapLog) Info(msg string, keysAndValues ...interface{}) {
 		return
 	}
 	msg = "cron_" + msg
-	fields := parseKVs(keysAndValues)
+	fields := l.parseKVs(keysAndValues)
 	l.zapLog.Info(msg, fields...)
 }
 
@@ -82,13 +134,13 @@ func (l *zapLog) Error(err error, msg string, keysAndValues ...interface{}) {
 	if l.zapLog == nil {
 		return
 	}
-	fields := parseKVs(keysAndValues)
+	fields := l.parseKVs(keysAndValues)
 	fields = append(fields, zap.String("err", err.Error()))
 	msg = "cron_" + msg
 	l.zapLog.Error(msg, fields...)
 }
 
-func parseKVs(kvs interface{}) []zap.Field {
+func (l *zapLog) parseKVs(kvs interface{}) []zap.Field {
 	var fields []zap.Field
 
 	infos, ok := kvs.([]interface{})
@@ -96,12 +148,12 @@ func parseKVs(kvs interface{}) []zap.Field {
 		return fields
 	}
 
-	l := len(infos)
-	if l%2 == 1 {
+	size := len(infos)
+	if size%2 == 1 {
 		return fields
 	}
 
-	for i := 0; i < l; i += 2 {
+	for i := 0; i < size; i += 2 {
 		key := infos[i].(string) //nolint
 		value := infos[i+1]
 
@@ -109,8 +161,10 @@ func parseKVs(kvs interface{}) []zap.Field {
 		if key == "entry" {
 			if id, ok := value.(cron.EntryID); ok {
 				key = "task"
-				if v, isExist := idName.Load(id); isExist {
-					value = v
+				if l.idName != nil {
+					if v, isExist := l.idName.Load(id); isExist {
+						value = v
+					}
 				}
 			}
 		}
@@ -0,0 +1,118 @@
+package gocron
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/18721889353/sunshine/pkg/errcode"
+	"github.com/18721889353/sunshine/pkg/gin/response"
+)
+
+// RescheduleRequest request body of reschedule
+type RescheduleRequest struct {
+	TimeSpec string `json:"timeSpec" binding:"required"`
+}
+
+// RegisterRouter register the task management api to the gin router group, e.g.
+//
+//	gocron.RegisterRouter(r.Group("/admin/cron"), gocron.GetScheduler())
+//
+// routes:
+//
+//	GET    /tasks                list tasks
+//	GET    /tasks/:name/logs     list the latest execution logs, query parameter limit, default 20
+//	POST   /tasks/:name/pause    pause task
+//	POST   /tasks/:name/resume   resume task
+//	POST   /tasks/:name/trigger  run task immediately
+//	PUT    /tasks/:name/schedule change time spec, body {"timeSpec": "@every 1m"}
+//	DELETE /tasks/:name          delete task
+func RegisterRouter(group *gin.RouterGroup, s *Scheduler) {
+	h := &taskHandler{s: s}
+	group.GET("/tasks", h.List)
+	group.GET("/tasks/:name/logs", h.ListRunLogs)
+	group.POST("/tasks/:name/pause", h.Pause)
+	group.POST("/tasks/:name/resume", h.Resume)
+	group.POST("/tasks/:name/trigger", h.Trigger)
+	group.PUT("/tasks/:name/schedule", h.Reschedule)
+	group.DELETE("/tasks/:name", h.Delete)
+}
+
+type taskHandler struct {
+	s *Scheduler
+}
+
+func (h *taskHandler) List(c *gin.Context) {
+	response.Success(c, gin.H{"tasks": h.s.ListTasks()})
+}
+
+func (h *taskHandler) ListRunLogs(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		response.Error(c, errcode.InvalidParams.RewriteMsg("invalid limit"))
+		return
+	}
+	logs, err := h.s.GetRunLogs(c.Param("name"), limit)
+	if err != nil {
+		response.Error(c, errcode.InternalServerError.RewriteMsg(err.Error()))
+		return
+	}
+	response.Success(c, gin.H{"logs": logs})
+}
+
+func (h *taskHandler) Pause(c *gin.Context) {
+	handleResult(c, h.s.Pause(c.Param("name")))
+}
+
+func (h *taskHandler) Resume(c *gin.Context) {
+	handleResult(c, h.s.Resume(c.Param("name")))
+}
+
+func (h *taskHandler) Trigger(c *gin.Context) {
+	handleResult(c, h.s.Trigger(c.Param("name")))
+}
+
+func (h *taskHandler) Reschedule(c *gin.Context) {
+	form := &RescheduleRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		response.Error(c, errcode.InvalidParams)
+		return
+	}
+	name := c.Param("name")
+	if !h.s.IsRunningTask(name) {
+		handleResult(c, ErrTaskNotExist)
+		return
+	}
+	if err := h.s.Reschedule(name, form.TimeSpec); err != nil {
+		if errors.Is(err, ErrTaskNotExist) {
+			handleResult(c, err)
+			return
+		}
+		response.Error(c, errcode.InvalidParams.RewriteMsg(err.Error()))
+		return
+	}
+	response.Success(c)
+}
+
+func (h *taskHandler) Delete(c *gin.Context) {
+	name := c.Param("name")
+	if !h.s.IsRunningTask(name) {
+		handleResult(c, ErrTaskNotExist)
+		return
+	}
+	h.s.DeleteTask(name)
+	response.Success(c)
+}
+
+func handleResult(c *gin.Context, err error) {
+	if err != nil {
+		if errors.Is(err, ErrTaskNotExist) {
+			response.Error(c, errcode.NotFound.RewriteMsg(err.Error()))
+			return
+		}
+		response.Error(c, errcode.InternalServerError.RewriteMsg(err.Error()))
+		return
+	}
+	response.Success(c)
+}
@@ -0,0 +1,57 @@
+package gocron
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/18721889353/sunshine/pkg/errcode"
+)
+
+func TestRegisterRouter(t *testing.T) {
+	s := NewScheduler(WithLog(zap.NewNop()))
+	defer s.Stop()
+	err := s.Run(&Task{Name: "task1", TimeSpec: "@every 1h", Fn: func() {}})
+	assert.NoError(t, err)
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	RegisterRouter(r.Group("/admin/cron"), s)
+
+	do := func(method string, path string, body interface{}) int {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, "/admin/cron"+path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		result := &struct {
+			Code int `json:"code"`
+		}{}
+		_ = json.Unmarshal(rec.Body.Bytes(), result)
+		return result.Code
+	}
+
+	assert.Equal(t, 0, do(http.MethodGet, "/tasks", nil))
+	assert.Equal(t, 0, do(http.MethodPost, "/tasks/task1/pause", nil))
+	assert.Equal(t, 0, do(http.MethodPost, "/tasks/task1/resume", nil))
+	assert.Equal(t, 0, do(http.MethodPost, "/tasks/task1/trigger", nil))
+	assert.Equal(t, 0, do(http.MethodGet, "/tasks/task1/logs?limit=5", nil))
+	assert.Equal(t, errcode.InvalidParams.Code(), do(http.MethodGet, "/tasks/task1/logs?limit=x", nil))
+	assert.Equal(t, 0, do(http.MethodPut, "/tasks/task1/schedule", &RescheduleRequest{TimeSpec: "@every 2h"}))
+	assert.Equal(t, errcode.InvalidParams.Code(), do(http.MethodPut, "/tasks/task1/schedule", &RescheduleRequest{TimeSpec: "invalid"}))
+	assert.Equal(t, errcode.InvalidParams.Code(), do(http.MethodPut, "/tasks/task1/schedule", nil))
+	assert.Equal(t, errcode.NotFound.Code(), do(http.MethodPut, "/tasks/not_exist/schedule", &RescheduleRequest{TimeSpec: "@every 2h"}))
+	assert.Equal(t, errcode.NotFound.Code(), do(http.MethodPost, "/tasks/not_exist/pause", nil))
+	assert.Equal(t, 0, do(http.MethodDelete, "/tasks/task1", nil))
+	assert.Equal(t, errcode.NotFound.Code(), do(http.MethodDelete, "/tasks/task1", nil))
+}
@@ -0,0 +1,311 @@
+package gocron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ErrTaskNotExist task is not added to the scheduler
+var ErrTaskNotExist = errors.New("task is not exists")
+
+// TaskInfo task information and schedule state
+type TaskInfo struct {
+	Name          string        `json:"name"`
+	TimeSpec      string        `json:"timeSpec"`
+	IsRunOnce     bool          `json:"isRunOnce"`
+	Timeout       time.Duration `json:"timeout"`
+	Paused        bool          `json:"paused"`
+	PrevRunAt     time.Time     `json:"prevRunAt"` // previous fire time of this replica
+	NextRunAt     time.Time     `json:"nextRunAt"` // zero if paused
+	LastRunAt     time.Time     `json:"lastRunAt"` // last run time of all replicas
+	LastRunStatus string        `json:"lastRunStatus"`
+}
+
+type taskEntry struct {
+	runner *taskRunner
+	id     cron.EntryID
+}
+
+// Scheduler manages scheduled tasks, each scheduler owns its cron instance and tasks.
+type Scheduler struct {
+	cron   *cron.Cron
+	parser cron.Parser
+	opts   *options
+	log    *zapLog
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.RWMutex
+	entries map[string]*taskEntry
+	idName  *sync.Map // id and task name mapping, used in log printing
+
+	recordMu sync.Mutex // lock the read-modify-write of task records in this replica
+}
+
+// NewScheduler create and start a scheduler
+func NewScheduler(opts ...Option) *Scheduler {
+	o := defaultOptions()
+	o.apply(opts...)
+
+	idName := &sync.Map{}
+	log := &zapLog{zapLog: o.zapLog, isOnlyPrintError: o.isOnlyPrintError, idName: idName}
+
+	var parser cron.Parser
+	if o.granularity == SecondType { // second-level granularity, default is minute-level granularity
+		parser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	} else {
+		parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		cron: cron.New(
+			cron.WithLogger(log),
+			cron.WithChain(cron.Recover(log)),
+			cron.WithParser(parser),
+		),
+		parser:  parser,
+		opts:    o,
+		log:     log,
+		ctx:     ctx,
+		cancel:  cancel,
+		entries: make(map[string]*taskEntry),
+		idName:  idName,
+	}
+	s.cron.Start()
+
+	return s
+}
+
+// Run the tasks
+func (s *Scheduler) Run(tasks ...*Task) error {
+	var errs []string
+	for _, task := range tasks {
+		if s.IsRunningTask(task.Name) {
+			errs = append(errs, fmt.Sprintf("task '%s' is already exists", task.Name))
+			continue
+		}
+
+		if task.Fn == nil && task.FnWithContext == nil {
+			errs = append(errs, fmt.Sprintf("task '%s' is nil", task.Name))
+			continue
+		}
+
+		schedule, err := s.parser.Parse(task.TimeSpec)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("run task '%s' error: %v", task.Name, err))
+			continue
+		}
+		runner := &taskRunner{task: task, schedule: schedule, s: s}
+		runner.register()
+
+		s.mu.Lock()
+		s.entries[task.Name] = &taskEntry{runner: runner, id: s.schedule(runner)}
+		s.mu.Unlock()
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, " || "))
+	}
+
+	return nil
+}
+
+func (s *Scheduler) schedule(runner *taskRunner) cron.EntryID {
+	id := s.cron.Schedule(runner.schedule, runner)
+	s.idName.Store(id, runner.task.Name)
+	return id
+}
+
+func (s *Scheduler) unschedule(id cron.EntryID) {
+	s.cron.Remove(id)
+	s.idName.Delete(id)
+}
+
+func (s *Scheduler) getEntry(name string) (*taskEntry, error) {
+	entry, ok := s.entries[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotExist, name)
+	}
+	return entry, nil
+}
+
+// IsRunningTask determine if the task is added to the scheduler, including paused tasks
+func (s *Scheduler) IsRunningTask(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.entries[name]
+	return ok
+}
+
+// GetRunningTasks gets a list of task names, including paused tasks
+func (s *Scheduler) GetRunningTasks() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.entries))
+	for name := range s.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DeleteTask stop and delete the specified task
+func (s *Scheduler) DeleteTask(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[name]
+	if !ok {
+		return
+	}
+	s.unschedule(entry.id)
+	delete(s.entries, name)
+}
+
+// Pause the specified task, the paused state is persisted in the store and checked before each run,
+// if the store is shared by multiple replicas, the task is paused on all replicas.
+func (s *Scheduler) Pause(name string) error {
+	runner, err := s.getRunner(name)
+	if err != nil {
+		return err
+	}
+
+	return runner.updateRecord(func(record *TaskRecord) {
+		record.Paused = true
+		record.NextRunAt = time.Time{}
+	})
+}
+
+// Resume the paused task on all replicas, the runs missed during the pause are skipped
+func (s *Scheduler) Resume(name string) error {
+	runner, err := s.getRunner(name)
+	if err != nil {
+		return err
+	}
+
+	return runner.updateRecord(func(record *TaskRecord) {
+		if record.Paused {
+			record.Paused = false
+			record.NextRunAt = runner.schedule.Next(time.Now())
+		}
+	})
+}
+
+// Trigger run the specified task immediately in the background, even if it is paused
+func (s *Scheduler) Trigger(name string) error {
+	runner, err := s.getRunner(name)
+	if err != nil {
+		return err
+	}
+
+	go runner.runAt(time.Now().Truncate(time.Second), true)
+	return nil
+}
+
+// Reschedule change the time spec of the specified task, the time spec is persisted in the store,
+// the other replicas sharing the store apply it at their next run of the task.
+func (s *Scheduler) Reschedule(name string, timeSpec string) error {
+	runner, err := s.reschedule(name, timeSpec)
+	if err != nil {
+		return err
+	}
+
+	return runner.updateRecord(func(record *TaskRecord) {
+		record.TimeSpec = timeSpec
+		if !record.Paused {
+			record.NextRunAt = runner.schedule.Next(time.Now())
+		}
+	})
+}
+
+func (s *Scheduler) getRunner(name string) (*taskRunner, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, err := s.getEntry(name)
+	if err != nil {
+		return nil, err
+	}
+	return entry.runner, nil
+}
+
+// reschedule change the time spec of the task in this replica
+func (s *Scheduler) reschedule(name string, timeSpec string) (*taskRunner, error) {
+	schedule, err := s.parser.Parse(timeSpec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time spec '%s': %v", timeSpec, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, err := s.getEntry(name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.runner.task.TimeSpec == timeSpec {
+		return entry.runner, nil
+	}
+	task := *entry.runner.task
+	task.TimeSpec = timeSpec
+	runner := &taskRunner{task: &task, schedule: schedule, s: s}
+	s.unschedule(entry.id)
+	entry.id = s.schedule(runner)
+	entry.runner = runner
+	return runner, nil
+}
+
+// ListTasks list all tasks with their next fire times, sorted by name
+func (s *Scheduler) ListTasks() []*TaskInfo {
+	s.mu.RLock()
+	infos := make([]*TaskInfo, 0, len(s.entries))
+	for name, entry := range s.entries {
+		task := entry.runner.task
+		e := s.cron.Entry(entry.id)
+		infos = append(infos, &TaskInfo{
+			Name:      name,
+			TimeSpec:  task.TimeSpec,
+			IsRunOnce: task.IsRunOnce,
+			Timeout:   task.Timeout,
+			PrevRunAt: e.Prev,
+			NextRunAt: e.Next,
+		})
+	}
+	s.mu.RUnlock()
+
+	for _, info := range infos {
+		record, err := s.opts.store.GetTask(context.Background(), info.Name)
+		if err == nil {
+			info.LastRunAt = record.LastRunAt
+			info.LastRunStatus = record.LastRunStatus
+			if record.Paused {
+				info.Paused = true
+				info.NextRunAt = time.Time{}
+			}
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// GetTaskRecords gets the persisted definition and run state of all tasks
+func (s *Scheduler) GetTaskRecords() ([]*TaskRecord, error) {
+	return s.opts.store.ListTasks(context.Background())
+}
+
+// GetRunLogs gets the latest execution logs of the specified task
+func (s *Scheduler) GetRunLogs(name string, limit int) ([]*RunLog, error) {
+	return s.opts.store.ListRunLogs(context.Background(), name, limit)
+}
+
+// Stop all scheduled tasks, the context of running tasks is canceled
+func (s *Scheduler) Stop() {
+	s.cron.Stop()
+	s.cancel()
+}
@@ -0,0 +1,153 @@
+package gocron
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestScheduler(t *testing.T) {
+	s := NewScheduler(WithLog(zap.NewNop()))
+	defer s.Stop()
+
+	var count int32
+	err := s.Run(&Task{
+		Name:     "task1",
+		TimeSpec: "@every 1h",
+		Fn: func() {
+			atomic.AddInt32(&count, 1)
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, s.IsRunningTask("task1"))
+	assert.Equal(t, []string{"task1"}, s.GetRunningTasks())
+
+	tasks := s.ListTasks()
+	assert.Len(t, tasks, 1)
+	assert.False(t, tasks[0].Paused)
+	assert.False(t, tasks[0].NextRunAt.IsZero())
+
+	// trigger now
+	err = s.Trigger("task1")
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+	assert.Equal(t, StatusSuccess, s.ListTasks()[0].LastRunStatus)
+
+	// pause and resume
+	err = s.Pause("task1")
+	assert.NoError(t, err)
+	assert.NoError(t, s.Pause("task1"))
+	tasks = s.ListTasks()
+	assert.True(t, tasks[0].Paused)
+	assert.True(t, tasks[0].NextRunAt.IsZero())
+	records, _ := s.GetTaskRecords()
+	assert.True(t, records[0].Paused)
+
+	err = s.Resume("task1")
+	assert.NoError(t, err)
+	assert.NoError(t, s.Resume("task1"))
+	assert.False(t, s.ListTasks()[0].Paused)
+
+	// reschedule
+	err = s.Reschedule("task1", "@every 1s")
+	assert.NoError(t, err)
+	tasks = s.ListTasks()
+	assert.Equal(t, "@every 1s", tasks[0].TimeSpec)
+	assert.True(t, tasks[0].NextRunAt.Before(time.Now().Add(time.Second*2)))
+	err = s.Reschedule("task1", "invalid")
+	assert.Error(t, err)
+
+	// not exist
+	assert.ErrorIs(t, s.Pause("not_exist"), ErrTaskNotExist)
+	assert.ErrorIs(t, s.Resume("not_exist"), ErrTaskNotExist)
+	assert.ErrorIs(t, s.Trigger("not_exist"), ErrTaskNotExist)
+	assert.ErrorIs(t, s.Reschedule("not_exist", "@every 1s"), ErrTaskNotExist)
+
+	s.DeleteTask("task1")
+	assert.False(t, s.IsRunningTask("task1"))
+	assert.Len(t, s.ListTasks(), 0)
+}
+
+func TestScheduler_persistedPause(t *testing.T) {
+	store := NewMemoryStore()
+	task := &Task{Name: "task1", TimeSpec: "@every 1s", Fn: func() {}}
+
+	s1 := NewScheduler(WithLog(zap.NewNop()), WithStore(store))
+	err := s1.Run(task)
+	assert.NoError(t, err)
+	err = s1.Pause(task.Name)
+	assert.NoError(t, err)
+	s1.Stop()
+
+	// restart, the task is still paused
+	s2 := NewScheduler(WithLog(zap.NewNop()), WithStore(store))
+	defer s2.Stop()
+	err = s2.Run(task)
+	assert.NoError(t, err)
+	assert.True(t, s2.ListTasks()[0].Paused)
+}
+
+func TestScheduler_sharedStore(t *testing.T) {
+	store := NewMemoryStore()
+	var count int32
+	newTask := func() *Task {
+		return &Task{Name: "task1", TimeSpec: "@every 1h", Fn: func() { atomic.AddInt32(&count, 1) }}
+	}
+	s1 := NewScheduler(WithLog(zap.NewNop()), WithStore(store))
+	defer s1.Stop()
+	s2 := NewScheduler(WithLog(zap.NewNop()), WithStore(store))
+	defer s2.Stop()
+	assert.NoError(t, s1.Run(newTask()))
+	assert.NoError(t, s2.Run(newTask()))
+	runner2, err := s2.getRunner("task1")
+	assert.NoError(t, err)
+
+	// paused by s1, s2 skips the run
+	assert.NoError(t, s1.Pause("task1"))
+	assert.True(t, s2.ListTasks()[0].Paused)
+	runner2.Run()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&count))
+
+	// resumed by s1, s2 runs
+	assert.NoError(t, s1.Resume("task1"))
+	runner2.Run()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+
+	// rescheduled by s1, s2 applies the time spec at the next run
+	assert.NoError(t, s1.Reschedule("task1", "@every 2h"))
+	runner2.Run()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+	assert.Equal(t, "@every 2h", s2.ListTasks()[0].TimeSpec)
+}
+
+func TestScheduler_pauseWhileRunning(t *testing.T) {
+	s := NewScheduler(WithLog(zap.NewNop()))
+	defer s.Stop()
+
+	running := make(chan struct{})
+	release := make(chan struct{})
+	err := s.Run(&Task{Name: "task1", TimeSpec: "@every 1h", Fn: func() {
+		close(running)
+		<-release
+	}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Trigger("task1"))
+	<-running
+
+	// the end of the run does not overwrite the paused state
+	assert.NoError(t, s.Pause("task1"))
+	close(release)
+	assert.Eventually(t, func() bool {
+		logs, _ := s.GetRunLogs("task1", 1)
+		return len(logs) == 1
+	}, time.Second, time.Millisecond*10)
+	records, err := s.GetTaskRecords()
+	assert.NoError(t, err)
+	assert.True(t, records[0].Paused)
+	assert.Equal(t, StatusSuccess, records[0].LastRunStatus)
+	assert.True(t, records[0].NextRunAt.IsZero())
+}
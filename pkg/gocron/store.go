@@ -0,0 +1,242 @@
+package gocron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// run status of task
+const (
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+	StatusTimeout = "timeout"
+)
+
+// ErrTaskNotFound task record not found in store
+var ErrTaskNotFound = errors.New("task record not found")
+
+// TaskRecord persisted task definition and run state
+type TaskRecord struct {
+	Name      string        `json:"name"`
+	TimeSpec  string        `json:"timeSpec"`
+	IsRunOnce bool          `json:"isRunOnce"`
+	Timeout   time.Duration `json:"timeout"`
+	Misfire   MisfirePolicy `json:"misfire"`
+	Paused    bool          `json:"paused"`
+
+	LastScheduledAt time.Time `json:"lastScheduledAt"` // scheduled time of the last run, used to avoid duplicate runs between replicas
+	LastRunAt       time.Time `json:"lastRunAt"`
+	LastRunStatus   string    `json:"lastRunStatus"`
+	NextRunAt       time.Time `json:"nextRunAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// RunLog execution log of a task
+type RunLog struct {
+	Name        string    `json:"name"`
+	NodeID      string    `json:"nodeID"`
+	ScheduledAt time.Time `json:"scheduledAt"`
+	StartAt     time.Time `json:"startAt"`
+	EndAt       time.Time `json:"endAt"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Store persist task definitions and execution logs
+type Store interface {
+	SaveTask(ctx context.Context, record *TaskRecord) error
+	// GetTask returns ErrTaskNotFound if the task does not exist
+	GetTask(ctx context.Context, name string) (*TaskRecord, error)
+	ListTasks(ctx context.Context) ([]*TaskRecord, error)
+	DeleteTask(ctx context.Context, name string) error
+	// AddRunLog add execution log, only the latest limit logs are kept
+	AddRunLog(ctx context.Context, log *RunLog, limit int) error
+	// ListRunLogs returns the latest execution logs, sorted by start time in descending order
+	ListRunLogs(ctx context.Context, name string, limit int) ([]*RunLog, error)
+}
+
+// ---------------------------------- memory store ----------------------------------
+
+type memoryStore struct {
+	mu      sync.RWMutex
+	records map[string]TaskRecord
+	logs    map[string][]*RunLog
+}
+
+// NewMemoryStore create a store in memory, the data is lost after restart, it is the default store.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		records: make(map[string]TaskRecord),
+		logs:    make(map[string][]*RunLog),
+	}
+}
+
+func (s *memoryStore) SaveTask(_ context.Context, record *TaskRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.Name] = *record
+	return nil
+}
+
+func (s *memoryStore) GetTask(_ context.Context, name string) (*TaskRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.records[name]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	return &record, nil
+}
+
+func (s *memoryStore) ListTasks(_ context.Context) ([]*TaskRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	records := make([]*TaskRecord, 0, len(s.records))
+	for _, record := range s.records {
+		r := record
+		records = append(records, &r)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	return records, nil
+}
+
+func (s *memoryStore) DeleteTask(_ context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, name)
+	delete(s.logs, name)
+	return nil
+}
+
+func (s *memoryStore) AddRunLog(_ context.Context, log *RunLog, limit int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	logs := append([]*RunLog{log}, s.logs[log.Name]...)
+	if limit > 0 && len(logs) > limit {
+		logs = logs[:limit]
+	}
+	s.logs[log.Name] = logs
+	return nil
+}
+
+func (s *memoryStore) ListRunLogs(_ context.Context, name string, limit int) ([]*RunLog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	logs := s.logs[name]
+	if limit > 0 && len(logs) > limit {
+		logs = logs[:limit]
+	}
+	return append([]*RunLog{}, logs...), nil
+}
+
+// ---------------------------------- redis store ----------------------------------
+
+type redisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore create a store in redis, it can be shared by multiple replicas, default prefix is "gocron:".
+func NewRedisStore(client redis.UniversalClient, prefix ...string) Store {
+	p := "gocron:"
+	if len(prefix) > 0 && prefix[0] != "" {
+		p = prefix[0]
+	}
+	return &redisStore{client: client, prefix: p}
+}
+
+func (s *redisStore) tasksKey() string {
+	return s.prefix + "tasks"
+}
+
+func (s *redisStore) logsKey(name string) string {
+	return s.prefix + "logs:" + name
+}
+
+func (s *redisStore) SaveTask(ctx context.Context, record *TaskRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.client.HSet(ctx, s.tasksKey(), record.Name, data).Err()
+}
+
+func (s *redisStore) GetTask(ctx context.Context, name string) (*TaskRecord, error) {
+	data, err := s.client.HGet(ctx, s.tasksKey(), name).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	record := &TaskRecord{}
+	err = json.Unmarshal(data, record)
+	return record, err
+}
+
+func (s *redisStore) ListTasks(ctx context.Context) ([]*TaskRecord, error) {
+	values, err := s.client.HGetAll(ctx, s.tasksKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*TaskRecord, 0, len(values))
+	for _, value := range values {
+		record := &TaskRecord{}
+		if err = json.Unmarshal([]byte(value), record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	return records, nil
+}
+
+func (s *redisStore) DeleteTask(ctx context.Context, name string) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, s.tasksKey(), name)
+		pipe.Del(ctx, s.logsKey(name))
+		return nil
+	})
+	return err
+}
+
+func (s *redisStore) AddRunLog(ctx context.Context, log *RunLog, limit int) error {
+	data, err := json.Marshal(log)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, s.logsKey(log.Name), data)
+		if limit > 0 {
+			pipe.LTrim(ctx, s.logsKey(log.Name), 0, int64(limit-1))
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *redisStore) ListRunLogs(ctx context.Context, name string, limit int) ([]*RunLog, error) {
+	stop := int64(-1)
+	if limit > 0 {
+		stop = int64(limit - 1)
+	}
+	values, err := s.client.LRange(ctx, s.logsKey(name), 0, stop).Result()
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]*RunLog, 0, len(values))
+	for _, value := range values {
+		log := &RunLog{}
+		if err = json.Unmarshal([]byte(value), log); err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
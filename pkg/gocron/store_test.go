@@ -0,0 +1,66 @@
+package gocron
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	_, err := store.GetTask(ctx, "task1")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	for _, name := range []string{"task2", "task1"} {
+		err = store.SaveTask(ctx, &TaskRecord{Name: name, TimeSpec: "@every 1s", NextRunAt: time.Now()})
+		assert.NoError(t, err)
+	}
+	record, err := store.GetTask(ctx, "task1")
+	assert.NoError(t, err)
+	assert.Equal(t, "@every 1s", record.TimeSpec)
+
+	records, err := store.ListTasks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "task1", records[0].Name)
+
+	for i := 0; i < 5; i++ {
+		err = store.AddRunLog(ctx, &RunLog{Name: "task1", NodeID: string(rune('a' + i)), Status: StatusSuccess}, 3)
+		assert.NoError(t, err)
+	}
+	logs, err := store.ListRunLogs(ctx, "task1", 0)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 3)
+	assert.Equal(t, "e", logs[0].NodeID)
+	logs, err = store.ListRunLogs(ctx, "task1", 1)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+
+	err = store.DeleteTask(ctx, "task1")
+	assert.NoError(t, err)
+	_, err = store.GetTask(ctx, "task1")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	logs, err = store.ListRunLogs(ctx, "task1", 0)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 0)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestRedisStore(t *testing.T) {
+	redisServer, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redisServer.Close()
+	client := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+
+	testStore(t, NewRedisStore(client, "test:"))
+}
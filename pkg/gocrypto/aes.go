@@ -5,6 +5,9 @@
 package gocrypto
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
 	"encoding/hex"
 	"errors"
 
@@ -77,6 +80,10 @@ func getCipherMode(mode string) (wcipher.CipherMode, error) {
 }
 
 func aesEncryptByMode(mode string, rawData []byte, key []byte) ([]byte, error) {
+	if mode == modeGCM {
+		return aesGCMEncrypt(rawData, key)
+	}
+
 	cipherMode, err := getCipherMode(mode)
 	if err != nil {
 		return nil, err
@@ -91,6 +98,10 @@ func aesEncryptByMode(mode string, rawData []byte, key []byte) ([]byte, error) {
 }
 
 func aesDecryptByMode(mode string, cipherData []byte, key []byte) ([]byte, error) {
+	if mode == modeGCM {
+		return aesGCMDecrypt(cipherData, key)
+	}
+
 	cipherMode, err := getCipherMode(mode)
 	if err != nil {
 		return nil, err
@@ -103,3 +114,35 @@ func aesDecryptByMode(mode string, cipherData []byte, key []byte) ([]byte, error
 
 	return cip.Decrypt(cipherData), nil
 }
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func aesGCMEncrypt(rawData []byte, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, rawData, nil), nil // the random nonce is stored in front of ciphertext
+}
+
+func aesGCMDecrypt(cipherData []byte, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(cipherData) < gcm.NonceSize() {
+		return nil, errors.New("invalid ciphertext")
+	}
+	nonce, ciphertext := cipherData[:gcm.NonceSize()], cipherData[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
@@ -61,6 +61,31 @@ func TestAes(t *testing.T) {
 		}
 		t.Logf("[%s]  <=>  [%x]", aesRawData, cypherData)
 	})
+
+	// GCM
+	t.Run("aes gcm", func(t *testing.T) {
+		cypherData, err := AesEncrypt(aesRawData, WithAesKey(aesKey), WithAesModeGCM())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := AesDecrypt(cypherData, WithAesKey(aesKey), WithAesModeGCM())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Fatalf("got [%s], want [%s]", got, want)
+		}
+		t.Logf("[%s]  <=>  [%x]", aesRawData, cypherData)
+
+		// tampered ciphertext or wrong key fails to decrypt
+		cypherData[len(cypherData)-1] ^= 0xff
+		if _, err = AesDecrypt(cypherData, WithAesKey(aesKey), WithAesModeGCM()); err == nil {
+			t.Fatal("expected error for tampered ciphertext")
+		}
+		if _, err = AesDecrypt([]byte("short"), WithAesKey(aesKey), WithAesModeGCM()); err == nil {
+			t.Fatal("expected error for short ciphertext")
+		}
+	})
 }
 
 func TestAesHex(t *testing.T) {
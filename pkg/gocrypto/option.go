@@ -7,6 +7,7 @@ const (
 	modeCBC = "CBC"
 	modeCFB = "CFB"
 	modeCTR = "CTR"
+	modeGCM = "GCM"
 )
 
 var (
@@ -22,7 +23,7 @@ type aesOptions struct {
 	// the length of the key must be one of 16,24,32, corresponding to
 	// AES-128,AES-192,AES-256 respectively.
 	aesKey []byte
-	// there are four operating modes in total, ECB CBC CFB CTR
+	// there are five operating modes in total, ECB CBC CFB CTR GCM
 	mode string
 }
 
@@ -77,6 +78,13 @@ func WithAesModeCTR() AesOption {
 	}
 }
 
+// WithAesModeGCM set mode to GCM, the random nonce is stored in front of the ciphertext
+func WithAesModeGCM() AesOption {
+	return func(o *aesOptions) {
+		o.mode = modeGCM
+	}
+}
+
 // ------------------------------------------------------------------------------------------
 
 type desOptions struct {
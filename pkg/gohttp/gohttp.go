@@ -1,4 +1,5 @@
-// Package gohttp is http request client, which only supports returning json format.
+// Package gohttp is http request client, which only supports returning json format,
+// the requests are sent by the shared client of pkg/httpcli.
 // Deprecated: moved to pkg/httpcli, will remove in future version.
 package gohttp
 
@@ -12,9 +13,9 @@ import (
 	"net/url"
 	"strings"
 	"time"
-)
 
-const defaultTimeout = 30 * time.Second
+	"github.com/18721889353/sunshine/pkg/httpcli"
+)
 
 // Request HTTP request
 // Deprecated: moved to pkg/httpcli Request
@@ -27,7 +28,6 @@ type Request struct {
 	timeout       time.Duration          // Client timeout
 	headers       map[string]string
 
-	request  *http.Request
 	response *Response
 	method   string
 	err      error
@@ -50,7 +50,6 @@ func (req *Request) Reset() {
 	req.timeout = 0
 	req.headers = nil
 
-	req.request = nil
 	req.response = nil
 	req.method = ""
 	req.err = nil
@@ -251,29 +250,24 @@ func (req *Request) push() (*Response, error) {
 	return req.send(buf, buf)
 }
 
+// the request is sent by the shared client of pkg/httpcli, the connections are reused between requests.
 func (req *Request) send(body io.Reader, buf *bytes.Buffer) (*Response, error) {
-	req.request, req.err = http.NewRequest(req.method, req.url, body)
-	if req.err != nil {
-		return nil, req.err
-	}
-
+	r := httpcli.New().SetURL(req.url).SetTimeout(req.timeout).SetHeaders(req.headers)
 	if req.customRequest != nil {
-		req.customRequest(req.request, buf)
+		r.CustomRequest(func(request *http.Request, _ *bytes.Buffer) {
+			req.customRequest(request, buf)
+		})
 	}
-
-	if req.headers != nil {
-		for k, v := range req.headers {
-			req.request.Header.Add(k, v)
-		}
+	if body != nil && buf != nil {
+		r.SetBody(buf.Bytes())
 	}
 
-	if req.timeout < 1 {
-		req.timeout = defaultTimeout
-	}
-
-	client := http.Client{Timeout: req.timeout}
 	resp := new(Response)
-	resp.Response, resp.err = client.Do(req.request)
+	var res *httpcli.Response
+	res, resp.err = r.Do(req.method, nil)
+	if res != nil {
+		resp.Response = res.Response
+	}
 
 	req.response = resp
 	req.err = resp.err
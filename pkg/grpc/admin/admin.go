@@ -0,0 +1,175 @@
+// Package admin is the admin http endpoints of grpc server, including the registered services and methods,
+// the interceptor chain, the config, the connection stats and runtime log level changes.
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+
+	"google.golang.org/grpc"
+
+	"github.com/18721889353/sunshine/pkg/logger"
+)
+
+// Option set the admin options.
+type Option func(*options)
+
+type options struct {
+	prefix             string
+	stats              *ConnStats
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
+	config             func() string
+}
+
+func defaultOptions() *options {
+	return &options{
+		prefix: "/admin",
+	}
+}
+
+func (o *options) apply(opts ...Option) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithPrefix set the route prefix, default /admin.
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		if prefix != "" {
+			o.prefix = prefix
+		}
+	}
+}
+
+// WithConnStats set the connection stats, it must be the stats handler of grpc server, e.g. grpc.StatsHandler(stats).
+func WithConnStats(stats *ConnStats) Option {
+	return func(o *options) {
+		o.stats = stats
+	}
+}
+
+// WithUnaryInterceptors set the unary interceptor chain of grpc server, used for displaying.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(o *options) {
+		o.unaryInterceptors = interceptors
+	}
+}
+
+// WithStreamInterceptors set the stream interceptor chain of grpc server, used for displaying.
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) Option {
+	return func(o *options) {
+		o.streamInterceptors = interceptors
+	}
+}
+
+// WithConfig set the function that returns the config of service, sensitive fields should be hidden.
+func WithConfig(fn func() string) Option {
+	return func(o *options) {
+		o.config = fn
+	}
+}
+
+// Register the admin routes to mux:
+//
+//	GET  /admin/services      registered services and methods
+//	GET  /admin/interceptors  interceptor chain
+//	GET  /admin/config        config of service
+//	GET  /admin/connections   connection and rpc stats
+//	GET  /admin/loglevel      current log level and levels of named loggers
+//	PUT  /admin/loglevel      change the log level, body {"level":"debug"} or {"name":"kafka","level":"debug"}, or query ?level=debug
+//	DELETE /admin/loglevel    reset the level of named logger by ?name=kafka, or all named loggers if name is empty
+func Register(mux *http.ServeMux, server *grpc.Server, opts ...Option) {
+	o := defaultOptions()
+	o.apply(opts...)
+
+	mux.HandleFunc(o.prefix+"/services", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, listServices(server))
+	})
+
+	mux.HandleFunc(o.prefix+"/interceptors", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string][]string{
+			"unary":  funcNames(o.unaryInterceptors),
+			"stream": funcNames(o.streamInterceptors),
+		})
+	})
+
+	mux.HandleFunc(o.prefix+"/config", func(w http.ResponseWriter, r *http.Request) {
+		if o.config == nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "config is not set"})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(o.config()))
+	})
+
+	mux.HandleFunc(o.prefix+"/connections", func(w http.ResponseWriter, r *http.Request) {
+		if o.stats == nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "connection stats is not set"})
+			return
+		}
+		writeJSON(w, http.StatusOK, o.stats.Snapshot())
+	})
+
+	mux.HandleFunc(o.prefix+"/loglevel", logger.LevelHandler())
+}
+
+// Service a registered grpc service
+type Service struct {
+	Name    string    `json:"name"`
+	Methods []*Method `json:"methods"`
+}
+
+// Method a method of grpc service
+type Method struct {
+	Name           string `json:"name"`
+	FullMethod     string `json:"fullMethod"`
+	IsClientStream bool   `json:"isClientStream"`
+	IsServerStream bool   `json:"isServerStream"`
+}
+
+func listServices(server *grpc.Server) []*Service {
+	var services []*Service
+	for name, info := range server.GetServiceInfo() {
+		service := &Service{Name: name}
+		for _, m := range info.Methods {
+			service.Methods = append(service.Methods, &Method{
+				Name:           m.Name,
+				FullMethod:     "/" + name + "/" + m.Name,
+				IsClientStream: m.IsClientStream,
+				IsServerStream: m.IsServerStream,
+			})
+		}
+		sort.Slice(service.Methods, func(i, j int) bool { return service.Methods[i].Name < service.Methods[j].Name })
+		services = append(services, service)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services
+}
+
+// e.g. github.com/foo/interceptor.UnaryServerRecovery.func1 --> github.com/foo/interceptor.UnaryServerRecovery
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$`)
+
+func funcNames[T any](fns []T) []string {
+	names := make([]string, 0, len(fns))
+	for _, fn := range fns {
+		name := "unknown"
+		if f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()); f != nil {
+			name = closureSuffix.ReplaceAllString(f.Name(), "")
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,120 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/18721889353/sunshine/pkg/grpc/interceptor"
+	"github.com/18721889353/sunshine/pkg/logger"
+)
+
+func runServer(t *testing.T) (*grpc.Server, *ConnStats, *httptest.Server, grpc_health_v1.HealthClient) {
+	stats := NewConnStats()
+	unaryInterceptors := []grpc.UnaryServerInterceptor{interceptor.UnaryServerRecovery(), interceptor.UnaryServerRequestID()}
+	server := grpc.NewServer(grpc.StatsHandler(stats), grpc.ChainUnaryInterceptor(unaryInterceptors...))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	mux := http.NewServeMux()
+	Register(mux, server,
+		WithConnStats(stats),
+		WithUnaryInterceptors(unaryInterceptors...),
+		WithConfig(func() string { return `{"app":{"name":"user"}}` }),
+	)
+	httpServer := httptest.NewServer(mux)
+	t.Cleanup(httpServer.Close)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return server, stats, httpServer, grpc_health_v1.NewHealthClient(conn)
+}
+
+func getJSON(t *testing.T, method string, url string, body string, v interface{}) int {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	return resp.StatusCode
+}
+
+func TestRegister(t *testing.T) {
+	_, _, httpServer, client := runServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, err)
+	}
+
+	var services []*Service
+	assert.Equal(t, http.StatusOK, getJSON(t, http.MethodGet, httpServer.URL+"/admin/services", "", &services))
+	require.Len(t, services, 1)
+	assert.Equal(t, "grpc.health.v1.Health", services[0].Name)
+	assert.Equal(t, "/grpc.health.v1.Health/Check", services[0].Methods[0].FullMethod)
+	assert.True(t, services[0].Methods[len(services[0].Methods)-1].IsServerStream)
+
+	interceptors := map[string][]string{}
+	getJSON(t, http.MethodGet, httpServer.URL+"/admin/interceptors", "", &interceptors)
+	assert.Len(t, interceptors["unary"], 2)
+	assert.Equal(t, "github.com/18721889353/sunshine/pkg/grpc/interceptor.UnaryServerRequestID", interceptors["unary"][1])
+
+	config := map[string]interface{}{}
+	getJSON(t, http.MethodGet, httpServer.URL+"/admin/config", "", &config)
+	assert.NotNil(t, config["app"])
+
+	snapshot := &Snapshot{}
+	getJSON(t, http.MethodGet, httpServer.URL+"/admin/connections", "", snapshot)
+	assert.Equal(t, 1, snapshot.ActiveConns)
+	assert.Equal(t, int64(3), snapshot.RPCSucceeded)
+	assert.Equal(t, int64(3), snapshot.Connections[0].RPCs)
+}
+
+func TestLogLevel(t *testing.T) {
+	_, err := logger.Init(logger.WithLevel("info"))
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	Register(mux, grpc.NewServer(), WithPrefix("/debug"))
+	httpServer := httptest.NewServer(mux)
+	defer httpServer.Close()
+
+	result := map[string]interface{}{}
+	assert.Equal(t, http.StatusOK, getJSON(t, http.MethodGet, httpServer.URL+"/debug/loglevel", "", &result))
+	assert.Equal(t, "info", result["level"])
+
+	assert.Equal(t, http.StatusOK, getJSON(t, http.MethodPut, httpServer.URL+"/debug/loglevel", `{"level":"debug"}`, &result))
+	assert.Equal(t, "debug", logger.GetLevel())
+
+	assert.Equal(t, http.StatusOK, getJSON(t, http.MethodPost, httpServer.URL+"/debug/loglevel?level=warn", "", &result))
+	assert.Equal(t, "warn", logger.GetLevel())
+
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, http.MethodPut, httpServer.URL+"/debug/loglevel", `{"level":"foo"}`, &result))
+	assert.Equal(t, "warn", logger.GetLevel())
+
+	assert.Equal(t, http.StatusOK, getJSON(t, http.MethodPut, httpServer.URL+"/debug/loglevel", `{"name":"kafka","level":"debug"}`, &result))
+	assert.Equal(t, map[string]string{"kafka": "debug"}, logger.GetNamedLevels())
+	assert.NoError(t, logger.SetNamedLevel("kafka", ""))
+
+	assert.Equal(t, http.StatusNotFound, getJSON(t, http.MethodGet, httpServer.URL+"/debug/connections", "", &result))
+}
@@ -0,0 +1,142 @@
+package admin
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/stats"
+)
+
+var _ stats.Handler = (*ConnStats)(nil)
+
+// ConnStats is a grpc stats handler that records the connections and rpc counts of server,
+// usage: grpc.NewServer(grpc.StatsHandler(connStats)).
+type ConnStats struct {
+	mu    sync.Mutex
+	conns map[*connInfo]struct{}
+
+	totalConns   int64
+	rpcStarted   int64
+	rpcSucceeded int64
+	rpcFailed    int64
+	rpcInflight  int64
+}
+
+// NewConnStats create a connection stats handler.
+func NewConnStats() *ConnStats {
+	return &ConnStats{conns: make(map[*connInfo]struct{})}
+}
+
+type connInfo struct {
+	remoteAddr  string
+	localAddr   string
+	connectedAt time.Time
+	rpcs        int64
+}
+
+type connKey struct{}
+
+// Connection stats of a connection
+type Connection struct {
+	RemoteAddr  string    `json:"remoteAddr"`
+	LocalAddr   string    `json:"localAddr"`
+	ConnectedAt time.Time `json:"connectedAt"`
+	RPCs        int64     `json:"rpcs"`
+}
+
+// Snapshot of connection stats
+type Snapshot struct {
+	ActiveConns  int           `json:"activeConns"`
+	TotalConns   int64         `json:"totalConns"`
+	RPCStarted   int64         `json:"rpcStarted"`
+	RPCSucceeded int64         `json:"rpcSucceeded"`
+	RPCFailed    int64         `json:"rpcFailed"`
+	RPCInflight  int64         `json:"rpcInflight"`
+	Connections  []*Connection `json:"connections"`
+}
+
+// Snapshot returns the current stats.
+func (s *ConnStats) Snapshot() *Snapshot {
+	snapshot := &Snapshot{
+		TotalConns:   atomic.LoadInt64(&s.totalConns),
+		RPCStarted:   atomic.LoadInt64(&s.rpcStarted),
+		RPCSucceeded: atomic.LoadInt64(&s.rpcSucceeded),
+		RPCFailed:    atomic.LoadInt64(&s.rpcFailed),
+		RPCInflight:  atomic.LoadInt64(&s.rpcInflight),
+	}
+
+	s.mu.Lock()
+	for c := range s.conns {
+		snapshot.Connections = append(snapshot.Connections, &Connection{
+			RemoteAddr:  c.remoteAddr,
+			LocalAddr:   c.localAddr,
+			ConnectedAt: c.connectedAt,
+			RPCs:        atomic.LoadInt64(&c.rpcs),
+		})
+	}
+	s.mu.Unlock()
+
+	snapshot.ActiveConns = len(snapshot.Connections)
+	sort.Slice(snapshot.Connections, func(i, j int) bool {
+		return snapshot.Connections[i].ConnectedAt.Before(snapshot.Connections[j].ConnectedAt)
+	})
+	return snapshot
+}
+
+// TagConn attach the connection info to context.
+func (s *ConnStats) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	c := &connInfo{connectedAt: time.Now()}
+	if info.RemoteAddr != nil {
+		c.remoteAddr = info.RemoteAddr.String()
+	}
+	if info.LocalAddr != nil {
+		c.localAddr = info.LocalAddr.String()
+	}
+	return context.WithValue(ctx, connKey{}, c)
+}
+
+// HandleConn record the connection begin and end.
+func (s *ConnStats) HandleConn(ctx context.Context, st stats.ConnStats) {
+	c, ok := ctx.Value(connKey{}).(*connInfo)
+	if !ok {
+		return
+	}
+	switch st.(type) {
+	case *stats.ConnBegin:
+		atomic.AddInt64(&s.totalConns, 1)
+		s.mu.Lock()
+		s.conns[c] = struct{}{}
+		s.mu.Unlock()
+	case *stats.ConnEnd:
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+	}
+}
+
+// TagRPC returns the context unchanged.
+func (s *ConnStats) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC record the rpc begin and end.
+func (s *ConnStats) HandleRPC(ctx context.Context, st stats.RPCStats) {
+	switch rs := st.(type) {
+	case *stats.Begin:
+		atomic.AddInt64(&s.rpcStarted, 1)
+		atomic.AddInt64(&s.rpcInflight, 1)
+		if c, ok := ctx.Value(connKey{}).(*connInfo); ok {
+			atomic.AddInt64(&c.rpcs, 1)
+		}
+	case *stats.End:
+		atomic.AddInt64(&s.rpcInflight, -1)
+		if rs.Error != nil {
+			atomic.AddInt64(&s.rpcFailed, 1)
+		} else {
+			atomic.AddInt64(&s.rpcSucceeded, 1)
+		}
+	}
+}
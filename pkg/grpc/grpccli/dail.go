@@ -11,6 +11,7 @@ import (
 
 	"github.com/18721889353/sunshine/pkg/grpc/gtls"
 	"github.com/18721889353/sunshine/pkg/grpc/interceptor"
+	"github.com/18721889353/sunshine/pkg/grpc/loadbalance"
 	"github.com/18721889353/sunshine/pkg/logger"
 	"github.com/18721889353/sunshine/pkg/servicerd/discovery"
 )
@@ -33,7 +34,7 @@ func Dial(ctx context.Context, endpoint string, opts ...Option) (*grpc.ClientCon
 
 	// load balance option
 	if o.enableLoadBalance {
-		clientOptions = append(clientOptions, grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"round_robin":{}}]}`))
+		clientOptions = append(clientOptions, grpc.WithDefaultServiceConfig(loadbalance.ServiceConfig(o.loadBalancePolicy)))
 	}
 
 	// secure option
@@ -105,7 +106,7 @@ func unaryClientOptions(o *options) grpc.DialOption {
 
 	unaryClientInterceptors = append(unaryClientInterceptors, interceptor.UnaryClientRecovery())
 
-	if o.requestTimeout > 0 {
+	if o.requestTimeout > 0 && len(o.methodPolicies) == 0 {
 		unaryClientInterceptors = append(unaryClientInterceptors, interceptor.UnaryClientTimeout(o.requestTimeout))
 	}
 
@@ -137,6 +138,15 @@ func unaryClientOptions(o *options) grpc.DialOption {
 		unaryClientInterceptors = append(unaryClientInterceptors, interceptor.UnaryClientRetry())
 	}
 
+	// per-method policies
+	if len(o.methodPolicies) > 0 {
+		policyOptions := o.policyOptions
+		if o.requestTimeout > 0 {
+			policyOptions = append([]interceptor.PolicyOption{interceptor.WithPolicyTimeout(o.requestTimeout)}, policyOptions...)
+		}
+		unaryClientInterceptors = append(unaryClientInterceptors, interceptor.UnaryClientPolicy(o.methodPolicies, policyOptions...))
+	}
+
 	// trace
 	if o.enableTrace {
 		unaryClientInterceptors = append(unaryClientInterceptors, interceptor.UnaryClientTracing())
@@ -6,6 +6,7 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 
+	"github.com/18721889353/sunshine/pkg/grpc/interceptor"
 	"github.com/18721889353/sunshine/pkg/servicerd/registry"
 )
 
@@ -41,9 +42,14 @@ type options struct {
 	enableMetrics        bool               // whether to turn on metrics
 	enableRetry          bool               // whether to turn on retry
 	enableLoadBalance    bool               // whether to turn on load balance
+	loadBalancePolicy    string             // load balancing policy, default round_robin
 	enableCircuitBreaker bool               // whether to turn on circuit breaker
 	discovery            registry.Discovery // if not nil means use service discovery
 
+	// per-method policies of timeout, retry, hedging and circuit breaker
+	methodPolicies []*interceptor.MethodPolicy
+	policyOptions  []interceptor.PolicyOption
+
 	discoveryInsecure bool
 
 	// custom setting
@@ -62,6 +68,7 @@ func defaultOptions() *options {
 
 		enableLog:         false,
 		discoveryInsecure: true,
+		loadBalancePolicy: "round_robin",
 
 		dialOptions:        nil,
 		unaryInterceptors:  nil,
@@ -123,6 +130,16 @@ func WithEnableLoadBalance() Option {
 	}
 }
 
+// WithLoadBalancePolicy enable load balance and set the policy, supports round_robin and the
+// metadata-aware policies in package loadbalance, e.g. loadbalance.WeightRoundRobin, loadbalance.P2CEWMA,
+// loadbalance.ConsistentHash.
+func WithLoadBalancePolicy(policy string) Option {
+	return func(o *options) {
+		o.enableLoadBalance = true
+		o.loadBalancePolicy = policy
+	}
+}
+
 // WithEnableRetry enable registry
 func WithEnableRetry() Option {
 	return func(o *options) {
@@ -137,6 +154,17 @@ func WithEnableCircuitBreaker() Option {
 	}
 }
 
+// WithMethodPolicies set the per-method policies of timeout, retry with exponential backoff and jitter,
+// hedging and circuit breaker, valid only for unary. The timeout set by WithTimeout is used for the methods
+// whose policy has no timeout and takes precedence over the * policy, the retry and circuit breaker set by
+// WithEnableRetry and WithEnableCircuitBreaker should not be used together with the policies.
+func WithMethodPolicies(policies []*interceptor.MethodPolicy, opts ...interceptor.PolicyOption) Option {
+	return func(o *options) {
+		o.methodPolicies = append(o.methodPolicies, policies...)
+		o.policyOptions = append(o.policyOptions, opts...)
+	}
+}
+
 // WithDiscoveryInsecure setting discovery insecure
 func WithDiscoveryInsecure(b bool) Option {
 	return func(o *options) {
@@ -43,6 +43,22 @@ func TestWithEnableLoadBalance(t *testing.T) {
 	assert.Equal(t, true, o.enableLoadBalance)
 }
 
+func TestWithLoadBalancePolicy(t *testing.T) {
+	opt := WithLoadBalancePolicy("p2c_ewma")
+	o := new(options)
+	o.apply(opt)
+	assert.Equal(t, true, o.enableLoadBalance)
+	assert.Equal(t, "p2c_ewma", o.loadBalancePolicy)
+}
+
+func TestWithMethodPolicies(t *testing.T) {
+	opt := WithMethodPolicies([]*interceptor.MethodPolicy{{Method: "*", Timeout: time.Second}}, interceptor.WithRetryBudget(10, 0.1))
+	o := new(options)
+	o.apply(opt)
+	assert.Len(t, o.methodPolicies, 1)
+	assert.Len(t, o.policyOptions, 1)
+}
+
 func TestWithEnableRequestID(t *testing.T) {
 	opt := WithEnableRequestID()
 	o := new(options)
@@ -10,6 +10,7 @@ import (
 
 	"github.com/18721889353/sunshine/pkg/container/group"
 	"github.com/18721889353/sunshine/pkg/errcode"
+	"github.com/18721889353/sunshine/pkg/metrics"
 	"github.com/18721889353/sunshine/pkg/shield/circuitbreaker"
 )
 
@@ -26,6 +27,9 @@ type circuitBreakerOptions struct {
 
 	// degrade handler for unary server
 	unaryServerDegradeHandler func(ctx context.Context, req interface{}) (reply interface{}, error error)
+
+	// label name of the breaker metrics
+	metricsName string
 }
 
 func defaultCircuitBreakerOptions() *circuitBreakerOptions {
@@ -72,10 +76,27 @@ func WithUnaryServerDegradeHandler(handler func(ctx context.Context, req interfa
 	}
 }
 
+// WithBreakerMetricsName set the label name of the breaker metrics, default is grpc_unary_client,
+// grpc_stream_client, grpc_unary_server or grpc_stream_server, the name must be unique if more than
+// one group is used, empty means the metrics are not registered.
+func WithBreakerMetricsName(name string) CircuitBreakerOption {
+	return func(o *circuitBreakerOptions) {
+		o.metricsName = name
+	}
+}
+
+func (o *circuitBreakerOptions) registerMetrics() {
+	if o.metricsName != "" {
+		_ = metrics.RegisterBreakers(o.metricsName, o.group)
+	}
+}
+
 // UnaryClientCircuitBreaker client-side unary circuit breaker interceptor
 func UnaryClientCircuitBreaker(opts ...CircuitBreakerOption) grpc.UnaryClientInterceptor {
 	o := defaultCircuitBreakerOptions()
+	o.metricsName = "grpc_unary_client"
 	o.apply(opts...)
+	o.registerMetrics()
 
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		breaker := o.group.Get(method).(circuitbreaker.CircuitBreaker)
@@ -104,7 +125,9 @@ func UnaryClientCircuitBreaker(opts ...CircuitBreakerOption) grpc.UnaryClientInt
 // StreamClientCircuitBreaker client-side stream circuit breaker interceptor
 func StreamClientCircuitBreaker(opts ...CircuitBreakerOption) grpc.StreamClientInterceptor {
 	o := defaultCircuitBreakerOptions()
+	o.metricsName = "grpc_stream_client"
 	o.apply(opts...)
+	o.registerMetrics()
 
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
 		breaker := o.group.Get(method).(circuitbreaker.CircuitBreaker)
@@ -133,7 +156,9 @@ func StreamClientCircuitBreaker(opts ...CircuitBreakerOption) grpc.StreamClientI
 // UnaryServerCircuitBreaker server-side unary circuit breaker interceptor
 func UnaryServerCircuitBreaker(opts ...CircuitBreakerOption) grpc.UnaryServerInterceptor {
 	o := defaultCircuitBreakerOptions()
+	o.metricsName = "grpc_unary_server"
 	o.apply(opts...)
+	o.registerMetrics()
 
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		breaker := o.group.Get(info.FullMethod).(circuitbreaker.CircuitBreaker)
@@ -166,7 +191,9 @@ func UnaryServerCircuitBreaker(opts ...CircuitBreakerOption) grpc.UnaryServerInt
 // StreamServerCircuitBreaker server-side stream circuit breaker interceptor
 func StreamServerCircuitBreaker(opts ...CircuitBreakerOption) grpc.StreamServerInterceptor {
 	o := defaultCircuitBreakerOptions()
+	o.metricsName = "grpc_stream_server"
 	o.apply(opts...)
+	o.registerMetrics()
 
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		breaker := o.group.Get(info.FullMethod).(circuitbreaker.CircuitBreaker)
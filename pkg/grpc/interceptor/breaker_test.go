@@ -9,6 +9,7 @@ import (
 
 	"github.com/18721889353/sunshine/pkg/container/group"
 	"github.com/18721889353/sunshine/pkg/errcode"
+	"github.com/18721889353/sunshine/pkg/metrics"
 	"github.com/18721889353/sunshine/pkg/shield/circuitbreaker"
 	"google.golang.org/grpc/codes"
 )
@@ -113,3 +114,32 @@ func TestSteamServerCircuitBreaker(t *testing.T) {
 	err := interceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: "/test"}, handler)
 	assert.Error(t, err)
 }
+
+func TestUnaryServerCircuitBreaker_metrics(t *testing.T) {
+	interceptor := UnaryServerCircuitBreaker(WithBreakerMetricsName("test_grpc_breaker"))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test"}, handler)
+	assert.NoError(t, err)
+
+	mfs, err := metrics.Registry().Gather()
+	assert.NoError(t, err)
+	found := false
+	for _, mf := range mfs {
+		if mf.GetName() != "circuit_breaker_open" {
+			continue
+		}
+		for _, m := range mf.Metric {
+			labels := map[string]string{}
+			for _, l := range m.Label {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["name"] == "test_grpc_breaker" && labels["key"] == "/test" {
+				found = true
+				assert.Equal(t, float64(0), m.GetGauge().GetValue())
+			}
+		}
+	}
+	assert.True(t, found)
+}
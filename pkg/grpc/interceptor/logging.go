@@ -125,6 +125,7 @@ type logOptions struct {
 	isReplaceGRPCLogger bool
 	maxLength           int
 	logFrom             string
+	redactor            *pkgLogger.Redactor
 }
 
 func defaultLogOptions() *logOptions {
@@ -133,6 +134,7 @@ func defaultLogOptions() *logOptions {
 		ignoreMethods: make(map[string]struct{}),
 		maxLength:     300,
 		logFrom:       "",
+		redactor:      pkgLogger.DefaultRedactor(),
 	}
 }
 
@@ -183,6 +185,14 @@ func WithLogIgnoreMethods(fullMethodNames ...string) LogOption {
 	}
 }
 
+// WithLogRedactor set the redactor that masks the sensitive values in request and response,
+// default is logger.DefaultRedactor(), nil means not masking.
+func WithLogRedactor(redactor *pkgLogger.Redactor) LogOption {
+	return func(o *logOptions) {
+		o.redactor = redactor
+	}
+}
+
 // UnaryServerLog server-side log unary interceptor
 func UnaryServerLog(logger *zap.Logger, opts ...LogOption) grpc.UnaryServerInterceptor {
 	o := defaultLogOptions()
@@ -205,21 +215,29 @@ func UnaryServerLog(logger *zap.Logger, opts ...LogOption) grpc.UnaryServerInter
 		startTime := time.Now()
 		requestID := ServerCtxRequestID(ctx)
 
+		traceFields := pkgLogger.TraceFields(ctx)
+		requestField := pkgLogger.Any("request", req)
+		if o.redactor != nil {
+			requestField = zap.String("request", string(o.redactor.RedactAny(req)))
+		}
+
 		fields := []zap.Field{
 			zap.String("current_time", time.Now().Format("2006-01-02 15:04:05.000000000")),
 			zap.String("type", "unary"),
 			zap.String("method", info.FullMethod),
-			pkgLogger.Any("request", req),
+			requestField,
 		}
 		if requestID != "" {
 			fields = append(fields, zap.String(ContextRequestIDKey, requestID))
 		}
+		fields = append(fields, traceFields...)
 		fields = append(fields, zap.String("log_from", o.logFrom+" request UnaryServerLog"))
 		pkgLogger.Info(`<<<<`, fields...)
 
 		resp, err := handler(ctx, req)
 
 		data, _ := json.Marshal(resp)
+		data = o.redactor.Redact(data)
 		if len(data) > o.maxLength {
 			data = append(data[:o.maxLength], []byte("......")...)
 		}
@@ -237,6 +255,7 @@ func UnaryServerLog(logger *zap.Logger, opts ...LogOption) grpc.UnaryServerInter
 		if requestID != "" {
 			fields = append(fields, zap.String(ContextRequestIDKey, requestID))
 		}
+		fields = append(fields, traceFields...)
 		fields = append(fields, zap.String("log_from", o.logFrom+" response UnaryServerLog"))
 		pkgLogger.Info(`>>>>`, fields...)
 
@@ -282,6 +301,7 @@ func UnaryServerSimpleLog(logger *zap.Logger, opts ...LogOption) grpc.UnaryServe
 		if requestID != "" {
 			fields = append(fields, zap.String(ContextRequestIDKey, requestID))
 		}
+		fields = append(fields, pkgLogger.TraceFields(ctx)...)
 		fields = append(fields, zap.String("log_from", o.logFrom+` [GRPC] UnaryServerSimpleLog`))
 		pkgLogger.Info(`[GRPC]`, fields...)
 
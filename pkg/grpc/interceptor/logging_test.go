@@ -1,9 +1,15 @@
 package interceptor
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
 	"github.com/18721889353/sunshine/pkg/logger"
 )
 
@@ -28,6 +34,44 @@ func TestUnaryServerLog(t *testing.T) {
 	_ = sayHelloMethod(cli)
 }
 
+func TestUnaryServerLog_redact(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	_, err := logger.Init(logger.WithCores(core))
+	require.NoError(t, err)
+	defer func() { _, _ = logger.Init() }()
+
+	addr := newUnaryRPCServer(
+		UnaryServerRequestID(),
+		UnaryServerTracing(),
+		UnaryServerLog(logger.Get(), WithLogRedactor(logger.NewRedactor(logger.WithRedactPaths("$.message")))),
+		UnaryServerLog(logger.Get(), WithLogRedactor(logger.NewRedactor(logger.WithRedactFields("name")))),
+	)
+	time.Sleep(time.Millisecond * 200)
+	cli := newUnaryRPCClient(addr)
+	require.NoError(t, sayHelloMethod(cli))
+
+	var requests, responses []string
+	for _, entry := range logs.All() {
+		fields := map[string]string{}
+		if json.Unmarshal([]byte(entry.Message), &fields) != nil {
+			continue
+		}
+		switch fields["log_msg"] {
+		case "<<<<":
+			requests = append(requests, fields["request"])
+		case ">>>>":
+			responses = append(responses, fields["response"])
+		}
+	}
+	// the outer interceptor masks the path $.message, the inner interceptor masks the field name
+	require.Len(t, requests, 2)
+	assert.Equal(t, `{"name":"foo"}`, requests[0])
+	assert.Equal(t, `{"name":"******"}`, requests[1])
+	require.Len(t, responses, 2)
+	assert.Contains(t, responses[0], `"message":"hello foo"`)
+	assert.Equal(t, `{"message":"******"}`, responses[1])
+}
+
 func TestStreamClientLog(t *testing.T) {
 	addr := newStreamRPCServer()
 	time.Sleep(time.Millisecond * 200)
@@ -0,0 +1,458 @@
+package interceptor
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/18721889353/sunshine/pkg/container/group"
+	"github.com/18721889353/sunshine/pkg/errcode"
+	"github.com/18721889353/sunshine/pkg/grpc/loadbalance"
+	"github.com/18721889353/sunshine/pkg/metrics"
+	"github.com/18721889353/sunshine/pkg/shield/circuitbreaker"
+)
+
+// ---------------------------------- client interceptor ----------------------------------
+
+// MethodPolicy the policy of grpc methods, valid only for unary.
+type MethodPolicy struct {
+	// Method full method name, e.g. /api.user.v1.User/GetByID,
+	// /api.user.v1.User/* matches all methods of the service, * matches all methods.
+	Method string
+	// Timeout request timeout, if 0 means the default timeout, the timeout of * policy is
+	// used only if the default timeout is not set.
+	Timeout time.Duration
+	// Retry if nil means no retry.
+	Retry *RetryPolicy
+	// Hedging if not nil, hedged requests are sent and Retry is ignored, valid only for idempotent methods.
+	Hedging *HedgingPolicy
+	// Breaker whether to turn on circuit breaker per method, if the load balancing policy is one of
+	// package loadbalance, instances are also broken per method.
+	Breaker bool
+}
+
+// RetryPolicy retry with exponential backoff and jitter.
+type RetryPolicy struct {
+	Times          uint          // number of retries, max 10
+	Codes          []codes.Code  // codes triggering a retry, default codes.Unavailable
+	InitialBackoff time.Duration // backoff of the first retry, default 100ms
+	MaxBackoff     time.Duration // max backoff, default 1s
+	Multiplier     float64       // backoff multiplier, default 2
+	Jitter         float64       // the backoff is random in [d*(1-Jitter), d*(1+Jitter)], default 0.2
+}
+
+// HedgingPolicy sends hedged requests if no response is received after delay, the first response is used.
+type HedgingPolicy struct {
+	Times uint          // number of hedged requests, max 5
+	Delay time.Duration // delay before sending each hedged request, if 0 means all requests are sent at once
+	// NonFatalCodes codes that the other requests are waited for, other errors are returned immediately,
+	// default codes.Unavailable
+	NonFatalCodes []codes.Code
+}
+
+func (p *RetryPolicy) normalize() *RetryPolicy {
+	r := *p
+	if r.Times > 10 {
+		r.Times = 10
+	}
+	if len(r.Codes) == 0 {
+		r.Codes = []codes.Code{codes.Unavailable}
+	}
+	if r.InitialBackoff <= 0 {
+		r.InitialBackoff = time.Millisecond * 100
+	}
+	if r.MaxBackoff < r.InitialBackoff {
+		r.MaxBackoff = time.Second
+		if r.MaxBackoff < r.InitialBackoff {
+			r.MaxBackoff = r.InitialBackoff
+		}
+	}
+	if r.Multiplier < 1 {
+		r.Multiplier = 2
+	}
+	if r.Jitter <= 0 || r.Jitter > 1 {
+		r.Jitter = 0.2
+	}
+	return &r
+}
+
+// backoff of the nth retry, n starts from 1
+func (p *RetryPolicy) backoff(n uint) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(n-1))
+	if d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	d *= 1 + p.Jitter*(rand.Float64()*2-1) //nolint
+	return time.Duration(d)
+}
+
+func (p *HedgingPolicy) normalize() *HedgingPolicy {
+	h := *p
+	if h.Times > 5 {
+		h.Times = 5
+	}
+	if len(h.NonFatalCodes) == 0 {
+		h.NonFatalCodes = []codes.Code{codes.Unavailable}
+	}
+	return &h
+}
+
+func containsCode(cs []codes.Code, c codes.Code) bool {
+	for _, v := range cs {
+		if v == c {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCodes parse grpc code names, e.g. UNAVAILABLE, DEADLINE_EXCEEDED, the names are case-insensitive.
+func ParseCodes(names ...string) ([]codes.Code, error) {
+	cs := make([]codes.Code, 0, len(names))
+	for _, name := range names {
+		var c codes.Code
+		if err := c.UnmarshalJSON([]byte(`"` + strings.ToUpper(name) + `"`)); err != nil {
+			return nil, err
+		}
+		cs = append(cs, c)
+	}
+	return cs, nil
+}
+
+// --------------------------------------------------------------------------------------
+
+// retryBudget token bucket to avoid retry storms, the same as retryThrottling of grpc service config,
+// each failure decreases a token, each success increases tokenRatio tokens, retries and hedged requests
+// are allowed only if the tokens are more than half of maxTokens.
+type retryBudget struct {
+	mu         sync.Mutex
+	tokens     float64
+	maxTokens  float64
+	tokenRatio float64
+}
+
+func newRetryBudget(maxTokens float64, tokenRatio float64) *retryBudget {
+	return &retryBudget{tokens: maxTokens, maxTokens: maxTokens, tokenRatio: tokenRatio}
+}
+
+func (b *retryBudget) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens > b.maxTokens/2
+}
+
+func (b *retryBudget) onSuccess() {
+	b.mu.Lock()
+	b.tokens = math.Min(b.maxTokens, b.tokens+b.tokenRatio)
+	b.mu.Unlock()
+}
+
+func (b *retryBudget) onFailure() {
+	b.mu.Lock()
+	b.tokens = math.Max(0, b.tokens-1)
+	b.mu.Unlock()
+}
+
+// --------------------------------------------------------------------------------------
+
+// PolicyOption set the policy options.
+type PolicyOption func(*policyOptions)
+
+type policyOptions struct {
+	timeout    time.Duration
+	maxTokens  float64
+	tokenRatio float64
+
+	// label name of the breaker metrics
+	metricsName string
+}
+
+func defaultPolicyOptions() *policyOptions {
+	return &policyOptions{
+		maxTokens:   10,
+		tokenRatio:  0.1,
+		metricsName: "grpc_client_policy",
+	}
+}
+
+func (o *policyOptions) apply(opts ...PolicyOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithPolicyTimeout set the default timeout of methods whose policy has no timeout,
+// it also overrides the timeout of the * policy.
+func WithPolicyTimeout(d time.Duration) PolicyOption {
+	return func(o *policyOptions) {
+		o.timeout = d
+	}
+}
+
+// WithRetryBudget set the retry budget, default maxTokens is 10, tokenRatio is 0.1, that means
+// retries are stopped if more than about 1 in 10 requests fails with the retryable codes of the policy.
+func WithRetryBudget(maxTokens float64, tokenRatio float64) PolicyOption {
+	return func(o *policyOptions) {
+		if maxTokens > 0 {
+			o.maxTokens = maxTokens
+		}
+		if tokenRatio > 0 {
+			o.tokenRatio = tokenRatio
+		}
+	}
+}
+
+// WithPolicyMetricsName set the label name of the breaker metrics, default is grpc_client_policy,
+// the name must be unique if more than one client uses the policies, empty means the metrics are not registered.
+func WithPolicyMetricsName(name string) PolicyOption {
+	return func(o *policyOptions) {
+		o.metricsName = name
+	}
+}
+
+// policyTable matches the policy of method, the exact method first, then the service, then *.
+type policyTable struct {
+	methods  map[string]*MethodPolicy
+	services map[string]*MethodPolicy
+	all      *MethodPolicy
+}
+
+func newPolicyTable(policies []*MethodPolicy) *policyTable {
+	t := &policyTable{
+		methods:  make(map[string]*MethodPolicy),
+		services: make(map[string]*MethodPolicy),
+	}
+	for _, p := range policies {
+		if p == nil {
+			continue
+		}
+		mp := *p
+		if mp.Retry != nil {
+			mp.Retry = mp.Retry.normalize()
+		}
+		if mp.Hedging != nil {
+			mp.Hedging = mp.Hedging.normalize()
+		}
+
+		method := strings.TrimSpace(mp.Method)
+		switch {
+		case method == "*" || method == "":
+			t.all = &mp
+		case strings.HasSuffix(method, "/*"):
+			t.services["/"+strings.Trim(strings.TrimSuffix(method, "/*"), "/")] = &mp
+		default:
+			t.methods["/"+strings.TrimPrefix(method, "/")] = &mp
+		}
+	}
+	return t
+}
+
+func (t *policyTable) get(fullMethod string) *MethodPolicy {
+	if p, ok := t.methods[fullMethod]; ok {
+		return p
+	}
+	if i := strings.LastIndex(fullMethod, "/"); i > 0 {
+		if p, ok := t.services[fullMethod[:i]]; ok {
+			return p
+		}
+	}
+	return t.all
+}
+
+// UnaryClientPolicy client-side unary interceptor of per-method policies, including timeout,
+// retry with exponential backoff and jitter, retry budget, hedged requests and circuit breaker.
+func UnaryClientPolicy(policies []*MethodPolicy, opts ...PolicyOption) grpc.UnaryClientInterceptor {
+	o := defaultPolicyOptions()
+	o.apply(opts...)
+
+	table := newPolicyTable(policies)
+	budget := newRetryBudget(o.maxTokens, o.tokenRatio)
+	breakers := group.NewGroup(func() interface{} {
+		return circuitbreaker.NewBreaker()
+	})
+	if o.metricsName != "" {
+		_ = metrics.RegisterBreakers(o.metricsName, breakers)
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		p := table.get(method)
+		if p == nil {
+			p = &MethodPolicy{}
+		}
+
+		timeout := p.Timeout
+		if timeout <= 0 || (p == table.all && o.timeout > 0) {
+			timeout = o.timeout // the timeout set by the caller takes precedence over the * policy
+		}
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		var breaker circuitbreaker.CircuitBreaker
+		if p.Breaker {
+			breaker = breakers.Get(method).(circuitbreaker.CircuitBreaker)
+			if err := breaker.Allow(); err != nil {
+				// NOTE: when client reject request locally, keep adding counter let the drop ratio higher.
+				breaker.MarkFailed()
+				return errcode.StatusServiceUnavailable.ToRPCErr(err.Error())
+			}
+			ctx = loadbalance.WithInstanceBreaker(ctx)
+		}
+
+		isHedging := p.Hedging != nil && p.Hedging.Times > 0
+		// only the errors of retryable codes are failures of retry budget, same as grpc retryThrottling
+		var retryableCodes []codes.Code
+		if isHedging {
+			retryableCodes = p.Hedging.NonFatalCodes
+		} else if p.Retry != nil {
+			retryableCodes = p.Retry.Codes
+		}
+		call := func(ctx context.Context, reply interface{}) error {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil {
+				budget.onSuccess()
+			} else if ctx.Err() == nil && containsCode(retryableCodes, status.Code(err)) { // the cancelled hedged requests are not failures
+				budget.onFailure()
+			}
+			return err
+		}
+
+		var err error
+		if isHedging {
+			err = invokeHedging(ctx, p.Hedging, budget, reply, call)
+		} else {
+			err = invokeRetry(ctx, p.Retry, budget, reply, call)
+		}
+
+		if breaker != nil {
+			switch status.Code(err) {
+			case codes.Internal, codes.Unavailable:
+				breaker.MarkFailed()
+			default:
+				breaker.MarkSuccess()
+			}
+		}
+		return err
+	}
+}
+
+type callFn func(ctx context.Context, reply interface{}) error
+
+func invokeRetry(ctx context.Context, p *RetryPolicy, budget *retryBudget, reply interface{}, call callFn) error {
+	err := call(ctx, reply)
+	if p == nil {
+		return err
+	}
+
+	for n := uint(1); n <= p.Times; n++ {
+		if err == nil || !containsCode(p.Codes, status.Code(err)) || !budget.allow() {
+			return err
+		}
+
+		timer := time.NewTimer(p.backoff(n))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		err = call(ctx, reply)
+	}
+	return err
+}
+
+type hedgingResult struct {
+	reply interface{}
+	err   error
+}
+
+func invokeHedging(ctx context.Context, p *HedgingPolicy, budget *retryBudget, reply interface{}, call callFn) error {
+	msg, ok := reply.(proto.Message)
+	if !ok { // each hedged request needs its own reply
+		return call(ctx, reply)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan *hedgingResult, p.Times+1)
+	send := func() {
+		r := proto.Clone(msg)
+		proto.Reset(r)
+		go func() {
+			err := call(ctx, r)
+			results <- &hedgingResult{reply: r, err: err}
+		}()
+	}
+
+	send()
+	sent, done := uint(1), uint(0)
+	var lastErr error
+	timer := time.NewTimer(p.Delay)
+	defer timer.Stop()
+	for {
+		var hedge <-chan time.Time
+		if sent <= p.Times {
+			hedge = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return lastErr
+			}
+			return status.FromContextError(ctx.Err()).Err()
+
+		case <-hedge:
+			if !budget.allow() {
+				sent = p.Times + 1 // no more hedged requests, wait for the sent requests
+				continue
+			}
+			send()
+			sent++
+			timer.Reset(p.Delay)
+
+		case res := <-results:
+			done++
+			if res.err == nil {
+				proto.Reset(msg)
+				proto.Merge(msg, res.reply.(proto.Message))
+				return nil
+			}
+			lastErr = res.err
+			if !containsCode(p.NonFatalCodes, status.Code(res.err)) {
+				return res.err
+			}
+			if done == sent {
+				if sent > p.Times || !budget.allow() {
+					return lastErr
+				}
+				// all sent requests failed, send the next hedged request immediately
+				send()
+				sent++
+				resetTimer(timer, p.Delay)
+			}
+		}
+	}
+}
+
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Reset(d)
+}
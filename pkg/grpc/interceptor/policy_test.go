@@ -0,0 +1,250 @@
+package interceptor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestPolicyTable(t *testing.T) {
+	table := newPolicyTable([]*MethodPolicy{
+		{Method: "*", Timeout: time.Second},
+		{Method: "api.user.v1.User/*", Timeout: time.Second * 2},
+		{Method: "/api.user.v1.User/GetByID", Timeout: time.Second * 3, Retry: &RetryPolicy{Times: 20}},
+	})
+
+	assert.Equal(t, time.Second*3, table.get("/api.user.v1.User/GetByID").Timeout)
+	assert.Equal(t, uint(10), table.get("/api.user.v1.User/GetByID").Retry.Times)
+	assert.Equal(t, time.Second*2, table.get("/api.user.v1.User/List").Timeout)
+	assert.Equal(t, time.Second, table.get("/api.order.v1.Order/List").Timeout)
+
+	assert.Nil(t, newPolicyTable(nil).get("/api.user.v1.User/List"))
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := (&RetryPolicy{InitialBackoff: time.Millisecond * 100, MaxBackoff: time.Millisecond * 300}).normalize()
+	assert.Equal(t, []codes.Code{codes.Unavailable}, p.Codes)
+
+	for i := 0; i < 10; i++ {
+		d := p.backoff(1)
+		assert.True(t, d >= time.Millisecond*80 && d <= time.Millisecond*120, d)
+		d = p.backoff(2)
+		assert.True(t, d >= time.Millisecond*160 && d <= time.Millisecond*240, d)
+		d = p.backoff(5)
+		assert.True(t, d >= time.Millisecond*240 && d <= time.Millisecond*360, d)
+	}
+}
+
+func TestParseCodes(t *testing.T) {
+	cs, err := ParseCodes("UNAVAILABLE", "deadline_exceeded")
+	assert.NoError(t, err)
+	assert.Equal(t, []codes.Code{codes.Unavailable, codes.DeadlineExceeded}, cs)
+
+	_, err = ParseCodes("foo")
+	assert.Error(t, err)
+}
+
+func TestRetryBudget(t *testing.T) {
+	b := newRetryBudget(10, 0.1)
+	assert.True(t, b.allow())
+	for i := 0; i < 5; i++ {
+		b.onFailure()
+	}
+	assert.False(t, b.allow())
+	for i := 0; i < 10; i++ {
+		b.onSuccess()
+	}
+	assert.True(t, b.allow())
+}
+
+func TestUnaryClientPolicy_retry(t *testing.T) {
+	interceptor := UnaryClientPolicy([]*MethodPolicy{
+		{Method: "/api.user.v1.User/GetByID", Retry: &RetryPolicy{Times: 3, InitialBackoff: time.Millisecond}},
+	})
+
+	var calls int32
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return status.Error(codes.Unavailable, "unavailable")
+		}
+		return nil
+	}
+	err := interceptor(context.Background(), "/api.user.v1.User/GetByID", nil, nil, nil, invoker)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), calls)
+
+	// not retryable code
+	calls = 0
+	invoker = func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		atomic.AddInt32(&calls, 1)
+		return status.Error(codes.InvalidArgument, "invalid")
+	}
+	err = interceptor(context.Background(), "/api.user.v1.User/GetByID", nil, nil, nil, invoker)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, int32(1), calls)
+
+	// no policy, no retry
+	calls = 0
+	invoker = func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		atomic.AddInt32(&calls, 1)
+		return status.Error(codes.Unavailable, "unavailable")
+	}
+	err = interceptor(context.Background(), "/api.user.v1.User/List", nil, nil, nil, invoker)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestUnaryClientPolicy_retryBudget(t *testing.T) {
+	interceptor := UnaryClientPolicy([]*MethodPolicy{
+		{Method: "*", Retry: &RetryPolicy{Times: 10, InitialBackoff: time.Millisecond}},
+	}, WithRetryBudget(4, 0.1))
+
+	var calls int32
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		atomic.AddInt32(&calls, 1)
+		return status.Error(codes.Unavailable, "unavailable")
+	}
+	_ = interceptor(context.Background(), "/api.user.v1.User/List", nil, nil, nil, invoker)
+	// tokens 4 --> 3 --> 2, retries are stopped when the tokens are not more than 2
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestUnaryClientPolicy_retryBudgetNotRetryable(t *testing.T) {
+	interceptor := UnaryClientPolicy([]*MethodPolicy{
+		{Method: "*", Retry: &RetryPolicy{Times: 1, InitialBackoff: time.Millisecond}},
+	}, WithRetryBudget(4, 0.1))
+
+	var calls int32
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if atomic.AddInt32(&calls, 1) <= 10 {
+			return status.Error(codes.InvalidArgument, "invalid")
+		}
+		return status.Error(codes.Unavailable, "unavailable")
+	}
+	// the errors of not retryable codes do not drain the budget
+	for i := 0; i < 10; i++ {
+		_ = interceptor(context.Background(), "/api.user.v1.User/List", nil, nil, nil, invoker)
+	}
+	assert.Equal(t, int32(10), calls)
+	_ = interceptor(context.Background(), "/api.user.v1.User/List", nil, nil, nil, invoker)
+	assert.Equal(t, int32(12), calls)
+}
+
+func TestUnaryClientPolicy_timeout(t *testing.T) {
+	interceptor := UnaryClientPolicy([]*MethodPolicy{
+		{Method: "/api.user.v1.User/GetByID", Timeout: time.Millisecond * 50},
+	}, WithPolicyTimeout(time.Second))
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		if method == "/api.user.v1.User/GetByID" {
+			assert.True(t, time.Until(deadline) <= time.Millisecond*50)
+		} else {
+			assert.True(t, time.Until(deadline) > time.Millisecond*50)
+		}
+		return nil
+	}
+	assert.NoError(t, interceptor(context.Background(), "/api.user.v1.User/GetByID", nil, nil, nil, invoker))
+	assert.NoError(t, interceptor(context.Background(), "/api.user.v1.User/List", nil, nil, nil, invoker))
+}
+
+func TestUnaryClientPolicy_defaultTimeout(t *testing.T) {
+	policies := []*MethodPolicy{
+		{Method: "*", Timeout: time.Second * 10},
+		{Method: "/api.user.v1.User/GetByID", Timeout: time.Second * 30},
+	}
+	var deadline time.Time
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		deadline, _ = ctx.Deadline()
+		return nil
+	}
+
+	// the * policy is used if the caller has not set the timeout
+	interceptor := UnaryClientPolicy(policies)
+	assert.NoError(t, interceptor(context.Background(), "/api.user.v1.User/List", nil, nil, nil, invoker))
+	assert.True(t, time.Until(deadline) > time.Second*5)
+
+	// the timeout set by the caller takes precedence over the * policy, but not the method policy
+	interceptor = UnaryClientPolicy(policies, WithPolicyTimeout(time.Second))
+	assert.NoError(t, interceptor(context.Background(), "/api.user.v1.User/List", nil, nil, nil, invoker))
+	assert.True(t, time.Until(deadline) <= time.Second)
+	assert.NoError(t, interceptor(context.Background(), "/api.user.v1.User/GetByID", nil, nil, nil, invoker))
+	assert.True(t, time.Until(deadline) > time.Second*20)
+}
+
+func TestUnaryClientPolicy_hedging(t *testing.T) {
+	interceptor := UnaryClientPolicy([]*MethodPolicy{
+		{Method: "*", Hedging: &HedgingPolicy{Times: 2, Delay: time.Millisecond * 20}},
+	})
+
+	// the first request is slow, the hedged request responds first
+	var calls int32
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			select {
+			case <-ctx.Done():
+				return status.FromContextError(ctx.Err()).Err()
+			case <-time.After(time.Second):
+			}
+		}
+		reply.(*grpc_health_v1.HealthCheckResponse).Status = grpc_health_v1.HealthCheckResponse_ServingStatus(n)
+		return nil
+	}
+	reply := &grpc_health_v1.HealthCheckResponse{}
+	start := time.Now()
+	err := interceptor(context.Background(), "/api.user.v1.User/GetByID", nil, reply, nil, invoker)
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Millisecond*500)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_ServingStatus(2), reply.Status)
+
+	// fatal error is returned immediately
+	calls = 0
+	invoker = func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		atomic.AddInt32(&calls, 1)
+		return status.Error(codes.NotFound, "not found")
+	}
+	err = interceptor(context.Background(), "/api.user.v1.User/GetByID", nil, reply, nil, invoker)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, int32(1), calls)
+
+	// non-fatal errors, all hedged requests are sent
+	calls = 0
+	invoker = func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		atomic.AddInt32(&calls, 1)
+		return status.Error(codes.Unavailable, "unavailable")
+	}
+	err = interceptor(context.Background(), "/api.user.v1.User/GetByID", nil, reply, nil, invoker)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(3), calls)
+}
+
+func TestUnaryClientPolicy_breaker(t *testing.T) {
+	interceptor := UnaryClientPolicy([]*MethodPolicy{{Method: "*", Breaker: true}})
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "unavailable")
+	}
+	rejected := 0
+	for i := 0; i < 300; i++ {
+		err := interceptor(context.Background(), "/api.user.v1.User/GetByID", nil, nil, nil, invoker)
+		if st, _ := status.FromError(err); st.Code() != codes.Unavailable || st.Message() != "unavailable" {
+			rejected++
+		}
+	}
+	assert.Greater(t, rejected, 0)
+
+	// breaker of other method is closed
+	invoker = func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	}
+	assert.NoError(t, interceptor(context.Background(), "/api.user.v1.User/List", nil, nil, nil, invoker))
+}
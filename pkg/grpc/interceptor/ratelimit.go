@@ -7,6 +7,7 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/18721889353/sunshine/pkg/errcode"
+	"github.com/18721889353/sunshine/pkg/metrics"
 	rl "github.com/18721889353/sunshine/pkg/shield/ratelimit"
 )
 
@@ -24,6 +25,7 @@ type ratelimitOptions struct {
 	bucket       int
 	cpuThreshold int64
 	cpuQuota     float64
+	metricsName  string
 }
 
 func defaultRatelimitOptions() *ratelimitOptions {
@@ -68,16 +70,34 @@ func WithCPUQuota(quota float64) RatelimitOption {
 	}
 }
 
-// UnaryServerRateLimit server-side unary circuit breaker interceptor
-func UnaryServerRateLimit(opts ...RatelimitOption) grpc.UnaryServerInterceptor {
-	o := defaultRatelimitOptions()
-	o.apply(opts...)
+// WithRatelimitMetricsName set the label name of the limiter metrics, default is grpc_unary_server
+// or grpc_stream_server, the name must be unique if more than one limiter is used,
+// empty means the metrics are not registered.
+func WithRatelimitMetricsName(name string) RatelimitOption {
+	return func(o *ratelimitOptions) {
+		o.metricsName = name
+	}
+}
+
+func (o *ratelimitOptions) newLimiter() *rl.BBR {
 	limiter := rl.NewLimiter(
 		rl.WithWindow(o.window),
 		rl.WithBucket(o.bucket),
 		rl.WithCPUThreshold(o.cpuThreshold),
 		rl.WithCPUQuota(o.cpuQuota),
 	)
+	if o.metricsName != "" {
+		_ = metrics.RegisterLimiter(o.metricsName, limiter)
+	}
+	return limiter
+}
+
+// UnaryServerRateLimit server-side unary circuit breaker interceptor
+func UnaryServerRateLimit(opts ...RatelimitOption) grpc.UnaryServerInterceptor {
+	o := defaultRatelimitOptions()
+	o.metricsName = "grpc_unary_server"
+	o.apply(opts...)
+	limiter := o.newLimiter()
 
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 		done, err := limiter.Allow()
@@ -94,13 +114,9 @@ func UnaryServerRateLimit(opts ...RatelimitOption) grpc.UnaryServerInterceptor {
 // StreamServerRateLimit server-side stream circuit breaker interceptor
 func StreamServerRateLimit(opts ...RatelimitOption) grpc.StreamServerInterceptor {
 	o := defaultRatelimitOptions()
+	o.metricsName = "grpc_stream_server"
 	o.apply(opts...)
-	limiter := rl.NewLimiter(
-		rl.WithWindow(o.window),
-		rl.WithBucket(o.bucket),
-		rl.WithCPUThreshold(o.cpuThreshold),
-		rl.WithCPUQuota(o.cpuQuota),
-	)
+	limiter := o.newLimiter()
 
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		done, err := limiter.Allow()
@@ -10,6 +10,7 @@ import (
 	"google.golang.org/grpc/metadata"
 
 	"github.com/18721889353/sunshine/pkg/krand"
+	pkgLogger "github.com/18721889353/sunshine/pkg/logger"
 )
 
 var (
@@ -25,6 +26,7 @@ func SetContextRequestIDKey(key string) {
 	}
 	once.Do(func() {
 		ContextRequestIDKey = key
+		pkgLogger.SetContextRequestIDKey(key)
 	})
 }
 
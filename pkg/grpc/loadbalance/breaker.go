@@ -0,0 +1,75 @@
+package loadbalance
+
+import (
+	"context"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/18721889353/sunshine/pkg/container/group"
+	"github.com/18721889353/sunshine/pkg/shield/circuitbreaker"
+)
+
+// circuit breakers of instances, key is method@addr
+var instanceBreakers = group.NewGroup(func() interface{} {
+	return circuitbreaker.NewBreaker()
+})
+
+type instanceBreakerKey struct{}
+
+// WithInstanceBreaker enables the circuit breaker per method and instance for the request, the instances whose
+// breaker is open are skipped when picking, if the breakers of all candidates are open, all of them are candidates.
+func WithInstanceBreaker(ctx context.Context) context.Context {
+	return context.WithValue(ctx, instanceBreakerKey{}, true)
+}
+
+func isInstanceBreakerEnabled(ctx context.Context) bool {
+	v, _ := ctx.Value(instanceBreakerKey{}).(bool)
+	return v
+}
+
+func getInstanceBreaker(method string, n *node) circuitbreaker.CircuitBreaker {
+	return instanceBreakers.Get(method + "@" + n.addr).(circuitbreaker.CircuitBreaker)
+}
+
+// allowedNodes filters out the nodes whose breaker is open
+func allowedNodes(info balancer.PickInfo, nodes []*node) []*node {
+	if !isInstanceBreakerEnabled(info.Ctx) || len(nodes) <= 1 {
+		return nodes
+	}
+
+	allowed := make([]*node, 0, len(nodes))
+	for _, n := range nodes {
+		if getInstanceBreaker(info.FullMethodName, n).Allow() == nil {
+			allowed = append(allowed, n)
+		}
+	}
+	if len(allowed) == 0 {
+		return nodes
+	}
+	return allowed
+}
+
+// withBreakerDone marks the result of request to the breaker of the picked node, codes.Internal and
+// codes.Unavailable are failures.
+func withBreakerDone(info balancer.PickInfo, n *node, result balancer.PickResult) balancer.PickResult {
+	if !isInstanceBreakerEnabled(info.Ctx) {
+		return result
+	}
+
+	breaker := getInstanceBreaker(info.FullMethodName, n)
+	done := result.Done
+	result.Done = func(di balancer.DoneInfo) {
+		switch status.Code(di.Err) {
+		case codes.Internal, codes.Unavailable:
+			breaker.MarkFailed()
+		default:
+			breaker.MarkSuccess()
+		}
+		if done != nil {
+			done(di)
+		}
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package loadbalance
+
+import (
+	"hash/crc32"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+// number of virtual nodes of each instance on the hash ring
+const replicas = 160
+
+type hashPickerBuilder struct{}
+
+func (*hashPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	return &hashPicker{
+		subset: newSubset(info),
+		rings:  make(map[string]*hashRing),
+	}
+}
+
+// hashPicker consistent hashing on the request key, a request without key is routed randomly
+type hashPicker struct {
+	subset *subset
+
+	mu    sync.Mutex
+	rings map[string]*hashRing // candidates key --> hash ring
+}
+
+func (p *hashPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	nodes, key := p.subset.candidates(info.Ctx)
+
+	hashKey := getHeader(info.Ctx, HeaderHashKey)
+	if hashKey == "" {
+		nodes = allowedNodes(info, nodes)
+		n := nodes[rand.Intn(len(nodes))]
+		return withBreakerDone(info, n, balancer.PickResult{SubConn: n.sc}), nil
+	}
+
+	p.mu.Lock()
+	ring, ok := p.rings[key]
+	if !ok {
+		ring = newHashRing(nodes)
+		p.rings[key] = ring
+	}
+	p.mu.Unlock()
+
+	n := ring.get(hashKey)
+	if isInstanceBreakerEnabled(info.Ctx) && getInstanceBreaker(info.FullMethodName, n).Allow() != nil {
+		// the breaker of the hashed node is open, route to another node temporarily
+		nodes = allowedNodes(info, nodes)
+		n = nodes[rand.Intn(len(nodes))]
+	}
+	return withBreakerDone(info, n, balancer.PickResult{SubConn: n.sc}), nil
+}
+
+type hashRing struct {
+	hashes []uint32
+	nodes  map[uint32]*node
+}
+
+func newHashRing(nodes []*node) *hashRing {
+	r := &hashRing{nodes: make(map[uint32]*node, len(nodes)*replicas)}
+	for _, n := range nodes {
+		for i := 0; i < replicas; i++ {
+			h := crc32.ChecksumIEEE([]byte(n.addr + "#" + strconv.Itoa(i)))
+			if _, ok := r.nodes[h]; ok {
+				continue
+			}
+			r.nodes[h] = n
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+	return r
+}
+
+func (r *hashRing) get(key string) *node {
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.nodes[r.hashes[i]]
+}
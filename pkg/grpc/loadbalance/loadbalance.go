@@ -0,0 +1,164 @@
+// Package loadbalance is grpc client-side load balancing policies based on the metadata of service instances,
+// supports weighted round robin, p2c with ewma latency and consistent hashing, all of them support
+// subset routing by version and zone, e.g. canary release.
+package loadbalance
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/18721889353/sunshine/pkg/servicerd/discovery"
+	"github.com/18721889353/sunshine/pkg/servicerd/registry"
+)
+
+// load balancing policy names
+const (
+	// WeightRoundRobin smooth weighted round robin, weight is read from metadata "weight"
+	WeightRoundRobin = "weight_round_robin"
+	// P2CEWMA power of two choices, the instance with lower ewma latency * inflight requests is picked
+	P2CEWMA = "p2c_ewma"
+	// ConsistentHash consistent hashing on the request key set by WithHashKey
+	ConsistentHash = "consistent_hash"
+)
+
+const (
+	// MetadataKeyWeight key of weight in metadata of service instance, default weight is 10
+	MetadataKeyWeight = "weight"
+	// MetadataKeyZone key of zone in metadata of service instance
+	MetadataKeyZone = "zone"
+
+	// HeaderVersion request header, picks instances whose version matches, e.g. x-version: v2
+	HeaderVersion = "x-version"
+	// HeaderZone request header, picks instances whose zone matches
+	HeaderZone = "x-zone"
+	// HeaderHashKey request header, the key of consistent hashing
+	HeaderHashKey = "x-hash-key"
+
+	defaultWeight = 10
+)
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(WeightRoundRobin, &wrrPickerBuilder{}, base.Config{}))
+	balancer.Register(base.NewBalancerBuilder(P2CEWMA, &p2cPickerBuilder{}, base.Config{}))
+	balancer.Register(base.NewBalancerBuilder(ConsistentHash, &hashPickerBuilder{}, base.Config{}))
+}
+
+// ServiceConfig returns the grpc service config json of the load balancing policy,
+// used for grpc.WithDefaultServiceConfig.
+func ServiceConfig(policy string) string {
+	return fmt.Sprintf(`{"loadBalancingConfig": [{"%s":{}}]}`, policy)
+}
+
+// WithVersion sets the version of instances that the request is routed to, if no instance matches,
+// the request is routed to all instances.
+func WithVersion(ctx context.Context, version string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, HeaderVersion, version)
+}
+
+// WithZone sets the zone of instances that the request is routed to, if no instance matches,
+// the request is routed to all instances.
+func WithZone(ctx context.Context, zone string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, HeaderZone, zone)
+}
+
+// WithHashKey sets the key of consistent hashing, requests with the same key are routed to the same instance.
+func WithHashKey(ctx context.Context, key string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, HeaderHashKey, key)
+}
+
+// --------------------------------------------------------------------------------------
+
+// node is a ready sub connection with the metadata of service instance
+type node struct {
+	sc      balancer.SubConn
+	addr    string
+	version string
+	zone    string
+	weight  int
+
+	currentWeight int // used by weighted round robin
+	stat          *latencyStat
+}
+
+func newNode(sc balancer.SubConn, info base.SubConnInfo) *node {
+	n := &node{
+		sc:     sc,
+		addr:   info.Address.Addr,
+		weight: defaultWeight,
+		stat:   &latencyStat{},
+	}
+
+	var md map[string]string
+	if in, ok := info.Address.Attributes.Value(discovery.AttrKeyServiceInstance).(*registry.ServiceInstance); ok && in != nil {
+		n.version = in.Version
+		md = in.Metadata
+	}
+	if w, err := strconv.Atoi(md[MetadataKeyWeight]); err == nil && w > 0 {
+		n.weight = w
+	}
+	n.zone = md[MetadataKeyZone]
+
+	return n
+}
+
+// subset selects the candidate nodes of the request by version and zone
+type subset struct {
+	all       []*node
+	byVersion map[string][]*node
+}
+
+func newSubset(info base.PickerBuildInfo) *subset {
+	s := &subset{byVersion: make(map[string][]*node)}
+	for sc, scInfo := range info.ReadySCs {
+		n := newNode(sc, scInfo)
+		s.all = append(s.all, n)
+		if n.version != "" {
+			s.byVersion[n.version] = append(s.byVersion[n.version], n)
+		}
+	}
+	return s
+}
+
+// candidates returns the nodes matching the version and zone of request, the key identifies the candidates.
+func (s *subset) candidates(ctx context.Context) (nodes []*node, key string) {
+	nodes = s.all
+	version, zone := getHeader(ctx, HeaderVersion), getHeader(ctx, HeaderZone)
+
+	if version != "" {
+		if ns, ok := s.byVersion[version]; ok {
+			nodes = ns
+			key = version
+		}
+	}
+
+	if zone != "" {
+		var ns []*node
+		for _, n := range nodes {
+			if n.zone == zone {
+				ns = append(ns, n)
+			}
+		}
+		if len(ns) > 0 {
+			nodes = ns
+			key += "|" + zone
+		}
+	}
+
+	return nodes, key
+}
+
+func getHeader(ctx context.Context, key string) string {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[len(values)-1]
+	}
+	return ""
+}
@@ -0,0 +1,227 @@
+package loadbalance
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/18721889353/sunshine/pkg/servicerd/discovery"
+	"github.com/18721889353/sunshine/pkg/servicerd/registry"
+)
+
+// in-memory discovery, returns the instances once
+type memDiscovery struct {
+	instances []*registry.ServiceInstance
+}
+
+func (d *memDiscovery) GetService(ctx context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+	return d.instances, nil
+}
+
+func (d *memDiscovery) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
+	return &memWatcher{ctx: ctx, instances: d.instances}, nil
+}
+
+type memWatcher struct {
+	ctx       context.Context
+	instances []*registry.ServiceInstance
+}
+
+func (w *memWatcher) Next() ([]*registry.ServiceInstance, error) {
+	if w.instances != nil {
+		ins := w.instances
+		w.instances = nil
+		return ins, nil
+	}
+	<-w.ctx.Done()
+	return nil, w.ctx.Err()
+}
+
+func (w *memWatcher) Stop() error {
+	return nil
+}
+
+// start servers, returns the instances, the version and metadata of instance i is versions[i] and mds[i]
+func runServers(t *testing.T, versions []string, mds []map[string]string) []*registry.ServiceInstance {
+	var instances []*registry.ServiceInstance
+	for i, version := range versions {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		server := grpc.NewServer()
+		grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+		go func() { _ = server.Serve(lis) }()
+		t.Cleanup(server.Stop)
+
+		instances = append(instances, registry.NewServiceInstance(
+			lis.Addr().String(),
+			"hello",
+			[]string{"grpc://" + lis.Addr().String()},
+			registry.WithVersion(version),
+			registry.WithMetadata(mds[i]),
+		))
+	}
+	return instances
+}
+
+func dial(t *testing.T, policy string, instances []*registry.ServiceInstance) grpc_health_v1.HealthClient {
+	conn, err := grpc.NewClient("discovery:///hello",
+		grpc.WithResolvers(discovery.NewBuilder(&memDiscovery{instances: instances}, discovery.WithInsecure(true), discovery.DisableDebugLog())),
+		grpc.WithDefaultServiceConfig(ServiceConfig(policy)),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+// call n times, returns the number of requests handled by each address
+func call(t *testing.T, client grpc_health_v1.HealthClient, ctx context.Context, n int) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		p := &peer.Peer{}
+		ctx, cancel := context.WithTimeout(ctx, time.Second*3)
+		_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.Peer(p), grpc.WaitForReady(true))
+		cancel()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[p.Addr.String()]++
+	}
+	return counts
+}
+
+func addrOf(in *registry.ServiceInstance) string {
+	return in.ID
+}
+
+func TestWeightRoundRobin(t *testing.T) {
+	instances := runServers(t,
+		[]string{"v1", "v1", "v2"},
+		[]map[string]string{{"weight": "30"}, {"weight": "10"}, {"weight": "10", "zone": "zone-a"}},
+	)
+	client := dial(t, WeightRoundRobin, instances)
+
+	call(t, client, context.Background(), 10) // wait for all sub connections to be ready
+	time.Sleep(time.Millisecond * 100)
+
+	counts := call(t, client, context.Background(), 50)
+	assert.Equal(t, 30, counts[addrOf(instances[0])])
+	assert.Equal(t, 10, counts[addrOf(instances[1])])
+	assert.Equal(t, 10, counts[addrOf(instances[2])])
+
+	// canary
+	counts = call(t, client, WithVersion(context.Background(), "v2"), 10)
+	assert.Equal(t, 10, counts[addrOf(instances[2])])
+
+	// zone
+	counts = call(t, client, WithZone(context.Background(), "zone-a"), 10)
+	assert.Equal(t, 10, counts[addrOf(instances[2])])
+
+	// no instance matches, fall back to all instances
+	counts = call(t, client, WithVersion(context.Background(), "v3"), 50)
+	assert.Len(t, counts, 3)
+}
+
+func TestP2CEWMA(t *testing.T) {
+	instances := runServers(t, []string{"v1", "v1", "v2"}, make([]map[string]string, 3))
+	client := dial(t, P2CEWMA, instances)
+
+	counts := call(t, client, context.Background(), 100)
+	assert.Equal(t, 100, counts[addrOf(instances[0])]+counts[addrOf(instances[1])]+counts[addrOf(instances[2])])
+
+	counts = call(t, client, WithVersion(context.Background(), "v1"), 20)
+	assert.Equal(t, 0, counts[addrOf(instances[2])])
+}
+
+func TestConsistentHash(t *testing.T) {
+	instances := runServers(t, []string{"v1", "v1", "v1", "v2"}, make([]map[string]string, 4))
+	client := dial(t, ConsistentHash, instances)
+
+	call(t, client, context.Background(), 20) // without key, random
+	time.Sleep(time.Millisecond * 100)
+
+	for _, key := range []string{"user-1", "user-2", "user-3"} {
+		counts := call(t, client, WithHashKey(context.Background(), key), 10)
+		assert.Len(t, counts, 1)
+	}
+
+	counts := call(t, client, WithHashKey(WithVersion(context.Background(), "v2"), "user-1"), 10)
+	assert.Equal(t, 10, counts[addrOf(instances[3])])
+}
+
+func TestHashRing(t *testing.T) {
+	nodes := []*node{{addr: "127.0.0.1:8282"}, {addr: "127.0.0.1:8283"}, {addr: "127.0.0.1:8284"}}
+	r := newHashRing(nodes)
+	assert.Len(t, r.hashes, len(nodes)*replicas)
+
+	// removing a node only moves the keys on it
+	r2 := newHashRing(nodes[:2])
+	for i := 0; i < 100; i++ {
+		key := "key" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+		n := r.get(key)
+		if n != nodes[2] {
+			assert.Equal(t, n, r2.get(key))
+		}
+	}
+}
+
+func TestLatencyStat(t *testing.T) {
+	s := &latencyStat{}
+	assert.Equal(t, float64(0), s.load())
+	s.observe(time.Millisecond * 10)
+	assert.Equal(t, float64(time.Millisecond*10), s.load())
+	s.inflight = 1
+	assert.Equal(t, float64(time.Millisecond*20), s.load())
+	s.observe(time.Millisecond * 20)
+	assert.Greater(t, s.ewma, float64(time.Millisecond*10))
+
+	assert.False(t, s.pickedBefore(time.Now(), time.Second))
+	s.setPick(time.Now().Add(-time.Second * 2))
+	assert.True(t, s.pickedBefore(time.Now(), time.Second))
+
+	assert.Equal(t, `{"loadBalancingConfig": [{"p2c_ewma":{}}]}`, ServiceConfig(P2CEWMA))
+}
+
+func TestInstanceBreaker(t *testing.T) {
+	instances := runServers(t, []string{"v1"}, make([]map[string]string, 1))
+
+	// the instance responds codes.Unavailable to all requests
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+		return status.Error(codes.Unavailable, "unavailable")
+	}))
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+	instances = append(instances, registry.NewServiceInstance(lis.Addr().String(), "hello",
+		[]string{"grpc://" + lis.Addr().String()}, registry.WithVersion("v1")))
+
+	client := dial(t, WeightRoundRobin, instances)
+	failed := 0
+	for i := 0; i < 600; i++ {
+		ctx, cancel := context.WithTimeout(WithInstanceBreaker(context.Background()), time.Second*3)
+		_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.WaitForReady(true))
+		cancel()
+		if err != nil {
+			failed++
+		}
+	}
+	// without breaker, half of the requests fail
+	assert.Less(t, failed, 250)
+}
@@ -0,0 +1,123 @@
+package loadbalance
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+const (
+	// time constant of ewma decay, the weight of old latency halves in about 7s
+	decayTime = 10 * time.Second
+	// a node that has not been picked for a long time is picked once to refresh its latency
+	forcePickTime = 3 * time.Second
+)
+
+// latencyStat ewma latency and inflight requests of a node
+type latencyStat struct {
+	inflight int64
+
+	mu       sync.Mutex
+	ewma     float64 // nanoseconds
+	lastTime time.Time
+	lastPick time.Time
+}
+
+func (s *latencyStat) load() float64 {
+	s.mu.Lock()
+	ewma := s.ewma
+	s.mu.Unlock()
+	// new node without latency, is picked first
+	return ewma * float64(atomic.LoadInt64(&s.inflight)+1)
+}
+
+func (s *latencyStat) observe(latency time.Duration) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastTime.IsZero() {
+		s.ewma = float64(latency)
+	} else {
+		w := math.Exp(-float64(now.Sub(s.lastTime)) / float64(decayTime))
+		s.ewma = s.ewma*w + float64(latency)*(1-w)
+	}
+	s.lastTime = now
+}
+
+func (s *latencyStat) pickedBefore(now time.Time, d time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.lastPick.IsZero() && now.Sub(s.lastPick) > d
+}
+
+func (s *latencyStat) setPick(now time.Time) {
+	s.mu.Lock()
+	s.lastPick = now
+	s.mu.Unlock()
+}
+
+// --------------------------------------------------------------------------------------
+
+type p2cPickerBuilder struct{}
+
+func (*p2cPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	return &p2cPicker{
+		subset: newSubset(info),
+		r:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// p2cPicker picks two nodes randomly, and choose the one with lower load (ewma latency * inflight requests)
+type p2cPicker struct {
+	subset *subset
+
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (p *p2cPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	nodes, _ := p.subset.candidates(info.Ctx)
+	nodes = allowedNodes(info, nodes)
+
+	var chosen *node
+	if len(nodes) == 1 {
+		chosen = nodes[0]
+	} else {
+		p.mu.Lock()
+		a := p.r.Intn(len(nodes))
+		b := p.r.Intn(len(nodes) - 1)
+		p.mu.Unlock()
+		if b >= a {
+			b++
+		}
+		n1, n2 := nodes[a], nodes[b]
+		if n1.stat.load() > n2.stat.load() {
+			n1, n2 = n2, n1
+		}
+		chosen = n1
+		if n2.stat.pickedBefore(time.Now(), forcePickTime) {
+			chosen = n2
+		}
+	}
+
+	start := time.Now()
+	stat := chosen.stat
+	stat.setPick(start)
+	atomic.AddInt64(&stat.inflight, 1)
+
+	return withBreakerDone(info, chosen, balancer.PickResult{
+		SubConn: chosen.sc,
+		Done: func(balancer.DoneInfo) {
+			atomic.AddInt64(&stat.inflight, -1)
+			stat.observe(time.Since(start))
+		},
+	}), nil
+}
@@ -0,0 +1,43 @@
+package loadbalance
+
+import (
+	"sync"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+type wrrPickerBuilder struct{}
+
+func (*wrrPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	return &wrrPicker{subset: newSubset(info)}
+}
+
+// wrrPicker smooth weighted round robin, the same algorithm as nginx
+type wrrPicker struct {
+	subset *subset
+	mu     sync.Mutex
+}
+
+func (p *wrrPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	nodes, _ := p.subset.candidates(info.Ctx)
+	nodes = allowedNodes(info, nodes)
+
+	p.mu.Lock()
+	var best *node
+	total := 0
+	for _, n := range nodes {
+		n.currentWeight += n.weight
+		total += n.weight
+		if best == nil || n.currentWeight > best.currentWeight {
+			best = n
+		}
+	}
+	best.currentWeight -= total
+	p.mu.Unlock()
+
+	return withBreakerDone(info, best, balancer.PickResult{SubConn: best.sc}), nil
+}
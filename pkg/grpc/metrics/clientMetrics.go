@@ -5,9 +5,9 @@ import (
 	"sync"
 
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
-	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
+
+	pkgMetrics "github.com/18721889353/sunshine/pkg/metrics"
 )
 
 // https://github.com/grpc-ecosystem/go-grpc-prometheus/tree/master/examples/grpc-server-with-prometheus
@@ -16,9 +16,6 @@ var (
 	// client side default router
 	clientPattern = "/rpc_client/metrics"
 
-	// create a Registry
-	cliReg = prometheus.NewRegistry()
-
 	// initialize the client's default metrics
 	grpcClientMetrics = grpc_prometheus.NewClientMetrics()
 
@@ -27,8 +24,8 @@ var (
 
 func cliRegisterMetrics() {
 	cliOnce.Do(func() {
-		// register metrics, including custom metrics
-		cliReg.MustRegister(grpcClientMetrics)
+		// register metrics to the shared registry
+		pkgMetrics.MustRegister(grpcClientMetrics)
 	})
 }
 
@@ -42,14 +39,14 @@ func SetClientPattern(pattern string) {
 // ClientRegister for http routing and grpc methods
 func ClientRegister(mux *http.ServeMux) {
 	// register for http routing
-	mux.Handle(clientPattern, promhttp.HandlerFor(cliReg, promhttp.HandlerOpts{}))
+	mux.Handle(clientPattern, pkgMetrics.Handler())
 }
 
 // ClientHTTPService initialize the client's prometheus exporter service and use http://ip:port/metrics to fetch data
 func ClientHTTPService(addr string) *http.Server {
 	httpServer := &http.Server{
 		Addr:    addr,
-		Handler: promhttp.HandlerFor(cliReg, promhttp.HandlerOpts{}),
+		Handler: pkgMetrics.Handler(),
 	}
 
 	// run http server
@@ -7,9 +7,9 @@ import (
 
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/collectors"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
+
+	pkgMetrics "github.com/18721889353/sunshine/pkg/metrics"
 )
 
 // https://github.com/grpc-ecosystem/go-grpc-prometheus/tree/master/examples/grpc-server-with-prometheus
@@ -18,15 +18,9 @@ var (
 	// server side default router
 	serverPattern = "/metrics"
 
-	// create a Registry
-	srvReg = prometheus.NewRegistry()
-
 	// initialize server-side default metrics
 	grpcServerMetrics = grpc_prometheus.NewServerMetrics()
 
-	// go metrics
-	goMetrics = collectors.NewGoCollector()
-
 	// user-defined metrics https://prometheus.io/docs/concepts/metric_types/#histogram
 	customizedCounterMetrics   = []*prometheus.CounterVec{}
 	customizedSummaryMetrics   = []*prometheus.SummaryVec{}
@@ -84,24 +78,22 @@ func srvRegisterMetrics() {
 		// enable time record
 		grpcServerMetrics.EnableHandlingTimeHistogram()
 
-		// register go metrics
-		srvReg.MustRegister(goMetrics)
-
-		// register metrics to capture, custom metrics also need to be registered
-		srvReg.MustRegister(grpcServerMetrics)
+		// register metrics to the shared registry, custom metrics also need to be registered,
+		// the go metrics are served by the shared handler
+		pkgMetrics.MustRegister(grpcServerMetrics)
 
 		// register custom Counter metrics
 		for _, metric := range customizedCounterMetrics {
-			srvReg.MustRegister(metric)
+			pkgMetrics.MustRegister(metric)
 		}
 		for _, metric := range customizedSummaryMetrics {
-			srvReg.MustRegister(metric)
+			pkgMetrics.MustRegister(metric)
 		}
 		for _, metric := range customizedGaugeMetrics {
-			srvReg.MustRegister(metric)
+			pkgMetrics.MustRegister(metric)
 		}
 		for _, metric := range customizedHistogramMetrics {
-			srvReg.MustRegister(metric)
+			pkgMetrics.MustRegister(metric)
 		}
 	})
 }
@@ -116,7 +108,7 @@ func SetServerPattern(pattern string) {
 // Register for http routing and grpc methods
 func Register(mux *http.ServeMux, grpcServer *grpc.Server) {
 	// register for http routing
-	mux.Handle(serverPattern, promhttp.HandlerFor(srvReg, promhttp.HandlerOpts{}))
+	mux.Handle(serverPattern, pkgMetrics.Handler())
 
 	// register all gRPC methods to metrics
 	grpcServerMetrics.InitializeMetrics(grpcServer)
@@ -126,7 +118,7 @@ func Register(mux *http.ServeMux, grpcServer *grpc.Server) {
 func ServerHTTPService(addr string, grpcServer *grpc.Server) *http.Server {
 	httpServer := &http.Server{
 		Addr:    addr,
-		Handler: promhttp.HandlerFor(srvReg, promhttp.HandlerOpts{}),
+		Handler: pkgMetrics.Handler(),
 	}
 
 	// run http server
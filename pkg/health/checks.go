@@ -0,0 +1,51 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DBCheck returns a check that pings the database.
+func DBCheck(db *sql.DB) CheckFn {
+	return func(ctx context.Context) error {
+		if db == nil {
+			return errors.New("db is nil")
+		}
+		return db.PingContext(ctx)
+	}
+}
+
+// RedisCheck returns a check that pings redis.
+func RedisCheck(client redis.UniversalClient) CheckFn {
+	return func(ctx context.Context) error {
+		if client == nil {
+			return errors.New("redis client is nil")
+		}
+		return client.Ping(ctx).Err()
+	}
+}
+
+// TCPCheck returns a check that dials the addresses, it is healthy if any address is reachable,
+// used for message brokers such as kafka and rabbitmq.
+func TCPCheck(addrs ...string) CheckFn {
+	return func(ctx context.Context) error {
+		if len(addrs) == 0 {
+			return errors.New("addrs is empty")
+		}
+		var err error
+		var d net.Dialer
+		for _, addr := range addrs {
+			var conn net.Conn
+			conn, err = d.DialContext(ctx, "tcp", addr)
+			if err == nil {
+				_ = conn.Close()
+				return nil
+			}
+		}
+		return err
+	}
+}
@@ -0,0 +1,18 @@
+package health
+
+var defaultChecker = New()
+
+// Default returns the default checker.
+func Default() *Checker {
+	return defaultChecker
+}
+
+// AddLiveness add a liveness check to the default checker.
+func AddLiveness(name string, fn CheckFn) {
+	defaultChecker.AddLiveness(name, fn)
+}
+
+// AddReadiness add a readiness check to the default checker.
+func AddReadiness(name string, fn CheckFn) {
+	defaultChecker.AddReadiness(name, fn)
+}
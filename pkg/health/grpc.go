@@ -0,0 +1,31 @@
+package health
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// RegisterGRPC register the standard grpc health service (grpc.health.v1.Health) to server, the serving status
+// of the whole server ("") and the specified services follows the readiness, the background checks are started.
+func (c *Checker) RegisterGRPC(server grpc.ServiceRegistrar, services ...string) *health.Server {
+	hs := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, hs)
+
+	services = append([]string{""}, services...)
+	setStatus := func(ready bool) {
+		status := grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		if ready {
+			status = grpc_health_v1.HealthCheckResponse_SERVING
+		}
+		for _, service := range services {
+			hs.SetServingStatus(service, status)
+		}
+	}
+
+	c.OnChange(setStatus)
+	c.Start()
+	setStatus(c.IsReady())
+
+	return hs
+}
@@ -0,0 +1,288 @@
+// Package health is the health subsystem of service, components (database, redis, message broker, etc.)
+// contribute liveness and readiness checks, the results are exposed by http /health/live, /health/ready
+// and the standard grpc health service, and used to keep the registration of service instance consistent with its health.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/18721889353/sunshine/pkg/utils"
+)
+
+// status of check
+const (
+	StatusUp   = "UP"
+	StatusDown = "DOWN"
+)
+
+// CheckFn is a health check, returns nil if healthy.
+type CheckFn func(ctx context.Context) error
+
+// Result of a check
+type Result struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report of all checks
+type Report struct {
+	Status   string             `json:"status"`
+	Hostname string             `json:"hostname"`
+	Checks   map[string]*Result `json:"checks,omitempty"`
+}
+
+// IsUp returns true if the status is UP.
+func (r *Report) IsUp() bool {
+	return r.Status == StatusUp
+}
+
+// Option set the checker options.
+type Option func(*options)
+
+type options struct {
+	timeout  time.Duration
+	interval time.Duration
+}
+
+func defaultOptions() *options {
+	return &options{
+		timeout:  3 * time.Second,
+		interval: 10 * time.Second,
+	}
+}
+
+func (o *options) apply(opts ...Option) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithTimeout set the timeout of each check, default 3s.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.timeout = d
+		}
+	}
+}
+
+// WithInterval set the interval of background readiness checks, default 10s.
+func WithInterval(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.interval = d
+		}
+	}
+}
+
+// --------------------------------------------------------------------------------------
+
+// Checker holds the liveness and readiness checks.
+// Liveness checks tell whether the process should be restarted, readiness checks tell whether
+// the service can serve requests, e.g. the database and redis are reachable.
+type Checker struct {
+	opts *options
+
+	mu        sync.RWMutex
+	liveness  map[string]CheckFn
+	readiness map[string]CheckFn
+	listeners []func(ready bool)
+	report    *Report // last readiness report, nil if not checked yet
+	checkedAt time.Time
+	started   bool // the background checks are running
+	shutdown  bool
+
+	startOnce sync.Once
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// New create a checker.
+func New(opts ...Option) *Checker {
+	o := defaultOptions()
+	o.apply(opts...)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Checker{
+		opts:      o,
+		liveness:  make(map[string]CheckFn),
+		readiness: make(map[string]CheckFn),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// AddLiveness add a liveness check, the check with the same name is replaced.
+func (c *Checker) AddLiveness(name string, fn CheckFn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.liveness[name] = fn
+}
+
+// AddReadiness add a readiness check, the check with the same name is replaced.
+func (c *Checker) AddReadiness(name string, fn CheckFn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readiness[name] = fn
+}
+
+// OnChange add a function called when the readiness changes.
+func (c *Checker) OnChange(fn func(ready bool)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+// CheckLiveness run liveness checks.
+func (c *Checker) CheckLiveness(ctx context.Context) *Report {
+	c.mu.RLock()
+	checks := copyChecks(c.liveness)
+	c.mu.RUnlock()
+	return c.run(ctx, checks)
+}
+
+// CheckReadiness run readiness checks, the readiness is updated and the listeners are notified if it changes.
+func (c *Checker) CheckReadiness(ctx context.Context) *Report {
+	c.mu.RLock()
+	checks := copyChecks(c.readiness)
+	c.mu.RUnlock()
+	report := c.run(ctx, checks)
+
+	c.mu.Lock()
+	if c.shutdown {
+		report.Status = StatusDown
+	}
+	changed := c.report == nil || c.report.Status != report.Status
+	c.report = report
+	c.checkedAt = time.Now()
+	listeners := append([]func(bool){}, c.listeners...)
+	c.mu.Unlock()
+
+	if changed {
+		for _, fn := range listeners {
+			fn(report.IsUp())
+		}
+	}
+	return report
+}
+
+// Readiness returns the last readiness report, the checks are run if the readiness is not checked yet,
+// or the background checks are not started and the last report is older than the interval.
+func (c *Checker) Readiness(ctx context.Context) *Report {
+	c.mu.RLock()
+	report := c.report
+	expired := !c.started && time.Since(c.checkedAt) >= c.opts.interval
+	c.mu.RUnlock()
+	if report == nil || expired {
+		return c.CheckReadiness(ctx)
+	}
+	return report
+}
+
+// IsReady returns true if the service is ready.
+func (c *Checker) IsReady() bool {
+	return c.Readiness(context.Background()).IsUp()
+}
+
+// Start run readiness checks in background periodically, it can be called multiple times.
+func (c *Checker) Start() {
+	c.startOnce.Do(func() {
+		c.mu.Lock()
+		c.started = true
+		c.mu.Unlock()
+		c.CheckReadiness(c.ctx)
+		go func() {
+			ticker := time.NewTicker(c.opts.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-c.ctx.Done():
+					return
+				case <-ticker.C:
+					c.CheckReadiness(c.ctx)
+				}
+			}
+		}()
+	})
+}
+
+// Shutdown mark the service not ready permanently, called before the service stops,
+// so that the traffic is drained before the server is closed.
+func (c *Checker) Shutdown() {
+	c.mu.Lock()
+	c.shutdown = true
+	c.mu.Unlock()
+	c.CheckReadiness(context.Background())
+}
+
+// Stop the background checks.
+func (c *Checker) Stop() {
+	c.cancel()
+	c.mu.Lock()
+	c.started = false
+	c.mu.Unlock()
+}
+
+func (c *Checker) run(ctx context.Context, checks map[string]CheckFn) *Report {
+	report := &Report{
+		Status:   StatusUp,
+		Hostname: utils.GetHostname(),
+		Checks:   make(map[string]*Result, len(checks)),
+	}
+
+	type item struct {
+		name   string
+		result *Result
+	}
+	ch := make(chan item, len(checks))
+	for name, fn := range checks {
+		go func(name string, fn CheckFn) {
+			ctx, cancel := context.WithTimeout(ctx, c.opts.timeout)
+			defer cancel()
+			result := &Result{Status: StatusUp}
+			if err := safeCheck(ctx, fn); err != nil {
+				result.Status = StatusDown
+				result.Error = err.Error()
+			}
+			ch <- item{name: name, result: result}
+		}(name, fn)
+	}
+	for range checks {
+		it := <-ch
+		report.Checks[it.name] = it.result
+		if it.result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	return report
+}
+
+// safeCheck the check is abandoned if it does not return before ctx is done
+func safeCheck(ctx context.Context, fn CheckFn) error {
+	errCh := make(chan error, 1)
+	go func() {
+		defer func() {
+			if e := recover(); e != nil {
+				errCh <- fmt.Errorf("check panic: %v", e)
+			}
+		}()
+		errCh <- fn(ctx)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func copyChecks(m map[string]CheckFn) map[string]CheckFn {
+	checks := make(map[string]CheckFn, len(m))
+	for k, v := range m {
+		checks[k] = v
+	}
+	return checks
+}
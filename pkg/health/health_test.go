@@ -0,0 +1,195 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestChecker(t *testing.T) {
+	c := New(WithTimeout(time.Millisecond*100), WithInterval(time.Millisecond*50))
+	defer c.Stop()
+
+	var fail atomic.Bool
+	c.AddLiveness("process", func(ctx context.Context) error { return nil })
+	c.AddReadiness("db", func(ctx context.Context) error {
+		if fail.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	c.AddReadiness("slow", func(ctx context.Context) error {
+		time.Sleep(time.Millisecond * 10)
+		return nil
+	})
+
+	var changes atomic.Int32
+	c.OnChange(func(ready bool) { changes.Add(1) })
+
+	assert.True(t, c.CheckLiveness(context.Background()).IsUp())
+	report := c.CheckReadiness(context.Background())
+	assert.True(t, report.IsUp())
+	assert.Len(t, report.Checks, 2)
+	assert.Equal(t, int32(1), changes.Load())
+
+	c.Start()
+	c.Start()
+	fail.Store(true)
+	time.Sleep(time.Millisecond * 200)
+	assert.False(t, c.IsReady())
+	assert.Equal(t, StatusDown, c.Readiness(context.Background()).Checks["db"].Status)
+	assert.Equal(t, int32(2), changes.Load())
+
+	fail.Store(false)
+	time.Sleep(time.Millisecond * 200)
+	assert.True(t, c.IsReady())
+
+	c.Shutdown()
+	assert.False(t, c.IsReady())
+}
+
+func TestChecker_timeoutAndPanic(t *testing.T) {
+	c := New(WithTimeout(time.Millisecond * 50))
+	c.AddReadiness("timeout", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	c.AddReadiness("panic", func(ctx context.Context) error {
+		panic("boom")
+	})
+
+	report := c.CheckReadiness(context.Background())
+	assert.False(t, report.IsUp())
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["timeout"].Error)
+	assert.Contains(t, report.Checks["panic"].Error, "boom")
+}
+
+func TestHandler(t *testing.T) {
+	c := New()
+	ready := true
+	c.AddReadiness("redis", func(ctx context.Context) error {
+		if !ready {
+			return errors.New("redis down")
+		}
+		return nil
+	})
+	mux := http.NewServeMux()
+	c.Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	get := func(path string) (int, *Report) {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		report := &Report{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(report))
+		return resp.StatusCode, report
+	}
+
+	code, report := get("/health/live")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusUp, report.Status)
+
+	code, report = get("/health/ready")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusUp, report.Checks["redis"].Status)
+
+	ready = false
+	c.CheckReadiness(context.Background())
+	code, report = get("/health/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "redis down", report.Checks["redis"].Error)
+}
+
+// the background checks are not started, e.g. http service without registry
+func TestHandler_recover(t *testing.T) {
+	c := New(WithInterval(time.Millisecond * 300))
+	var fail atomic.Bool
+	fail.Store(true)
+	c.AddReadiness("db", func(ctx context.Context) error {
+		if fail.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	server := httptest.NewServer(c.ReadyHandler())
+	defer server.Close()
+
+	getCode := func() int {
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, getCode())
+	fail.Store(false)
+	assert.Equal(t, http.StatusServiceUnavailable, getCode()) // within the interval, the last report is returned
+	time.Sleep(time.Millisecond * 300)
+	assert.Equal(t, http.StatusOK, getCode())
+}
+
+func TestRegisterGRPC(t *testing.T) {
+	c := New()
+	defer c.Stop()
+	var fail atomic.Bool
+	c.AddReadiness("db", func(ctx context.Context) error {
+		if fail.Load() {
+			return errors.New("db down")
+		}
+		return nil
+	})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	c.RegisterGRPC(server, "api.user.v1.User")
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	check := func(service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+		defer cancel()
+		resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		return resp.Status
+	}
+
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, check(""))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, check("api.user.v1.User"))
+
+	fail.Store(true)
+	c.CheckReadiness(context.Background())
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, check(""))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, check("api.user.v1.User"))
+}
+
+func TestChecks(t *testing.T) {
+	ctx := context.Background()
+	assert.Error(t, DBCheck(nil)(ctx))
+	assert.Error(t, RedisCheck(nil)(ctx))
+	assert.Error(t, TCPCheck()(ctx))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+	assert.NoError(t, TCPCheck("127.0.0.1:1", lis.Addr().String())(ctx))
+}
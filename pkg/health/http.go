@@ -0,0 +1,38 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// LiveHandler returns the http handler of liveness, status code is 200 if up, otherwise 503.
+func (c *Checker) LiveHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, c.CheckLiveness(r.Context()))
+	}
+}
+
+// ReadyHandler returns the http handler of readiness, status code is 200 if up, otherwise 503.
+// If the background checks are started, the last report is returned, otherwise the checks are run
+// when the last report is older than the interval.
+func (c *Checker) ReadyHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, c.Readiness(r.Context()))
+	}
+}
+
+// Register the routes /health/live and /health/ready to mux.
+func (c *Checker) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/health/live", c.LiveHandler())
+	mux.HandleFunc("/health/ready", c.ReadyHandler())
+}
+
+func writeReport(w http.ResponseWriter, report *Report) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if report.IsUp() {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
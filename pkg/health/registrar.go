@@ -0,0 +1,188 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/18721889353/sunshine/pkg/logger"
+	"github.com/18721889353/sunshine/pkg/servicerd/registry"
+)
+
+// RegistrarOption set the registrar options.
+type RegistrarOption func(*registrarOptions)
+
+type registrarOptions struct {
+	refreshInterval time.Duration
+	timeout         time.Duration
+	statusMetadata  bool
+}
+
+func defaultRegistrarOptions() *registrarOptions {
+	return &registrarOptions{
+		refreshInterval: 15 * time.Second,
+		timeout:         5 * time.Second,
+	}
+}
+
+func (o *registrarOptions) apply(opts ...RegistrarOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithRefreshInterval set the interval of re-registering while the service is ready, default 15s.
+func WithRefreshInterval(d time.Duration) RegistrarOption {
+	return func(o *registrarOptions) {
+		if d > 0 {
+			o.refreshInterval = d
+		}
+	}
+}
+
+// WithRegisterTimeout set the timeout of register and deregister, default 5s.
+func WithRegisterTimeout(d time.Duration) RegistrarOption {
+	return func(o *registrarOptions) {
+		if d > 0 {
+			o.timeout = d
+		}
+	}
+}
+
+// WithStatusMetadata when the service is not ready, the instance is kept registered with metadata
+// status=unhealthy instead of being deregistered, discovery skips unhealthy instances.
+func WithStatusMetadata() RegistrarOption {
+	return func(o *registrarOptions) {
+		o.statusMetadata = true
+	}
+}
+
+// Registrar keeps the service instance registered while the service is ready, deregisters it (or flips its status
+// to unhealthy) when readiness fails, and registers it again on recovery.
+type Registrar struct {
+	opts     *registrarOptions
+	checker  *Checker
+	registry registry.Registry
+	instance *registry.ServiceInstance
+
+	changed chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewRegistrar create a registrar.
+func NewRegistrar(checker *Checker, iRegistry registry.Registry, instance *registry.ServiceInstance, opts ...RegistrarOption) *Registrar {
+	o := defaultRegistrarOptions()
+	o.apply(opts...)
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Registrar{
+		opts:     o,
+		checker:  checker,
+		registry: iRegistry,
+		instance: instance,
+		changed:  make(chan struct{}, 1),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	checker.OnChange(func(bool) {
+		select {
+		case r.changed <- struct{}{}:
+		default:
+		}
+	})
+	return r
+}
+
+// Start the background checks, register the instance if the service is ready, then keep the
+// registration consistent with the readiness.
+func (r *Registrar) Start() error {
+	r.checker.Start()
+	ready := r.checker.IsReady()
+	if ready || r.opts.statusMetadata {
+		if err := r.register(ready); err != nil {
+			return err
+		}
+	} else {
+		logger.Warn("service is not ready, wait for registering", logger.String("id", r.instance.ID))
+	}
+
+	r.wg.Add(1)
+	go r.loop(ready)
+	return nil
+}
+
+// Stop deregister the instance.
+func (r *Registrar) Stop() error {
+	r.cancel()
+	r.wg.Wait()
+	ctx, cancel := context.WithTimeout(context.Background(), r.opts.timeout)
+	defer cancel()
+	return r.registry.Deregister(ctx, r.instance)
+}
+
+func (r *Registrar) loop(lastReady bool) {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.opts.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-r.changed:
+		case <-ticker.C:
+		}
+
+		ready := r.checker.IsReady()
+		var err error
+		switch {
+		case ready || r.opts.statusMetadata:
+			err = r.register(ready)
+		case lastReady:
+			err = r.deregister()
+		default:
+			continue
+		}
+		if err != nil {
+			logger.Warn("update registration error", logger.Err(err), logger.String("id", r.instance.ID), logger.Bool("ready", ready))
+			continue
+		}
+		if ready != lastReady {
+			logger.Info("registration changed by readiness", logger.String("id", r.instance.ID), logger.Bool("ready", ready))
+		}
+		lastReady = ready
+	}
+}
+
+func (r *Registrar) register(ready bool) error {
+	instance := r.instance
+	if r.opts.statusMetadata {
+		instance = withStatus(r.instance, ready)
+	}
+	ctx, cancel := context.WithTimeout(r.ctx, r.opts.timeout)
+	defer cancel()
+	return r.registry.Register(ctx, instance)
+}
+
+func (r *Registrar) deregister() error {
+	ctx, cancel := context.WithTimeout(r.ctx, r.opts.timeout)
+	defer cancel()
+	return r.registry.Deregister(ctx, r.instance)
+}
+
+// withStatus returns a copy of instance with the status in metadata
+func withStatus(in *registry.ServiceInstance, ready bool) *registry.ServiceInstance {
+	md := make(map[string]string, len(in.Metadata)+1)
+	for k, v := range in.Metadata {
+		md[k] = v
+	}
+	if ready {
+		delete(md, registry.MetadataKeyStatus)
+	} else {
+		md[registry.MetadataKeyStatus] = registry.StatusUnhealthy
+	}
+	instance := *in
+	instance.Metadata = md
+	return &instance
+}
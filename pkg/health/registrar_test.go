@@ -0,0 +1,96 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/18721889353/sunshine/pkg/servicerd/registry"
+)
+
+// records the registered instance, nil if deregistered
+type fakeRegistry struct {
+	mu       sync.Mutex
+	instance *registry.ServiceInstance
+}
+
+func (r *fakeRegistry) Register(ctx context.Context, instance *registry.ServiceInstance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.instance = instance
+	return nil
+}
+
+func (r *fakeRegistry) Deregister(ctx context.Context, instance *registry.ServiceInstance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.instance = nil
+	return nil
+}
+
+func (r *fakeRegistry) get() *registry.ServiceInstance {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.instance
+}
+
+func newTestChecker(fail *atomic.Bool) *Checker {
+	c := New(WithInterval(time.Millisecond * 20))
+	c.AddReadiness("db", func(ctx context.Context) error {
+		if fail.Load() {
+			return errors.New("db down")
+		}
+		return nil
+	})
+	return c
+}
+
+func TestRegistrar(t *testing.T) {
+	var fail atomic.Bool
+	c := newTestChecker(&fail)
+	defer c.Stop()
+	iRegistry := &fakeRegistry{}
+	instance := registry.NewServiceInstance("user_1", "user", []string{"grpc://127.0.0.1:8282"})
+
+	r := NewRegistrar(c, iRegistry, instance, WithRefreshInterval(time.Millisecond*50))
+	assert.NoError(t, r.Start())
+	assert.Equal(t, instance, iRegistry.get())
+
+	fail.Store(true)
+	assert.Eventually(t, func() bool { return iRegistry.get() == nil }, time.Second, time.Millisecond*10)
+
+	fail.Store(false)
+	assert.Eventually(t, func() bool { return iRegistry.get() != nil }, time.Second, time.Millisecond*10)
+
+	assert.NoError(t, r.Stop())
+	assert.Nil(t, iRegistry.get())
+}
+
+func TestRegistrar_statusMetadata(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	c := newTestChecker(&fail)
+	defer c.Stop()
+	iRegistry := &fakeRegistry{}
+	instance := registry.NewServiceInstance("user_1", "user", []string{"grpc://127.0.0.1:8282"},
+		registry.WithMetadata(map[string]string{"weight": "10"}))
+
+	r := NewRegistrar(c, iRegistry, instance, WithStatusMetadata(), WithRegisterTimeout(time.Second))
+	assert.NoError(t, r.Start())
+	assert.Equal(t, registry.StatusUnhealthy, iRegistry.get().Metadata[registry.MetadataKeyStatus])
+	assert.Equal(t, "10", iRegistry.get().Metadata["weight"])
+	assert.Empty(t, instance.Metadata[registry.MetadataKeyStatus])
+
+	fail.Store(false)
+	assert.Eventually(t, func() bool {
+		in := iRegistry.get()
+		return in != nil && in.Metadata[registry.MetadataKeyStatus] == ""
+	}, time.Second, time.Millisecond*10)
+
+	assert.NoError(t, r.Stop())
+}
@@ -0,0 +1,313 @@
+package httpcli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/18721889353/sunshine/pkg/container/group"
+	"github.com/18721889353/sunshine/pkg/shield/circuitbreaker"
+)
+
+// ErrNotAllowed error not allowed for the circuit breaker of host is open.
+var ErrNotAllowed = circuitbreaker.ErrNotAllowed
+
+// the shared client of Request and the request functions Get, Post, etc.
+var defaultClient = NewClient()
+
+// Client is a reusable http client, it is safe for concurrent use, the connections are pooled by the
+// shared transport, the requests go through the middleware chain, the idempotent requests are retried
+// with backoff, and the circuit breaker is per host if enabled.
+type Client struct {
+	opts      *clientOptions
+	client    *http.Client
+	transport http.RoundTripper // transport wrapped by middlewares
+	breakers  *group.Group
+}
+
+// ClientOption set the client options.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	timeout     time.Duration
+	transport   http.RoundTripper
+	middlewares []Middleware
+
+	retryTimes       int
+	retryMinInterval time.Duration
+	retryMaxInterval time.Duration
+	retryCodes       map[int]struct{}
+
+	enableBreaker bool
+	breakerOpts   []circuitbreaker.Option
+}
+
+func defaultClientOptions() *clientOptions {
+	return &clientOptions{
+		timeout:          defaultTimeout,
+		retryMinInterval: time.Millisecond * 100,
+		retryMaxInterval: time.Second * 2,
+		retryCodes: map[int]struct{}{
+			http.StatusBadGateway:         {},
+			http.StatusServiceUnavailable: {},
+			http.StatusGatewayTimeout:     {},
+		},
+	}
+}
+
+func (o *clientOptions) apply(opts ...ClientOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithClientTimeout set the timeout of each request attempt, including reading the response body, default 30s.
+func WithClientTimeout(t time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		if t > 0 {
+			o.timeout = t
+		}
+	}
+}
+
+// WithTransport set the transport, default is NewTransport().
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(o *clientOptions) {
+		if transport != nil {
+			o.transport = transport
+		}
+	}
+}
+
+// WithMiddlewares add middlewares, the first middleware is the outermost.
+func WithMiddlewares(middlewares ...Middleware) ClientOption {
+	return func(o *clientOptions) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
+// WithRetryTimes set number of retries of idempotent requests (GET, HEAD, OPTIONS, PUT, DELETE), max 10, default 0.
+func WithRetryTimes(n int) ClientOption {
+	return func(o *clientOptions) {
+		if n > 10 {
+			n = 10
+		}
+		o.retryTimes = n
+	}
+}
+
+// WithRetryBackoff set the exponential backoff interval of retries, the interval starts at minInterval
+// and is doubled after each retry up to maxInterval, default 100ms and 2s.
+func WithRetryBackoff(minInterval time.Duration, maxInterval time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		if minInterval > 0 {
+			o.retryMinInterval = minInterval
+		}
+		if maxInterval >= o.retryMinInterval {
+			o.retryMaxInterval = maxInterval
+		}
+	}
+}
+
+// WithRetryStatusCodes set the http status codes that trigger a retry, default 502, 503 and 504,
+// the network errors always trigger a retry.
+func WithRetryStatusCodes(codes ...int) ClientOption {
+	return func(o *clientOptions) {
+		o.retryCodes = make(map[int]struct{}, len(codes))
+		for _, code := range codes {
+			o.retryCodes[code] = struct{}{}
+		}
+	}
+}
+
+// WithCircuitBreaker enable the circuit breaker per host, the network errors and 5xx responses are marked as failed.
+func WithCircuitBreaker(opts ...circuitbreaker.Option) ClientOption {
+	return func(o *clientOptions) {
+		o.enableBreaker = true
+		o.breakerOpts = opts
+	}
+}
+
+// NewTransport creates a transport tuned for calling services, the idle connections are reused between requests.
+func NewTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          200,
+		MaxIdleConnsPerHost:   50,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// NewClient creates a reusable http client.
+func NewClient(opts ...ClientOption) *Client {
+	o := defaultClientOptions()
+	o.apply(opts...)
+	if o.transport == nil {
+		o.transport = NewTransport()
+	}
+
+	transport := o.transport
+	for i := len(o.middlewares) - 1; i >= 0; i-- {
+		transport = o.middlewares[i](transport)
+	}
+
+	c := &Client{
+		opts:      o,
+		client:    &http.Client{Transport: transport, Timeout: o.timeout},
+		transport: transport,
+	}
+	if o.enableBreaker {
+		c.breakers = group.NewGroup(func() interface{} {
+			return circuitbreaker.NewBreaker(o.breakerOpts...)
+		})
+	}
+	return c
+}
+
+// NewRequest creates a Request sent by the client.
+func (c *Client) NewRequest() *Request {
+	return &Request{client: c}
+}
+
+// Do sends the request, the request is canceled when the context of request is done.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	return c.do(req, 0, false)
+}
+
+// Get request with context, return custom json format
+func (c *Client) Get(ctx context.Context, result interface{}, urlStr string, opts ...Option) error {
+	return Get(result, urlStr, append(opts, WithContext(ctx), withClient(c))...)
+}
+
+// Delete request with context, return custom json format
+func (c *Client) Delete(ctx context.Context, result interface{}, urlStr string, opts ...Option) error {
+	return Delete(result, urlStr, append(opts, WithContext(ctx), withClient(c))...)
+}
+
+// Post request with context, return custom json format
+func (c *Client) Post(ctx context.Context, result interface{}, urlStr string, body interface{}, opts ...Option) error {
+	return Post(result, urlStr, body, append(opts, WithContext(ctx), withClient(c))...)
+}
+
+// Put request with context, return custom json format
+func (c *Client) Put(ctx context.Context, result interface{}, urlStr string, body interface{}, opts ...Option) error {
+	return Put(result, urlStr, body, append(opts, WithContext(ctx), withClient(c))...)
+}
+
+// Patch request with context, return custom json format
+func (c *Client) Patch(ctx context.Context, result interface{}, urlStr string, body interface{}, opts ...Option) error {
+	return Patch(result, urlStr, body, append(opts, WithContext(ctx), withClient(c))...)
+}
+
+// send the request through circuit breaker and retry, timeout overrides the timeout of client if it is greater than 0,
+// enableTrace records the request in a span.
+func (c *Client) do(req *http.Request, timeout time.Duration, enableTrace bool) (*http.Response, error) {
+	client := c.client
+	if (timeout > 0 && timeout != client.Timeout) || enableTrace {
+		client = &http.Client{Transport: c.transport, Timeout: client.Timeout}
+		if timeout > 0 {
+			client.Timeout = timeout
+		}
+		if enableTrace {
+			client.Transport = otelhttp.NewTransport(c.transport)
+		}
+	}
+
+	if c.breakers == nil {
+		return c.doWithRetry(client, req)
+	}
+
+	breaker := c.breakers.Get(req.URL.Host).(circuitbreaker.CircuitBreaker)
+	if err := breaker.Allow(); err != nil {
+		// NOTE: when client reject request locally, keep adding counter let the drop ratio higher.
+		breaker.MarkFailed()
+		return nil, err
+	}
+
+	resp, err := c.doWithRetry(client, req)
+	switch {
+	case errors.Is(err, context.Canceled):
+		// canceled by caller, not the failure of host
+	case err != nil, resp.StatusCode >= http.StatusInternalServerError:
+		breaker.MarkFailed()
+	default:
+		breaker.MarkSuccess()
+	}
+	return resp, err
+}
+
+func (c *Client) doWithRetry(client *http.Client, req *http.Request) (*http.Response, error) {
+	retryTimes := 0
+	if isIdempotent(req.Method) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil) {
+		retryTimes = c.opts.retryTimes
+	}
+
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+
+		resp, err := client.Do(r)
+		if attempt >= retryTimes || !c.shouldRetry(req.Context(), resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(c.backoff(attempt)):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+func (c *Client) shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, ErrNotAllowed)
+	}
+	_, ok := c.opts.retryCodes[resp.StatusCode]
+	return ok
+}
+
+// exponential backoff with jitter, the interval is in [d/2, d)
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.opts.retryMinInterval << uint(attempt)
+	if d <= 0 || d > c.opts.retryMaxInterval {
+		d = c.opts.retryMaxInterval
+	}
+	half := int64(d / 2)
+	return time.Duration(half + rand.Int63n(half+1)) //nolint
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package httpcli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/18721889353/sunshine/pkg/shield/circuitbreaker"
+)
+
+func newStatusServer(codes ...int) (*httptest.Server, *int32) {
+	var count int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&count, 1))
+		code := http.StatusOK
+		if n <= len(codes) {
+			code = codes[n-1]
+		}
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(`{"code":0,"msg":"ok","data":"` + r.Header.Get(HeaderXRequestIDKey) + `"}`))
+	}))
+	return srv, &count
+}
+
+func TestClient_Retry(t *testing.T) {
+	srv, count := newStatusServer(http.StatusServiceUnavailable, http.StatusBadGateway)
+	defer srv.Close()
+
+	c := NewClient(WithRetryTimes(3), WithRetryBackoff(time.Millisecond, time.Millisecond*5))
+	result := &StdResult{}
+	err := c.Get(context.Background(), result, srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result.Msg)
+	assert.Equal(t, int32(3), atomic.LoadInt32(count))
+
+	// not idempotent method is not retried
+	srv2, count2 := newStatusServer(http.StatusServiceUnavailable)
+	defer srv2.Close()
+	err = c.Post(context.Background(), &StdResult{}, srv2.URL, &myBody{Name: "foo"})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(count2))
+
+	// retry with body
+	srv3, count3 := newStatusServer(http.StatusServiceUnavailable)
+	defer srv3.Close()
+	err = c.Put(context.Background(), &StdResult{}, srv3.URL, &myBody{Name: "foo"})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(count3))
+
+	// status code not in retry codes
+	srv4, count4 := newStatusServer(http.StatusInternalServerError)
+	defer srv4.Close()
+	err = c.Get(context.Background(), &StdResult{}, srv4.URL)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(count4))
+}
+
+func TestClient_RetryContext(t *testing.T) {
+	srv, count := newStatusServer(http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	c := NewClient(WithRetryTimes(3), WithRetryBackoff(time.Second, time.Second*2))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	err := c.Get(ctx, &StdResult{}, srv.URL)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, int32(1), atomic.LoadInt32(count))
+}
+
+func TestClient_backoff(t *testing.T) {
+	c := NewClient(WithRetryBackoff(time.Millisecond*100, time.Millisecond*300))
+	for attempt := 0; attempt < 5; attempt++ {
+		d := c.backoff(attempt)
+		max := time.Millisecond * 100 << uint(attempt)
+		if max > time.Millisecond*300 {
+			max = time.Millisecond * 300
+		}
+		assert.True(t, d >= max/2 && d <= max, d)
+	}
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	codes := make([]int, 1000)
+	for i := range codes {
+		codes[i] = http.StatusInternalServerError
+	}
+	srv, count := newStatusServer(codes...)
+	defer srv.Close()
+
+	c := NewClient(WithCircuitBreaker(circuitbreaker.WithRequest(10)))
+	var err error
+	for i := 0; i < 100; i++ {
+		err = c.Get(context.Background(), &StdResult{}, srv.URL)
+		if errors.Is(err, ErrNotAllowed) {
+			break
+		}
+	}
+	assert.True(t, errors.Is(err, ErrNotAllowed))
+	assert.Less(t, atomic.LoadInt32(count), int32(100))
+
+	// the breaker is per host
+	srv2, _ := newStatusServer()
+	defer srv2.Close()
+	err = c.Get(context.Background(), &StdResult{}, srv2.URL)
+	assert.NoError(t, err)
+}
+
+func TestClient_Middlewares(t *testing.T) {
+	srv, _ := newStatusServer()
+	defer srv.Close()
+
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	var signedBody string
+	c := NewClient(
+		WithClientTimeout(time.Second*5),
+		WithMiddlewares(
+			mw("first"),
+			mw("second"),
+			RequestIDMiddleware(),
+			TracingMiddleware(),
+			LoggingMiddleware(zap.NewNop()),
+			MetricsMiddleware(),
+			SignMiddleware(func(req *http.Request, body []byte) error {
+				signedBody = string(body)
+				req.Header.Set("X-Sign", "sign")
+				return nil
+			}),
+		),
+	)
+
+	ctx := context.WithValue(context.Background(), ContextRequestIDKey, "req-id-123") //nolint
+	result := &StdResult{}
+	err := c.Post(ctx, result, srv.URL, &myBody{Name: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, order)
+	assert.Equal(t, "req-id-123", result.Data)
+	assert.Equal(t, `{"name":"foo","email":""}`, signedBody)
+
+	// Request created by client
+	resp, err := c.NewRequest().SetURL(srv.URL).SetContext(ctx).GET()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// sign error
+	c = NewClient(WithMiddlewares(SignMiddleware(func(req *http.Request, body []byte) error {
+		return errors.New("sign error")
+	})))
+	err = c.Get(context.Background(), &StdResult{}, srv.URL)
+	assert.Error(t, err)
+}
+
+func TestClient_Do(t *testing.T) {
+	srv, _ := newStatusServer()
+	defer srv.Close()
+
+	c := NewClient(WithTransport(NewTransport()), WithRetryStatusCodes(http.StatusTooManyRequests))
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = c.Delete(ctx, &StdResult{}, srv.URL)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
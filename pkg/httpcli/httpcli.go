@@ -3,6 +3,7 @@ package httpcli
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -24,6 +25,9 @@ type Request struct {
 	bodyJSON      interface{}            // JSON marshal body data
 	timeout       time.Duration          // Client timeout
 	headers       map[string]string
+	ctx           context.Context
+	enableTrace   bool
+	client        *Client // default is the shared client
 
 	request  *http.Request
 	response *Response
@@ -51,6 +55,8 @@ func (req *Request) Reset() {
 	req.bodyJSON = nil
 	req.timeout = 0
 	req.headers = nil
+	req.ctx = nil
+	req.enableTrace = false
 
 	req.request = nil
 	req.response = nil
@@ -98,7 +104,7 @@ func (req *Request) SetBody(body interface{}) *Request {
 	return req
 }
 
-// SetTimeout set timeout
+// SetTimeout set timeout, default is the timeout of client
 func (req *Request) SetTimeout(t time.Duration) *Request {
 	req.timeout = t
 	return req
@@ -130,6 +136,18 @@ func (req *Request) SetHeaders(headers map[string]string) *Request {
 	return req
 }
 
+// SetContext set context of request, the request is canceled when ctx is done
+func (req *Request) SetContext(ctx context.Context) *Request {
+	req.ctx = ctx
+	return req
+}
+
+// EnableTrace the request is recorded in a span, and the trace context is sent with the request headers
+func (req *Request) EnableTrace() *Request {
+	req.enableTrace = true
+	return req
+}
+
 // CustomRequest customize request, e.g. add sign, set header, etc.
 func (req *Request) CustomRequest(f func(req *http.Request, data *bytes.Buffer)) *Request {
 	req.customRequest = f
@@ -239,7 +257,11 @@ func (req *Request) push() (*Response, error) {
 }
 
 func (req *Request) send(body io.Reader, buf *bytes.Buffer) (*Response, error) {
-	req.request, req.err = http.NewRequest(req.method, req.url, body)
+	ctx := req.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req.request, req.err = http.NewRequestWithContext(ctx, req.method, req.url, body)
 	if req.err != nil {
 		return nil, req.err
 	}
@@ -254,13 +276,12 @@ func (req *Request) send(body io.Reader, buf *bytes.Buffer) (*Response, error) {
 		}
 	}
 
-	if req.timeout < 1 {
-		req.timeout = defaultTimeout
+	client := req.client
+	if client == nil {
+		client = defaultClient
 	}
-
-	client := http.Client{Timeout: req.timeout}
 	resp := new(Response)
-	resp.Response, resp.err = client.Do(req.request)
+	resp.Response, resp.err = client.do(req.request, req.timeout, req.enableTrace)
 
 	req.response = resp
 	req.err = resp.err
@@ -329,9 +350,12 @@ func (resp *Response) BindJSON(v interface{}) error {
 type Option func(*options)
 
 type options struct {
-	params  map[string]interface{}
-	headers map[string]string
-	timeout time.Duration
+	params      map[string]interface{}
+	headers     map[string]string
+	timeout     time.Duration
+	ctx         context.Context
+	enableTrace bool
+	client      *Client
 }
 
 func (o *options) apply(opts ...Option) {
@@ -347,7 +371,7 @@ func defaultOptions() *options {
 // WithParams set params
 func WithParams(params map[string]interface{}) Option {
 	return func(o *options) {
-		if o.params != nil {
+		if params != nil {
 			o.params = params
 		}
 	}
@@ -356,7 +380,7 @@ func WithParams(params map[string]interface{}) Option {
 // WithHeaders set headers
 func WithHeaders(headers map[string]string) Option {
 	return func(o *options) {
-		if o.headers != nil {
+		if headers != nil {
 			o.headers = headers
 		}
 	}
@@ -369,42 +393,76 @@ func WithTimeout(t time.Duration) Option {
 	}
 }
 
+// WithContext set context
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithEnableTrace enable trace, the trace context is sent with the request headers
+func WithEnableTrace() Option {
+	return func(o *options) {
+		o.enableTrace = true
+	}
+}
+
+// send the request by client c, used by the methods of Client
+func withClient(c *Client) Option {
+	return func(o *options) {
+		o.client = c
+	}
+}
+
 // Get request, return custom json format
 func Get(result interface{}, urlStr string, opts ...Option) error {
 	o := defaultOptions()
 	o.apply(opts...)
-	return gDo("GET", result, urlStr, o.params, o.headers, o.timeout)
+	return gDo("GET", result, urlStr, o)
 }
 
 // Delete request, return custom json format
 func Delete(result interface{}, urlStr string, opts ...Option) error {
 	o := defaultOptions()
 	o.apply(opts...)
-	return gDo("DELETE", result, urlStr, o.params, o.headers, o.timeout)
+	return gDo("DELETE", result, urlStr, o)
 }
 
 // Post request, return custom json format
 func Post(result interface{}, urlStr string, body interface{}, opts ...Option) error {
 	o := defaultOptions()
 	o.apply(opts...)
-	return do("POST", result, urlStr, body, o.params, o.headers, o.timeout)
+	return do("POST", result, urlStr, body, o)
 }
 
 // Put request, return custom json format
 func Put(result interface{}, urlStr string, body interface{}, opts ...Option) error {
 	o := defaultOptions()
 	o.apply(opts...)
-	return do("PUT", result, urlStr, body, o.params, o.headers, o.timeout)
+	return do("PUT", result, urlStr, body, o)
 }
 
 // Patch request, return custom json format
 func Patch(result interface{}, urlStr string, body interface{}, opts ...Option) error {
 	o := defaultOptions()
 	o.apply(opts...)
-	return do("PATCH", result, urlStr, body, o.params, o.headers, o.timeout)
+	return do("PATCH", result, urlStr, body, o)
 }
 
-var requestErr = func(err error) error { return fmt.Errorf("request error, err=%v", err) }
+func (o *options) newRequest(urlStr string) *Request {
+	req := &Request{client: o.client}
+	req.SetURL(urlStr)
+	req.SetParams(o.params)
+	req.SetHeaders(o.headers)
+	req.SetTimeout(o.timeout)
+	req.SetContext(o.ctx)
+	if o.enableTrace {
+		req.EnableTrace()
+	}
+	return req
+}
+
+var requestErr = func(err error) error { return fmt.Errorf("request error, err=%w", err) }
 var jsonParseErr = func(err error) error { return fmt.Errorf("json parsing error, err=%v", err) }
 var notOKErr = func(resp *Response) error {
 	body, err := resp.ReadBody()
@@ -417,18 +475,14 @@ var notOKErr = func(resp *Response) error {
 	return fmt.Errorf("statusCode=%d, body=%s", resp.StatusCode, body)
 }
 
-func do(method string, result interface{}, urlStr string, body interface{}, params KV, headers map[string]string, timeout time.Duration) error {
+func do(method string, result interface{}, urlStr string, body interface{}, o *options) error {
 	if result == nil {
 		return fmt.Errorf("'result' can not be nil")
 	}
 
-	req := &Request{}
-	req.SetURL(urlStr)
+	req := o.newRequest(urlStr)
 	req.SetContentType("application/json")
-	req.SetParams(params)
-	req.SetHeaders(headers)
 	req.SetBody(body)
-	req.SetTimeout(timeout)
 
 	var resp *Response
 	var err error
@@ -457,12 +511,8 @@ func do(method string, result interface{}, urlStr string, body interface{}, para
 	return nil
 }
 
-func gDo(method string, result interface{}, urlStr string, params KV, headers map[string]string, timeout time.Duration) error {
-	req := &Request{}
-	req.SetURL(urlStr)
-	req.SetParams(params)
-	req.SetHeaders(headers)
-	req.SetTimeout(timeout)
+func gDo(method string, result interface{}, urlStr string, o *options) error {
+	req := o.newRequest(urlStr)
 
 	var resp *Response
 	var err error
@@ -2,14 +2,22 @@ package httpcli
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/18721889353/sunshine/pkg/utils"
 )
@@ -720,11 +728,54 @@ func TestError(t *testing.T) {
 	err = notOKErr(resp)
 	assert.Error(t, err)
 
-	err = do(http.MethodPost, nil, "", nil, nil, nil, 0)
+	err = do(http.MethodPost, nil, "", nil, defaultOptions())
 	assert.Error(t, err)
-	err = do(http.MethodPost, &StdResult{}, "http://127.0.0.1:0", nil, KV{"foo": "bar"}, nil, 0)
+	err = do(http.MethodPost, &StdResult{}, "http://127.0.0.1:0", nil, &options{params: KV{"foo": "bar"}})
 	assert.Error(t, err)
 
-	err = gDo(http.MethodGet, nil, "http://127.0.0.1:0", nil, nil, 0)
+	err = gDo(http.MethodGet, nil, "http://127.0.0.1:0", defaultOptions())
 	assert.Error(t, err)
 }
+
+func TestEnableTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(tp)
+		otel.SetTextMapPropagator(propagator)
+	}()
+
+	var traceparents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"code":0,"msg":"ok"}`))
+	}))
+	defer srv.Close()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	resp, err := New().SetURL(srv.URL).SetContext(ctx).EnableTrace().GET()
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	err = Get(&StdResult{}, srv.URL, WithContext(ctx), WithEnableTrace())
+	require.NoError(t, err)
+	// no trace
+	err = Get(&StdResult{}, srv.URL, WithContext(ctx))
+	require.NoError(t, err)
+	parent.End()
+
+	require.Len(t, traceparents, 3)
+	assert.NotEmpty(t, traceparents[0])
+	assert.NotEmpty(t, traceparents[1])
+	assert.Empty(t, traceparents[2])
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	for _, span := range spans[:2] {
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+		assert.Contains(t, traceparents, fmt.Sprintf("00-%s-%s-01", span.SpanContext().TraceID(), span.SpanContext().SpanID()))
+	}
+}
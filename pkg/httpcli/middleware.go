@@ -0,0 +1,145 @@
+package httpcli
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.uber.org/zap"
+
+	"github.com/18721889353/sunshine/pkg/logger"
+	"github.com/18721889353/sunshine/pkg/metrics"
+)
+
+const (
+	// ContextRequestIDKey request id for context, same as the key of gin and grpc request id middleware
+	ContextRequestIDKey = "request_id"
+	// HeaderXRequestIDKey header request id key
+	HeaderXRequestIDKey = "X-Request-Id"
+)
+
+// Middleware wraps the round tripper of client, e.g. set headers, logging, metrics, the request must be
+// cloned before modifying it.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is an adapter to allow the use of ordinary functions as http.RoundTripper.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// RequestIDMiddleware sends the request id in context with the header X-Request-Id, ctxKey is the key
+// of request id in context, default is request_id, the header set by request is not overwritten.
+func RequestIDMiddleware(ctxKey ...string) Middleware {
+	key := ContextRequestIDKey
+	if len(ctxKey) > 0 && ctxKey[0] != "" {
+		key = ctxKey[0]
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(HeaderXRequestIDKey) == "" {
+				if requestID, ok := req.Context().Value(key).(string); ok && requestID != "" { //nolint
+					req = req.Clone(req.Context())
+					req.Header.Set(HeaderXRequestIDKey, requestID)
+				}
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// TracingMiddleware records the request in a span, and propagates the trace context with the request headers.
+func TracingMiddleware(opts ...otelhttp.Option) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return otelhttp.NewTransport(next, opts...)
+	}
+}
+
+// LoggingMiddleware logs the method, url, status code and cost of request, the failed request is logged at
+// warn level, if l is nil, the global logger is used.
+func LoggingMiddleware(l *zap.Logger) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			log := l
+			if log == nil {
+				log = logger.Get()
+			}
+
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			fields := []zap.Field{
+				zap.String("method", req.Method),
+				zap.String("url", req.URL.String()),
+				zap.Duration("cost", time.Since(start)),
+			}
+			if requestID := req.Header.Get(HeaderXRequestIDKey); requestID != "" {
+				fields = append(fields, zap.String(ContextRequestIDKey, requestID))
+			}
+			if err != nil {
+				log.Warn("[httpcli] request failed", append(fields, zap.Error(err))...)
+				return resp, err
+			}
+
+			fields = append(fields, zap.Int("code", resp.StatusCode))
+			if resp.StatusCode >= http.StatusInternalServerError {
+				log.Warn("[httpcli] request failed", fields...)
+			} else {
+				log.Info("[httpcli] request", fields...)
+			}
+			return resp, err
+		})
+	}
+}
+
+// MetricsMiddleware records the requests total and duration of request to the shared registry of pkg/metrics,
+// the metrics are http_client_requests_total and http_client_request_duration_seconds with labels method, host and code,
+// the code of network error is error.
+func MetricsMiddleware() Middleware {
+	requests := metrics.NewCounter("http_client_requests_total",
+		"The total number of http client requests", "method", "host", "code")
+	duration := metrics.NewHistogram("http_client_request_duration_seconds",
+		"The duration of http client requests", nil, "method", "host", "code")
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			code := "error"
+			if err == nil {
+				code = strconv.Itoa(resp.StatusCode)
+			}
+			requests.Inc(req.Context(), req.Method, req.URL.Host, code)
+			duration.Observe(req.Context(), time.Since(start).Seconds(), req.Method, req.URL.Host, code)
+			return resp, err
+		})
+	}
+}
+
+// SignMiddleware signs the request, e.g. add signature header, body is the request body, it is nil if there is no body.
+func SignMiddleware(sign func(req *http.Request, body []byte) error) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			r := req.Clone(req.Context())
+			var body []byte
+			if req.GetBody != nil {
+				rc, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				body, err = io.ReadAll(rc)
+				_ = rc.Close()
+				if err != nil {
+					return nil, err
+				}
+			}
+			if err := sign(r, body); err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(r)
+		})
+	}
+}
@@ -16,6 +16,8 @@ type Args struct {
 	Name      string // name of structure
 	SubStruct bool   // are sub-structures separated
 	Tags      string // add additional tags, multiple tags separated by commas
+	// FieldTags add tags to the specified fields, key is the path of field, e.g. {"app.name": `validate:"required"`}
+	FieldTags map[string]string
 
 	tags          []string //nolint
 	convertFloats bool
@@ -68,7 +70,7 @@ func Convert(args *Args) (string, error) {
 
 	input := bytes.NewReader(data)
 
-	output, err := jyParse(input, args.parser, args.Name, "main", args.tags, args.SubStruct, args.convertFloats)
+	output, err := jyParse(input, args.parser, args.Name, "main", args.tags, args.FieldTags, args.SubStruct, args.convertFloats)
 	if err != nil {
 		return "", err
 	}
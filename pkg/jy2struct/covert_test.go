@@ -79,3 +79,25 @@ age: 10`,
 	_, err = Convert(arg)
 	assert.Error(t, err)
 }
+
+func TestConvert_fieldTags(t *testing.T) {
+	got, err := Convert(&Args{
+		Data: `app:
+  name: "foo"
+  env: "dev"
+servers:
+  - name: "bar"
+`,
+		Format:    "yaml",
+		SubStruct: true,
+		Tags:      "json",
+		FieldTags: map[string]string{
+			"app.name":     `validate:"required"`,
+			"servers.name": `validate:"required"`,
+		},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, got, "Name string `yaml:\"name\" json:\"name\" validate:\"required\"`")
+	assert.Contains(t, got, "Env  string `yaml:\"env\" json:\"env\"`")
+	assert.Contains(t, got, "Servers []Servers")
+}
@@ -106,7 +106,7 @@ func readFile(input io.Reader) ([]byte, error) {
 }
 
 // json or yaml parse
-func jyParse(input io.Reader, parser Parser, structName, pkgName string, tags []string, subStruct bool, convertFloats bool) ([]byte, error) {
+func jyParse(input io.Reader, parser Parser, structName, pkgName string, tags []string, fieldTags map[string]string, subStruct bool, convertFloats bool) ([]byte, error) {
 	_ = pkgName
 	var subStructMap map[string]string
 	if subStruct {
@@ -126,7 +126,7 @@ func jyParse(input io.Reader, parser Parser, structName, pkgName string, tags []
 	case map[string]interface{}:
 		result = iresult
 	case []interface{}:
-		src := fmt.Sprintf("\ntype %s %s\n", structName, typeForValue(iresult, structName, tags, subStructMap, convertFloats))
+		src := fmt.Sprintf("\ntype %s %s\n", structName, typeForValue(iresult, structName, tags, fieldTags, "", subStructMap, convertFloats))
 		// supplementary sub-structures
 		for k, v := range subStructMap {
 			src += fmt.Sprintf("\n\ntype %s %s\n\n", v, k)
@@ -141,7 +141,7 @@ func jyParse(input io.Reader, parser Parser, structName, pkgName string, tags []
 		return nil, fmt.Errorf("unexpected type: %T", iresult)
 	}
 
-	src := fmt.Sprintf("\ntype %s %s}", structName, generateTypes(result, structName, tags, 0, subStructMap, convertFloats))
+	src := fmt.Sprintf("\ntype %s %s}", structName, generateTypes(result, structName, tags, fieldTags, "", 0, subStructMap, convertFloats))
 
 	keys := make([]string, 0, len(subStructMap))
 	for key := range subStructMap {
@@ -171,8 +171,8 @@ func convertKeysToStrings(obj map[interface{}]interface{}) map[string]interface{
 	return res
 }
 
-// jyParse go struct entries for a map[string]interface{} structure
-func generateTypes(obj map[string]interface{}, structName string, tags []string, depth int, subStructMap map[string]string, convertFloats bool) string {
+// jyParse go struct entries for a map[string]interface{} structure, path is the keys of obj joined by dot
+func generateTypes(obj map[string]interface{}, structName string, tags []string, fieldTags map[string]string, path string, depth int, subStructMap map[string]string, convertFloats bool) string {
 	structure := "struct {"
 
 	keys := make([]string, 0, len(obj))
@@ -183,7 +183,11 @@ func generateTypes(obj map[string]interface{}, structName string, tags []string,
 
 	for _, key := range keys {
 		value := obj[key]
-		valueType := typeForValue(value, structName, tags, subStructMap, convertFloats)
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		valueType := typeForValue(value, structName, tags, fieldTags, fieldPath, subStructMap, convertFloats)
 
 		//value = mergeElements(value)
 		//If a nested value, recurse
@@ -192,9 +196,9 @@ func generateTypes(obj map[string]interface{}, structName string, tags []string,
 			if len(value) > 0 {
 				sub := ""
 				if v, ok := value[0].(map[interface{}]interface{}); ok {
-					sub = generateTypes(convertKeysToStrings(v), structName, tags, depth+1, subStructMap, convertFloats) + "}"
+					sub = generateTypes(convertKeysToStrings(v), structName, tags, fieldTags, fieldPath, depth+1, subStructMap, convertFloats) + "}"
 				} else if v, ok := value[0].(map[string]interface{}); ok {
-					sub = generateTypes(v, structName, tags, depth+1, subStructMap, convertFloats) + "}"
+					sub = generateTypes(v, structName, tags, fieldTags, fieldPath, depth+1, subStructMap, convertFloats) + "}"
 				}
 
 				if sub != "" {
@@ -214,7 +218,7 @@ func generateTypes(obj map[string]interface{}, structName string, tags []string,
 				}
 			}
 		case map[interface{}]interface{}:
-			sub := generateTypes(convertKeysToStrings(value), structName, tags, depth+1, subStructMap, convertFloats) + "}"
+			sub := generateTypes(convertKeysToStrings(value), structName, tags, fieldTags, fieldPath, depth+1, subStructMap, convertFloats) + "}"
 			subName := sub
 
 			if subStructMap != nil {
@@ -228,7 +232,7 @@ func generateTypes(obj map[string]interface{}, structName string, tags []string,
 			}
 			valueType = subName
 		case map[string]interface{}:
-			sub := generateTypes(value, structName, tags, depth+1, subStructMap, convertFloats) + "}"
+			sub := generateTypes(value, structName, tags, fieldTags, fieldPath, depth+1, subStructMap, convertFloats) + "}"
 			subName := sub
 
 			if subStructMap != nil {
@@ -250,6 +254,9 @@ func generateTypes(obj map[string]interface{}, structName string, tags []string,
 		for _, t := range tags {
 			tagList = append(tagList, fmt.Sprintf("%s:\"%s\"", t, key))
 		}
+		if t, ok := fieldTags[fieldPath]; ok {
+			tagList = append(tagList, t)
+		}
 
 		structure += fmt.Sprintf("\n%s %s `%s`",
 			fieldName,
@@ -379,7 +386,7 @@ func lintFieldName(name string) string {
 }
 
 // generate an appropriate struct type entry
-func typeForValue(value interface{}, structName string, tags []string, subStructMap map[string]string, convertFloats bool) string {
+func typeForValue(value interface{}, structName string, tags []string, fieldTags map[string]string, path string, subStructMap map[string]string, convertFloats bool) string {
 	//Check if this is an array
 	if objects, ok := value.([]interface{}); ok {
 		types := make(map[reflect.Type]bool, 0)
@@ -387,13 +394,13 @@ func typeForValue(value interface{}, structName string, tags []string, subStruct
 			types[reflect.TypeOf(o)] = true
 		}
 		if len(types) == 1 {
-			return "[]" + typeForValue(mergeElements(objects).([]interface{})[0], structName, tags, subStructMap, convertFloats)
+			return "[]" + typeForValue(mergeElements(objects).([]interface{})[0], structName, tags, fieldTags, path, subStructMap, convertFloats)
 		}
 		return "[]interface{}"
 	} else if object, ok := value.(map[interface{}]interface{}); ok {
-		return generateTypes(convertKeysToStrings(object), structName, tags, 0, subStructMap, convertFloats) + "}"
+		return generateTypes(convertKeysToStrings(object), structName, tags, fieldTags, path, 0, subStructMap, convertFloats) + "}"
 	} else if object, ok := value.(map[string]interface{}); ok {
-		return generateTypes(object, structName, tags, 0, subStructMap, convertFloats) + "}"
+		return generateTypes(object, structName, tags, fieldTags, path, 0, subStructMap, convertFloats) + "}"
 	} else if reflect.TypeOf(value) == nil {
 		return "interface{}"
 	}
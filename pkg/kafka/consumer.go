@@ -15,6 +15,7 @@ type ConsumerGroup struct {
 	groupID          string
 	zapLogger        *zap.Logger
 	autoCommitEnable bool
+	enableTrace      bool
 }
 
 // InitConsumerGroup init consumer group
@@ -48,11 +49,15 @@ func InitConsumerGroup(addrs []string, groupID string, opts ...ConsumerOption) (
 		groupID:          groupID,
 		zapLogger:        o.zapLogger,
 		autoCommitEnable: config.Consumer.Offsets.AutoCommit.Enable,
+		enableTrace:      o.enableTrace,
 	}, nil
 }
 
 // Consume consume messages
 func (c *ConsumerGroup) Consume(ctx context.Context, topics []string, handleMessageFn HandleMessageFn) error {
+	if c.enableTrace {
+		handleMessageFn = traceHandleMessageFn(c.groupID, handleMessageFn)
+	}
 	handler := &defaultConsumerHandler{
 		ctx:              ctx,
 		handleMessageFn:  handleMessageFn,
@@ -138,8 +143,9 @@ func (h *defaultConsumerHandler) ConsumeClaim(sess sarama.ConsumerGroupSession,
 
 // Consumer consume partition
 type Consumer struct {
-	C         sarama.Consumer
-	zapLogger *zap.Logger
+	C           sarama.Consumer
+	zapLogger   *zap.Logger
+	enableTrace bool
 }
 
 // InitConsumer init consumer
@@ -167,17 +173,25 @@ func InitConsumer(addrs []string, opts ...ConsumerOption) (*Consumer, error) {
 	}
 
 	return &Consumer{
-		C:         consumer,
-		zapLogger: o.zapLogger,
+		C:           consumer,
+		zapLogger:   o.zapLogger,
+		enableTrace: o.enableTrace,
 	}, nil
 }
 
 // ConsumePartition consumer one partition, blocking
 func (c *Consumer) ConsumePartition(ctx context.Context, topic string, partition int32, offset int64, handleFn HandleMessageFn) {
+	if c.enableTrace {
+		handleFn = traceHandleMessageFn("", handleFn)
+	}
+	c.consumePartition(ctx, topic, partition, offset, handleFn)
+}
+
+func (c *Consumer) consumePartition(ctx context.Context, topic string, partition int32, offset int64, handleFn HandleMessageFn) {
 	defer func() {
 		if e := recover(); e != nil {
 			c.zapLogger.Error("panic occurred while consuming messages", zap.Any("error", e))
-			c.ConsumePartition(ctx, topic, partition, offset, handleFn)
+			c.consumePartition(ctx, topic, partition, offset, handleFn)
 		}
 	}()
 
@@ -20,6 +20,8 @@ type consumerOptions struct {
 	clientID  string              // default "sarama"
 	tlsConfig *tls.Config         // default nil
 
+	enableTrace bool // default false
+
 	// consumer group options
 	groupStrategies           []sarama.BalanceStrategy // default NewBalanceStrategyRange
 	offsetsInitial            int64                    // default OffsetOldest
@@ -116,6 +118,14 @@ func ConsumerWithZapLogger(zapLogger *zap.Logger) ConsumerOption {
 	}
 }
 
+// ConsumerWithEnableTrace enable trace, each message is processed in a span which continues the trace of producer,
+// use ExtractContext(ctx, msg) in the handler to get the context of the span.
+func ConsumerWithEnableTrace() ConsumerOption {
+	return func(o *consumerOptions) {
+		o.enableTrace = true
+	}
+}
+
 // ConsumerWithConfig set custom config.
 func ConsumerWithConfig(config *sarama.Config) ConsumerOption {
 	return func(o *consumerOptions) {
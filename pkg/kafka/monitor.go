@@ -0,0 +1,268 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+var (
+	backlogGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "kafka",
+			Name:      "consumer_group_backlog",
+			Help:      "Number of messages not yet consumed by the consumer group, per partition.",
+		}, []string{"group", "topic", "partition"},
+	)
+
+	backlogTotalGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "kafka",
+			Name:      "consumer_group_backlog_total",
+			Help:      "Number of messages not yet consumed by the consumer group, per topic.",
+		}, []string{"group", "topic"},
+	)
+
+	registerMetricsOnce sync.Once
+)
+
+// registered to the default prometheus registry, same as gin metrics middleware
+func registerBacklogMetrics() {
+	registerMetricsOnce.Do(func() {
+		prometheus.MustRegister(backlogGauge, backlogTotalGauge)
+	})
+}
+
+// backlogGetter is implemented by *ClientManager
+type backlogGetter interface {
+	GetBacklog(topic string) (int64, []*Backlog, error)
+	Close() error
+}
+
+// TopicBacklog backlog of a topic in consumer group
+type TopicBacklog struct {
+	Group      string     `json:"group"`
+	Topic      string     `json:"topic"`
+	Total      int64      `json:"total"`
+	Partitions []*Backlog `json:"partitions"`
+	Error      string     `json:"error,omitempty"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// BacklogMonitor poll the backlog of the consumer groups periodically, export them to prometheus,
+// and call the alarm function when the threshold is exceeded.
+type BacklogMonitor struct {
+	opts     *monitorOptions
+	managers map[string]backlogGetter // consumer group id --> client manager
+
+	mu       sync.RWMutex
+	backlogs map[string]*TopicBacklog // key is group/topic
+	alarmAt  map[string]time.Time
+	exported map[string][]*Backlog // key is group/topic, the partitions exported last time
+
+	startOnce sync.Once
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// InitBacklogMonitor init backlog monitor, at least one consumer group must be set by MonitorWithGroup.
+func InitBacklogMonitor(addrs []string, opts ...MonitorOption) (*BacklogMonitor, error) {
+	o := defaultMonitorOptions()
+	o.apply(opts...)
+
+	if len(o.groupTopics) == 0 {
+		return nil, errors.New("no consumer group to monitor, please set by MonitorWithGroup")
+	}
+
+	managers := make(map[string]backlogGetter, len(o.groupTopics))
+	for groupID := range o.groupTopics {
+		if m, ok := o.clientManagers[groupID]; ok {
+			managers[groupID] = m
+			continue
+		}
+		m, err := InitClientManager(addrs, groupID)
+		if err != nil {
+			for _, cm := range managers {
+				_ = cm.Close()
+			}
+			return nil, fmt.Errorf("init client manager of group %s error: %v", groupID, err)
+		}
+		managers[groupID] = m
+	}
+
+	if o.enableMetrics {
+		registerBacklogMetrics()
+	}
+
+	return &BacklogMonitor{
+		opts:     o,
+		managers: managers,
+		backlogs: make(map[string]*TopicBacklog),
+		alarmAt:  make(map[string]time.Time),
+		exported: make(map[string][]*Backlog),
+	}, nil
+}
+
+// Start polling in the background, the first poll is executed immediately, calling Start more than once has no effect.
+func (m *BacklogMonitor) Start() {
+	m.startOnce.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		m.cancel = cancel
+		m.done = make(chan struct{})
+
+		go func() {
+			defer close(m.done)
+			ticker := time.NewTicker(m.opts.interval)
+			defer ticker.Stop()
+
+			m.poll()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					m.poll()
+				}
+			}
+		}()
+	})
+}
+
+// Stop polling, delete the exported series and close all client managers.
+func (m *BacklogMonitor) Stop() error {
+	m.startOnce.Do(func() {}) // prevent starting after stop
+	if m.cancel != nil {
+		m.cancel()
+		<-m.done
+	}
+
+	for key := range m.exported {
+		m.unexport(key, nil)
+	}
+
+	var errs []error
+	for _, cm := range m.managers {
+		if err := cm.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Backlogs returns the latest backlog of all monitored topics, sorted by group and topic.
+func (m *BacklogMonitor) Backlogs() []*TopicBacklog {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list := make([]*TopicBacklog, 0, len(m.backlogs))
+	for _, tb := range m.backlogs {
+		list = append(list, tb)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Group == list[j].Group {
+			return list[i].Topic < list[j].Topic
+		}
+		return list[i].Group < list[j].Group
+	})
+	return list
+}
+
+// ServeHTTP returns the latest backlog in json format
+func (m *BacklogMonitor) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(m.Backlogs())
+}
+
+// Register backlog http route to the admin server mux, e.g. the http server of pprof and metrics.
+func (m *BacklogMonitor) Register(mux *http.ServeMux) {
+	mux.Handle(m.opts.httpPattern, m)
+}
+
+func (m *BacklogMonitor) poll() {
+	for groupID, topics := range m.opts.groupTopics {
+		cm := m.managers[groupID]
+		for _, topic := range topics {
+			key := groupID + "/" + topic
+			tb := &TopicBacklog{
+				Group:     groupID,
+				Topic:     topic,
+				UpdatedAt: time.Now(),
+			}
+			total, partitions, err := cm.GetBacklog(topic)
+			if err != nil {
+				tb.Error = err.Error()
+				m.opts.zapLogger.Warn("get kafka backlog error", zap.String("group", groupID),
+					zap.String("topic", topic), zap.Error(err))
+			} else {
+				tb.Total = total
+				tb.Partitions = partitions
+				m.export(key, tb)
+				m.checkAlarm(tb)
+			}
+
+			m.mu.Lock()
+			m.backlogs[key] = tb
+			m.mu.Unlock()
+		}
+	}
+}
+
+func (m *BacklogMonitor) export(key string, tb *TopicBacklog) {
+	if !m.opts.enableMetrics {
+		return
+	}
+	backlogTotalGauge.WithLabelValues(tb.Group, tb.Topic).Set(float64(tb.Total))
+	for _, p := range tb.Partitions {
+		backlogGauge.WithLabelValues(tb.Group, tb.Topic, strconv.Itoa(int(p.Partition))).Set(float64(p.Backlog))
+	}
+	m.unexport(key, tb.Partitions)
+	m.exported[key] = tb.Partitions
+}
+
+// delete the series of partitions exported last time but not in the partitions,
+// all series of the topic are deleted if partitions is nil
+func (m *BacklogMonitor) unexport(key string, partitions []*Backlog) {
+	exists := make(map[int32]struct{}, len(partitions))
+	for _, p := range partitions {
+		exists[p.Partition] = struct{}{}
+	}
+	i := strings.LastIndex(key, "/") // topic name does not contain '/'
+	group, topic := key[:i], key[i+1:]
+	for _, p := range m.exported[key] {
+		if _, ok := exists[p.Partition]; !ok {
+			backlogGauge.DeleteLabelValues(group, topic, strconv.Itoa(int(p.Partition)))
+		}
+	}
+	if partitions == nil {
+		backlogTotalGauge.DeleteLabelValues(group, topic)
+		delete(m.exported, key)
+	}
+}
+
+func (m *BacklogMonitor) checkAlarm(tb *TopicBacklog) {
+	if m.opts.alarmFn == nil {
+		return
+	}
+
+	key := tb.Group + "/" + tb.Topic
+	if tb.Total < m.opts.threshold {
+		delete(m.alarmAt, key) // recovered, alarm immediately next time
+		return
+	}
+
+	if at, ok := m.alarmAt[key]; ok && time.Since(at) < m.opts.alarmInterval {
+		return
+	}
+	m.alarmAt[key] = time.Now()
+	m.opts.alarmFn(tb.Group, tb.Topic, tb.Total)
+}
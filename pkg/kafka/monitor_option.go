@@ -0,0 +1,123 @@
+package kafka
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// BacklogAlarmFn is called when the backlog of a topic in a consumer group exceeds the threshold
+type BacklogAlarmFn func(groupID string, topic string, total int64)
+
+// MonitorOption set options.
+type MonitorOption func(*monitorOptions)
+
+type monitorOptions struct {
+	groupTopics map[string][]string // consumer group id --> topics
+
+	interval       time.Duration // default 30s
+	threshold      int64         // default 0, alarm is disabled
+	alarmFn        BacklogAlarmFn
+	alarmInterval  time.Duration // default 15m, minimum interval between two alarms of the same topic
+	httpPattern    string        // default /kafka/backlog
+	enableMetrics  bool          // default true
+	clientManagers map[string]backlogGetter
+
+	zapLogger *zap.Logger // default NewProduction
+}
+
+func (o *monitorOptions) apply(opts ...MonitorOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+func defaultMonitorOptions() *monitorOptions {
+	zapLogger, _ := zap.NewProduction()
+	return &monitorOptions{
+		groupTopics:   make(map[string][]string),
+		interval:      30 * time.Second,
+		alarmInterval: 15 * time.Minute,
+		httpPattern:   "/kafka/backlog",
+		enableMetrics: true,
+		zapLogger:     zapLogger,
+	}
+}
+
+// MonitorWithGroup add the consumer group and the topics it consumes to the monitor, can be called multiple times.
+func MonitorWithGroup(groupID string, topics ...string) MonitorOption {
+	return func(o *monitorOptions) {
+		if groupID == "" || len(topics) == 0 {
+			return
+		}
+		o.groupTopics[groupID] = append(o.groupTopics[groupID], topics...)
+	}
+}
+
+// MonitorWithInterval set polling interval, minimum 1 second.
+func MonitorWithInterval(d time.Duration) MonitorOption {
+	return func(o *monitorOptions) {
+		if d < time.Second {
+			return
+		}
+		o.interval = d
+	}
+}
+
+// MonitorWithAlarm set the backlog threshold of a topic, alarmFn is called when the total backlog
+// of a topic is greater than or equal to threshold.
+func MonitorWithAlarm(threshold int64, alarmFn BacklogAlarmFn) MonitorOption {
+	return func(o *monitorOptions) {
+		if threshold <= 0 || alarmFn == nil {
+			return
+		}
+		o.threshold = threshold
+		o.alarmFn = alarmFn
+	}
+}
+
+// MonitorWithAlarmInterval set the minimum interval between two alarms of the same topic.
+func MonitorWithAlarmInterval(d time.Duration) MonitorOption {
+	return func(o *monitorOptions) {
+		if d <= 0 {
+			return
+		}
+		o.alarmInterval = d
+	}
+}
+
+// MonitorWithHTTPPattern set http route of backlog info, default is /kafka/backlog.
+func MonitorWithHTTPPattern(pattern string) MonitorOption {
+	return func(o *monitorOptions) {
+		if pattern == "" {
+			return
+		}
+		o.httpPattern = pattern
+	}
+}
+
+// MonitorWithDisableMetrics do not export backlog to prometheus.
+func MonitorWithDisableMetrics() MonitorOption {
+	return func(o *monitorOptions) {
+		o.enableMetrics = false
+	}
+}
+
+// MonitorWithZapLogger set zapLogger.
+func MonitorWithZapLogger(zapLogger *zap.Logger) MonitorOption {
+	return func(o *monitorOptions) {
+		if zapLogger != nil {
+			o.zapLogger = zapLogger
+		}
+	}
+}
+
+// use the specified client manager of consumer group, mainly for testing
+func monitorWithClientManager(groupID string, m backlogGetter) MonitorOption {
+	return func(o *monitorOptions) {
+		if o.clientManagers == nil {
+			o.clientManagers = make(map[string]backlogGetter)
+		}
+		o.clientManagers[groupID] = m
+	}
+}
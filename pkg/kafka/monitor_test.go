@@ -0,0 +1,161 @@
+package kafka
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClientManager struct {
+	total int64
+	err   error
+}
+
+func (f *fakeClientManager) GetBacklog(topic string) (int64, []*Backlog, error) {
+	if f.err != nil {
+		return 0, nil, f.err
+	}
+	total := atomic.LoadInt64(&f.total)
+	return total, []*Backlog{{Partition: 0, Backlog: total, NextConsumeOffset: 1}}, nil
+}
+
+func (f *fakeClientManager) Close() error {
+	return nil
+}
+
+func TestInitBacklogMonitor(t *testing.T) {
+	_, err := InitBacklogMonitor(addrs)
+	assert.Error(t, err)
+
+	m, err := InitBacklogMonitor(addrs, MonitorWithGroup(groupID, "my-topic"))
+	if err != nil {
+		t.Log(err)
+		return
+	}
+	m.Start()
+	time.Sleep(time.Millisecond * 100)
+	_ = m.Stop()
+}
+
+func TestBacklogMonitor(t *testing.T) {
+	fcm := &fakeClientManager{total: 10}
+	var alarmCount int32
+	m, err := InitBacklogMonitor(addrs,
+		MonitorWithGroup("group1", "topic1"),
+		MonitorWithGroup("group2", "topic2"),
+		MonitorWithInterval(time.Second),
+		MonitorWithAlarm(100, func(groupID string, topic string, total int64) {
+			t.Log("alarm", groupID, topic, total)
+			atomic.AddInt32(&alarmCount, 1)
+		}),
+		MonitorWithAlarmInterval(time.Hour),
+		MonitorWithHTTPPattern("/kafka/backlog"),
+		MonitorWithZapLogger(nil),
+		monitorWithClientManager("group1", fcm),
+		monitorWithClientManager("group2", &fakeClientManager{err: errors.New("mock error")}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.poll()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&alarmCount))
+
+	atomic.StoreInt64(&fcm.total, 200)
+	m.poll()
+	m.poll() // within alarm interval, no alarm
+	assert.Equal(t, int32(1), atomic.LoadInt32(&alarmCount))
+
+	atomic.StoreInt64(&fcm.total, 0)
+	m.poll()
+	atomic.StoreInt64(&fcm.total, 300)
+	m.poll() // recovered and exceeded again
+	assert.Equal(t, int32(2), atomic.LoadInt32(&alarmCount))
+
+	backlogs := m.Backlogs()
+	assert.Len(t, backlogs, 2)
+	assert.Equal(t, int64(300), backlogs[0].Total)
+	assert.NotEmpty(t, backlogs[1].Error)
+
+	mux := http.NewServeMux()
+	m.Register(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/kafka/backlog", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var got []*TopicBacklog
+	err = json.Unmarshal(rec.Body.Bytes(), &got)
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	m.Start()
+	time.Sleep(time.Millisecond * 100)
+	err = m.Stop()
+	assert.NoError(t, err)
+}
+
+func TestBacklogMonitorDisableMetrics(t *testing.T) {
+	m, err := InitBacklogMonitor(addrs,
+		MonitorWithGroup("group3", "topic3"),
+		MonitorWithDisableMetrics(),
+		monitorWithClientManager("group3", &fakeClientManager{total: 1}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.poll()
+	assert.Equal(t, int64(1), m.Backlogs()[0].Total)
+	_ = m.Stop()
+}
+
+type fakePartitionManager struct {
+	partitions atomic.Value // []*Backlog
+}
+
+func (f *fakePartitionManager) GetBacklog(topic string) (int64, []*Backlog, error) {
+	partitions := f.partitions.Load().([]*Backlog)
+	var total int64
+	for _, p := range partitions {
+		total += p.Backlog
+	}
+	return total, partitions, nil
+}
+
+func (f *fakePartitionManager) Close() error {
+	return nil
+}
+
+func TestBacklogMonitorStaleSeries(t *testing.T) {
+	fpm := &fakePartitionManager{}
+	fpm.partitions.Store([]*Backlog{{Partition: 0, Backlog: 1}, {Partition: 1, Backlog: 2}})
+	m, err := InitBacklogMonitor(addrs,
+		MonitorWithGroup("group4", "topic4"),
+		monitorWithClientManager("group4", fpm),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.poll()
+	assert.Equal(t, 2.0, testutil.ToFloat64(backlogGauge.WithLabelValues("group4", "topic4", "1")))
+
+	// partition 1 is removed
+	fpm.partitions.Store([]*Backlog{{Partition: 0, Backlog: 3}})
+	m.poll()
+	assert.False(t, backlogGauge.DeleteLabelValues("group4", "topic4", "1"))
+	assert.Equal(t, 3.0, testutil.ToFloat64(backlogTotalGauge.WithLabelValues("group4", "topic4")))
+
+	// start twice, only one polling goroutine
+	m.Start()
+	m.Start()
+	time.Sleep(time.Millisecond * 100)
+	assert.NoError(t, m.Stop())
+	assert.False(t, backlogGauge.DeleteLabelValues("group4", "topic4", "0"))
+	assert.False(t, backlogTotalGauge.DeleteLabelValues("group4", "topic4"))
+}
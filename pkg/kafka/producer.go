@@ -2,10 +2,12 @@
 package kafka
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -16,7 +18,8 @@ type ProducerMessage = sarama.ProducerMessage
 
 // SyncProducer is a sync producer.
 type SyncProducer struct {
-	Producer sarama.SyncProducer
+	Producer    sarama.SyncProducer
+	enableTrace bool
 }
 
 // InitSyncProducer init sync producer.
@@ -45,16 +48,34 @@ func InitSyncProducer(addrs []string, opts ...SyncProducerOption) (*SyncProducer
 		return nil, err
 	}
 
-	return &SyncProducer{Producer: producer}, nil
+	return &SyncProducer{Producer: producer, enableTrace: o.enableTrace}, nil
 }
 
 // SendMessage sends a message to a topic.
 func (p *SyncProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
-	return p.Producer.SendMessage(msg)
+	return p.SendMessageContext(context.Background(), msg)
+}
+
+// SendMessageContext sends a message to a topic, if trace is enabled, the trace context of ctx is sent with the message.
+func (p *SyncProducer) SendMessageContext(ctx context.Context, msg *sarama.ProducerMessage) (int32, int64, error) {
+	if !p.enableTrace {
+		return p.Producer.SendMessage(msg)
+	}
+
+	_, span := startProducerSpan(ctx, msg)
+	partition, offset, err := p.Producer.SendMessage(msg)
+	endProducerSpan(span, partition, offset, err)
+	return partition, offset, err
 }
 
 // SendData sends a message to a topic with multiple types of data.
 func (p *SyncProducer) SendData(topic string, data interface{}) (int32, int64, error) {
+	return p.SendDataContext(context.Background(), topic, data)
+}
+
+// SendDataContext sends a message to a topic with multiple types of data, if trace is enabled,
+// the trace context of ctx is sent with the message.
+func (p *SyncProducer) SendDataContext(ctx context.Context, topic string, data interface{}) (int32, int64, error) {
 	var msg *sarama.ProducerMessage
 	switch val := data.(type) {
 	case *sarama.ProducerMessage:
@@ -73,7 +94,7 @@ func (p *SyncProducer) SendData(topic string, data interface{}) (int32, int64, e
 		msg = &sarama.ProducerMessage{Topic: topic, Value: sarama.ByteEncoder(buf)}
 	}
 
-	return p.Producer.SendMessage(msg)
+	return p.SendMessageContext(ctx, msg)
 }
 
 // Close closes the producer.
@@ -95,9 +116,10 @@ type Message struct {
 
 // AsyncProducer is async producer.
 type AsyncProducer struct {
-	Producer  sarama.AsyncProducer
-	zapLogger *zap.Logger
-	exit      chan struct{}
+	Producer    sarama.AsyncProducer
+	zapLogger   *zap.Logger
+	exit        chan struct{}
+	enableTrace bool
 }
 
 // InitAsyncProducer init async producer.
@@ -130,9 +152,10 @@ func InitAsyncProducer(addrs []string, opts ...AsyncProducerOption) (*AsyncProdu
 	}
 
 	p := &AsyncProducer{
-		Producer:  producer,
-		zapLogger: o.zapLogger,
-		exit:      make(chan struct{}),
+		Producer:    producer,
+		zapLogger:   o.zapLogger,
+		exit:        make(chan struct{}),
+		enableTrace: o.enableTrace,
 	}
 
 	go p.handleResponse(o.handleFailedFn)
@@ -142,11 +165,28 @@ func InitAsyncProducer(addrs []string, opts ...AsyncProducerOption) (*AsyncProdu
 
 // SendMessage sends messages to a topic.
 func (p *AsyncProducer) SendMessage(messages ...*sarama.ProducerMessage) error {
+	return p.SendMessageContext(context.Background(), messages...)
+}
+
+// SendMessageContext sends messages to a topic, if trace is enabled, the trace context of ctx is sent with the messages,
+// the span ends when the message is handed over to the producer.
+func (p *AsyncProducer) SendMessageContext(ctx context.Context, messages ...*sarama.ProducerMessage) error {
 	for _, msg := range messages {
+		var span trace.Span
+		if p.enableTrace {
+			_, span = startProducerSpan(ctx, msg)
+		}
 		select {
 		case p.Producer.Input() <- msg:
+			if span != nil {
+				endSpan(span, nil)
+			}
 		case <-p.exit:
-			return fmt.Errorf("async produce message had exited")
+			err := fmt.Errorf("async produce message had exited")
+			if span != nil {
+				endSpan(span, err)
+			}
+			return err
 		}
 	}
 
@@ -155,6 +195,12 @@ func (p *AsyncProducer) SendMessage(messages ...*sarama.ProducerMessage) error {
 
 // SendData sends messages to a topic with multiple types of data.
 func (p *AsyncProducer) SendData(topic string, multiData ...interface{}) error {
+	return p.SendDataContext(context.Background(), topic, multiData...)
+}
+
+// SendDataContext sends messages to a topic with multiple types of data, if trace is enabled,
+// the trace context of ctx is sent with the messages.
+func (p *AsyncProducer) SendDataContext(ctx context.Context, topic string, multiData ...interface{}) error {
 	var messages []*sarama.ProducerMessage
 
 	for _, data := range multiData {
@@ -178,7 +224,7 @@ func (p *AsyncProducer) SendData(topic string, multiData ...interface{}) error {
 		messages = append(messages, msg)
 	}
 
-	return p.SendMessage(messages...)
+	return p.SendMessageContext(ctx, messages...)
 }
 
 // handleResponse handles the response of async producer, if producer message failed, you can handle it, e.g. add to other queue to handle later.
@@ -23,6 +23,7 @@ type syncProducerOptions struct {
 	returnSuccesses bool                          // default true
 	clientID        string                        // default "sarama"
 	tlsConfig       *tls.Config                   // default nil
+	enableTrace     bool                          // default false
 
 	// custom config, if not nil, it will override the default config, the above parameters are invalid
 	config *sarama.Config // default nil
@@ -91,6 +92,13 @@ func SyncProducerWithTLS(certFile, keyFile, caFile string, isSkipVerify bool) Sy
 	}
 }
 
+// SyncProducerWithEnableTrace enable trace, the trace context is injected into the message headers.
+func SyncProducerWithEnableTrace() SyncProducerOption {
+	return func(o *syncProducerOptions) {
+		o.enableTrace = true
+	}
+}
+
 // SyncProducerWithConfig set custom config.
 func SyncProducerWithConfig(config *sarama.Config) SyncProducerOption {
 	return func(o *syncProducerOptions) {
@@ -116,6 +124,7 @@ type asyncProducerOptions struct {
 	flushFrequency  time.Duration                 // default 2 second
 	flushBytes      int                           // default 0
 	tlsConfig       *tls.Config
+	enableTrace     bool // default false
 
 	// custom config, if not nil, it will override the default config, the above parameters are invalid
 	config *sarama.Config // default nil
@@ -228,6 +237,13 @@ func AsyncProducerWithHandleFailed(handleFailedFn AsyncSendFailedHandlerFn) Asyn
 	}
 }
 
+// AsyncProducerWithEnableTrace enable trace, the trace context is injected into the message headers.
+func AsyncProducerWithEnableTrace() AsyncProducerOption {
+	return func(o *asyncProducerOptions) {
+		o.enableTrace = true
+	}
+}
+
 // AsyncProducerWithConfig set custom config.
 func AsyncProducerWithConfig(config *sarama.Config) AsyncProducerOption {
 	return func(o *asyncProducerOptions) {
@@ -0,0 +1,191 @@
+package kafka
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/18721889353/sunshine/pkg/kafka"
+
+// ProducerMessageCarrier adapts the headers of sarama.ProducerMessage to propagation.TextMapCarrier.
+type ProducerMessageCarrier struct {
+	msg *sarama.ProducerMessage
+}
+
+var _ propagation.TextMapCarrier = (*ProducerMessageCarrier)(nil)
+
+// NewProducerMessageCarrier creates a carrier of producer message.
+func NewProducerMessageCarrier(msg *sarama.ProducerMessage) *ProducerMessageCarrier {
+	return &ProducerMessageCarrier{msg: msg}
+}
+
+// Get returns the value associated with the passed key.
+func (c *ProducerMessageCarrier) Get(key string) string {
+	for _, h := range c.msg.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// Set stores the key-value pair, the existing key is overwritten.
+func (c *ProducerMessageCarrier) Set(key string, value string) {
+	for i := 0; i < len(c.msg.Headers); i++ {
+		if string(c.msg.Headers[i].Key) == key {
+			c.msg.Headers = append(c.msg.Headers[:i], c.msg.Headers[i+1:]...)
+			i--
+		}
+	}
+	c.msg.Headers = append(c.msg.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+}
+
+// Keys lists the keys stored in this carrier.
+func (c *ProducerMessageCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.msg.Headers))
+	for _, h := range c.msg.Headers {
+		keys = append(keys, string(h.Key))
+	}
+	return keys
+}
+
+// ConsumerMessageCarrier adapts the headers of sarama.ConsumerMessage to propagation.TextMapCarrier.
+type ConsumerMessageCarrier struct {
+	msg *sarama.ConsumerMessage
+}
+
+var _ propagation.TextMapCarrier = (*ConsumerMessageCarrier)(nil)
+
+// NewConsumerMessageCarrier creates a carrier of consumer message.
+func NewConsumerMessageCarrier(msg *sarama.ConsumerMessage) *ConsumerMessageCarrier {
+	return &ConsumerMessageCarrier{msg: msg}
+}
+
+// Get returns the value associated with the passed key.
+func (c *ConsumerMessageCarrier) Get(key string) string {
+	for _, h := range c.msg.Headers {
+		if h != nil && string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// Set stores the key-value pair, the existing key is overwritten.
+func (c *ConsumerMessageCarrier) Set(key string, value string) {
+	for i := 0; i < len(c.msg.Headers); i++ {
+		if c.msg.Headers[i] != nil && string(c.msg.Headers[i].Key) == key {
+			c.msg.Headers = append(c.msg.Headers[:i], c.msg.Headers[i+1:]...)
+			i--
+		}
+	}
+	c.msg.Headers = append(c.msg.Headers, &sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+}
+
+// Keys lists the keys stored in this carrier.
+func (c *ConsumerMessageCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.msg.Headers))
+	for _, h := range c.msg.Headers {
+		if h != nil {
+			keys = append(keys, string(h.Key))
+		}
+	}
+	return keys
+}
+
+// ExtractContext returns a context with the trace context carried by the message headers,
+// if tracing is enabled in consumer, the parent is the span of processing the message.
+func ExtractContext(ctx context.Context, msg *sarama.ConsumerMessage) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, NewConsumerMessageCarrier(msg))
+}
+
+// start a producer span and inject the trace context into the message headers
+func startProducerSpan(ctx context.Context, msg *sarama.ProducerMessage) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKafka,
+		semconv.MessagingOperationTypePublish,
+		semconv.MessagingOperationName("publish"),
+		semconv.MessagingDestinationName(msg.Topic),
+	}
+	if msg.Key != nil {
+		if key, err := msg.Key.Encode(); err == nil {
+			attrs = append(attrs, semconv.MessagingKafkaMessageKey(string(key)))
+		}
+	}
+	if msg.Value != nil {
+		attrs = append(attrs, semconv.MessagingMessageBodySize(msg.Value.Length()))
+	}
+
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, msg.Topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attrs...),
+	)
+	otel.GetTextMapPropagator().Inject(ctx, NewProducerMessageCarrier(msg))
+	return ctx, span
+}
+
+func endProducerSpan(span trace.Span, partition int32, offset int64, err error) {
+	if err == nil {
+		span.SetAttributes(
+			semconv.MessagingDestinationPartitionID(strconv.Itoa(int(partition))),
+			semconv.MessagingKafkaMessageOffset(int(offset)),
+		)
+	}
+	endSpan(span, err)
+}
+
+// extract the trace context from the message headers, start a consumer span as its child,
+// and inject the consumer span into the message headers, so that ExtractContext gets it.
+func startConsumerSpan(ctx context.Context, groupID string, msg *sarama.ConsumerMessage) (context.Context, trace.Span) {
+	carrier := NewConsumerMessageCarrier(msg)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKafka,
+		semconv.MessagingOperationTypeDeliver,
+		semconv.MessagingOperationName("process"),
+		semconv.MessagingDestinationName(msg.Topic),
+		semconv.MessagingDestinationPartitionID(strconv.Itoa(int(msg.Partition))),
+		semconv.MessagingKafkaMessageOffset(int(msg.Offset)),
+		semconv.MessagingMessageBodySize(len(msg.Value)),
+	}
+	if len(msg.Key) > 0 {
+		attrs = append(attrs, semconv.MessagingKafkaMessageKey(string(msg.Key)))
+	}
+	if groupID != "" {
+		attrs = append(attrs, semconv.MessagingKafkaConsumerGroup(groupID))
+	}
+
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, msg.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attrs...),
+	)
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return ctx, span
+}
+
+// wrap the handler with a span of processing the message
+func traceHandleMessageFn(groupID string, handleFn HandleMessageFn) HandleMessageFn {
+	return func(msg *sarama.ConsumerMessage) error {
+		_, span := startConsumerSpan(context.Background(), groupID, msg)
+		err := handleFn(msg)
+		endSpan(span, err)
+		return err
+	}
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
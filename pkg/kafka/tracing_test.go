@@ -0,0 +1,131 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setTestTracer(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	tp, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(tp)
+		otel.SetTextMapPropagator(propagator)
+	})
+	return recorder
+}
+
+func spanAttr(span sdkTrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes() {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestSyncProducer_SendMessageContext(t *testing.T) {
+	recorder := setTestTracer(t)
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+
+	mockProducer := mocks.NewSyncProducer(t, nil)
+	mockProducer.ExpectSendMessageAndSucceed()
+	mockProducer.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+	p := &SyncProducer{Producer: mockProducer, enableTrace: true}
+	defer p.Close()
+
+	msg := &sarama.ProducerMessage{Topic: testTopic, Key: sarama.StringEncoder("foo"), Value: sarama.StringEncoder("hello")}
+	_, _, err := p.SendMessageContext(ctx, msg)
+	require.NoError(t, err)
+	carrier := NewProducerMessageCarrier(msg)
+	assert.Contains(t, carrier.Keys(), "traceparent")
+
+	_, _, err = p.SendDataContext(ctx, testTopic, "hello")
+	assert.Error(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, testTopic+" publish", spans[0].Name())
+	assert.Equal(t, trace.SpanKindProducer, spans[0].SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, "kafka", spanAttr(spans[0], "messaging.system").AsString())
+	assert.Equal(t, testTopic, spanAttr(spans[0], "messaging.destination.name").AsString())
+	assert.Equal(t, "foo", spanAttr(spans[0], "messaging.kafka.message.key").AsString())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+
+	// the injected trace context is the span of publishing
+	extracted := trace.SpanContextFromContext(otel.GetTextMapPropagator().Extract(context.Background(), carrier))
+	assert.Equal(t, spans[0].SpanContext().SpanID(), extracted.SpanID())
+}
+
+func TestAsyncProducer_SendMessageContext(t *testing.T) {
+	recorder := setTestTracer(t)
+
+	config := mocks.NewTestConfig()
+	config.Producer.Return.Successes = true
+	mockProducer := mocks.NewAsyncProducer(t, config)
+	mockProducer.ExpectInputAndSucceed()
+	p := &AsyncProducer{Producer: mockProducer, exit: make(chan struct{}), enableTrace: true}
+
+	err := p.SendDataContext(context.Background(), testTopic, []byte("hello"))
+	assert.NoError(t, err)
+	<-mockProducer.Successes()
+	_ = p.Close()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, testTopic+" publish", spans[0].Name())
+	assert.Equal(t, int64(5), spanAttr(spans[0], "messaging.message.body.size").AsInt64())
+}
+
+func TestTraceHandleMessageFn(t *testing.T) {
+	recorder := setTestTracer(t)
+
+	// message sent by a producer with trace
+	producerMsg := &sarama.ProducerMessage{Topic: testTopic, Value: sarama.StringEncoder("hello")}
+	_, producerSpan := startProducerSpan(context.Background(), producerMsg)
+	endProducerSpan(producerSpan, 1, 10, nil)
+
+	msg := &sarama.ConsumerMessage{Topic: testTopic, Partition: 1, Offset: 10, Value: []byte("hello")}
+	for _, h := range producerMsg.Headers {
+		h := h
+		msg.Headers = append(msg.Headers, &h)
+	}
+
+	var handlerSpanCtx trace.SpanContext
+	handleFn := traceHandleMessageFn("my-group", func(msg *sarama.ConsumerMessage) error {
+		handlerSpanCtx = trace.SpanContextFromContext(ExtractContext(context.Background(), msg))
+		return errors.New("handle error")
+	})
+	assert.Error(t, handleFn(msg))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	consumerSpan := spans[1]
+	assert.Equal(t, testTopic+" process", consumerSpan.Name())
+	assert.Equal(t, trace.SpanKindConsumer, consumerSpan.SpanKind())
+	assert.Equal(t, spans[0].SpanContext().TraceID(), consumerSpan.SpanContext().TraceID())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), consumerSpan.Parent().SpanID())
+	assert.Equal(t, consumerSpan.SpanContext().SpanID(), handlerSpanCtx.SpanID())
+	assert.Equal(t, "my-group", spanAttr(consumerSpan, "messaging.kafka.consumer.group").AsString())
+	assert.Equal(t, codes.Error, consumerSpan.Status().Code)
+
+	// the traceparent header is overwritten, not appended
+	assert.Len(t, NewConsumerMessageCarrier(msg).Keys(), 1)
+}
@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// TraceIDKey field name of trace id
+	TraceIDKey = "trace_id"
+	// SpanIDKey field name of span id
+	SpanIDKey = "span_id"
+)
+
+var contextRequestIDKey atomic.Value
+
+func init() {
+	contextRequestIDKey.Store("request_id")
+}
+
+// SetContextRequestIDKey set the key of request id in context and grpc metadata, default is request_id,
+// it should be the same as the key used by the request id middleware or interceptor.
+func SetContextRequestIDKey(key string) {
+	if key != "" {
+		contextRequestIDKey.Store(key)
+	}
+}
+
+func getContextRequestIDKey() string {
+	return contextRequestIDKey.Load().(string)
+}
+
+// ContextFields returns the trace id, span id and request id in ctx as log fields,
+// the trace comes from opentelemetry span, the request id comes from the value of ctx
+// (e.g. gin.Context or the context wrapped by middleware.WrapCtx) or the incoming grpc metadata.
+func ContextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+
+	fields := TraceFields(ctx)
+	key := getContextRequestIDKey()
+	if requestID, ok := ctx.Value(key).(string); ok && requestID != "" { //nolint
+		fields = append(fields, zap.String(key, requestID))
+	} else if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) > 0 && values[0] != "" {
+			fields = append(fields, zap.String(key, values[0]))
+		}
+	}
+
+	return fields
+}
+
+// TraceFields returns the trace id and span id of opentelemetry span in ctx as log fields.
+func TraceFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return []Field{zap.String(TraceIDKey, sc.TraceID().String()), zap.String(SpanIDKey, sc.SpanID().String())}
+	}
+	return nil
+}
+
+// WithContext returns a logger that carries the trace id, span id and request id in ctx, e.g.
+// logger.WithContext(ctx).Info("create user", logger.String("name", name))
+func WithContext(ctx context.Context) *zap.Logger {
+	fields := ContextFields(ctx)
+	if len(fields) == 0 {
+		return Get()
+	}
+	return Get().With(fields...)
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestContextFields(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	fields := ContextFields(ctx)
+	assert.Len(t, fields, 2)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", fields[0].String)
+	assert.Equal(t, "00f067aa0ba902b7", fields[1].String)
+
+	ctx = context.WithValue(ctx, "request_id", "abc") //nolint
+	fields = ContextFields(ctx)
+	assert.Len(t, fields, 3)
+	assert.Equal(t, "abc", fields[2].String)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("request_id", "xyz"))
+	fields = ContextFields(ctx)
+	assert.Len(t, fields, 1)
+	assert.Equal(t, "xyz", fields[0].String)
+
+	SetContextRequestIDKey("x_request_id")
+	assert.Empty(t, ContextFields(ctx))
+	SetContextRequestIDKey("request_id")
+
+	assert.Empty(t, ContextFields(context.Background()))
+	assert.Empty(t, ContextFields(nil)) //nolint
+	assert.Empty(t, TraceFields(nil))   //nolint
+}
+
+func TestWithContext(t *testing.T) {
+	var entries []zapcore.Entry
+	_, err := Init(WithHooks(func(entry zapcore.Entry) error {
+		entries = append(entries, entry)
+		return nil
+	}))
+	assert.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("request_id", "xyz"))
+	WithContext(ctx).Info("with request id")
+	WithContext(context.Background()).Info("without request id")
+	assert.Equal(t, "without request id", entries[len(entries)-1].Message)
+}
@@ -0,0 +1,234 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// level of the default logger, can be changed at runtime
+var atomicLevel = zap.NewAtomicLevel()
+
+var namedLevels = &levelOverrides{levels: make(map[string]zapcore.Level)}
+
+// levelOverrides the levels of named loggers, a level applies to the named logger and its children,
+// e.g. the level of "kafka" applies to "kafka.consumer".
+type levelOverrides struct {
+	mu     sync.RWMutex
+	levels map[string]zapcore.Level
+	size   int32 // number of overrides, avoid locking if there is no override
+	min    int32 // min level of overrides
+}
+
+func (o *levelOverrides) set(name string, level zapcore.Level) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.levels[name] = level
+	o.refresh()
+}
+
+func (o *levelOverrides) remove(name string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.levels, name)
+	o.refresh()
+}
+
+func (o *levelOverrides) clear() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.levels = make(map[string]zapcore.Level)
+	o.refresh()
+}
+
+func (o *levelOverrides) refresh() {
+	minLevel := zapcore.FatalLevel
+	for _, l := range o.levels {
+		if l < minLevel {
+			minLevel = l
+		}
+	}
+	atomic.StoreInt32(&o.min, int32(minLevel))
+	atomic.StoreInt32(&o.size, int32(len(o.levels)))
+}
+
+// get the level of the named logger, ok is false if there is no override
+func (o *levelOverrides) get(name string) (zapcore.Level, bool) {
+	if atomic.LoadInt32(&o.size) == 0 || name == "" {
+		return 0, false
+	}
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	for {
+		if l, ok := o.levels[name]; ok {
+			return l, true
+		}
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			return 0, false
+		}
+		name = name[:i]
+	}
+}
+
+func (o *levelOverrides) all() map[string]string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	m := make(map[string]string, len(o.levels))
+	for name, l := range o.levels {
+		m[name] = l.String()
+	}
+	return m
+}
+
+// levelCore decides whether an entry is logged by the level of its logger name, the wrapped core must enable all levels
+type levelCore struct {
+	zapcore.Core
+}
+
+func newLevelCore(core zapcore.Core) zapcore.Core {
+	return &levelCore{Core: core}
+}
+
+func (c *levelCore) Enabled(l zapcore.Level) bool {
+	if atomicLevel.Enabled(l) {
+		return true
+	}
+	return atomic.LoadInt32(&namedLevels.size) > 0 && l >= zapcore.Level(atomic.LoadInt32(&namedLevels.min))
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields)}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	level, ok := namedLevels.get(ent.LoggerName)
+	if !ok {
+		level = atomicLevel.Level()
+	}
+	if ent.Level < level {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+func parseLevel(levelName string) (zapcore.Level, error) {
+	switch strings.ToUpper(levelName) {
+	case levelDebug, levelInfo, levelWarn, levelError:
+		return getLevelSize(levelName), nil
+	}
+	return 0, fmt.Errorf("unsupported log level '%s', supported levels are debug, info, warn, error", levelName)
+}
+
+// SetLevel change the log level of the default logger at runtime, levels are debug, info, warn, error.
+func SetLevel(levelName string) error {
+	level, err := parseLevel(levelName)
+	if err != nil {
+		return err
+	}
+	atomicLevel.SetLevel(level)
+	return nil
+}
+
+// GetLevel returns the log level of the default logger.
+func GetLevel() string {
+	return atomicLevel.Level().String()
+}
+
+// SetNamedLevel set the log level of the named logger and its children, e.g. the level of "kafka"
+// applies to Named("kafka") and Named("kafka").Named("consumer"), if levelName is empty, the override is removed.
+func SetNamedLevel(name string, levelName string) error {
+	if name == "" {
+		return SetLevel(levelName)
+	}
+	if levelName == "" {
+		namedLevels.remove(name)
+		return nil
+	}
+	level, err := parseLevel(levelName)
+	if err != nil {
+		return err
+	}
+	namedLevels.set(name, level)
+	return nil
+}
+
+// GetNamedLevels returns the log levels of named loggers.
+func GetNamedLevels() map[string]string {
+	return namedLevels.all()
+}
+
+// ResetNamedLevels remove the log levels of all named loggers, they use the level of the default logger.
+func ResetNamedLevels() {
+	namedLevels.clear()
+}
+
+// Named returns a named logger, its level can be set by SetNamedLevel.
+func Named(name string) *zap.Logger {
+	return Get().Named(name)
+}
+
+// LevelHandler returns the http handler of log level,
+// GET returns the levels, e.g. {"level":"info","namedLevels":{"kafka":"debug"}},
+// PUT or POST changes the level, the body is {"level":"debug"}, or {"name":"kafka","level":"debug"} for a named logger,
+// or use query parameters ?level=debug&name=kafka,
+// DELETE removes the level of the named logger by ?name=kafka, or the levels of all named loggers if name is empty.
+func LevelHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			form := struct {
+				Name  string `json:"name"`
+				Level string `json:"level"`
+			}{
+				Name:  r.URL.Query().Get("name"),
+				Level: r.URL.Query().Get("level"),
+			}
+			if form.Level == "" && r.Body != nil {
+				if err := json.NewDecoder(r.Body).Decode(&form); err != nil {
+					writeLevelJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid body: " + err.Error()})
+					return
+				}
+			}
+			if form.Name == "" && form.Level == "" {
+				writeLevelJSON(w, http.StatusBadRequest, map[string]string{"error": "level is empty"})
+				return
+			}
+			if err := SetNamedLevel(form.Name, form.Level); err != nil {
+				writeLevelJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			Info("log level is changed", String("name", form.Name), String("level", form.Level))
+		case http.MethodDelete:
+			name := r.URL.Query().Get("name")
+			if name == "" {
+				ResetNamedLevels()
+			} else {
+				namedLevels.remove(name)
+			}
+			Info("log level of named logger is reset", String("name", name))
+		default:
+			writeLevelJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		writeLevelJSON(w, http.StatusOK, map[string]interface{}{
+			"level":       GetLevel(),
+			"namedLevels": GetNamedLevels(),
+		})
+	}
+}
+
+func writeLevelJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
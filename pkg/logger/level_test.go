@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSetNamedLevel(t *testing.T) {
+	var count int32
+	_, err := Init(
+		WithLevel("warn"),
+		WithNamedLevels(map[string]string{"kafka": "debug"}),
+		WithHooks(func(zapcore.Entry) error {
+			atomic.AddInt32(&count, 1)
+			return nil
+		}),
+	)
+	assert.NoError(t, err)
+	defer func() {
+		_ = SetNamedLevel("kafka", "")
+		_ = SetLevel("debug")
+	}()
+
+	atomic.StoreInt32(&count, 0)
+	Get().Info("ignored")
+	Named("kafka").Debug("logged")
+	Named("kafka").Named("consumer").Debug("logged")
+	Named("redis").Info("ignored")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+	assert.True(t, Get().Core().Enabled(zapcore.DebugLevel))
+	assert.Equal(t, map[string]string{"kafka": "debug"}, GetNamedLevels())
+
+	assert.NoError(t, SetNamedLevel("kafka.consumer", "error"))
+	atomic.StoreInt32(&count, 0)
+	Named("kafka").Named("consumer").Warn("ignored")
+	Named("kafka").Named("producer").Debug("logged")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+
+	assert.NoError(t, SetNamedLevel("kafka.consumer", ""))
+	assert.NoError(t, SetNamedLevel("kafka", ""))
+	assert.Empty(t, GetNamedLevels())
+	assert.False(t, Get().Core().Enabled(zapcore.DebugLevel))
+
+	assert.Error(t, SetNamedLevel("kafka", "foo"))
+	assert.NoError(t, SetNamedLevel("", "info"))
+	assert.Equal(t, "info", GetLevel())
+}
+
+func TestWithSampling(t *testing.T) {
+	var count int32
+	_, err := Init(
+		WithLevel("debug"),
+		WithSampling(2, 5),
+		WithHooks(func(zapcore.Entry) error {
+			atomic.AddInt32(&count, 1)
+			return nil
+		}),
+	)
+	assert.NoError(t, err)
+
+	atomic.StoreInt32(&count, 0)
+	for i := 0; i < 12; i++ {
+		Get().Debug("sampling message")
+	}
+	// the 1st, 2nd, 7th and 12th entries are logged
+	assert.Equal(t, int32(4), atomic.LoadInt32(&count))
+
+	_, err = Init(WithSampling(0, 0))
+	assert.NoError(t, err)
+}
+
+func TestLevelHandler(t *testing.T) {
+	_, err := Init(WithLevel("info"))
+	assert.NoError(t, err)
+	defer func() {
+		_ = SetNamedLevel("kafka", "")
+		_ = SetLevel("debug")
+	}()
+	handler := LevelHandler()
+
+	do := func(method string, url string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(method, url, strings.NewReader(body)))
+		return w
+	}
+
+	w := do(http.MethodGet, "/loglevel", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"level":"info"`)
+
+	w = do(http.MethodPut, "/loglevel", `{"level":"warn"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "warn", GetLevel())
+
+	w = do(http.MethodPost, "/loglevel?name=kafka&level=debug", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"namedLevels":{"kafka":"debug"}`)
+
+	w = do(http.MethodPut, "/loglevel", `{"name":"kafka","level":"foo"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = do(http.MethodPut, "/loglevel", `foo`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// reset the named levels
+	w = do(http.MethodPost, "/loglevel?name=redis&level=error", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = do(http.MethodDelete, "/loglevel?name=kafka", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, map[string]string{"redis": "error"}, GetNamedLevels())
+	w = do(http.MethodDelete, "/loglevel", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, GetNamedLevels())
+
+	w = do(http.MethodPatch, "/loglevel", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
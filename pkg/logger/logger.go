@@ -65,17 +65,25 @@ func Init(opts ...Option) (*zap.Logger, error) {
 	levelName := o.level
 	encoding := o.encoding
 
+	for name, level := range o.namedLevels {
+		if err := SetNamedLevel(name, level); err != nil {
+			return nil, err
+		}
+	}
+
+	setSinks(o.sinks)
+
 	var err error
 	var zapLog *zap.Logger
 	var str string
 	if !isSave {
-		zapLog, err = log2Terminal(levelName, encoding)
+		zapLog, err = log2Terminal(levelName, encoding, o.wrapCore)
 		if err != nil {
 			panic(err)
 		}
 		str = fmt.Sprintf("initialize logger finish, config is output to 'terminal', format=%s, level=%s", encoding, levelName)
 	} else {
-		zapLog = log2File(encoding, levelName, o.fileConfig)
+		zapLog = log2File(encoding, levelName, o.fileConfig, o.wrapCore)
 		str = fmt.Sprintf("initialize logger finish, config is output to 'file', format=%s, level=%s, file=%s", encoding, levelName, o.fileConfig.filename)
 	}
 
@@ -90,7 +98,7 @@ func Init(opts ...Option) (*zap.Logger, error) {
 	return defaultLogger, err
 }
 
-func log2Terminal(levelName string, encoding string) (*zap.Logger, error) {
+func log2Terminal(levelName string, encoding string, wrapCore func(zapcore.Core) zapcore.Core) (*zap.Logger, error) {
 	js := fmt.Sprintf(`{
       		"level": "%s",
             "encoding": "%s",
@@ -111,10 +119,12 @@ func log2Terminal(levelName string, encoding string) (*zap.Logger, error) {
 		config.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder // logging levels in the log file using upper case letters
 	}
 	config.EncoderConfig.EncodeTime = timeFormatter // default time format
-	return config.Build()
+	atomicLevel.SetLevel(config.Level.Level())
+	config.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel) // the level is decided by levelCore
+	return config.Build(zap.WrapCore(wrapCore))
 }
 
-func log2File(encoding string, levelName string, fo *fileOptions) *zap.Logger {
+func log2File(encoding string, levelName string, fo *fileOptions, wrapCore func(zapcore.Core) zapcore.Core) *zap.Logger {
 	encoderConfig := zap.NewProductionEncoderConfig()
 	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder   // modify Time Encoder
 	encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder // logging levels in the log file using upper case letters
@@ -132,7 +142,8 @@ func log2File(encoding string, levelName string, fo *fileOptions) *zap.Logger {
 		MaxAge:     fo.maxAge,        // maximum number of days for old documents
 		Compress:   fo.isCompression, // whether to compress and archive old files
 	})
-	core := zapcore.NewCore(encoder, ws, getLevelSize(levelName))
+	atomicLevel.SetLevel(getLevelSize(levelName))
+	core := wrapCore(zapcore.NewCore(encoder, ws, zapcore.DebugLevel))
 
 	// add the function call information log to the log.
 	return zap.New(core, zap.AddCaller())
@@ -7,7 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func printInfo() {
@@ -146,3 +148,33 @@ func Test_getLevelSize(t *testing.T) {
 	_ = GetWithSkip(5)
 	_ = Get()
 }
+
+func TestSetLevel(t *testing.T) {
+	_, err := Init(WithLevel("info"))
+	assert.NoError(t, err)
+	assert.Equal(t, "info", GetLevel())
+	assert.False(t, Get().Core().Enabled(zapcore.DebugLevel))
+
+	assert.NoError(t, SetLevel("debug"))
+	assert.Equal(t, "debug", GetLevel())
+	assert.True(t, Get().Core().Enabled(zapcore.DebugLevel))
+
+	assert.Error(t, SetLevel("foo"))
+	assert.Equal(t, "debug", GetLevel())
+}
+
+func TestWithCores(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	_, err := Init(WithLevel("info"), WithCores(core))
+	assert.NoError(t, err)
+	defer func() { _, _ = Init() }()
+
+	Debug("this is debug")
+	Info("this is info", String("foo", "bar"))
+	Infof("this is %s", "infof")
+	entries := logs.FilterMessageSnippet("this is info").All()
+	assert.Len(t, entries, 2)
+	assert.Contains(t, entries[0].Message, `"foo":"bar"`)
+	assert.Equal(t, "this is infof", entries[1].Message)
+	assert.Equal(t, 0, logs.FilterMessageSnippet("this is debug").Len())
+}
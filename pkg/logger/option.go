@@ -2,6 +2,7 @@ package logger
 
 import (
 	"strings"
+	"time"
 
 	"go.uber.org/zap/zapcore"
 )
@@ -27,6 +28,14 @@ type options struct {
 	fileConfig *fileOptions
 
 	hooks []func(zapcore.Entry) error
+
+	namedLevels map[string]string
+
+	samplingFirst      int
+	samplingThereafter int
+
+	sinks []*AsyncSink
+	cores []zapcore.Core
 }
 
 func defaultOptions() *options {
@@ -87,6 +96,47 @@ func WithHooks(hooks ...func(zapcore.Entry) error) Option {
 	}
 }
 
+// WithCores set the cores that the logs are also written to, in addition to the terminal or file,
+// the level is decided by the logger, e.g. the observer core of zaptest in tests.
+func WithCores(cores ...zapcore.Core) Option {
+	return func(o *options) {
+		o.cores = cores
+	}
+}
+
+// WithNamedLevels set the log levels of named loggers, key is the name of logger, e.g. {"kafka": "warn"},
+// the level applies to the named logger and its children, see Named.
+func WithNamedLevels(levels map[string]string) Option {
+	return func(o *options) {
+		o.namedLevels = levels
+	}
+}
+
+// WithSampling set the log sampling, in every second, log the first N entries with the same level and message,
+// then log every Mth entry after that, first or thereafter less than or equal to 0 means no sampling.
+func WithSampling(first int, thereafter int) Option {
+	return func(o *options) {
+		o.samplingFirst = first
+		o.samplingThereafter = thereafter
+	}
+}
+
+// wrapCore the logs are also written to sinks and cores, the level of core is controlled by levelCore, sampling is optional
+func (o *options) wrapCore(core zapcore.Core) zapcore.Core {
+	if len(o.sinks) > 0 || len(o.cores) > 0 {
+		cores := []zapcore.Core{core}
+		for _, sink := range o.sinks {
+			cores = append(cores, newSinkCore(sink))
+		}
+		cores = append(cores, o.cores...)
+		core = zapcore.NewTee(cores...)
+	}
+	if o.samplingFirst > 0 && o.samplingThereafter > 0 {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, o.samplingFirst, o.samplingThereafter)
+	}
+	return newLevelCore(core)
+}
+
 // ------------------------------------------------------------------------------------------
 
 type fileOptions struct {
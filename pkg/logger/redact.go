@@ -0,0 +1,286 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/18721889353/sunshine/pkg/utils"
+)
+
+const defaultRedactMask = "******"
+
+var (
+	// the sensitive fields are masked at any depth of json, case-insensitive, the same fields are masked by conf.Show
+	defaultRedactFields = utils.SensitiveFields()
+	// the password in dsn or url, e.g. root:123456@(127.0.0.1:3306) --> root:******@(127.0.0.1:3306)
+	defaultRedactPatterns = []string{
+		`[\w.-]+:([^:@/\s"]+)@`,
+	}
+
+	defaultRedactor = NewRedactor()
+)
+
+// DefaultRedactor returns the redactor with the default sensitive fields and patterns.
+func DefaultRedactor() *Redactor {
+	return defaultRedactor
+}
+
+// RedactOption set the redactor options.
+type RedactOption func(*redactOptions)
+
+type redactOptions struct {
+	fields   []string
+	paths    []string
+	patterns []string
+	mask     string
+}
+
+func defaultRedactOptions() *redactOptions {
+	return &redactOptions{
+		fields:   append([]string{}, defaultRedactFields...),
+		patterns: append([]string{}, defaultRedactPatterns...),
+		mask:     defaultRedactMask,
+	}
+}
+
+func (o *redactOptions) apply(opts ...RedactOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithRedactFields add the field names that are masked at any depth of json, case-insensitive, e.g. "phone", "idCard".
+func WithRedactFields(fields ...string) RedactOption {
+	return func(o *redactOptions) {
+		o.fields = append(o.fields, fields...)
+	}
+}
+
+// WithRedactPaths add the json paths that are masked, the segments are separated by dot, * matches any key,
+// arrays are traversed automatically, e.g. "$.user.phone", "data.list.*.cardNo".
+func WithRedactPaths(paths ...string) RedactOption {
+	return func(o *redactOptions) {
+		o.paths = append(o.paths, paths...)
+	}
+}
+
+// WithRedactPatterns add the regular expressions that are masked, if the expression has a capturing group,
+// only the first group is masked, otherwise the whole match is masked, e.g. `(?i)bearer\s+(\S+)`,
+// it panics if the expression is invalid.
+func WithRedactPatterns(patterns ...string) RedactOption {
+	return func(o *redactOptions) {
+		o.patterns = append(o.patterns, patterns...)
+	}
+}
+
+// WithRedactMask set the mask of sensitive value, default is ******.
+func WithRedactMask(mask string) RedactOption {
+	return func(o *redactOptions) {
+		if mask != "" {
+			o.mask = mask
+		}
+	}
+}
+
+// WithoutRedactDefaults do not use the default sensitive fields and patterns.
+func WithoutRedactDefaults() RedactOption {
+	return func(o *redactOptions) {
+		o.fields = nil
+		o.patterns = nil
+	}
+}
+
+// Redactor masks the sensitive values in the logged content, e.g. request and response body,
+// the json content is masked by field names and json paths, and all content is masked by regular expressions.
+type Redactor struct {
+	fields   map[string]struct{}
+	paths    [][]string
+	patterns []*regexp.Regexp
+	mask     string
+	keywords [][]byte // lower case keys of fields and paths, skip parsing json if none is contained
+}
+
+// NewRedactor create a redactor, the default sensitive fields are password, pwd, passwd, secret, token,
+// accessToken, refreshToken, authorization and dsn, the default pattern masks the password in dsn.
+func NewRedactor(opts ...RedactOption) *Redactor {
+	o := defaultRedactOptions()
+	o.apply(opts...)
+
+	r := &Redactor{fields: make(map[string]struct{}), mask: o.mask}
+	wildcard := false
+	for _, field := range o.fields {
+		field = strings.ToLower(field)
+		r.fields[field] = struct{}{}
+		r.keywords = append(r.keywords, []byte(`"`+field+`"`))
+	}
+	for _, path := range o.paths {
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+		if path == "" {
+			continue
+		}
+		segments := strings.Split(path, ".")
+		r.paths = append(r.paths, segments)
+		last := segments[len(segments)-1]
+		if last == "*" {
+			wildcard = true
+		} else {
+			r.keywords = append(r.keywords, []byte(`"`+strings.ToLower(last)+`"`))
+		}
+	}
+	if wildcard {
+		r.keywords = nil // always parse
+	}
+	for _, pattern := range o.patterns {
+		r.patterns = append(r.patterns, regexp.MustCompile(pattern))
+	}
+	// the sensitive fields in url query or form, e.g. ?token=xxx&name=foo --> ?token=******&name=foo
+	if len(o.fields) > 0 {
+		names := make([]string, 0, len(o.fields))
+		for _, field := range o.fields {
+			names = append(names, regexp.QuoteMeta(field))
+		}
+		r.patterns = append(r.patterns, regexp.MustCompile(`(?i)(?:^|[?&\s])(?:`+strings.Join(names, "|")+`)=([^&\s"]+)`))
+	}
+	return r
+}
+
+// Redact returns the content with the sensitive values masked, the content is returned as it is if nothing is masked.
+func (r *Redactor) Redact(data []byte) []byte {
+	if r == nil || len(data) == 0 {
+		return data
+	}
+	data = r.redactJSON(data)
+	for _, re := range r.patterns {
+		data = r.redactPattern(re, data)
+	}
+	return data
+}
+
+// RedactString returns the string with the sensitive values masked.
+func (r *Redactor) RedactString(s string) string {
+	return string(r.Redact([]byte(s)))
+}
+
+// RedactAny marshal v to json and returns the masked json.
+func (r *Redactor) RedactAny(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(err.Error())
+		return data
+	}
+	return r.Redact(data)
+}
+
+func (r *Redactor) redactJSON(data []byte) []byte {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return data
+	}
+	if len(r.fields) == 0 && len(r.paths) == 0 {
+		return data
+	}
+	if r.keywords != nil && !r.containsKeyword(trimmed) {
+		return data
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return data // not json or truncated
+	}
+
+	changed := false
+	v = r.redactValue(v, &changed)
+	for _, path := range r.paths {
+		v = r.redactPath(v, path, &changed)
+	}
+	if !changed {
+		return data
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return data
+	}
+	// keep the trailing characters, e.g. newline
+	if i := bytes.LastIndexAny(data, "}]"); i >= 0 && i < len(data)-1 {
+		out = append(out, data[i+1:]...)
+	}
+	return out
+}
+
+func (r *Redactor) containsKeyword(data []byte) bool {
+	lower := bytes.ToLower(data)
+	for _, keyword := range r.keywords {
+		if bytes.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// mask the values of sensitive fields at any depth
+func (r *Redactor) redactValue(v interface{}, changed *bool) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			if _, ok := r.fields[strings.ToLower(k)]; ok {
+				val[k] = r.mask
+				*changed = true
+				continue
+			}
+			val[k] = r.redactValue(child, changed)
+		}
+	case []interface{}:
+		for i, child := range val {
+			val[i] = r.redactValue(child, changed)
+		}
+	}
+	return v
+}
+
+// mask the value of json path
+func (r *Redactor) redactPath(v interface{}, path []string, changed *bool) interface{} {
+	switch val := v.(type) {
+	case []interface{}:
+		for i, child := range val {
+			val[i] = r.redactPath(child, path, changed)
+		}
+	case map[string]interface{}:
+		for k, child := range val {
+			if path[0] != "*" && !strings.EqualFold(path[0], k) {
+				continue
+			}
+			if len(path) == 1 {
+				val[k] = r.mask
+				*changed = true
+			} else {
+				val[k] = r.redactPath(child, path[1:], changed)
+			}
+		}
+	}
+	return v
+}
+
+func (r *Redactor) redactPattern(re *regexp.Regexp, data []byte) []byte {
+	matches := re.FindAllSubmatchIndex(data, -1)
+	if len(matches) == 0 {
+		return data
+	}
+
+	out := make([]byte, 0, len(data))
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) >= 4 && m[2] >= 0 { // mask the first capturing group
+			start, end = m[2], m[3]
+		}
+		out = append(out, data[last:start]...)
+		out = append(out, r.mask...)
+		last = end
+	}
+	return append(out, data[last:]...)
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	r := DefaultRedactor()
+
+	data := `{"name":"foo","Password":"123456","user":{"accessToken":"abc","age":18},"list":[{"token":"xyz"}]}` + "\n"
+	got := r.RedactString(data)
+	assert.Equal(t, `{"Password":"******","list":[{"token":"******"}],"name":"foo","user":{"accessToken":"******","age":18}}`+"\n", got)
+
+	// nothing is masked, returns as it is
+	data = `{"name": "foo", "age": 18.0}`
+	assert.Equal(t, data, r.RedactString(data))
+
+	// not json or truncated json
+	assert.Equal(t, "hello", r.RedactString("hello"))
+	assert.Equal(t, `{"password":"12345 ...... `, r.RedactString(`{"password":"12345 ...... `))
+
+	// dsn and url query
+	assert.Equal(t, `root:******@(127.0.0.1:3306)/account`, r.RedactString(`root:123456@(127.0.0.1:3306)/account`))
+	assert.Equal(t, `/api/v1/user?name=foo&token=******&pwd=******`, r.RedactString(`/api/v1/user?name=foo&token=abc&pwd=123`))
+
+	assert.Empty(t, r.Redact(nil))
+	var nilRedactor *Redactor
+	assert.Equal(t, "password=123", nilRedactor.RedactString("password=123"))
+}
+
+func TestNewRedactor(t *testing.T) {
+	r := NewRedactor(
+		WithRedactFields("phone"),
+		WithRedactPaths("$.data.list.cardNo", "data.*.id"),
+		WithRedactPatterns(`(?i)bearer\s+(\S+)`, `\d{4}-\d{4}`),
+		WithRedactMask("***"),
+	)
+
+	data := `{"phone":"13800000000","data":{"list":[{"cardNo":"6222"},{"cardNo":"6223"}],"user":{"id":1,"name":"foo"}},"password":"123"}`
+	assert.Equal(t, `{"data":{"list":[{"cardNo":"***"},{"cardNo":"***"}],"user":{"id":"***","name":"foo"}},"password":"***","phone":"***"}`, r.RedactString(data))
+	assert.Equal(t, "Authorization: Bearer *** code ***", r.RedactString("Authorization: Bearer abc.def; code 1234-5678"))
+
+	r = NewRedactor(WithoutRedactDefaults(), WithRedactFields("phone"))
+	assert.Equal(t, `{"password":"123","phone":"******"}`, r.RedactString(`{"password":"123","phone":1380}`))
+
+	type user struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}
+	assert.Equal(t, `{"name":"foo","password":"******"}`, string(DefaultRedactor().RedactAny(&user{Name: "foo", Password: "123"})))
+	assert.Equal(t, `"json: unsupported type: chan int"`, string(DefaultRedactor().RedactAny(make(chan int))))
+
+	assert.Panics(t, func() { NewRedactor(WithRedactPatterns(`(`)) })
+}
@@ -0,0 +1,462 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	defaultSinkBufferSize    = 10000       // maximum number of records waiting to be written
+	defaultSinkBatchSize     = 100         // maximum number of records written at a time
+	defaultSinkFlushInterval = time.Second // interval of flushing the buffered records
+
+	// registered sinks of the default logger, used for Sync, CloseSinks and GetSinkStats
+	sinksMu sync.Mutex
+	sinks   []*AsyncSink
+)
+
+// Record an encoded log entry, data is json format without the trailing newline.
+type Record struct {
+	Level zapcore.Level
+	Time  time.Time
+	Data  []byte
+}
+
+// BatchWriter writes a batch of records to the destination, e.g. kafka, syslog, http endpoint.
+type BatchWriter interface {
+	WriteBatch(records []*Record) error
+	Close() error
+}
+
+// BatchError is returned by BatchWriter when only some of the records failed to be written.
+type BatchError struct {
+	Failed int // number of failed records
+	Err    error
+}
+
+func (e *BatchError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// SinkOption set the sink options.
+type SinkOption func(*sinkOptions)
+
+type sinkOptions struct {
+	bufferSize    int
+	batchSize     int
+	flushInterval time.Duration
+
+	headers map[string]string // http headers
+	tag     string            // syslog tag
+}
+
+func defaultSinkOptions() *sinkOptions {
+	return &sinkOptions{
+		bufferSize:    defaultSinkBufferSize,
+		batchSize:     defaultSinkBatchSize,
+		flushInterval: defaultSinkFlushInterval,
+	}
+}
+
+func (o *sinkOptions) apply(opts ...SinkOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithSinkBufferSize set the maximum number of records waiting to be written, the new records are dropped when the buffer is full.
+func WithSinkBufferSize(size int) SinkOption {
+	return func(o *sinkOptions) {
+		if size > 0 {
+			o.bufferSize = size
+		}
+	}
+}
+
+// WithSinkBatchSize set the maximum number of records written at a time.
+func WithSinkBatchSize(size int) SinkOption {
+	return func(o *sinkOptions) {
+		if size > 0 {
+			o.batchSize = size
+		}
+	}
+}
+
+// WithSinkFlushInterval set the interval of flushing the buffered records.
+func WithSinkFlushInterval(d time.Duration) SinkOption {
+	return func(o *sinkOptions) {
+		if d > 0 {
+			o.flushInterval = d
+		}
+	}
+}
+
+// WithSinkHeaders set the request headers of http sink, e.g. Authorization.
+func WithSinkHeaders(headers map[string]string) SinkOption {
+	return func(o *sinkOptions) {
+		o.headers = headers
+	}
+}
+
+// WithSinkTag set the tag(app name) of syslog sink.
+func WithSinkTag(tag string) SinkOption {
+	return func(o *sinkOptions) {
+		o.tag = tag
+	}
+}
+
+// ------------------------------------------------------------------------------------------
+
+// SinkStats statistics of a sink
+type SinkStats struct {
+	Name      string `json:"name"`
+	Buffered  int    `json:"buffered"`
+	Written   int64  `json:"written"`
+	Dropped   int64  `json:"dropped"` // dropped because the buffer is full or the sink is closed
+	Failed    int64  `json:"failed"`  // failed to write to the destination
+	LastError string `json:"lastError,omitempty"`
+}
+
+// AsyncSink writes the log records to the destination asynchronously in batches,
+// the records are buffered in a bounded queue, and flushed when the batch is full,
+// the flush interval is reached or Sync is called.
+type AsyncSink struct {
+	name          string
+	writer        BatchWriter
+	batchSize     int
+	flushInterval time.Duration
+
+	records chan *Record
+	flushCh chan chan error
+	closeCh chan struct{}
+	done    chan struct{}
+	closed  int32
+	once    sync.Once
+
+	written int64
+	dropped int64
+	failed  int64
+	lastErr atomic.Value
+}
+
+// NewAsyncSink create an asynchronous sink that writes records to writer.
+func NewAsyncSink(name string, writer BatchWriter, opts ...SinkOption) *AsyncSink {
+	o := defaultSinkOptions()
+	o.apply(opts...)
+
+	s := &AsyncSink{
+		name:          name,
+		writer:        writer,
+		batchSize:     o.batchSize,
+		flushInterval: o.flushInterval,
+		records:       make(chan *Record, o.bufferSize),
+		flushCh:       make(chan chan error),
+		closeCh:       make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Name of sink
+func (s *AsyncSink) Name() string {
+	return s.name
+}
+
+// Write a record to buffer, it never blocks, the record is dropped if the buffer is full.
+func (s *AsyncSink) Write(r *Record) {
+	if atomic.LoadInt32(&s.closed) == 1 {
+		atomic.AddInt64(&s.dropped, 1)
+		return
+	}
+	select {
+	case s.records <- r:
+	default:
+		atomic.AddInt64(&s.dropped, 1)
+	}
+}
+
+// Sync flush the buffered records, returns the error of writing.
+func (s *AsyncSink) Sync() error {
+	ch := make(chan error, 1)
+	select {
+	case s.flushCh <- ch:
+	case <-s.done:
+		return nil
+	}
+	select {
+	case err := <-ch:
+		return err
+	case <-s.done:
+		return nil
+	}
+}
+
+// Close flush the buffered records and close the writer.
+func (s *AsyncSink) Close() error {
+	var err error
+	s.once.Do(func() {
+		atomic.StoreInt32(&s.closed, 1)
+		close(s.closeCh)
+		<-s.done
+		err = s.writer.Close()
+	})
+	return err
+}
+
+// Stats returns the statistics of sink.
+func (s *AsyncSink) Stats() *SinkStats {
+	stats := &SinkStats{
+		Name:     s.name,
+		Buffered: len(s.records),
+		Written:  atomic.LoadInt64(&s.written),
+		Dropped:  atomic.LoadInt64(&s.dropped),
+		Failed:   atomic.LoadInt64(&s.failed),
+	}
+	if err, ok := s.lastErr.Load().(string); ok {
+		stats.LastError = err
+	}
+	return stats
+}
+
+func (s *AsyncSink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Record, 0, s.batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := s.writer.WriteBatch(batch)
+		if err != nil {
+			failed := len(batch)
+			var batchErr *BatchError
+			if errors.As(err, &batchErr) && batchErr.Failed < failed {
+				failed = batchErr.Failed
+			}
+			atomic.AddInt64(&s.failed, int64(failed))
+			atomic.AddInt64(&s.written, int64(len(batch)-failed))
+			s.lastErr.Store(err.Error())
+		} else {
+			atomic.AddInt64(&s.written, int64(len(batch)))
+		}
+		batch = make([]*Record, 0, s.batchSize)
+		return err
+	}
+	// write all the buffered records
+	drain := func() error {
+		var errs []error
+		for {
+			select {
+			case r := <-s.records:
+				batch = append(batch, r)
+				if len(batch) >= s.batchSize {
+					if err := flush(); err != nil {
+						errs = append(errs, err)
+					}
+				}
+			default:
+				if err := flush(); err != nil {
+					errs = append(errs, err)
+				}
+				return errors.Join(errs...)
+			}
+		}
+	}
+
+	for {
+		select {
+		case r := <-s.records:
+			batch = append(batch, r)
+			if len(batch) >= s.batchSize {
+				_ = flush()
+			}
+		case <-ticker.C:
+			_ = flush()
+		case ch := <-s.flushCh:
+			ch <- drain()
+		case <-s.closeCh:
+			_ = drain()
+			return
+		}
+	}
+}
+
+// ------------------------------------------------------------------------------------------
+
+// sinkCore encodes the entries in json format and writes them to the sink,
+// the level is decided by levelCore, so all levels are enabled here.
+type sinkCore struct {
+	enc  zapcore.Encoder
+	sink *AsyncSink
+}
+
+func newSinkCore(sink *AsyncSink) zapcore.Core {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	return &sinkCore{enc: zapcore.NewJSONEncoder(encoderConfig), sink: sink}
+}
+
+func (c *sinkCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &sinkCore{enc: enc, sink: c.sink}
+}
+
+func (c *sinkCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c *sinkCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, buf.Len())
+	copy(data, buf.Bytes())
+	buf.Free()
+
+	c.sink.Write(&Record{Level: ent.Level, Time: ent.Time, Data: bytes.TrimRight(data, "\n")})
+	return nil
+}
+
+func (c *sinkCore) Sync() error {
+	return c.sink.Sync()
+}
+
+// WithSinks set the sinks that the logs are shipped to, in addition to the terminal or file,
+// e.g. WithSinks(NewKafkaSink(...), NewLokiSink(...)).
+func WithSinks(sinks ...*AsyncSink) Option {
+	return func(o *options) {
+		o.sinks = sinks
+	}
+}
+
+func setSinks(ss []*AsyncSink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks = ss
+}
+
+// GetSinkStats returns the statistics of sinks of the default logger.
+func GetSinkStats() []*SinkStats {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	stats := make([]*SinkStats, 0, len(sinks))
+	for _, s := range sinks {
+		stats = append(stats, s.Stats())
+	}
+	return stats
+}
+
+// CloseSinks flush the buffered records and close the sinks of the default logger, it should be called before exiting.
+func CloseSinks() error {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	var errs []error
+	for _, s := range sinks {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ------------------------------------------------------------------------------------------
+
+type teeWriter []BatchWriter
+
+// TeeWriter returns a writer that writes the records to all the writers, e.g. ship to both kafka and elasticsearch
+// with a shared buffer, the error of a writer does not prevent writing to the others.
+func TeeWriter(writers ...BatchWriter) BatchWriter {
+	return teeWriter(writers)
+}
+
+func (t teeWriter) WriteBatch(records []*Record) error {
+	var errs []error
+	for _, w := range t {
+		if err := w.WriteBatch(records); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t teeWriter) Close() error {
+	var errs []error
+	for _, w := range t {
+		if err := w.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ------------------------------------------------------------------------------------------
+
+// SinkConfig settings of sink, used for creating sinks from configuration file.
+type SinkConfig struct {
+	Type          string            // kafka, syslog, http, loki, elasticsearch
+	Addrs         []string          // kafka broker addresses
+	Topic         string            // kafka topic
+	Network       string            // syslog network, tcp or udp
+	Addr          string            // syslog server address
+	Tag           string            // syslog tag, default is the name of program
+	URL           string            // http, loki or elasticsearch url
+	Index         string            // elasticsearch index
+	Labels        map[string]string // loki stream labels
+	Headers       map[string]string // http request headers
+	BufferSize    int               // maximum number of buffered records
+	BatchSize     int               // maximum number of records written at a time
+	FlushInterval time.Duration     // interval of flushing the buffered records
+}
+
+// NewSink create a sink by configuration.
+func NewSink(cfg *SinkConfig) (*AsyncSink, error) {
+	opts := []SinkOption{
+		WithSinkBufferSize(cfg.BufferSize),
+		WithSinkBatchSize(cfg.BatchSize),
+		WithSinkFlushInterval(cfg.FlushInterval),
+		WithSinkHeaders(cfg.Headers),
+		WithSinkTag(cfg.Tag),
+	}
+
+	switch strings.ToLower(cfg.Type) {
+	case "kafka":
+		return NewKafkaSink(cfg.Addrs, cfg.Topic, opts...)
+	case "syslog":
+		network := cfg.Network
+		if network == "" {
+			network = "udp"
+		}
+		return NewSyslogSink(network, cfg.Addr, opts...), nil
+	case "http":
+		return NewHTTPSink(cfg.URL, opts...), nil
+	case "loki":
+		return NewLokiSink(cfg.URL, cfg.Labels, opts...), nil
+	case "elasticsearch":
+		return NewElasticsearchSink(cfg.URL, cfg.Index, opts...), nil
+	}
+	return nil, fmt.Errorf("unsupported sink type '%s', supported types are kafka, syslog, http, loki, elasticsearch", cfg.Type)
+}
@@ -0,0 +1,251 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type httpWriter struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+	encode  func(records []*Record) (body []byte, contentType string, err error)
+}
+
+func newHTTPWriter(url string, o *sinkOptions, encode func(records []*Record) ([]byte, string, error)) *httpWriter {
+	return &httpWriter{
+		url:     url,
+		headers: o.headers,
+		client:  &http.Client{Timeout: time.Second * 10},
+		encode:  encode,
+	}
+}
+
+func (w *httpWriter) WriteBatch(records []*Record) error {
+	_, err := w.post(records)
+	return err
+}
+
+// post the records, returns the response body
+func (w *httpWriter) post(records []*Record) ([]byte, error) {
+	body, contentType, err := w.encode(records)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint
+	if resp.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("http sink: status code %d, %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (w *httpWriter) Close() error {
+	w.client.CloseIdleConnections()
+	return nil
+}
+
+// NewHTTPSink create a sink that posts the records to url in batches, the body is newline delimited json.
+func NewHTTPSink(url string, opts ...SinkOption) *AsyncSink {
+	o := defaultSinkOptions()
+	o.apply(opts...)
+	return NewAsyncSink("http", newHTTPWriter(url, o, encodeNDJSON), opts...)
+}
+
+func encodeNDJSON(records []*Record) ([]byte, string, error) {
+	buf := &bytes.Buffer{}
+	for _, r := range records {
+		buf.Write(r.Data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), "application/x-ndjson", nil
+}
+
+// NewLokiSink create a sink that pushes the records to loki, url is the push api, e.g. http://localhost:3100/loki/api/v1/push,
+// labels are the stream labels, e.g. {"app":"user"}, the level of record is added to the labels.
+func NewLokiSink(url string, labels map[string]string, opts ...SinkOption) *AsyncSink {
+	o := defaultSinkOptions()
+	o.apply(opts...)
+	return NewAsyncSink("loki", newHTTPWriter(url, o, lokiEncoder(labels)), opts...)
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func lokiEncoder(labels map[string]string) func(records []*Record) ([]byte, string, error) {
+	return func(records []*Record) ([]byte, string, error) {
+		streams := map[string]*lokiStream{}
+		var levels []string
+		for _, r := range records {
+			level := r.Level.String()
+			stream, ok := streams[level]
+			if !ok {
+				stream = &lokiStream{Stream: map[string]string{"level": level}}
+				for k, v := range labels {
+					stream.Stream[k] = v
+				}
+				streams[level] = stream
+				levels = append(levels, level)
+			}
+			stream.Values = append(stream.Values, [2]string{strconv.FormatInt(r.Time.UnixNano(), 10), string(r.Data)})
+		}
+
+		body := struct {
+			Streams []*lokiStream `json:"streams"`
+		}{}
+		for _, level := range levels {
+			body.Streams = append(body.Streams, streams[level])
+		}
+		data, err := json.Marshal(body)
+		return data, "application/json", err
+	}
+}
+
+var (
+	esMaxRetries   = 3                      // maximum number of retries of the records rejected temporarily
+	esRetryBackoff = time.Millisecond * 100 // backoff of the first retry, doubled for each retry
+)
+
+// NewElasticsearchSink create a sink that writes the records to elasticsearch by bulk api,
+// url is the address of elasticsearch, e.g. http://localhost:9200, index is the name of index.
+// The records rejected temporarily by elasticsearch (status 429 or 5xx) are retried, the others are reported as failed.
+func NewElasticsearchSink(url string, index string, opts ...SinkOption) *AsyncSink {
+	o := defaultSinkOptions()
+	o.apply(opts...)
+	url = strings.TrimSuffix(url, "/") + "/_bulk"
+	return NewAsyncSink("elasticsearch", &esWriter{httpWriter: newHTTPWriter(url, o, elasticsearchEncoder(index))}, opts...)
+}
+
+func elasticsearchEncoder(index string) func(records []*Record) ([]byte, string, error) {
+	action, _ := json.Marshal(map[string]interface{}{"index": map[string]string{"_index": index}})
+	return func(records []*Record) ([]byte, string, error) {
+		buf := &bytes.Buffer{}
+		for _, r := range records {
+			buf.Write(action)
+			buf.WriteByte('\n')
+			buf.Write(r.Data)
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes(), "application/x-ndjson", nil
+	}
+}
+
+// esWriter checks the status of each item in the bulk response, the bulk api returns 200
+// even if some items failed, e.g. {"errors":true,"items":[{"index":{"status":429,"error":{...}}}]}
+type esWriter struct {
+	*httpWriter
+}
+
+type esBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+func (w *esWriter) WriteBatch(records []*Record) error {
+	total, failed := len(records), 0
+	var firstErr error
+	backoff := esRetryBackoff
+	for i := 0; ; i++ {
+		retries, n, err := w.bulk(records)
+		if err != nil {
+			if len(retries) == 0 && n == 0 { // the request failed, all the records are failed
+				if i == 0 {
+					return err
+				}
+				n = len(records)
+			}
+			if firstErr == nil && n > 0 {
+				firstErr = err
+			}
+		}
+		failed += n
+		if len(retries) > 0 && i >= esMaxRetries {
+			failed += len(retries)
+			retries = nil
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		if len(retries) == 0 {
+			if failed == 0 {
+				return nil
+			}
+			return &BatchError{Failed: failed, Err: fmt.Errorf("elasticsearch sink: %d of %d records failed, %v", failed, total, firstErr)}
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		records = retries
+	}
+}
+
+// bulk writes the records, returns the records to be retried, the number of records that failed permanently
+// and the error of items, the error of failed items is preferred to the error of retried items.
+func (w *esWriter) bulk(records []*Record) ([]*Record, int, error) {
+	body, err := w.post(records)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp := &esBulkResponse{}
+	if err = json.Unmarshal(body, resp); err != nil {
+		return nil, 0, fmt.Errorf("elasticsearch sink: invalid bulk response, %v", err)
+	}
+	if !resp.Errors {
+		return nil, 0, nil
+	}
+
+	var retries []*Record
+	var failed int
+	var failedErr, retryErr error
+	for i, item := range resp.Items {
+		if i >= len(records) {
+			break
+		}
+		for _, result := range item { // only one action per item
+			if result.Status < http.StatusMultipleChoices {
+				continue
+			}
+			err := fmt.Errorf("status %d, %s", result.Status, string(result.Error))
+			if result.Status == http.StatusTooManyRequests || result.Status >= http.StatusInternalServerError {
+				retries = append(retries, records[i])
+				if retryErr == nil {
+					retryErr = err
+				}
+			} else {
+				failed++
+				if failedErr == nil {
+					failedErr = err
+				}
+			}
+		}
+	}
+	if failedErr != nil {
+		return retries, failed, failedErr
+	}
+	return retries, failed, retryErr
+}
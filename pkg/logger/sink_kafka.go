@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"github.com/IBM/sarama"
+)
+
+type kafkaWriter struct {
+	producer sarama.SyncProducer
+	topic    string
+}
+
+// NewKafkaSink create a sink that sends the records to kafka topic.
+func NewKafkaSink(addrs []string, topic string, opts ...SinkOption) (*AsyncSink, error) {
+	config := sarama.NewConfig()
+	config.Producer.RequiredAcks = sarama.WaitForLocal
+	config.Producer.Return.Successes = true
+	producer, err := sarama.NewSyncProducer(addrs, config)
+	if err != nil {
+		return nil, err
+	}
+	return NewKafkaSinkWithProducer(producer, topic, opts...), nil
+}
+
+// NewKafkaSinkWithProducer create a sink that sends the records to kafka topic with the specified producer,
+// the producer is closed when the sink is closed.
+func NewKafkaSinkWithProducer(producer sarama.SyncProducer, topic string, opts ...SinkOption) *AsyncSink {
+	return NewAsyncSink("kafka", &kafkaWriter{producer: producer, topic: topic}, opts...)
+}
+
+func (w *kafkaWriter) WriteBatch(records []*Record) error {
+	msgs := make([]*sarama.ProducerMessage, 0, len(records))
+	for _, r := range records {
+		msgs = append(msgs, &sarama.ProducerMessage{
+			Topic:     w.topic,
+			Value:     sarama.ByteEncoder(r.Data),
+			Timestamp: r.Time,
+		})
+	}
+	return w.producer.SendMessages(msgs)
+}
+
+func (w *kafkaWriter) Close() error {
+	return w.producer.Close()
+}
@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const syslogFacilityUser = 1
+
+type syslogWriter struct {
+	network  string
+	addr     string
+	tag      string
+	hostname string
+	conn     net.Conn
+}
+
+// NewSyslogSink create a sink that sends the records to syslog server in RFC 5424 format,
+// network is tcp or udp, e.g. NewSyslogSink("udp", "localhost:514"), the connection is re-established on failure.
+func NewSyslogSink(network string, addr string, opts ...SinkOption) *AsyncSink {
+	o := defaultSinkOptions()
+	o.apply(opts...)
+	if o.tag == "" {
+		o.tag = filepath.Base(os.Args[0])
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	w := &syslogWriter{network: network, addr: addr, tag: o.tag, hostname: hostname}
+	return NewAsyncSink("syslog", w, opts...)
+}
+
+func (w *syslogWriter) WriteBatch(records []*Record) error {
+	if w.conn == nil {
+		conn, err := net.DialTimeout(w.network, w.addr, time.Second*5)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+
+	for _, r := range records {
+		_ = w.conn.SetWriteDeadline(time.Now().Add(time.Second * 5))
+		if _, err := w.conn.Write(w.format(r)); err != nil {
+			_ = w.conn.Close()
+			w.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (w *syslogWriter) format(r *Record) []byte {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "<%d>1 %s %s %s %d - - ", syslogFacilityUser*8+syslogSeverity(r.Level),
+		r.Time.Format(time.RFC3339Nano), w.hostname, w.tag, os.Getpid())
+	buf.Write(r.Data)
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func (w *syslogWriter) Close() error {
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
+
+func syslogSeverity(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 7
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.ErrorLevel:
+		return 3
+	}
+	return 2 // critical
+}
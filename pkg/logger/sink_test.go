@@ -0,0 +1,325 @@
+package logger
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+type memWriter struct {
+	mu      sync.Mutex
+	records []*Record
+	err     error
+	delay   time.Duration
+}
+
+func (w *memWriter) WriteBatch(records []*Record) error {
+	time.Sleep(w.delay)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	w.records = append(w.records, records...)
+	return nil
+}
+
+func (w *memWriter) Close() error { return nil }
+
+func (w *memWriter) len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.records)
+}
+
+func newRecord(msg string) *Record {
+	return &Record{Level: zapcore.InfoLevel, Time: time.Now(), Data: []byte(`{"msg":"` + msg + `"}`)}
+}
+
+type fakeHTTPServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	bodies []string
+	header http.Header
+}
+
+func newFakeHTTPServer(t *testing.T) *fakeHTTPServer {
+	s := &fakeHTTPServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.bodies = append(s.bodies, string(body))
+		s.header = r.Header
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeHTTPServer) body() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return strings.Join(s.bodies, "")
+}
+
+func TestAsyncSink(t *testing.T) {
+	w := &memWriter{}
+	sink := NewAsyncSink("mem", w, WithSinkBatchSize(2), WithSinkFlushInterval(time.Hour))
+	for i := 0; i < 5; i++ {
+		sink.Write(newRecord("foo"))
+	}
+	assert.NoError(t, sink.Sync())
+	assert.Equal(t, 5, w.len())
+	assert.Equal(t, int64(5), sink.Stats().Written)
+
+	w.mu.Lock()
+	w.err = errors.New("connection refused")
+	w.mu.Unlock()
+	sink.Write(newRecord("foo"))
+	assert.Error(t, sink.Sync())
+	stats := sink.Stats()
+	assert.Equal(t, int64(1), stats.Failed)
+	assert.Equal(t, "connection refused", stats.LastError)
+
+	assert.NoError(t, sink.Close())
+	sink.Write(newRecord("foo"))
+	assert.Equal(t, int64(1), sink.Stats().Dropped)
+	assert.NoError(t, sink.Sync())
+	assert.NoError(t, sink.Close())
+}
+
+func TestAsyncSink_Drop(t *testing.T) {
+	w := &memWriter{delay: time.Millisecond * 100}
+	sink := NewAsyncSink("mem", w, WithSinkBufferSize(3), WithSinkBatchSize(1))
+	defer sink.Close()
+	for i := 0; i < 10; i++ {
+		sink.Write(newRecord("foo"))
+	}
+	stats := sink.Stats()
+	assert.Greater(t, stats.Dropped, int64(0))
+	assert.NoError(t, sink.Sync())
+	assert.Equal(t, int64(10), int64(w.len())+sink.Stats().Dropped)
+}
+
+func TestAsyncSink_FlushInterval(t *testing.T) {
+	w := &memWriter{}
+	sink := NewAsyncSink("mem", w, WithSinkFlushInterval(time.Millisecond*50))
+	defer sink.Close()
+	sink.Write(newRecord("foo"))
+	assert.Eventually(t, func() bool { return w.len() == 1 }, time.Second, time.Millisecond*10)
+}
+
+func TestTeeWriter(t *testing.T) {
+	w1, w2 := &memWriter{}, &memWriter{err: errors.New("error")}
+	tee := TeeWriter(w1, w2)
+	assert.Error(t, tee.WriteBatch([]*Record{newRecord("foo")}))
+	assert.Equal(t, 1, w1.len())
+	assert.NoError(t, tee.Close())
+}
+
+func TestHTTPSink(t *testing.T) {
+	server := newFakeHTTPServer(t)
+	sink := NewHTTPSink(server.URL, WithSinkHeaders(map[string]string{"Authorization": "Bearer token"}))
+	sink.Write(newRecord("foo"))
+	sink.Write(newRecord("bar"))
+	require.NoError(t, sink.Sync())
+	assert.Equal(t, "{\"msg\":\"foo\"}\n{\"msg\":\"bar\"}\n", server.body())
+	assert.Equal(t, "Bearer token", server.header.Get("Authorization"))
+	assert.Equal(t, "application/x-ndjson", server.header.Get("Content-Type"))
+	assert.NoError(t, sink.Close())
+
+	errServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer errServer.Close()
+	sink = NewHTTPSink(errServer.URL)
+	defer sink.Close()
+	sink.Write(newRecord("foo"))
+	assert.ErrorContains(t, sink.Sync(), "status code 400")
+}
+
+func TestLokiSink(t *testing.T) {
+	server := newFakeHTTPServer(t)
+	sink := NewLokiSink(server.URL+"/loki/api/v1/push", map[string]string{"app": "user"})
+	defer sink.Close()
+	sink.Write(newRecord("foo"))
+	sink.Write(&Record{Level: zapcore.ErrorLevel, Time: time.Now(), Data: []byte(`{"msg":"bar"}`)})
+	require.NoError(t, sink.Sync())
+	body := server.body()
+	assert.Contains(t, body, `"stream":{"app":"user","level":"info"}`)
+	assert.Contains(t, body, `"stream":{"app":"user","level":"error"}`)
+	assert.Contains(t, body, `{\"msg\":\"bar\"}`)
+}
+
+func TestElasticsearchSink(t *testing.T) {
+	esRetryBackoff = time.Millisecond
+	var mu sync.Mutex
+	var bodies []string
+	var responses []string // bulk responses in order, the last one is used if there are no more
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		resp := responses[0]
+		if len(responses) > 1 {
+			responses = responses[1:]
+		}
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(resp))
+	}))
+	defer server.Close()
+	reset := func(resps ...string) {
+		mu.Lock()
+		bodies, responses = nil, resps
+		mu.Unlock()
+	}
+
+	sink := NewElasticsearchSink(server.URL+"/", "logs")
+	defer sink.Close()
+
+	reset(`{"errors":false,"items":[{"index":{"status":201}}]}`)
+	sink.Write(newRecord("foo"))
+	require.NoError(t, sink.Sync())
+	assert.Equal(t, []string{"{\"index\":{\"_index\":\"logs\"}}\n{\"msg\":\"foo\"}\n"}, bodies)
+
+	// the rejected record is retried, the record with mapping error is failed
+	reset(
+		`{"errors":true,"items":[{"index":{"status":201}},{"index":{"status":429,"error":{"type":"es_rejected_execution_exception"}}},{"index":{"status":400,"error":{"type":"mapper_parsing_exception"}}}]}`,
+		`{"errors":false,"items":[{"index":{"status":201}}]}`,
+	)
+	sink.Write(newRecord("foo"))
+	sink.Write(newRecord("bar"))
+	sink.Write(newRecord("baz"))
+	err := sink.Sync()
+	assert.ErrorContains(t, err, "1 of 3 records failed")
+	assert.ErrorContains(t, err, "mapper_parsing_exception")
+	require.Len(t, bodies, 2)
+	assert.Equal(t, "{\"index\":{\"_index\":\"logs\"}}\n{\"msg\":\"bar\"}\n", bodies[1])
+	stats := sink.Stats()
+	assert.Equal(t, int64(3), stats.Written)
+	assert.Equal(t, int64(1), stats.Failed)
+
+	// the retries are exhausted
+	reset(`{"errors":true,"items":[{"index":{"status":503,"error":{"type":"unavailable_shards_exception"}}}]}`)
+	sink.Write(newRecord("foo"))
+	err = sink.Sync()
+	assert.ErrorContains(t, err, "1 of 1 records failed")
+	assert.ErrorContains(t, err, "unavailable_shards_exception")
+	assert.Len(t, bodies, esMaxRetries+1)
+	assert.Equal(t, int64(2), sink.Stats().Failed)
+}
+
+func TestSyslogSink(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+	lines := make(chan string, 10)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	sink := NewSyslogSink("tcp", lis.Addr().String(), WithSinkTag("user"))
+	sink.Write(&Record{Level: zapcore.ErrorLevel, Time: time.Now(), Data: []byte(`{"msg":"foo"}`)})
+	require.NoError(t, sink.Sync())
+	select {
+	case line := <-lines:
+		assert.True(t, strings.HasPrefix(line, "<11>1 "), line)
+		assert.Contains(t, line, " user ")
+		assert.True(t, strings.HasSuffix(line, ` - - {"msg":"foo"}`), line)
+	case <-time.After(time.Second * 3):
+		t.Fatal("timeout")
+	}
+	assert.NoError(t, sink.Close())
+
+	sink = NewSyslogSink("tcp", "127.0.0.1:1")
+	defer sink.Close()
+	sink.Write(newRecord("foo"))
+	assert.Error(t, sink.Sync())
+}
+
+func TestKafkaSink(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("logs", 0, broker.BrokerID()),
+		"ProduceRequest": sarama.NewMockProduceResponse(t),
+	})
+
+	sink, err := NewKafkaSink([]string{broker.Addr()}, "logs")
+	require.NoError(t, err)
+	sink.Write(newRecord("foo"))
+	sink.Write(newRecord("bar"))
+	require.NoError(t, sink.Sync())
+	assert.Equal(t, int64(2), sink.Stats().Written)
+	assert.NoError(t, sink.Close())
+
+	_, err = NewKafkaSink([]string{"127.0.0.1:1"}, "logs")
+	assert.Error(t, err)
+}
+
+func TestWithSinks(t *testing.T) {
+	w1, w2 := &memWriter{}, &memWriter{}
+	sink1 := NewAsyncSink("mem1", w1, WithSinkFlushInterval(time.Hour))
+	sink2 := NewAsyncSink("mem2", TeeWriter(w2), WithSinkFlushInterval(time.Hour))
+	_, err := Init(WithLevel("info"), WithSinks(sink1, sink2))
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, CloseSinks())
+		_, _ = Init()
+	}()
+
+	WithFields(String("foo", "bar")).Info("hello")
+	Debug("ignored")
+	require.NoError(t, Sync())
+
+	assert.Equal(t, 2, w1.len()) // including the initialization log
+	assert.Equal(t, 2, w2.len())
+	last := string(w1.records[1].Data)
+	assert.Contains(t, last, `"msg":"hello"`)
+	assert.Contains(t, last, `"foo":"bar"`)
+	assert.Contains(t, last, `"level":"INFO"`)
+
+	stats := GetSinkStats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, "mem1", stats[0].Name)
+	assert.Equal(t, int64(2), stats[0].Written)
+}
+
+func TestNewSink(t *testing.T) {
+	server := newFakeHTTPServer(t)
+	for _, typ := range []string{"http", "loki", "elasticsearch", "syslog"} {
+		sink, err := NewSink(&SinkConfig{Type: typ, URL: server.URL, Addr: "127.0.0.1:514", FlushInterval: time.Second})
+		require.NoError(t, err)
+		assert.Equal(t, typ, sink.Name())
+		_ = sink.Close()
+	}
+
+	_, err := NewSink(&SinkConfig{Type: "kafka", Addrs: []string{"127.0.0.1:1"}})
+	assert.Error(t, err)
+	_, err = NewSink(&SinkConfig{Type: "foo"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/18721889353/sunshine/pkg/container/group"
+	"github.com/18721889353/sunshine/pkg/shield/circuitbreaker"
+	"github.com/18721889353/sunshine/pkg/shield/ratelimit"
+)
+
+// RegisterDBStats registers the collector of sql.DBStats to the shared registry, dbName is used as label db_name,
+// for gorm, the *sql.DB is got by db.DB().
+func RegisterDBStats(dbName string, db *sql.DB) error {
+	return Register(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// ------------------------------------------------------------------------------------------
+
+// RedisPoolStater is implemented by redis.Client, redis.ClusterClient, redis.Ring, etc.
+type RedisPoolStater interface {
+	PoolStats() *redis.PoolStats
+}
+
+// RegisterRedisPool registers the collector of redis pool stats to the shared registry, name is used as label name.
+func RegisterRedisPool(name string, client RedisPoolStater) error {
+	return Register(newRedisPoolCollector(name, client))
+}
+
+type redisPoolCollector struct {
+	client RedisPoolStater
+
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	timeouts   *prometheus.Desc
+	totalConns *prometheus.Desc
+	idleConns  *prometheus.Desc
+	staleConns *prometheus.Desc
+}
+
+func newRedisPoolCollector(name string, client RedisPoolStater) *redisPoolCollector {
+	labels := prometheus.Labels{"name": name}
+	return &redisPoolCollector{
+		client:     client,
+		hits:       prometheus.NewDesc("redis_pool_hits_total", "Number of times free connection was found in the pool.", nil, labels),
+		misses:     prometheus.NewDesc("redis_pool_misses_total", "Number of times free connection was NOT found in the pool.", nil, labels),
+		timeouts:   prometheus.NewDesc("redis_pool_timeouts_total", "Number of times a wait timeout occurred.", nil, labels),
+		totalConns: prometheus.NewDesc("redis_pool_total_connections", "Number of total connections in the pool.", nil, labels),
+		idleConns:  prometheus.NewDesc("redis_pool_idle_connections", "Number of idle connections in the pool.", nil, labels),
+		staleConns: prometheus.NewDesc("redis_pool_stale_connections_total", "Number of stale connections removed from the pool.", nil, labels),
+	}
+}
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.totalConns
+	ch <- c.idleConns
+	ch <- c.staleConns
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	if stats == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
+
+// ------------------------------------------------------------------------------------------
+
+// LimiterStater is implemented by ratelimit.BBR.
+type LimiterStater interface {
+	Stat() ratelimit.Stat
+}
+
+// RegisterLimiter registers the collector of bbr limiter stat to the shared registry, name is used as label name.
+func RegisterLimiter(name string, limiter LimiterStater) error {
+	return Register(newLimiterCollector(name, limiter))
+}
+
+type limiterCollector struct {
+	limiter LimiterStater
+
+	cpu         *prometheus.Desc
+	inFlight    *prometheus.Desc
+	maxInFlight *prometheus.Desc
+	minRt       *prometheus.Desc
+	maxPass     *prometheus.Desc
+}
+
+func newLimiterCollector(name string, limiter LimiterStater) *limiterCollector {
+	labels := prometheus.Labels{"name": name}
+	return &limiterCollector{
+		limiter:     limiter,
+		cpu:         prometheus.NewDesc("ratelimit_cpu_usage", "CPU usage seen by the limiter, 1000 means 100%.", nil, labels),
+		inFlight:    prometheus.NewDesc("ratelimit_in_flight", "Number of requests in processing.", nil, labels),
+		maxInFlight: prometheus.NewDesc("ratelimit_max_in_flight", "Max number of requests allowed in processing.", nil, labels),
+		minRt:       prometheus.NewDesc("ratelimit_min_rt_milliseconds", "Min response time of the window.", nil, labels),
+		maxPass:     prometheus.NewDesc("ratelimit_max_pass", "Max number of requests passed in a bucket.", nil, labels),
+	}
+}
+
+func (c *limiterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.cpu
+	ch <- c.inFlight
+	ch <- c.maxInFlight
+	ch <- c.minRt
+	ch <- c.maxPass
+}
+
+func (c *limiterCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.limiter.Stat()
+	ch <- prometheus.MustNewConstMetric(c.cpu, prometheus.GaugeValue, float64(stat.CPU))
+	ch <- prometheus.MustNewConstMetric(c.inFlight, prometheus.GaugeValue, float64(stat.InFlight))
+	ch <- prometheus.MustNewConstMetric(c.maxInFlight, prometheus.GaugeValue, float64(stat.MaxInFlight))
+	ch <- prometheus.MustNewConstMetric(c.minRt, prometheus.GaugeValue, float64(stat.MinRt))
+	ch <- prometheus.MustNewConstMetric(c.maxPass, prometheus.GaugeValue, float64(stat.MaxPass))
+}
+
+// ------------------------------------------------------------------------------------------
+
+// BreakerStater is implemented by circuitbreaker.Breaker.
+type BreakerStater interface {
+	State() int32
+}
+
+// RegisterBreakers registers the collector of circuit breaker states to the shared registry, name is used as
+// label name, each breaker in the group is a series with label key, the value is 1 if the breaker is open, otherwise 0.
+func RegisterBreakers(name string, breakers *group.Group) error {
+	return Register(newBreakerCollector(name, breakers))
+}
+
+type breakerCollector struct {
+	breakers *group.Group
+	open     *prometheus.Desc
+}
+
+func newBreakerCollector(name string, breakers *group.Group) *breakerCollector {
+	return &breakerCollector{
+		breakers: breakers,
+		open: prometheus.NewDesc("circuit_breaker_open", "Whether the circuit breaker is open, 1 is open, 0 is closed.",
+			[]string{"key"}, prometheus.Labels{"name": name}),
+	}
+}
+
+func (c *breakerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.open
+}
+
+func (c *breakerCollector) Collect(ch chan<- prometheus.Metric) {
+	c.breakers.Range(func(key string, value interface{}) bool {
+		b, ok := value.(BreakerStater)
+		if !ok {
+			return true
+		}
+		var v float64
+		if b.State() == circuitbreaker.StateOpen {
+			v = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, v, key)
+		return true
+	})
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/18721889353/sunshine/pkg/container/group"
+	"github.com/18721889353/sunshine/pkg/shield/circuitbreaker"
+	"github.com/18721889353/sunshine/pkg/shield/ratelimit"
+)
+
+type fakeRedisClient struct{}
+
+func (c *fakeRedisClient) PoolStats() *redis.PoolStats {
+	return &redis.PoolStats{Hits: 10, Misses: 2, TotalConns: 5, IdleConns: 3}
+}
+
+type fakeLimiter struct{}
+
+func (l *fakeLimiter) Stat() ratelimit.Stat {
+	return ratelimit.Stat{CPU: 800, InFlight: 3, MaxInFlight: 10, MinRt: 5, MaxPass: 100}
+}
+
+func TestRegisterDBStats(t *testing.T) {
+	// sql.Open does not connect to the database
+	db, err := sql.Open("mysql", "root:123456@(127.0.0.1:3306)/test")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	assert.NoError(t, RegisterDBStats("test", db))
+	assert.NoError(t, RegisterDBStats("test", db))
+}
+
+func TestRegisterRedisPool(t *testing.T) {
+	c := newRedisPoolCollector("default", &fakeRedisClient{})
+	expected := `
+# HELP redis_pool_hits_total Number of times free connection was found in the pool.
+# TYPE redis_pool_hits_total counter
+redis_pool_hits_total{name="default"} 10
+# HELP redis_pool_idle_connections Number of idle connections in the pool.
+# TYPE redis_pool_idle_connections gauge
+redis_pool_idle_connections{name="default"} 3
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "redis_pool_hits_total", "redis_pool_idle_connections")
+	assert.NoError(t, err)
+	assert.Equal(t, 6, testutil.CollectAndCount(c))
+
+	assert.NoError(t, RegisterRedisPool("default", &fakeRedisClient{}))
+	assert.NoError(t, RegisterRedisPool("default", &fakeRedisClient{}))
+}
+
+func TestRegisterLimiter(t *testing.T) {
+	c := newLimiterCollector("http", &fakeLimiter{})
+	expected := `
+# HELP ratelimit_cpu_usage CPU usage seen by the limiter, 1000 means 100%.
+# TYPE ratelimit_cpu_usage gauge
+ratelimit_cpu_usage{name="http"} 800
+# HELP ratelimit_max_pass Max number of requests passed in a bucket.
+# TYPE ratelimit_max_pass gauge
+ratelimit_max_pass{name="http"} 100
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "ratelimit_cpu_usage", "ratelimit_max_pass")
+	assert.NoError(t, err)
+
+	assert.NoError(t, RegisterLimiter("bbr", ratelimit.NewLimiter()))
+}
+
+func TestRegisterBreakers(t *testing.T) {
+	breakers := group.NewGroup(func() interface{} {
+		return circuitbreaker.NewBreaker()
+	})
+	c := newBreakerCollector("grpc", breakers)
+	assert.Equal(t, 0, testutil.CollectAndCount(c))
+
+	breakers.Get("/api.user.v1.User/GetByID")
+	expected := `
+# HELP circuit_breaker_open Whether the circuit breaker is open, 1 is open, 0 is closed.
+# TYPE circuit_breaker_open gauge
+circuit_breaker_open{key="/api.user.v1.User/GetByID",name="grpc"} 0
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected))
+	assert.NoError(t, err)
+
+	assert.NoError(t, RegisterBreakers("grpc", breakers))
+}
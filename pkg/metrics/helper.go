@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// exemplarLabels returns the trace id of ctx as exemplar, returns nil if the span is not sampled
+func exemplarLabels(ctx context.Context) prometheus.Labels {
+	if ctx == nil {
+		return nil
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() || !sc.IsSampled() {
+		return nil
+	}
+	return prometheus.Labels{"trace_id": sc.TraceID().String()}
+}
+
+// register the collector, if a collector with the same descriptor is already registered, return the existing one
+func registerOrExisting(c prometheus.Collector) prometheus.Collector {
+	err := registry.Register(c)
+	if err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			return are.ExistingCollector
+		}
+		panic(err)
+	}
+	return c
+}
+
+// ------------------------------------------------------------------------------------------
+
+// Counter is a counter with labels, registered to the shared registry.
+type Counter struct {
+	vec *prometheus.CounterVec
+}
+
+// NewCounter creates a counter and registers it to the shared registry, name should end with _total,
+// the counter with the same name and labels registered before is returned.
+func NewCounter(name string, help string, labelNames ...string) *Counter {
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labelNames)
+	return &Counter{vec: registerOrExisting(vec).(*prometheus.CounterVec)}
+}
+
+// Inc increments the counter by 1, the trace id of ctx is attached as exemplar.
+func (c *Counter) Inc(ctx context.Context, labelValues ...string) {
+	c.Add(ctx, 1, labelValues...)
+}
+
+// Add adds the value to the counter, the value must be non-negative, the trace id of ctx is attached as exemplar.
+func (c *Counter) Add(ctx context.Context, value float64, labelValues ...string) {
+	counter := c.vec.WithLabelValues(labelValues...)
+	if exemplar := exemplarLabels(ctx); exemplar != nil {
+		if adder, ok := counter.(prometheus.ExemplarAdder); ok {
+			adder.AddWithExemplar(value, exemplar)
+			return
+		}
+	}
+	counter.Add(value)
+}
+
+// Vec returns the prometheus.CounterVec.
+func (c *Counter) Vec() *prometheus.CounterVec {
+	return c.vec
+}
+
+// ------------------------------------------------------------------------------------------
+
+// Histogram is a histogram with labels, registered to the shared registry.
+type Histogram struct {
+	vec *prometheus.HistogramVec
+}
+
+// NewHistogram creates a histogram and registers it to the shared registry, if buckets is empty,
+// prometheus.DefBuckets is used, the histogram with the same name and labels registered before is returned.
+func NewHistogram(name string, help string, buckets []float64, labelNames ...string) *Histogram {
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: buckets}, labelNames)
+	return &Histogram{vec: registerOrExisting(vec).(*prometheus.HistogramVec)}
+}
+
+// Observe adds an observation, the trace id of ctx is attached as exemplar.
+func (h *Histogram) Observe(ctx context.Context, value float64, labelValues ...string) {
+	observer := h.vec.WithLabelValues(labelValues...)
+	if exemplar := exemplarLabels(ctx); exemplar != nil {
+		if eo, ok := observer.(prometheus.ExemplarObserver); ok {
+			eo.ObserveWithExemplar(value, exemplar)
+			return
+		}
+	}
+	observer.Observe(value)
+}
+
+// Vec returns the prometheus.HistogramVec.
+func (h *Histogram) Vec() *prometheus.HistogramVec {
+	return h.vec
+}
+
+// ------------------------------------------------------------------------------------------
+
+// Gauge is a gauge with labels, registered to the shared registry, exemplars are not supported by gauge.
+type Gauge struct {
+	vec *prometheus.GaugeVec
+}
+
+// NewGauge creates a gauge and registers it to the shared registry,
+// the gauge with the same name and labels registered before is returned.
+func NewGauge(name string, help string, labelNames ...string) *Gauge {
+	vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labelNames)
+	return &Gauge{vec: registerOrExisting(vec).(*prometheus.GaugeVec)}
+}
+
+// Set sets the gauge to the value.
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.vec.WithLabelValues(labelValues...).Set(value)
+}
+
+// Inc increments the gauge by 1.
+func (g *Gauge) Inc(labelValues ...string) {
+	g.vec.WithLabelValues(labelValues...).Inc()
+}
+
+// Dec decrements the gauge by 1.
+func (g *Gauge) Dec(labelValues ...string) {
+	g.vec.WithLabelValues(labelValues...).Dec()
+}
+
+// Add adds the value to the gauge, the value can be negative.
+func (g *Gauge) Add(value float64, labelValues ...string) {
+	g.vec.WithLabelValues(labelValues...).Add(value)
+}
+
+// Vec returns the prometheus.GaugeVec.
+func (g *Gauge) Vec() *prometheus.GaugeVec {
+	return g.vec
+}
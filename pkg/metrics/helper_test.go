@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func sampledContext() (context.Context, string) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+	return trace.ContextWithSpanContext(context.Background(), sc), traceID.String()
+}
+
+func TestCounter(t *testing.T) {
+	c := NewCounter("test_orders_total", "test", "status")
+	defer Unregister(c.Vec())
+	ctx, traceID := sampledContext()
+
+	c.Inc(ctx, "paid")
+	c.Add(context.Background(), 2, "paid")
+	c.Inc(nil, "canceled") //nolint
+	assert.Equal(t, 3.0, testutil.ToFloat64(c.Vec().WithLabelValues("paid")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.Vec().WithLabelValues("canceled")))
+
+	m := &dto.Metric{}
+	_ = c.Vec().WithLabelValues("paid").Write(m)
+	assert.Equal(t, traceID, m.GetCounter().GetExemplar().GetLabel()[0].GetValue())
+
+	// the same counter is returned
+	c2 := NewCounter("test_orders_total", "test", "status")
+	assert.Equal(t, c.Vec(), c2.Vec())
+	assert.Panics(t, func() { NewCounter("test_orders_total", "test", "other") })
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram("test_order_amount", "test", []float64{10, 100}, "status")
+	defer Unregister(h.Vec())
+	ctx, traceID := sampledContext()
+
+	h.Observe(ctx, 50, "paid")
+	h.Observe(context.Background(), 5, "paid")
+	assert.Equal(t, 1, testutil.CollectAndCount(h.Vec()))
+
+	m := &dto.Metric{}
+	_ = h.Vec().WithLabelValues("paid").(interface{ Write(*dto.Metric) error }).Write(m)
+	assert.Equal(t, uint64(2), m.GetHistogram().GetSampleCount())
+	assert.Equal(t, traceID, m.GetHistogram().GetBucket()[1].GetExemplar().GetLabel()[0].GetValue())
+
+	h2 := NewHistogram("test_order_amount", "test", nil, "status")
+	assert.Equal(t, h.Vec(), h2.Vec())
+}
+
+func TestGauge(t *testing.T) {
+	g := NewGauge("test_queue_size", "test", "queue")
+	defer Unregister(g.Vec())
+
+	g.Set(10, "order")
+	g.Inc("order")
+	g.Dec("order")
+	g.Add(-5, "order")
+	assert.Equal(t, 5.0, testutil.ToFloat64(g.Vec().WithLabelValues("order")))
+
+	g2 := NewGauge("test_queue_size", "test", "queue")
+	assert.Equal(t, g.Vec(), g2.Vec())
+}
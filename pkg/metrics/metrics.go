@@ -0,0 +1,162 @@
+// Package metrics is a facade of prometheus, it provides a shared registry with the standard labels
+// service, version and host, helpers of business metrics with exemplars linked to trace id,
+// built-in collectors of db pool, redis pool, bbr limiter and circuit breaker, and one handler
+// serving all of them.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultPath is the default path of metrics endpoint.
+const DefaultPath = "/metrics"
+
+var (
+	// the shared registry, the go and process metrics are collected by prometheus.DefaultRegisterer
+	registry = prometheus.NewRegistry()
+
+	labelsMutex    sync.RWMutex
+	standardLabels []*dto.LabelPair
+)
+
+// Init set the standard labels service, version and host, which are added to all metrics served by Handler,
+// the host is hostname by default.
+func Init(service string, version string, opts ...Option) {
+	o := defaultOptions()
+	o.apply(opts...)
+
+	labels := map[string]string{}
+	for k, v := range o.constLabels {
+		labels[k] = v
+	}
+	labels["service"] = service
+	labels["version"] = version
+	labels["host"] = o.host
+
+	pairs := make([]*dto.LabelPair, 0, len(labels))
+	for k, v := range labels {
+		if v == "" {
+			continue
+		}
+		pairs = append(pairs, &dto.LabelPair{Name: proto.String(k), Value: proto.String(v)})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].GetName() < pairs[j].GetName() })
+
+	labelsMutex.Lock()
+	standardLabels = pairs
+	labelsMutex.Unlock()
+}
+
+func getStandardLabels() []*dto.LabelPair {
+	labelsMutex.RLock()
+	defer labelsMutex.RUnlock()
+	return standardLabels
+}
+
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// Registry returns the shared registry.
+func Registry() *prometheus.Registry {
+	return registry
+}
+
+// Register registers the collector to the shared registry, the collector already registered is ignored.
+func Register(c prometheus.Collector) error {
+	err := registry.Register(c)
+	if err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			return nil
+		}
+	}
+	return err
+}
+
+// MustRegister registers the collectors to the shared registry, panic if error occurs,
+// the collectors already registered are ignored.
+func MustRegister(cs ...prometheus.Collector) {
+	for _, c := range cs {
+		if err := Register(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Unregister unregisters the collector from the shared registry.
+func Unregister(c prometheus.Collector) bool {
+	return registry.Unregister(c)
+}
+
+// Gatherer returns the gatherer of all metrics, including the shared registry and
+// prometheus.DefaultGatherer, the standard labels are added to all metrics.
+func Gatherer() prometheus.Gatherer {
+	return &labeledGatherer{
+		gatherer: prometheus.Gatherers{prometheus.DefaultGatherer, registry},
+	}
+}
+
+// Handler returns the http handler serving all metrics, the OpenMetrics format is supported
+// for exemplars if negotiated by the prometheus server.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Gatherer(), promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	})
+}
+
+// RegisterHandler registers the handler serving all metrics to mux, the default path is /metrics.
+func RegisterHandler(mux *http.ServeMux, path ...string) {
+	pattern := DefaultPath
+	if len(path) > 0 && path[0] != "" {
+		pattern = path[0]
+	}
+	mux.Handle(pattern, Handler())
+}
+
+// labeledGatherer adds the standard labels to all metrics, the label that already exists is not overwritten.
+type labeledGatherer struct {
+	gatherer prometheus.Gatherer
+}
+
+func (g *labeledGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.gatherer.Gather()
+	labels := getStandardLabels()
+	if len(labels) == 0 {
+		return mfs, err
+	}
+
+	for _, mf := range mfs {
+		for _, m := range mf.Metric {
+			m.Label = mergeLabels(m.Label, labels)
+		}
+	}
+	return mfs, err
+}
+
+func mergeLabels(labels []*dto.LabelPair, addLabels []*dto.LabelPair) []*dto.LabelPair {
+	exists := make(map[string]struct{}, len(labels))
+	for _, l := range labels {
+		exists[l.GetName()] = struct{}{}
+	}
+	for _, l := range addLabels {
+		if _, ok := exists[l.GetName()]; !ok {
+			labels = append(labels, l)
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].GetName() < labels[j].GetName() })
+	return labels
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInit(t *testing.T) {
+	defer Init("", "", WithHost(""))
+
+	Init("user", "v1.0.0", WithHost("host-1"), WithConstLabels(map[string]string{"env": "dev", "service": "ignored"}))
+	labels := getStandardLabels()
+	names := []string{}
+	for _, l := range labels {
+		names = append(names, l.GetName()+"="+l.GetValue())
+	}
+	assert.Equal(t, []string{"env=dev", "host=host-1", "service=user", "version=v1.0.0"}, names)
+
+	Init("user", "", WithHost(""))
+	assert.Len(t, getStandardLabels(), 1)
+
+	assert.NotEmpty(t, defaultOptions().host)
+}
+
+func TestRegister(t *testing.T) {
+	c := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_register_total", Help: "test"})
+	assert.NoError(t, Register(c))
+	assert.NoError(t, Register(c))
+	MustRegister(c)
+	assert.Equal(t, registry, Registry())
+	assert.True(t, Unregister(c))
+
+	err := Register(prometheus.NewCounter(prometheus.CounterOpts{Name: "invalid-name", Help: "test"}))
+	assert.Error(t, err)
+	assert.Panics(t, func() {
+		MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: "invalid-name", Help: "test"}))
+	})
+}
+
+func TestHandler(t *testing.T) {
+	defer Init("", "", WithHost(""))
+	Init("user", "v1.0.0", WithHost("host-1"))
+
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_handler_total", Help: "test"}, []string{"service"})
+	MustRegister(c)
+	defer Unregister(c)
+	c.WithLabelValues("order").Inc()
+
+	mux := http.NewServeMux()
+	RegisterHandler(mux)
+	RegisterHandler(mux, "/custom/metrics")
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	for _, path := range []string{DefaultPath, "/custom/metrics"} {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		content := string(body)
+
+		// the existing label is not overwritten
+		assert.Contains(t, content, `test_handler_total{host="host-1",service="order",version="v1.0.0"} 1`)
+		// the metrics of prometheus.DefaultGatherer are served too
+		assert.True(t, strings.Contains(content, "go_goroutines{"))
+	}
+}
@@ -0,0 +1,37 @@
+package metrics
+
+// Option set the standard labels.
+type Option func(*options)
+
+type options struct {
+	host        string
+	constLabels map[string]string
+}
+
+func defaultOptions() *options {
+	return &options{
+		host: hostname(),
+	}
+}
+
+func (o *options) apply(opts ...Option) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithHost set the host label, default is hostname.
+// The label is not named instance, because prometheus renames it to exported_instance
+// to keep the instance label of the scrape target.
+func WithHost(host string) Option {
+	return func(o *options) {
+		o.host = host
+	}
+}
+
+// WithConstLabels set other labels added to all metrics, e.g. env, zone.
+func WithConstLabels(labels map[string]string) Option {
+	return func(o *options) {
+		o.constLabels = labels
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/nacos-group/nacos-sdk-go/v2/clients"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
@@ -93,15 +94,7 @@ func GetConfig(params *Params, opts ...Option) (string, []byte, error) {
 		return "", nil, err
 	}
 
-	setParams(params, opts...)
-
-	// create a dynamic configuration client
-	configClient, err := clients.NewConfigClient(
-		vo.NacosClientParam{
-			ClientConfig:  params.clientConfig,
-			ServerConfigs: params.serverConfigs,
-		},
-	)
+	configClient, err := NewConfigClient(params, opts...)
 	if err != nil {
 		return "", nil, err
 	}
@@ -118,6 +111,20 @@ func GetConfig(params *Params, opts ...Option) (string, []byte, error) {
 	return params.Format, []byte(data), err
 }
 
+// NewConfigClient create a dynamic configuration client of nacos.
+// Note: If parameter WithClientConfig is set, params.NamespaceID is invalid,
+// if parameter WithServerConfigs is set, params.IPAddr, params.Port, params.Scheme and params.ContextPath are invalid.
+func NewConfigClient(params *Params, opts ...Option) (config_client.IConfigClient, error) {
+	setParams(params, opts...)
+
+	return clients.NewConfigClient(
+		vo.NacosClientParam{
+			ClientConfig:  params.clientConfig,
+			ServerConfigs: params.serverConfigs,
+		},
+	)
+}
+
 // Init get configuration from nacos and parse to struct, use for configuration center
 //
 // Deprecated: use GetConfig instead.
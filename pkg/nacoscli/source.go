@@ -0,0 +1,100 @@
+package nacoscli
+
+import (
+	"context"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+
+	"github.com/18721889353/sunshine/pkg/conf"
+)
+
+// ConfigClient is the nacos configuration client used by Source, config_client.IConfigClient implements it.
+type ConfigClient interface {
+	GetConfig(param vo.ConfigParam) (string, error)
+	ListenConfig(params vo.ConfigParam) error
+	CancelListenConfig(params vo.ConfigParam) error
+}
+
+// Source is the configuration source of nacos, it implements conf.Source.
+type Source struct {
+	client ConfigClient
+	group  string
+	dataID string
+	format string
+}
+
+var _ conf.Source = (*Source)(nil)
+
+// NewSource create a configuration source of nacos.
+func NewSource(params *Params, opts ...Option) (*Source, error) {
+	err := params.valid()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := NewConfigClient(params, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSourceWithClient(client, params)
+}
+
+// NewSourceWithClient create a configuration source with the specified client, the client can be a fake for testing.
+func NewSourceWithClient(client ConfigClient, params *Params) (*Source, error) {
+	err := params.valid()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Source{
+		client: client,
+		group:  params.Group,
+		dataID: params.DataID,
+		format: params.Format,
+	}, nil
+}
+
+// Name returns the name of source.
+func (s *Source) Name() string {
+	return "nacos"
+}
+
+// Format returns the format of configuration data.
+func (s *Source) Format() string {
+	return s.format
+}
+
+// Load read the configuration data from nacos.
+func (s *Source) Load(_ context.Context) ([]byte, error) {
+	data, err := s.client.GetConfig(vo.ConfigParam{
+		DataId: s.dataID,
+		Group:  s.group,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(data), nil
+}
+
+// Watch listen for configuration changes in nacos until ctx is done.
+func (s *Source) Watch(ctx context.Context, onChange func(data []byte)) error {
+	param := vo.ConfigParam{
+		DataId: s.dataID,
+		Group:  s.group,
+		OnChange: func(_, _, _, data string) {
+			onChange([]byte(data))
+		},
+	}
+	err := s.client.ListenConfig(param)
+	if err != nil {
+		return err
+	}
+	// the configuration may be changed between Load and Watch
+	if data, err := s.Load(ctx); err == nil {
+		onChange(data)
+	}
+
+	<-ctx.Done()
+	return s.client.CancelListenConfig(param)
+}
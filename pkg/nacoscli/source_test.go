@@ -0,0 +1,95 @@
+package nacoscli
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/18721889353/sunshine/pkg/conf"
+)
+
+type fakeConfigClient struct {
+	mu        sync.Mutex
+	data      string
+	listeners map[string]func(namespace, group, dataID, data string)
+}
+
+func newFakeConfigClient(data string) *fakeConfigClient {
+	return &fakeConfigClient{data: data, listeners: make(map[string]func(namespace, group, dataID, data string))}
+}
+
+func (c *fakeConfigClient) GetConfig(_ vo.ConfigParam) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data, nil
+}
+
+func (c *fakeConfigClient) ListenConfig(params vo.ConfigParam) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners[params.Group+params.DataId] = params.OnChange
+	return nil
+}
+
+func (c *fakeConfigClient) CancelListenConfig(params vo.ConfigParam) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.listeners, params.Group+params.DataId)
+	return nil
+}
+
+func (c *fakeConfigClient) publish(group string, dataID string, data string) {
+	c.mu.Lock()
+	c.data = data
+	onChange := c.listeners[group+dataID]
+	c.mu.Unlock()
+	if onChange != nil {
+		onChange("", group, dataID, data)
+	}
+}
+
+func (c *fakeConfigClient) listening() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.listeners) > 0
+}
+
+func TestSource(t *testing.T) {
+	client := newFakeConfigClient("app:\n  name: foo\n")
+	params := &Params{Group: "dev", DataID: "app.yml", Format: "yml"}
+	source, err := NewSourceWithClient(client, params)
+	require.NoError(t, err)
+	assert.Equal(t, "nacos", source.Name())
+	assert.Equal(t, "yaml", source.Format())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan *conf.Event, 1)
+	cfg := make(map[string]interface{})
+	err = conf.ParseSources(ctx, &cfg, []conf.Source{source}, conf.WithOnChange(func(e *conf.Event) {
+		events <- e
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "foo"}, cfg["app"])
+
+	client.publish("dev", "app.yml", "app:\n  name: bar\n")
+	select {
+	case e := <-events:
+		assert.Equal(t, []string{"app.name"}, e.ChangedKeys)
+	case <-time.After(time.Second * 3):
+		t.Fatal("timeout")
+	}
+
+	cancel()
+	time.Sleep(time.Millisecond * 100)
+	assert.False(t, client.listening())
+
+	_, err = NewSourceWithClient(client, &Params{})
+	assert.Error(t, err)
+	_, err = NewSource(&Params{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,312 @@
+package prof
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/pprof"
+	"sync"
+	"time"
+)
+
+// the profile types supported by the continuous profiler
+const (
+	TypeCPU          = "cpu"
+	TypeHeap         = "heap"
+	TypeGoroutine    = "goroutine"
+	TypeMutex        = "mutex"
+	TypeBlock        = "block"
+	TypeThreadCreate = "threadcreate"
+
+	// TriggerInterval is the trigger label of the profiles collected at intervals
+	TriggerInterval = "interval"
+)
+
+// ProfilerOption set the continuous profiler options.
+type ProfilerOption func(*profilerOptions)
+
+type profilerOptions struct {
+	labels          map[string]string
+	interval        time.Duration
+	cpuDuration     time.Duration
+	types           []string
+	triggers        []Trigger
+	checkInterval   time.Duration
+	triggerCooldown time.Duration
+	uploadTimeout   time.Duration
+	errorHandler    func(err error)
+}
+
+func defaultProfilerOptions() *profilerOptions {
+	host, _ := os.Hostname()
+	return &profilerOptions{
+		labels:          map[string]string{"service": serverName, "host": host},
+		interval:        time.Minute,
+		cpuDuration:     10 * time.Second,
+		types:           []string{TypeCPU, TypeHeap, TypeGoroutine},
+		checkInterval:   5 * time.Second,
+		triggerCooldown: 5 * time.Minute,
+		uploadTimeout:   30 * time.Second,
+		errorHandler: func(err error) {
+			fmt.Printf("[profiler] %v\n", err)
+		},
+	}
+}
+
+func (o *profilerOptions) apply(opts ...ProfilerOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithService set the labels service and version of profiles, default service is the name of executable file.
+func WithService(name string, version string) ProfilerOption {
+	return func(o *profilerOptions) {
+		if name != "" {
+			o.labels["service"] = name
+		}
+		if version != "" {
+			o.labels["version"] = version
+		}
+	}
+}
+
+// WithHost set the label host of profiles, default is hostname, same as the standard label of metrics.
+func WithHost(host string) ProfilerOption {
+	return func(o *profilerOptions) {
+		if host != "" {
+			o.labels["host"] = host
+		}
+	}
+}
+
+// WithLabels set other labels of profiles, e.g. env, zone.
+func WithLabels(labels map[string]string) ProfilerOption {
+	return func(o *profilerOptions) {
+		for k, v := range labels {
+			o.labels[k] = v
+		}
+	}
+}
+
+// WithInterval set the interval of collecting profiles, default is 1 minute, 0 means only collecting when triggered.
+func WithInterval(d time.Duration) ProfilerOption {
+	return func(o *profilerOptions) {
+		if d < 0 {
+			return
+		}
+		o.interval = d
+	}
+}
+
+// WithCPUDuration set the duration of sampling cpu profile, default is 10 seconds.
+func WithCPUDuration(d time.Duration) ProfilerOption {
+	return func(o *profilerOptions) {
+		if d <= 0 {
+			return
+		}
+		o.cpuDuration = d
+	}
+}
+
+// WithTypes set the profile types collected, default is cpu, heap and goroutine, the mutex and block
+// profiles are empty unless runtime.SetMutexProfileFraction and runtime.SetBlockProfileRate are set.
+func WithTypes(types ...string) ProfilerOption {
+	return func(o *profilerOptions) {
+		if len(types) == 0 {
+			return
+		}
+		o.types = types
+	}
+}
+
+// WithTriggers set the triggers that collect profiles immediately, e.g. NewGoroutineTrigger, NewLatencyTrigger,
+// checkInterval is the interval of checking triggers, default is 5 seconds, cooldown is the minimum interval
+// between two triggered collections, default is 5 minutes.
+func WithTriggers(checkInterval time.Duration, cooldown time.Duration, triggers ...Trigger) ProfilerOption {
+	return func(o *profilerOptions) {
+		if checkInterval > 0 {
+			o.checkInterval = checkInterval
+		}
+		if cooldown >= 0 {
+			o.triggerCooldown = cooldown
+		}
+		o.triggers = append(o.triggers, triggers...)
+	}
+}
+
+// WithErrorHandler set the function handling the errors of collecting and uploading, default is printing them.
+func WithErrorHandler(fn func(err error)) ProfilerOption {
+	return func(o *profilerOptions) {
+		if fn != nil {
+			o.errorHandler = fn
+		}
+	}
+}
+
+// ------------------------------------------------------------------------------------------
+
+// Profiler is a continuous profiler, it collects profiles at intervals or when triggered, and pushes them
+// to the storage by the uploader.
+type Profiler struct {
+	uploader Uploader
+	opts     *profilerOptions
+
+	triggerCh chan string
+
+	mu          sync.Mutex
+	cancel      context.CancelFunc
+	done        chan struct{}
+	triggeredAt time.Time
+}
+
+// NewProfiler creates a continuous profiler, call Start to start it.
+func NewProfiler(uploader Uploader, opts ...ProfilerOption) *Profiler {
+	o := defaultProfilerOptions()
+	o.apply(opts...)
+
+	return &Profiler{
+		uploader:  uploader,
+		opts:      o,
+		triggerCh: make(chan string, 1),
+	}
+}
+
+// Start starts collecting profiles in background.
+func (p *Profiler) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		return // already started
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	go p.run(ctx, p.done)
+}
+
+// Stop stops collecting profiles, and waits for the collection in progress to finish.
+func (p *Profiler) Stop() {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.cancel = nil
+	p.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Trigger collects profiles immediately, reason is used as the label trigger of profiles, e.g. cpu, memory,
+// it is ignored if it is in cooldown or a triggered collection is waiting.
+func (p *Profiler) Trigger(reason string) {
+	select {
+	case p.triggerCh <- reason:
+	default:
+	}
+}
+
+func (p *Profiler) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	var intervalC <-chan time.Time
+	if p.opts.interval > 0 {
+		ticker := time.NewTicker(p.opts.interval)
+		defer ticker.Stop()
+		intervalC = ticker.C
+	}
+	var checkC <-chan time.Time
+	if len(p.opts.triggers) > 0 {
+		ticker := time.NewTicker(p.opts.checkInterval)
+		defer ticker.Stop()
+		checkC = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-intervalC:
+			p.collectAndUpload(ctx, TriggerInterval)
+		case <-checkC:
+			for _, t := range p.opts.triggers {
+				if t.Check() {
+					p.triggered(ctx, t.Name())
+					break
+				}
+			}
+		case reason := <-p.triggerCh:
+			p.triggered(ctx, reason)
+		}
+	}
+}
+
+func (p *Profiler) triggered(ctx context.Context, reason string) {
+	if !p.triggeredAt.IsZero() && time.Since(p.triggeredAt) < p.opts.triggerCooldown {
+		return
+	}
+	p.triggeredAt = time.Now()
+	p.collectAndUpload(ctx, reason)
+}
+
+func (p *Profiler) collectAndUpload(ctx context.Context, trigger string) {
+	for _, typ := range p.opts.types {
+		data, err := p.collect(ctx, typ, trigger)
+		if err != nil {
+			p.opts.errorHandler(fmt.Errorf("collect %s profile error: %v", typ, err))
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		uploadCtx, cancel := context.WithTimeout(context.Background(), p.opts.uploadTimeout)
+		err = p.uploader.Upload(uploadCtx, data)
+		cancel()
+		if err != nil {
+			p.opts.errorHandler(fmt.Errorf("upload %s profile error: %v", typ, err))
+		}
+	}
+}
+
+func (p *Profiler) collect(ctx context.Context, typ string, trigger string) (*ProfileData, error) {
+	buf := &bytes.Buffer{}
+	start := time.Now()
+
+	if typ == TypeCPU {
+		// fail if cpu profile is sampling by others, e.g. SIGTRAP or http pprof
+		if err := pprof.StartCPUProfile(buf); err != nil {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(p.opts.cpuDuration):
+		}
+		pprof.StopCPUProfile()
+	} else {
+		pp := pprof.Lookup(typ)
+		if pp == nil {
+			return nil, errors.New("unknown profile type")
+		}
+		if err := pp.WriteTo(buf, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	labels := make(map[string]string, len(p.opts.labels))
+	for k, v := range p.opts.labels {
+		labels[k] = v
+	}
+	return &ProfileData{
+		Type:    typ,
+		Data:    buf.Bytes(),
+		Start:   start,
+		End:     time.Now(),
+		Labels:  labels,
+		Trigger: trigger,
+	}, nil
+}
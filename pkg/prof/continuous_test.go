@@ -0,0 +1,180 @@
+package prof
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type chanUploader struct {
+	ch  chan *ProfileData
+	err error
+}
+
+func (u *chanUploader) Upload(ctx context.Context, p *ProfileData) error {
+	select {
+	case u.ch <- p:
+	default:
+	}
+	return u.err
+}
+
+func waitProfile(t *testing.T, ch chan *ProfileData) *ProfileData {
+	select {
+	case p := <-ch:
+		return p
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout")
+		return nil
+	}
+}
+
+func TestProfiler_Interval(t *testing.T) {
+	u := &chanUploader{ch: make(chan *ProfileData, 10)}
+	p := NewProfiler(u,
+		WithService("user", "v1.0.0"),
+		WithHost("host-1"),
+		WithLabels(map[string]string{"env": "dev"}),
+		WithInterval(time.Millisecond*100),
+		WithCPUDuration(time.Millisecond*100),
+		WithTypes(TypeCPU, TypeHeap),
+	)
+	p.Start()
+	p.Start() // ignored
+	defer p.Stop()
+
+	data := waitProfile(t, u.ch)
+	assert.Equal(t, TypeCPU, data.Type)
+	assert.Equal(t, TriggerInterval, data.Trigger)
+	assert.NotEmpty(t, data.Data)
+	assert.Equal(t, map[string]string{"service": "user", "version": "v1.0.0", "host": "host-1", "env": "dev"}, data.Labels)
+
+	data = waitProfile(t, u.ch)
+	assert.Equal(t, TypeHeap, data.Type)
+}
+
+func TestProfiler_Trigger(t *testing.T) {
+	var errs []error
+	u := &chanUploader{ch: make(chan *ProfileData, 10), err: errors.New("upload failed")}
+	p := NewProfiler(u,
+		WithInterval(0),
+		WithTypes(TypeGoroutine, "unknown"),
+		WithTriggers(time.Millisecond*50, time.Hour, NewGoroutineTrigger(0)),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }),
+	)
+	p.Start()
+
+	data := waitProfile(t, u.ch)
+	assert.Equal(t, TypeGoroutine, data.Type)
+	assert.Equal(t, "goroutine", data.Trigger)
+
+	// in cooldown
+	p.Trigger("cpu")
+	time.Sleep(time.Millisecond * 200)
+	p.Stop()
+	assert.Empty(t, u.ch)
+	assert.Len(t, errs, 2) // upload failed and unknown type
+}
+
+func TestProfiler_ManualTrigger(t *testing.T) {
+	u := &chanUploader{ch: make(chan *ProfileData, 10)}
+	p := NewProfiler(u, WithInterval(0), WithTypes(TypeHeap))
+	p.Start()
+	defer p.Stop()
+
+	p.Trigger("memory")
+	data := waitProfile(t, u.ch)
+	assert.Equal(t, "memory", data.Trigger)
+}
+
+func TestLatencyTrigger(t *testing.T) {
+	lt := NewLatencyTrigger(time.Millisecond*100, 2)
+	assert.Equal(t, "latency", lt.Name())
+
+	lt.Observe(time.Second)
+	assert.False(t, lt.Check()) // less than minCount
+
+	lt.Observe(time.Millisecond * 150)
+	lt.Observe(time.Millisecond * 150)
+	assert.True(t, lt.Check())
+	assert.False(t, lt.Check()) // reset
+
+	lt.Observe(time.Millisecond * 10)
+	lt.Observe(time.Millisecond * 10)
+	assert.False(t, lt.Check())
+
+	assert.NotNil(t, NewLatencyTrigger(time.Second, 0))
+}
+
+func TestGoroutineTrigger(t *testing.T) {
+	assert.True(t, NewGoroutineTrigger(0).Check())
+	assert.False(t, NewGoroutineTrigger(runtime.NumGoroutine()+1000).Check())
+}
+
+func TestIngestUploader(t *testing.T) {
+	var query string
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ingest" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		query = r.URL.Query().Get("name") + "," + r.URL.Query().Get("format")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	u := NewIngestUploader(server.URL+"/", map[string]string{"Authorization": "Bearer token"})
+	err := u.Upload(context.Background(), &ProfileData{
+		Type:    TypeCPU,
+		Data:    []byte("pprof data"),
+		Start:   time.Now(),
+		End:     time.Now(),
+		Labels:  map[string]string{"service": "user", "version": "v1.0.0", "host": "host-1"},
+		Trigger: TriggerInterval,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "user.cpu{host=host-1,version=v1.0.0,trigger=interval},pprof", query)
+	assert.Contains(t, body, `name="profile"`)
+	assert.Contains(t, body, "pprof data")
+
+	u = NewIngestUploader(server.URL+"/notfound", nil)
+	err = u.Upload(context.Background(), &ProfileData{Type: TypeHeap, Labels: map[string]string{}})
+	assert.Error(t, err)
+}
+
+func TestStorageUploader(t *testing.T) {
+	dir := t.TempDir()
+	u := NewStorageUploader(NewDirStorage(dir), "/profiles/")
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+	err := u.Upload(context.Background(), &ProfileData{
+		Type:    TypeHeap,
+		Data:    []byte("pprof data"),
+		Start:   start,
+		Labels:  map[string]string{"service": "user", "host": "host-1"},
+		Trigger: "memory",
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "profiles", "user", "host-1", "20240102T030405_memory_heap.pb.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, "pprof data", string(data))
+
+	key := storageKey("", &ProfileData{Type: TypeCPU, Start: start, Trigger: TriggerInterval, Labels: map[string]string{}})
+	assert.True(t, strings.HasSuffix(key, "20240102T030405_interval_cpu.pb.gz"))
+
+	err = NewStorageUploader(nil, "").Upload(context.Background(), &ProfileData{})
+	assert.Error(t, err)
+}
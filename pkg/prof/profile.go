@@ -11,6 +11,7 @@ import (
 	"runtime/pprof"
 	"runtime/trace"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
@@ -27,6 +28,9 @@ var (
 	status      uint32
 	statusStart uint32 = 1 // status=1
 	statusStop  uint32     // status=0
+
+	stopListenersMutex sync.RWMutex
+	stopListeners      []func(files []string)
 )
 
 // WaitSign wait system notification signals
@@ -139,14 +143,38 @@ func (p *profile) stopProfile() {
 	for _, fn := range p.closeFns {
 		fn()
 	}
+	files := p.files
 
 	select {
 	case p.stopCh <- struct{}{}:
 	default:
 	}
 
-	// reset profile
-	p = NewProfile() //nolint
+	// reset profile, the files of next sampling are recorded from scratch
+	p.files = nil
+	p.closeFns = nil
+
+	notifyStop(files)
+}
+
+// OnStop adds a function called with the profile files after sampling profile is stopped,
+// e.g. attach the files to the alarm notification.
+func OnStop(fn func(files []string)) {
+	stopListenersMutex.Lock()
+	defer stopListenersMutex.Unlock()
+	stopListeners = append(stopListeners, fn)
+}
+
+func notifyStop(files []string) {
+	stopListenersMutex.RLock()
+	listeners := stopListeners
+	stopListenersMutex.RUnlock()
+
+	for _, fn := range listeners {
+		fs := make([]string, len(files))
+		copy(fs, files)
+		fn(fs)
+	}
 }
 
 func (p *profile) checkTimeout() {
@@ -154,7 +182,7 @@ func (p *profile) checkTimeout() {
 		return
 	}
 
-	ctx, _ := context.WithTimeout(context.Background(), time.Second*time.Duration(durationSecond)) //nolint
+	ctx, _ := context.WithTimeout(context.Background(), time.Second*time.Duration(GetDurationSecond())) //nolint
 	select {
 	case <-p.stopCh:
 		fmt.Println("[profile] stop collecting profiles: manual")
@@ -313,6 +341,11 @@ func SetDurationSecond(d uint32) {
 	atomic.StoreUint32(&durationSecond, d)
 }
 
+// GetDurationSecond get sampling profile duration
+func GetDurationSecond() uint32 {
+	return atomic.LoadUint32(&durationSecond)
+}
+
 // EnableTrace enable sampling trace profile
 func EnableTrace() {
 	isSamplingTrace = true
@@ -3,6 +3,8 @@ package prof
 import (
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestProfile(t *testing.T) {
@@ -19,3 +21,31 @@ func TestProfile(t *testing.T) {
 	p.StartOrStop()
 	time.Sleep(time.Millisecond * 2100)
 }
+
+func TestOnStop(t *testing.T) {
+	SetDurationSecond(2)
+	filesCh := make(chan []string, 2)
+	OnStop(func(files []string) {
+		select {
+		case filesCh <- files:
+		default:
+		}
+	})
+
+	p := NewProfile()
+	p.StartOrStop()
+	time.Sleep(time.Millisecond * 100)
+	p.StartOrStop()
+
+	select {
+	case files := <-filesCh:
+		assert.NotEmpty(t, files)
+		for _, file := range files {
+			assert.FileExists(t, file)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	assert.Empty(t, p.files)
+	assert.Equal(t, uint32(2), GetDurationSecond())
+}
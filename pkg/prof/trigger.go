@@ -0,0 +1,88 @@
+package prof
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Trigger decides whether to collect profiles immediately, it is checked periodically by the continuous profiler.
+type Trigger interface {
+	// Name returns the name of trigger, which is used as the label trigger of profiles
+	Name() string
+	// Check returns true if the profiles should be collected
+	Check() bool
+}
+
+type funcTrigger struct {
+	name string
+	fn   func() bool
+}
+
+// NewFuncTrigger creates a trigger that collects profiles when fn returns true.
+func NewFuncTrigger(name string, fn func() bool) Trigger {
+	return &funcTrigger{name: name, fn: fn}
+}
+
+func (t *funcTrigger) Name() string {
+	return t.name
+}
+
+func (t *funcTrigger) Check() bool {
+	return t.fn()
+}
+
+// NewGoroutineTrigger creates a trigger that collects profiles when the number of goroutines exceeds threshold.
+func NewGoroutineTrigger(threshold int) Trigger {
+	return NewFuncTrigger("goroutine", func() bool {
+		return runtime.NumGoroutine() > threshold
+	})
+}
+
+// ------------------------------------------------------------------------------------------
+
+// LatencyTrigger collects profiles when the latency spikes, the average latency of the requests observed
+// since the last check exceeds threshold.
+type LatencyTrigger struct {
+	threshold time.Duration
+	minCount  int
+
+	mu    sync.Mutex
+	total time.Duration
+	count int
+}
+
+// NewLatencyTrigger creates a latency trigger, minCount is the minimum number of requests observed to avoid
+// false alarms of a few slow requests, the latency is observed by Observe, e.g. in http or grpc middleware.
+func NewLatencyTrigger(threshold time.Duration, minCount int) *LatencyTrigger {
+	if minCount < 1 {
+		minCount = 1
+	}
+	return &LatencyTrigger{threshold: threshold, minCount: minCount}
+}
+
+// Name returns latency.
+func (t *LatencyTrigger) Name() string {
+	return "latency"
+}
+
+// Observe records the latency of a request.
+func (t *LatencyTrigger) Observe(d time.Duration) {
+	t.mu.Lock()
+	t.total += d
+	t.count++
+	t.mu.Unlock()
+}
+
+// Check returns true if the average latency exceeds threshold, the observations are reset.
+func (t *LatencyTrigger) Check() bool {
+	t.mu.Lock()
+	total, count := t.total, t.count
+	t.total, t.count = 0, 0
+	t.mu.Unlock()
+
+	if count < t.minCount {
+		return false
+	}
+	return total/time.Duration(count) > t.threshold
+}
@@ -0,0 +1,179 @@
+package prof
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProfileData is a profile collected by the continuous profiler, the data is in gzipped pprof format.
+type ProfileData struct {
+	Type    string            // cpu, heap, goroutine, mutex, block, threadcreate
+	Data    []byte            // gzipped pprof data
+	Start   time.Time         // the start time of sampling
+	End     time.Time         // the end time of sampling
+	Labels  map[string]string // service, version, host and custom labels
+	Trigger string            // interval, or the name of trigger, e.g. goroutine, latency, cpu
+}
+
+// Uploader pushes the profile to the storage of profiles.
+type Uploader interface {
+	Upload(ctx context.Context, p *ProfileData) error
+}
+
+// ------------------------------------------------------------------------------------------
+
+type ingestUploader struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewIngestUploader creates an uploader that pushes the profiles to the HTTP ingest endpoint compatible with
+// pyroscope, e.g. http://localhost:4040, the path /ingest is appended automatically, headers is optional,
+// e.g. the authorization of grafana cloud.
+func NewIngestUploader(serverURL string, headers map[string]string) Uploader {
+	return &ingestUploader{
+		url:     strings.TrimSuffix(serverURL, "/") + "/ingest",
+		headers: headers,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// the name of application is service.type{label1=value1,label2=value2}
+func ingestName(p *ProfileData) string {
+	service := p.Labels["service"]
+	if service == "" {
+		service = serverName
+	}
+
+	keys := make([]string, 0, len(p.Labels))
+	for k := range p.Labels {
+		if k != "service" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys)+1)
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+p.Labels[k])
+	}
+	if p.Trigger != "" {
+		pairs = append(pairs, "trigger="+p.Trigger)
+	}
+
+	return fmt.Sprintf("%s.%s{%s}", service, p.Type, strings.Join(pairs, ","))
+}
+
+func (u *ingestUploader) Upload(ctx context.Context, p *ProfileData) error {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	fw, err := w.CreateFormFile("profile", "profile.pprof")
+	if err != nil {
+		return err
+	}
+	if _, err = fw.Write(p.Data); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("name", ingestName(p))
+	params.Set("from", strconv.FormatInt(p.Start.Unix(), 10))
+	params.Set("until", strconv.FormatInt(p.End.Unix(), 10))
+	params.Set("format", "pprof")
+	params.Set("spyName", "gospy")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.url+"?"+params.Encode(), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	for k, v := range u.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 500))
+		return fmt.Errorf("statusCode=%d, body=%s", resp.StatusCode, data)
+	}
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------
+
+// ObjectStorage is the object storage that the profiles are saved to, e.g. s3, oss, minio, local directory.
+type ObjectStorage interface {
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+type storageUploader struct {
+	storage ObjectStorage
+	prefix  string
+}
+
+// NewStorageUploader creates an uploader that saves the profiles to object storage, the key of object is
+// prefix/service/host/20060102T150405_trigger_type.pb.gz
+func NewStorageUploader(storage ObjectStorage, prefix string) Uploader {
+	return &storageUploader{storage: storage, prefix: strings.Trim(prefix, "/")}
+}
+
+func (u *storageUploader) Upload(ctx context.Context, p *ProfileData) error {
+	if u.storage == nil {
+		return errors.New("object storage is nil")
+	}
+	return u.storage.Put(ctx, storageKey(u.prefix, p), p.Data)
+}
+
+func storageKey(prefix string, p *ProfileData) string {
+	service := p.Labels["service"]
+	if service == "" {
+		service = serverName
+	}
+	host := p.Labels["host"]
+	if host == "" {
+		host = strconv.Itoa(pid)
+	}
+
+	name := fmt.Sprintf("%s_%s_%s.pb.gz", p.Start.Format(timeFormat), p.Trigger, p.Type)
+	elems := []string{service, host, name}
+	if prefix != "" {
+		elems = append([]string{prefix}, elems...)
+	}
+	return strings.Join(elems, "/")
+}
+
+type dirStorage struct {
+	dir string
+}
+
+// NewDirStorage creates an object storage saving the objects to local directory, the key is the relative path.
+func NewDirStorage(dir string) ObjectStorage {
+	return &dirStorage{dir: dir}
+}
+
+func (s *dirStorage) Put(_ context.Context, key string, data []byte) error {
+	file := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(file), 0766); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0666)
+}
@@ -233,18 +233,11 @@ func (p *Producer) PublishDirect(ctx context.Context, body []byte) error {
 	if p.Exchange.eType != exchangeTypeDirect {
 		return fmt.Errorf("invalid exchange type (%s), only supports direct type", p.Exchange.eType)
 	}
-	return p.ch.PublishWithContext(
-		ctx,
-		p.Exchange.name,
-		p.Exchange.routingKey,
-		p.mandatory,
-		false,
-		amqp.Publishing{
-			DeliveryMode: p.deliveryMode,
-			ContentType:  "text/plain",
-			Body:         body,
-		},
-	)
+	return p.publish(ctx, p.Exchange.routingKey, amqp.Publishing{
+		DeliveryMode: p.deliveryMode,
+		ContentType:  "text/plain",
+		Body:         body,
+	})
 }
 
 // PublishFanout send fanout type message
@@ -252,18 +245,11 @@ func (p *Producer) PublishFanout(ctx context.Context, body []byte) error {
 	if p.Exchange.eType != exchangeTypeFanout {
 		return fmt.Errorf("invalid exchange type (%s), only supports fanout type", p.Exchange.eType)
 	}
-	return p.ch.PublishWithContext(
-		ctx,
-		p.Exchange.name,
-		p.Exchange.routingKey,
-		p.mandatory,
-		false,
-		amqp.Publishing{
-			DeliveryMode: p.deliveryMode,
-			ContentType:  "text/plain",
-			Body:         body,
-		},
-	)
+	return p.publish(ctx, p.Exchange.routingKey, amqp.Publishing{
+		DeliveryMode: p.deliveryMode,
+		ContentType:  "text/plain",
+		Body:         body,
+	})
 }
 
 // PublishTopic send topic type message
@@ -271,18 +257,11 @@ func (p *Producer) PublishTopic(ctx context.Context, topicKey string, body []byt
 	if p.Exchange.eType != exchangeTypeTopic {
 		return fmt.Errorf("invalid exchange type (%s), only supports topic type", p.Exchange.eType)
 	}
-	return p.ch.PublishWithContext(
-		ctx,
-		p.Exchange.name,
-		topicKey,
-		p.mandatory,
-		false,
-		amqp.Publishing{
-			DeliveryMode: p.deliveryMode,
-			ContentType:  "text/plain",
-			Body:         body,
-		},
-	)
+	return p.publish(ctx, topicKey, amqp.Publishing{
+		DeliveryMode: p.deliveryMode,
+		ContentType:  "text/plain",
+		Body:         body,
+	})
 }
 
 // PublishHeaders send headers type message
@@ -290,19 +269,12 @@ func (p *Producer) PublishHeaders(ctx context.Context, headersKeys map[string]in
 	if p.Exchange.eType != exchangeTypeHeaders {
 		return fmt.Errorf("invalid exchange type (%s), only supports headers type", p.Exchange.eType)
 	}
-	return p.ch.PublishWithContext(
-		ctx,
-		p.Exchange.name,
-		p.Exchange.routingKey,
-		p.mandatory,
-		false,
-		amqp.Publishing{
-			DeliveryMode: p.deliveryMode,
-			Headers:      headersKeys,
-			ContentType:  "text/plain",
-			Body:         body,
-		},
-	)
+	return p.publish(ctx, p.Exchange.routingKey, amqp.Publishing{
+		DeliveryMode: p.deliveryMode,
+		Headers:      headersKeys,
+		ContentType:  "text/plain",
+		Body:         body,
+	})
 }
 
 // PublishDelayedMessage send delayed type message
@@ -329,19 +301,20 @@ func (p *Producer) PublishDelayedMessage(ctx context.Context, delayTime time.Dur
 	}
 	headersKeys["x-delay"] = int(delayTime / time.Millisecond) // delay time: milliseconds
 
-	return p.ch.PublishWithContext(
-		ctx,
-		p.Exchange.name,
-		routingKey,
-		p.mandatory,
-		false,
-		amqp.Publishing{
-			DeliveryMode: p.deliveryMode,
-			Headers:      headersKeys,
-			ContentType:  "text/plain",
-			Body:         body,
-		},
-	)
+	return p.publish(ctx, routingKey, amqp.Publishing{
+		DeliveryMode: p.deliveryMode,
+		Headers:      headersKeys,
+		ContentType:  "text/plain",
+		Body:         body,
+	})
+}
+
+// publish the message, the trace context is injected into the message headers
+func (p *Producer) publish(ctx context.Context, routingKey string, msg amqp.Publishing) error {
+	ctx, span := startPublishSpan(ctx, p.Exchange, routingKey, &msg)
+	err := p.ch.PublishWithContext(ctx, p.Exchange.name, routingKey, p.mandatory, false, msg)
+	endSpan(span, err)
+	return err
 }
 
 // Close the consumer
@@ -0,0 +1,109 @@
+package rabbitmq
+
+import (
+	"context"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/18721889353/sunshine/pkg/rabbitmq"
+
+// HeadersCarrier adapts amqp.Table to propagation.TextMapCarrier, trace context is injected
+// into the message headers when publishing and extracted from them when consuming.
+type HeadersCarrier amqp.Table
+
+var _ propagation.TextMapCarrier = HeadersCarrier{}
+
+// Get returns the value associated with the passed key.
+func (c HeadersCarrier) Get(key string) string {
+	if v, ok := c[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// Set stores the key-value pair.
+func (c HeadersCarrier) Set(key string, value string) {
+	c[key] = value
+}
+
+// Keys lists the keys stored in this carrier.
+func (c HeadersCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func exchangeName(exchange *Exchange) string {
+	if exchange == nil || exchange.name == "" {
+		return "(default)"
+	}
+	return exchange.name
+}
+
+// start a producer span and inject the trace context into a copy of the message headers
+func startPublishSpan(ctx context.Context, exchange *Exchange, routingKey string, msg *amqp.Publishing) (context.Context, trace.Span) {
+	name := exchangeName(exchange)
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, name+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemRabbitmq,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingOperationName("publish"),
+			semconv.MessagingDestinationName(name),
+			semconv.MessagingRabbitmqDestinationRoutingKey(routingKey),
+			semconv.MessagingMessageBodySize(len(msg.Body)),
+		),
+	)
+
+	headers := make(amqp.Table, len(msg.Headers)+2)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	otel.GetTextMapPropagator().Inject(ctx, HeadersCarrier(headers))
+	msg.Headers = headers
+
+	return ctx, span
+}
+
+// extract the trace context from the message headers and start a consumer span as its child
+func startConsumeSpan(ctx context.Context, queueName string, d *amqp.Delivery) (context.Context, trace.Span) {
+	if d.Headers != nil {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, HeadersCarrier(d.Headers))
+	}
+	return otel.Tracer(instrumentationName).Start(ctx, queueName+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemRabbitmq,
+			semconv.MessagingOperationTypeDeliver,
+			semconv.MessagingOperationName("process"),
+			semconv.MessagingDestinationName(d.Exchange),
+			semconv.MessagingRabbitmqDestinationRoutingKey(d.RoutingKey),
+			semconv.MessagingMessageBodySize(len(d.Body)),
+			attribute.String("messaging.rabbitmq.queue", queueName),
+			semconv.MessagingRabbitmqMessageDeliveryTag(int(d.DeliveryTag)),
+		),
+	)
+}
+
+// ExtractContext returns a context with the trace context carried by the message headers,
+// used to continue the trace in a custom consumer.
+func ExtractContext(ctx context.Context, headers amqp.Table) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, HeadersCarrier(headers))
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,66 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(tp)
+		otel.SetTextMapPropagator(propagator)
+	}()
+
+	// the headers of caller are not modified
+	headersKeys := map[string]interface{}{"x-match": "all", "foo": "bar"}
+	msg := amqp.Publishing{Headers: headersKeys, Body: []byte("hello")}
+	exchange := NewHeadersExchange("headers-exchange", HeadersTypeAll, headersKeys)
+	_, span := startPublishSpan(context.Background(), exchange, "", &msg)
+	endSpan(span, nil)
+	assert.Len(t, headersKeys, 2)
+	assert.NotEmpty(t, HeadersCarrier(msg.Headers).Get("traceparent"))
+	assert.Equal(t, "bar", HeadersCarrier(msg.Headers).Get("foo"))
+	assert.Len(t, HeadersCarrier(msg.Headers).Keys(), 3)
+
+	d := &amqp.Delivery{Headers: msg.Headers, Exchange: exchange.Name(), DeliveryTag: 1, Body: msg.Body}
+	ctx, span := startConsumeSpan(context.Background(), "test-queue", d)
+	endSpan(span, errors.New("handle error"))
+	assert.Equal(t, span.SpanContext().SpanID(), trace.SpanContextFromContext(ctx).SpanID())
+	extracted := trace.SpanContextFromContext(ExtractContext(context.Background(), d.Headers))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "headers-exchange publish", spans[0].Name())
+	assert.Equal(t, trace.SpanKindProducer, spans[0].SpanKind())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("messaging.system", "rabbitmq"))
+	assert.Equal(t, "test-queue process", spans[1].Name())
+	assert.Equal(t, trace.SpanKindConsumer, spans[1].SpanKind())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), spans[1].Parent().SpanID())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), extracted.SpanID())
+	assert.Contains(t, spans[1].Attributes(), attribute.String("messaging.destination.name", "headers-exchange"))
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+
+	// default exchange and no headers
+	_, span = startPublishSpan(context.Background(), NewDirectExchange("", "queue"), "queue", &amqp.Publishing{})
+	endSpan(span, nil)
+	_, span = startConsumeSpan(context.Background(), "queue", &amqp.Delivery{})
+	endSpan(span, nil)
+	assert.Equal(t, "(default) publish", recorder.Ended()[2].Name())
+	assert.Equal(t, "", HeadersCarrier(amqp.Table{"foo": 1}).Get("foo"))
+}
@@ -16,6 +16,9 @@ import (
 	"github.com/18721889353/sunshine/pkg/servicerd/registry"
 )
 
+// AttrKeyServiceInstance is the key of resolver address attributes, the value is *registry.ServiceInstance.
+const AttrKeyServiceInstance = "rawServiceInstance"
+
 type discoveryResolver struct {
 	w  registry.Watcher
 	cc resolver.ClientConn
@@ -51,6 +54,9 @@ func (r *discoveryResolver) update(ins []*registry.ServiceInstance) {
 	addrs := make([]resolver.Address, 0)
 	endpoints := make(map[string]struct{})
 	for _, in := range ins {
+		if in.Metadata[registry.MetadataKeyStatus] == registry.StatusUnhealthy {
+			continue
+		}
 		endpoint, err := parseEndpoint(in.Endpoints, "grpc", !r.insecure)
 		if err != nil {
 			//fmt.Printf("[resolver] Failed to parse discovery endpoint: %v\n", err)
@@ -69,7 +75,7 @@ func (r *discoveryResolver) update(ins []*registry.ServiceInstance) {
 			Attributes: parseAttributes(in.Metadata),
 			Addr:       endpoint,
 		}
-		addr.Attributes = addr.Attributes.WithValue("rawServiceInstance", in)
+		addr.Attributes = addr.Attributes.WithValue(AttrKeyServiceInstance, in)
 		addrs = append(addrs, addr)
 	}
 	if len(addrs) == 0 {
@@ -52,6 +52,50 @@ func Test_discoveryResolver_Close(t *testing.T) {
 	//time.Sleep(time.Millisecond * 100)
 }
 
+// records the state updated by resolver
+type stateConn struct {
+	cliConn
+	state resolver.State
+}
+
+func (c *stateConn) UpdateState(state resolver.State) error {
+	c.state = state
+	return nil
+}
+
+func Test_discoveryResolver_updateUnhealthy(t *testing.T) {
+	cc := &stateConn{}
+	r := &discoveryResolver{
+		cc:               cc,
+		insecure:         true,
+		debugLogDisabled: true,
+	}
+
+	r.update([]*registry.ServiceInstance{registry.NewServiceInstance(
+		"foo",
+		"bar",
+		[]string{"grpc://127.0.0.1:8282"},
+	), registry.NewServiceInstance(
+		"foo2",
+		"bar",
+		[]string{"grpc://127.0.0.1:8283"},
+		registry.WithMetadata(map[string]string{registry.MetadataKeyStatus: registry.StatusUnhealthy}),
+	)})
+	if assert.Len(t, cc.state.Addresses, 1) {
+		assert.Equal(t, "127.0.0.1:8282", cc.state.Addresses[0].Addr)
+	}
+
+	// all instances are unhealthy, the state is not updated
+	cc.state = resolver.State{}
+	r.update([]*registry.ServiceInstance{registry.NewServiceInstance(
+		"foo2",
+		"bar",
+		[]string{"grpc://127.0.0.1:8283"},
+		registry.WithMetadata(map[string]string{registry.MetadataKeyStatus: registry.StatusUnhealthy}),
+	)})
+	assert.Empty(t, cc.state.Addresses)
+}
+
 func Test_parseAttributes(t *testing.T) {
 	a := parseAttributes(map[string]string{"foo": "bar", "foo2": "bar2"})
 	assert.NotNil(t, a)
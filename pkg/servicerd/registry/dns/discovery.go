@@ -0,0 +1,172 @@
+// Package dns is service discovery based on dns A/AAAA or SRV records, suitable for kubernetes headless services,
+// the records are re-resolved periodically.
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/18721889353/sunshine/pkg/servicerd/registry"
+)
+
+var _ registry.Discovery = &Discovery{}
+
+// Option is dns discovery option.
+type Option func(o *options)
+
+type options struct {
+	resolver        *net.Resolver
+	refreshInterval time.Duration
+	scheme          string
+	defaultPort     int
+	srvService      string
+	srvProto        string
+	useSRV          bool
+}
+
+func defaultOptions() *options {
+	return &options{
+		resolver:        net.DefaultResolver,
+		refreshInterval: time.Second * 30,
+		scheme:          "grpc",
+	}
+}
+
+func (o *options) apply(opts ...Option) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithResolver set dns resolver, default is net.DefaultResolver.
+func WithResolver(resolver *net.Resolver) Option {
+	return func(o *options) {
+		if resolver != nil {
+			o.resolver = resolver
+		}
+	}
+}
+
+// WithRefreshInterval set the interval of re-resolution, default 30s.
+func WithRefreshInterval(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.refreshInterval = d
+		}
+	}
+}
+
+// WithScheme set the scheme of instance endpoints, default grpc.
+func WithScheme(scheme string) Option {
+	return func(o *options) {
+		o.scheme = scheme
+	}
+}
+
+// WithDefaultPort set the port used when the service name has no port, valid only for A records.
+func WithDefaultPort(port int) Option {
+	return func(o *options) {
+		o.defaultPort = port
+	}
+}
+
+// WithSRV resolve SRV records, the query is _service._proto.name, e.g. service=grpc, proto=tcp,
+// if service and proto are empty, the service name is queried directly.
+func WithSRV(service string, proto string) Option {
+	return func(o *options) {
+		o.useSRV = true
+		o.srvService = service
+		o.srvProto = proto
+	}
+}
+
+// Discovery is dns discovery, the service name is a domain name,
+// for A records it is host:port, e.g. user.default.svc.cluster.local:8282,
+// for SRV records it is the domain name, e.g. user.default.svc.cluster.local.
+type Discovery struct {
+	opts *options
+}
+
+// New creates a dns discovery.
+func New(opts ...Option) *Discovery {
+	o := defaultOptions()
+	o.apply(opts...)
+	return &Discovery{opts: o}
+}
+
+// GetService resolve the service instances according to the service name.
+func (d *Discovery) GetService(ctx context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+	var (
+		addrs []string
+		err   error
+	)
+	if d.opts.useSRV {
+		addrs, err = d.lookupSRV(ctx, serviceName)
+	} else {
+		addrs, err = d.lookupHost(ctx, serviceName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(addrs)
+	instances := make([]*registry.ServiceInstance, 0, len(addrs))
+	for _, addr := range addrs {
+		instances = append(instances, &registry.ServiceInstance{
+			ID:        addr,
+			Name:      serviceName,
+			Endpoints: []string{d.opts.scheme + "://" + addr},
+		})
+	}
+	return instances, nil
+}
+
+// Watch creates a watcher according to the service name.
+func (d *Discovery) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
+	return newWatcher(ctx, d, serviceName), nil
+}
+
+func (d *Discovery) lookupHost(ctx context.Context, serviceName string) ([]string, error) {
+	host, port, err := net.SplitHostPort(serviceName)
+	if err != nil {
+		if d.opts.defaultPort == 0 {
+			return nil, fmt.Errorf("missing port in service name %s", serviceName)
+		}
+		host, port = serviceName, strconv.Itoa(d.opts.defaultPort)
+	}
+
+	ips, err := d.opts.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, net.JoinHostPort(ip, port))
+	}
+	return addrs, nil
+}
+
+func (d *Discovery) lookupSRV(ctx context.Context, serviceName string) ([]string, error) {
+	_, srvs, err := d.opts.resolver.LookupSRV(ctx, d.opts.srvService, d.opts.srvProto, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []string
+	for _, srv := range srvs {
+		port := strconv.Itoa(int(srv.Port))
+		// resolve the target to ip, the target of headless service is the domain name of pod
+		ips, err := d.opts.resolver.LookupHost(ctx, srv.Target)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			addrs = append(addrs, net.JoinHostPort(ip, port))
+		}
+	}
+	return addrs, nil
+}
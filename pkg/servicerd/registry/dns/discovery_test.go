@@ -0,0 +1,40 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscovery_GetService(t *testing.T) {
+	d := New(
+		WithResolver(net.DefaultResolver),
+		WithRefreshInterval(time.Second),
+		WithScheme("grpc"),
+	)
+	instances, err := d.GetService(context.Background(), "localhost:8282")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, instances)
+	for _, in := range instances {
+		assert.Equal(t, "localhost:8282", in.Name)
+		assert.Contains(t, in.Endpoints[0], ":8282")
+	}
+
+	// missing port
+	_, err = d.GetService(context.Background(), "localhost")
+	assert.Error(t, err)
+	d = New(WithDefaultPort(8282))
+	instances, err = d.GetService(context.Background(), "localhost")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, instances)
+
+	// srv
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	d = New(WithSRV("grpc", "tcp"))
+	_, err = d.GetService(ctx, "not-exist.invalid")
+	assert.Error(t, err)
+}
@@ -0,0 +1,76 @@
+package dns
+
+import (
+	"context"
+	"time"
+
+	"github.com/18721889353/sunshine/pkg/logger"
+	"github.com/18721889353/sunshine/pkg/servicerd/registry"
+)
+
+var _ registry.Watcher = &watcher{}
+
+type watcher struct {
+	d           *Discovery
+	serviceName string
+	first       bool
+	last        string // endpoints of the last result
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+func newWatcher(ctx context.Context, d *Discovery, serviceName string) *watcher {
+	w := &watcher{
+		d:           d,
+		serviceName: serviceName,
+		first:       true,
+	}
+	w.ctx, w.cancel = context.WithCancel(ctx)
+	return w
+}
+
+// Next returns the instances immediately the first time, then blocks until the resolved instances change.
+func (w *watcher) Next() ([]*registry.ServiceInstance, error) {
+	if w.first {
+		w.first = false
+		instances, err := w.d.GetService(w.ctx, w.serviceName)
+		if err != nil {
+			return nil, err
+		}
+		w.last = key(instances)
+		return instances, nil
+	}
+
+	ticker := time.NewTicker(w.d.opts.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case <-ticker.C:
+			instances, err := w.d.GetService(w.ctx, w.serviceName)
+			if err != nil {
+				logger.Warn("[dns discovery] failed to resolve service", logger.String("name", w.serviceName), logger.Err(err))
+				continue
+			}
+			if k := key(instances); k != w.last {
+				w.last = k
+				return instances, nil
+			}
+		}
+	}
+}
+
+func (w *watcher) Stop() error {
+	w.cancel()
+	return nil
+}
+
+// instances are sorted by address
+func key(instances []*registry.ServiceInstance) string {
+	k := ""
+	for _, in := range instances {
+		k += in.ID + ","
+	}
+	return k
+}
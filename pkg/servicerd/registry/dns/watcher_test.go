@@ -0,0 +1,31 @@
+package dns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcher(t *testing.T) {
+	d := New(WithRefreshInterval(time.Millisecond * 20))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	w, err := d.Watch(ctx, "localhost:8282")
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	instances, err := w.Next()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, instances)
+
+	// no change, blocks until context done
+	_, err = w.Next()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	w, _ = d.Watch(context.Background(), "localhost")
+	_, err = w.Next()
+	assert.Error(t, err)
+}
@@ -0,0 +1,256 @@
+// Package file is service discovery based on a local yaml or json file of service instances,
+// the file is watched and changes take effect immediately, suitable for local development and static deployment.
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+
+	"github.com/18721889353/sunshine/pkg/logger"
+	"github.com/18721889353/sunshine/pkg/servicerd/registry"
+)
+
+var _ registry.Discovery = &Discovery{}
+
+// errEmptyFile the file is empty when reloading, e.g. it is truncated before writing by editors or cp
+var errEmptyFile = errors.New("file is empty")
+
+// Instance service instance in the file, fields are the same as registry.ServiceInstance
+type Instance struct {
+	ID        string            `yaml:"id" json:"id"`
+	Name      string            `yaml:"name" json:"name"`
+	Version   string            `yaml:"version" json:"version"`
+	Metadata  map[string]string `yaml:"metadata" json:"metadata"`
+	Endpoints []string          `yaml:"endpoints" json:"endpoints"`
+}
+
+// Services the content of file, example of yaml:
+//
+//	services:
+//	  - id: "user_1"
+//	    name: "user"
+//	    version: "v1"
+//	    metadata: {"weight": "10"}
+//	    endpoints: ["grpc://127.0.0.1:8282"]
+type Services struct {
+	Services []*Instance `yaml:"services" json:"services"`
+}
+
+// Discovery is file discovery.
+type Discovery struct {
+	path string
+
+	mu        sync.RWMutex
+	instances []*registry.ServiceInstance
+	watchers  map[*watcher]struct{}
+	stamp     string // resolved path, size and modification time of the file loaded last time
+
+	fsWatcher *fsnotify.Watcher
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// New creates a file discovery, the file format is determined by the extension, .json is json, others are yaml.
+func New(path string) (*Discovery, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// watch the directory, the file may be replaced by editors or kubernetes configmap
+	if err = fsWatcher.Add(filepath.Dir(absPath)); err != nil {
+		_ = fsWatcher.Close()
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Discovery{
+		path:      absPath,
+		watchers:  make(map[*watcher]struct{}),
+		fsWatcher: fsWatcher,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	if err = d.load(false); err != nil {
+		_ = d.Close()
+		return nil, err
+	}
+
+	go d.watch()
+	return d, nil
+}
+
+// GetService return the service instances in memory according to the service name.
+func (d *Discovery) GetService(_ context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return filter(d.instances, serviceName), nil
+}
+
+// Watch creates a watcher according to the service name.
+func (d *Discovery) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
+	w := &watcher{
+		d:           d,
+		serviceName: serviceName,
+		first:       true,
+		event:       make(chan struct{}, 1),
+	}
+	w.ctx, w.cancel = context.WithCancel(ctx)
+
+	d.mu.Lock()
+	d.watchers[w] = struct{}{}
+	d.mu.Unlock()
+	return w, nil
+}
+
+// Close stop watching the file.
+func (d *Discovery) Close() error {
+	d.cancel()
+	return d.fsWatcher.Close()
+}
+
+// watch the directory of file, any event in the directory re-stats the resolved target of file, so that
+// the file replaced by editors and the ..data symlink swapped by kubernetes configmap are both detected.
+func (d *Discovery) watch() {
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case event, ok := <-d.fsWatcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			stamp, err := fileStamp(d.path)
+			if err != nil {
+				continue // the file may be being replaced, wait for the next event
+			}
+			d.mu.RLock()
+			changed := stamp != d.stamp
+			d.mu.RUnlock()
+			if !changed {
+				continue
+			}
+			if err = d.load(true); err != nil {
+				if errors.Is(err, errEmptyFile) {
+					continue // keep the previous instances, wait for the content to be written
+				}
+				logger.Warn("[file discovery] failed to load file", logger.String("path", d.path), logger.Err(err))
+				continue
+			}
+			d.notify()
+		case err, ok := <-d.fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Warn("[file discovery] watch error", logger.Err(err))
+		}
+	}
+}
+
+// the stamp of the resolved target of path, it changes when the file or the symlinks to it are replaced
+func fileStamp(path string) (string, error) {
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	fi, err := os.Stat(realPath)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%d:%d", realPath, fi.Size(), fi.ModTime().UnixNano()), nil
+}
+
+// load the file, if isReload is true, the empty file is not loaded
+func (d *Discovery) load(isReload bool) error {
+	stamp, err := fileStamp(d.path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		return err
+	}
+	if isReload && len(bytes.TrimSpace(data)) == 0 {
+		return errEmptyFile
+	}
+	instances, err := parse(data, strings.EqualFold(filepath.Ext(d.path), ".json"))
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.instances = instances
+	d.stamp = stamp
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *Discovery) notify() {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for w := range d.watchers {
+		select {
+		case w.event <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func parse(data []byte, isJSON bool) ([]*registry.ServiceInstance, error) {
+	services := &Services{}
+	var err error
+	if isJSON {
+		err = json.Unmarshal(data, services)
+	} else {
+		err = yaml.Unmarshal(data, services)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]*registry.ServiceInstance, 0, len(services.Services))
+	for _, in := range services.Services {
+		if in.Name == "" {
+			return nil, errors.New("service name is empty")
+		}
+		id := in.ID
+		if id == "" && len(in.Endpoints) > 0 {
+			id = in.Name + "_" + in.Endpoints[0]
+		}
+		instances = append(instances, &registry.ServiceInstance{
+			ID:        id,
+			Name:      in.Name,
+			Version:   in.Version,
+			Metadata:  in.Metadata,
+			Endpoints: in.Endpoints,
+		})
+	}
+	return instances, nil
+}
+
+func filter(instances []*registry.ServiceInstance, serviceName string) []*registry.ServiceInstance {
+	items := make([]*registry.ServiceInstance, 0, len(instances))
+	for _, in := range instances {
+		if in.Name == serviceName {
+			items = append(items, in)
+		}
+	}
+	return items
+}
@@ -0,0 +1,60 @@
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var servicesYAML = `services:
+  - id: "user_1"
+    name: "user"
+    version: "v1"
+    metadata: {"weight": "10"}
+    endpoints: ["grpc://127.0.0.1:8282"]
+  - id: "user_2"
+    name: "user"
+    version: "v2"
+    endpoints: ["grpc://127.0.0.1:8283"]
+  - id: "order_1"
+    name: "order"
+    endpoints: ["grpc://127.0.0.1:9282"]`
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+
+	// yaml
+	path := filepath.Join(dir, "services.yml")
+	writeFile(t, path, servicesYAML)
+	d, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instances, err := d.GetService(context.Background(), "user")
+	assert.NoError(t, err)
+	assert.Len(t, instances, 2)
+	assert.Equal(t, "v1", instances[0].Version)
+	assert.Equal(t, "10", instances[0].Metadata["weight"])
+	assert.NoError(t, d.Close())
+
+	// json
+	path = filepath.Join(dir, "services.json")
+	writeFile(t, path, `{"services": [{"id": "order_1", "name": "order", "endpoints": ["grpc://127.0.0.1:9282"]}]}`)
+	d, err = New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instances, _ = d.GetService(context.Background(), "order")
+	assert.Len(t, instances, 1)
+	assert.NoError(t, d.Close())
+
+	// error
+	_, err = New(filepath.Join(dir, "not_found.yml"))
+	assert.Error(t, err)
+	path = filepath.Join(dir, "invalid.yml")
+	writeFile(t, path, `services: [{"endpoints": ["grpc://127.0.0.1:9282"]}]`)
+	_, err = New(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,40 @@
+package file
+
+import (
+	"context"
+
+	"github.com/18721889353/sunshine/pkg/servicerd/registry"
+)
+
+var _ registry.Watcher = &watcher{}
+
+type watcher struct {
+	d           *Discovery
+	serviceName string
+	first       bool
+	event       chan struct{}
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+func (w *watcher) Next() ([]*registry.ServiceInstance, error) {
+	if w.first {
+		w.first = false
+		return w.d.GetService(w.ctx, w.serviceName)
+	}
+
+	select {
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	case <-w.event:
+		return w.d.GetService(w.ctx, w.serviceName)
+	}
+}
+
+func (w *watcher) Stop() error {
+	w.cancel()
+	w.d.mu.Lock()
+	delete(w.d.watchers, w)
+	w.d.mu.Unlock()
+	return nil
+}
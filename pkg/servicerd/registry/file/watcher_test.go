@@ -0,0 +1,137 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "services.yml")
+	writeFile(t, path, servicesYAML)
+	d, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	w, err := d.Watch(ctx, "user")
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	instances, err := w.Next()
+	assert.NoError(t, err)
+	assert.Len(t, instances, 2)
+
+	// add an instance, the file is truncated before writing, wait for the update of new content
+	writeFile(t, path, servicesYAML+`
+  - name: "user"
+    endpoints: ["grpc://127.0.0.1:8284"]
+`)
+	for len(instances) != 3 {
+		instances, err = w.Next()
+		require.NoError(t, err)
+		require.NotEmpty(t, instances)
+	}
+	assert.Equal(t, "user_grpc://127.0.0.1:8284", instances[2].ID)
+
+	// stopped
+	_ = w.Stop()
+	_, err = w.Next()
+	assert.Error(t, err)
+}
+
+func TestWatcher_emptyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "services.yml")
+	writeFile(t, path, servicesYAML)
+	d, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	// the empty file keeps the previous instances
+	writeFile(t, path, "")
+	time.Sleep(time.Millisecond * 200)
+	instances, err := d.GetService(context.Background(), "user")
+	assert.NoError(t, err)
+	assert.Len(t, instances, 2)
+}
+
+func TestWatcher_timeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "services.yml")
+	writeFile(t, path, servicesYAML)
+	d, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	w, _ := d.Watch(ctx, "order")
+	instances, err := w.Next()
+	assert.NoError(t, err)
+	assert.Len(t, instances, 1)
+	_, err = w.Next()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// the layout of kubernetes configmap volume, the ..data symlink is swapped atomically when updating
+func TestWatcher_configMap(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigMap := func(version string, content string) {
+		dataDir := filepath.Join(dir, "..2024_"+version)
+		if err := os.Mkdir(dataDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(dataDir, "services.yml"), content)
+		tmpLink := filepath.Join(dir, "..data_tmp")
+		if err := os.Symlink(filepath.Base(dataDir), tmpLink); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmpLink, filepath.Join(dir, "..data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfigMap("1", servicesYAML)
+	path := filepath.Join(dir, "services.yml")
+	if err := os.Symlink(filepath.Join("..data", "services.yml"), path); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	w, err := d.Watch(context.Background(), "order")
+	assert.NoError(t, err)
+	defer w.Stop()
+	instances, err := w.Next()
+	assert.NoError(t, err)
+	assert.Len(t, instances, 1)
+
+	writeConfigMap("2", servicesYAML+`
+  - name: "order"
+    endpoints: ["grpc://127.0.0.1:9283"]
+`)
+	_ = os.RemoveAll(filepath.Join(dir, "..2024_1"))
+
+	instances, err = w.Next()
+	assert.NoError(t, err)
+	assert.Len(t, instances, 2)
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
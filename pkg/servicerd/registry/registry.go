@@ -30,6 +30,13 @@ type Watcher interface {
 	Stop() error
 }
 
+const (
+	// MetadataKeyStatus key of health status in metadata of service instance
+	MetadataKeyStatus = "status"
+	// StatusUnhealthy the instance is registered but unhealthy, it is skipped by discovery
+	StatusUnhealthy = "unhealthy"
+)
+
 // ServiceInstance is an instance of a service in a discovery system.
 type ServiceInstance struct {
 	// ID is the unique instance ID as registered.
@@ -140,6 +140,11 @@ func (b *Breaker) Allow() error {
 	return nil
 }
 
+// State returns the current state of the breaker, StateOpen or StateClosed.
+func (b *Breaker) State() int32 {
+	return atomic.LoadInt32(&b.state)
+}
+
 // MarkSuccess mark request is success.
 func (b *Breaker) MarkSuccess() {
 	b.stat.Add(1)
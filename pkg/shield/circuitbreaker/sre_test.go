@@ -188,3 +188,12 @@ func TestNewBreaker(t *testing.T) {
 
 	assert.NotNil(t, breaker)
 }
+
+func TestBreakerState(t *testing.T) {
+	b := getSREBreaker()
+	assert.Equal(t, StateClosed, b.State())
+
+	markFailed(b, 10000)
+	_ = b.Allow()
+	assert.Equal(t, StateOpen, b.State())
+}
@@ -1,8 +1,15 @@
 package stat
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"sync"
 	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/18721889353/sunshine/pkg/prof"
 )
 
 var (
@@ -14,7 +21,21 @@ var (
 // AlarmOption set the alarm options field.
 type AlarmOption func(*alarmOptions)
 
-type alarmOptions struct{}
+type alarmOptions struct {
+	serviceName   string
+	notifiers     []Notifier
+	cooldown      time.Duration
+	attachProfile bool
+	notifyTimeout time.Duration
+	profiler      *prof.Profiler
+}
+
+func defaultAlarmOptions() *alarmOptions {
+	return &alarmOptions{
+		cooldown:      time.Duration(triggerInterval) * time.Second,
+		notifyTimeout: 10 * time.Second,
+	}
+}
 
 func (o *alarmOptions) apply(opts ...AlarmOption) {
 	for _, opt := range opts {
@@ -42,9 +63,52 @@ func WithMemoryThreshold(threshold float64) AlarmOption {
 	}
 }
 
+// WithServiceName set the service name shown in the alert
+func WithServiceName(name string) AlarmOption {
+	return func(o *alarmOptions) {
+		o.serviceName = name
+	}
+}
+
+// WithNotifiers set the notifiers that send the alert to humans, e.g. webhook, email, dingtalk, feishu, slack
+func WithNotifiers(notifiers ...Notifier) AlarmOption {
+	return func(o *alarmOptions) {
+		o.notifiers = append(o.notifiers, notifiers...)
+	}
+}
+
+// WithNotifyCooldown set the minimum interval between two alerts of the same kind sent by a notifier, default is 15 minutes
+func WithNotifyCooldown(d time.Duration) AlarmOption {
+	return func(o *alarmOptions) {
+		if d < 0 {
+			return
+		}
+		o.cooldown = d
+	}
+}
+
+// WithAttachProfile attach the profile files captured by pkg/prof to the alert, the alert is sent after the
+// sampling profile is stopped, it works with pkg/app which samples profile when receiving SIGTRAP.
+func WithAttachProfile() AlarmOption {
+	return func(o *alarmOptions) {
+		o.attachProfile = true
+	}
+}
+
+// WithProfiler set the continuous profiler that collects profiles immediately when the alarm is triggered,
+// the label trigger of profiles is cpu or memory
+func WithProfiler(p *prof.Profiler) AlarmOption {
+	return func(o *alarmOptions) {
+		o.profiler = p
+	}
+}
+
 type statGroup struct {
 	data    [3]*statData
 	alarmAt time.Time
+
+	kind   string // the kind of the last alarm, cpu or memory
+	reason string // the reason of the last alarm
 }
 
 func newStatGroup() *statGroup {
@@ -87,7 +151,7 @@ func (g *statGroup) checkCPU(threshold float64) bool {
 	average := (g.data[0].proc.CPUUsage + g.data[1].proc.CPUUsage + g.data[2].proc.CPUUsage) / 3 / float64(g.data[0].sys.CPUCores)
 	threshold = threshold * 100
 	if average >= threshold {
-		fmt.Printf("[cpu] processes cpu usage(%.f%%) exceeds %.f%%\n", average, threshold)
+		g.setReason("cpu", fmt.Sprintf("processes cpu usage(%.f%%) exceeds %.f%%", average, threshold))
 		return true
 	}
 
@@ -103,9 +167,116 @@ func (g *statGroup) checkMemory(threshold float64) bool {
 	procAverage := (g.data[0].proc.RSS + g.data[1].proc.RSS + g.data[2].proc.RSS) / 3
 	procAverageUsage := float64(procAverage) / float64(g.data[0].sys.MemTotal)
 	if procAverageUsage >= threshold {
-		fmt.Printf("[memory] processes memory usage(%.f%%) exceeds %.f%%\n", procAverageUsage*100, threshold*100)
+		g.setReason("memory", fmt.Sprintf("processes memory usage(%.f%%) exceeds %.f%%", procAverageUsage*100, threshold*100))
 		return true
 	}
 
 	return false
 }
+
+func (g *statGroup) setReason(kind string, reason string) {
+	g.kind = kind
+	g.reason = reason
+	fmt.Printf("[%s] %s\n", kind, reason)
+}
+
+// ------------------------------------------------------------------------------------------
+
+// alarmNotifier sends the alert to notifiers, the alert of the same kind is sent by a notifier at most once in cooldown
+type alarmNotifier struct {
+	opts *alarmOptions
+	host string
+
+	mu     sync.Mutex
+	sentAt map[cooldownKey]time.Time
+
+	filesCh chan []string
+}
+
+func newAlarmNotifier(opts *alarmOptions) *alarmNotifier {
+	host, _ := os.Hostname()
+	n := &alarmNotifier{
+		opts:    opts,
+		host:    host,
+		sentAt:  make(map[cooldownKey]time.Time),
+		filesCh: make(chan []string, 1),
+	}
+	if opts.attachProfile {
+		prof.OnStop(func(files []string) {
+			select {
+			case n.filesCh <- files:
+			default:
+			}
+		})
+	}
+	return n
+}
+
+func (n *alarmNotifier) newAlert(g *statGroup, data *statData) *Alert {
+	return &Alert{
+		Service: n.opts.serviceName,
+		Host:    n.host,
+		Kind:    g.kind,
+		Reason:  g.reason,
+		Time:    time.Now(),
+		System:  data.sys,
+		Process: data.proc,
+	}
+}
+
+// wait for the profile files, the alert is sent without files if the sampling profile is not stopped in time
+func (n *alarmNotifier) waitProfileFiles() []string {
+	// drop the files of previous sampling
+	select {
+	case <-n.filesCh:
+	default:
+	}
+
+	wait := time.Duration(prof.GetDurationSecond())*time.Second + 10*time.Second
+	select {
+	case files := <-n.filesCh:
+		return files
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+func (n *alarmNotifier) notify(alert *Alert) {
+	if len(n.opts.notifiers) == 0 {
+		return
+	}
+	if n.opts.attachProfile {
+		alert.Files = n.waitProfileFiles()
+	}
+
+	for i, notifier := range n.opts.notifiers {
+		if !n.allow(i, alert.Kind) {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), n.opts.notifyTimeout)
+		err := notifier.Notify(ctx, alert)
+		cancel()
+		if err != nil {
+			zapLog.Warn("send alarm notification failed", zap.String("notifier", notifier.Name()), zap.Error(err))
+		}
+	}
+}
+
+// cooldownKey is keyed by the index of notifier, the notifiers of the same type have the same name,
+// e.g. two webhooks, they should not share the cooldown.
+type cooldownKey struct {
+	index int
+	kind  string
+}
+
+func (n *alarmNotifier) allow(index int, kind string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := cooldownKey{index: index, kind: kind}
+	if sentAt, ok := n.sentAt[key]; ok && time.Since(sentAt) < n.opts.cooldown {
+		return false
+	}
+	n.sentAt[key] = time.Now()
+	return true
+}
@@ -0,0 +1,80 @@
+package stat
+
+import (
+	"context"
+	"sync"
+
+	"github.com/18721889353/sunshine/pkg/metrics"
+)
+
+// the statistics are exported as gauges to the shared registry of pkg/metrics
+type statGauges struct {
+	sysCPUUsage  *metrics.Gauge
+	sysCPUCores  *metrics.Gauge
+	sysMemTotal  *metrics.Gauge
+	sysMemFree   *metrics.Gauge
+	sysMemUsage  *metrics.Gauge
+	procCPUUsage *metrics.Gauge
+	procRSS      *metrics.Gauge
+	procVMS      *metrics.Gauge
+	procAlloc    *metrics.Gauge
+	procTotal    *metrics.Gauge
+	procSys      *metrics.Gauge
+	procNumGc    *metrics.Gauge
+	procRoutines *metrics.Gauge
+
+	alarms *metrics.Counter
+}
+
+var (
+	gauges     *statGauges
+	gaugesOnce sync.Once
+)
+
+func getGauges() *statGauges {
+	gaugesOnce.Do(func() {
+		gauges = &statGauges{
+			sysCPUUsage:  metrics.NewGauge("stat_system_cpu_usage_percent", "System cpu usage, unit(%)."),
+			sysCPUCores:  metrics.NewGauge("stat_system_cpu_cores", "Number of cpu cores, multiple cpu accumulation."),
+			sysMemTotal:  metrics.NewGauge("stat_system_memory_total_megabytes", "System total physical memory, unit(M)."),
+			sysMemFree:   metrics.NewGauge("stat_system_memory_free_megabytes", "System free physical memory, unit(M)."),
+			sysMemUsage:  metrics.NewGauge("stat_system_memory_usage_percent", "System memory usage, unit(%)."),
+			procCPUUsage: metrics.NewGauge("stat_process_cpu_usage_percent", "Process cpu usage, unit(%)."),
+			procRSS:      metrics.NewGauge("stat_process_rss_megabytes", "Use of physical memory, unit(M)."),
+			procVMS:      metrics.NewGauge("stat_process_vms_megabytes", "Use of virtual memory, unit(M)."),
+			procAlloc:    metrics.NewGauge("stat_process_alloc_megabytes", "Allocated memory capacity, unit(M)."),
+			procTotal:    metrics.NewGauge("stat_process_total_alloc_megabytes", "Cumulative allocated memory capacity, unit(M)."),
+			procSys:      metrics.NewGauge("stat_process_sys_megabytes", "Requesting memory capacity from the system, unit(M)."),
+			procNumGc:    metrics.NewGauge("stat_process_num_gc", "Number of GC cycles."),
+			procRoutines: metrics.NewGauge("stat_process_goroutines", "Number of goroutines."),
+			alarms:       metrics.NewCounter("stat_alarms_total", "Number of alarms triggered.", "kind"),
+		}
+	})
+	return gauges
+}
+
+func recordMetrics(data *statData) {
+	if data == nil {
+		return
+	}
+	g := getGauges()
+
+	g.sysCPUUsage.Set(data.sys.CPUUsage)
+	g.sysCPUCores.Set(float64(data.sys.CPUCores))
+	g.sysMemTotal.Set(float64(data.sys.MemTotal))
+	g.sysMemFree.Set(float64(data.sys.MemFree))
+	g.sysMemUsage.Set(data.sys.MemUsage)
+
+	g.procCPUUsage.Set(data.proc.CPUUsage)
+	g.procRSS.Set(float64(data.proc.RSS))
+	g.procVMS.Set(float64(data.proc.VMS))
+	g.procAlloc.Set(float64(data.proc.Alloc))
+	g.procTotal.Set(float64(data.proc.TotalAlloc))
+	g.procSys.Set(float64(data.proc.Sys))
+	g.procNumGc.Set(float64(data.proc.NumGc))
+	g.procRoutines.Set(float64(data.proc.Goroutines))
+}
+
+func recordAlarm(kind string) {
+	getGauges().alarms.Inc(context.Background(), kind)
+}
@@ -0,0 +1,23 @@
+package stat
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_recordMetrics(t *testing.T) {
+	recordMetrics(nil)
+	recordMetrics(&statData{
+		sys:  system{CPUUsage: 12.5, CPUCores: 4, MemTotal: 8000},
+		proc: process{RSS: 64, Goroutines: 20},
+	})
+	recordAlarm("cpu")
+
+	g := getGauges()
+	assert.Equal(t, 12.5, testutil.ToFloat64(g.sysCPUUsage.Vec().WithLabelValues()))
+	assert.Equal(t, 4.0, testutil.ToFloat64(g.sysCPUCores.Vec().WithLabelValues()))
+	assert.Equal(t, 64.0, testutil.ToFloat64(g.procRSS.Vec().WithLabelValues()))
+	assert.Equal(t, 1.0, testutil.ToFloat64(g.alarms.Vec().WithLabelValues("cpu")))
+}
@@ -0,0 +1,324 @@
+package stat
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/18721889353/sunshine/pkg/httpcli"
+)
+
+// Alert is the alarm message sent to notifiers.
+type Alert struct {
+	Service string    `json:"service"`
+	Host    string    `json:"host"`
+	Kind    string    `json:"kind"`   // cpu or memory
+	Reason  string    `json:"reason"` // e.g. processes cpu usage(92%) exceeds 80%
+	Time    time.Time `json:"time"`
+	System  system    `json:"system"`
+	Process process   `json:"process"`
+	Files   []string  `json:"files,omitempty"` // the profile files captured by pkg/prof
+}
+
+// Text returns the alert as plain text, which is used by chat and email notifiers.
+func (a *Alert) Text() string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "[%s alarm] %s\n", a.Kind, a.Reason)
+	if a.Service != "" {
+		fmt.Fprintf(buf, "service: %s\n", a.Service)
+	}
+	fmt.Fprintf(buf, "host: %s\n", a.Host)
+	fmt.Fprintf(buf, "time: %s\n", a.Time.Format(time.RFC3339))
+	fmt.Fprintf(buf, "system: cpu %.1f%%, cores %d, memory %.f%%, total %dM, free %dM\n",
+		a.System.CPUUsage, a.System.CPUCores, a.System.MemUsage, a.System.MemTotal, a.System.MemFree)
+	fmt.Fprintf(buf, "process: cpu %.1f%%, rss %dM, vms %dM, alloc %dM, goroutines %d",
+		a.Process.CPUUsage, a.Process.RSS, a.Process.VMS, a.Process.Alloc, a.Process.Goroutines)
+	if len(a.Files) > 0 {
+		fmt.Fprintf(buf, "\nprofile files:\n%s", strings.Join(a.Files, "\n"))
+	}
+	return buf.String()
+}
+
+// Notifier sends the alert to humans.
+type Notifier interface {
+	// Name returns the name of notifier, the cooldown is counted by name
+	Name() string
+	// Notify sends the alert
+	Notify(ctx context.Context, alert *Alert) error
+}
+
+// ------------------------------------------------------------------------------------------
+
+// post the json body to url, the status code 2xx is successful
+func postJSON(ctx context.Context, urlStr string, body interface{}, headers map[string]string) ([]byte, error) {
+	resp, err := httpcli.New().
+		SetURL(urlStr).
+		SetContext(ctx).
+		SetContentType("application/json").
+		SetHeaders(headers).
+		SetBody(body).
+		POST()
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint
+
+	data, err := resp.ReadBody()
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("statusCode=%d, body=%s", resp.StatusCode, data)
+	}
+	return data, nil
+}
+
+type webhookNotifier struct {
+	url     string
+	headers map[string]string
+}
+
+// NewWebhookNotifier creates a notifier that posts the alert as json to url, headers is optional.
+func NewWebhookNotifier(url string, headers map[string]string) Notifier {
+	return &webhookNotifier{url: url, headers: headers}
+}
+
+func (n *webhookNotifier) Name() string {
+	return "webhook"
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, alert *Alert) error {
+	_, err := postJSON(ctx, n.url, alert, n.headers)
+	return err
+}
+
+// ------------------------------------------------------------------------------------------
+
+// the response of dingtalk and feishu robot, errcode or code is not 0 if failed
+type robotResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+	Code    int    `json:"code"`
+	Msg     string `json:"msg"`
+}
+
+func checkRobotResponse(data []byte) error {
+	resp := &robotResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil // not json, regarded as successful
+	}
+	if resp.ErrCode != 0 {
+		return fmt.Errorf("errcode=%d, errmsg=%s", resp.ErrCode, resp.ErrMsg)
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("code=%d, msg=%s", resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+type dingTalkNotifier struct {
+	url    string
+	secret string
+}
+
+// NewDingTalkNotifier creates a notifier that sends the alert to dingtalk robot, url is the webhook of robot,
+// secret is optional, it is required if the robot is secured by signature.
+func NewDingTalkNotifier(url string, secret string) Notifier {
+	return &dingTalkNotifier{url: url, secret: secret}
+}
+
+func (n *dingTalkNotifier) Name() string {
+	return "dingtalk"
+}
+
+func (n *dingTalkNotifier) Notify(ctx context.Context, alert *Alert) error {
+	urlStr := n.url
+	if n.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		mac := hmac.New(sha256.New, []byte(n.secret))
+		_, _ = mac.Write([]byte(timestamp + "\n" + n.secret))
+		sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		urlStr += joinQuery(urlStr) + "timestamp=" + timestamp + "&sign=" + url.QueryEscape(sign)
+	}
+
+	body := map[string]interface{}{
+		"msgtype": "text",
+		"text":    map[string]string{"content": alert.Text()},
+	}
+	data, err := postJSON(ctx, urlStr, body, nil)
+	if err != nil {
+		return err
+	}
+	return checkRobotResponse(data)
+}
+
+func joinQuery(urlStr string) string {
+	if strings.Contains(urlStr, "?") {
+		return "&"
+	}
+	return "?"
+}
+
+type feishuNotifier struct {
+	url    string
+	secret string
+}
+
+// NewFeishuNotifier creates a notifier that sends the alert to feishu robot, url is the webhook of robot,
+// secret is optional, it is required if the robot is secured by signature.
+func NewFeishuNotifier(url string, secret string) Notifier {
+	return &feishuNotifier{url: url, secret: secret}
+}
+
+func (n *feishuNotifier) Name() string {
+	return "feishu"
+}
+
+func (n *feishuNotifier) Notify(ctx context.Context, alert *Alert) error {
+	body := map[string]interface{}{
+		"msg_type": "text",
+		"content":  map[string]string{"text": alert.Text()},
+	}
+	if n.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(timestamp+"\n"+n.secret))
+		body["timestamp"] = timestamp
+		body["sign"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	data, err := postJSON(ctx, n.url, body, nil)
+	if err != nil {
+		return err
+	}
+	return checkRobotResponse(data)
+}
+
+type slackNotifier struct {
+	url string
+}
+
+// NewSlackNotifier creates a notifier that sends the alert to slack incoming webhook.
+func NewSlackNotifier(url string) Notifier {
+	return &slackNotifier{url: url}
+}
+
+func (n *slackNotifier) Name() string {
+	return "slack"
+}
+
+func (n *slackNotifier) Notify(ctx context.Context, alert *Alert) error {
+	body := map[string]string{"text": alert.Text()}
+	_, err := postJSON(ctx, n.url, body, nil)
+	return err
+}
+
+// ------------------------------------------------------------------------------------------
+
+// the attachments larger than this size are not sent by email
+const maxAttachmentSize = 10 << 20
+
+// EmailConfig email notifier settings
+type EmailConfig struct {
+	Addr     string   // smtp server address, e.g. smtp.example.com:587
+	Username string   // optional, the auth is skipped if it is empty
+	Password string   // optional
+	From     string   // sender address
+	To       []string // recipient addresses
+}
+
+type emailNotifier struct {
+	cfg *EmailConfig
+}
+
+// NewEmailNotifier creates a notifier that sends the alert by email, the profile files are attached.
+func NewEmailNotifier(cfg *EmailConfig) Notifier {
+	return &emailNotifier{cfg: cfg}
+}
+
+func (n *emailNotifier) Name() string {
+	return "email"
+}
+
+func (n *emailNotifier) Notify(ctx context.Context, alert *Alert) error {
+	if n.cfg == nil || n.cfg.Addr == "" || len(n.cfg.To) == 0 {
+		return errors.New("email addr and recipients are required")
+	}
+
+	msg, err := buildEmail(n.cfg.From, n.cfg.To, alert)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		host, _, _ := net.SplitHostPort(n.cfg.Addr)
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, host)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(n.cfg.Addr, auth, n.cfg.From, n.cfg.To, msg)
+	}()
+	select {
+	case err = <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func buildEmail(from string, to []string, alert *Alert) ([]byte, error) {
+	boundary := fmt.Sprintf("stat-alarm-%d", time.Now().UnixNano())
+	subject := fmt.Sprintf("[%s alarm] %s %s", alert.Kind, alert.Service, alert.Host)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "From: %s\r\n", from)
+	fmt.Fprintf(buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(buf, "Date: %s\r\n", alert.Time.Format(time.RFC1123Z))
+	fmt.Fprintf(buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", boundary)
+
+	fmt.Fprintf(buf, "--%s\r\n", boundary)
+	fmt.Fprintf(buf, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(alert.Text(), "\n", "\r\n"))
+	buf.WriteString("\r\n")
+
+	for _, file := range alert.Files {
+		info, err := os.Stat(file)
+		if err != nil || info.Size() > maxAttachmentSize {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(buf, "--%s\r\n", boundary)
+		fmt.Fprintf(buf, "Content-Type: application/octet-stream\r\n")
+		fmt.Fprintf(buf, "Content-Transfer-Encoding: base64\r\n")
+		fmt.Fprintf(buf, "Content-Disposition: attachment; filename=%q\r\n\r\n", filepath.Base(file))
+		encoded := base64.StdEncoding.EncodeToString(data)
+		for len(encoded) > 76 {
+			buf.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		buf.WriteString(encoded + "\r\n")
+	}
+
+	fmt.Fprintf(buf, "--%s--\r\n", boundary)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,277 @@
+package stat
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAlert() *Alert {
+	return &Alert{
+		Service: "user",
+		Host:    "host-1",
+		Kind:    "cpu",
+		Reason:  "processes cpu usage(92%) exceeds 80%",
+		Time:    time.Now(),
+		System:  system{CPUUsage: 90, CPUCores: 2, MemTotal: 6000, MemFree: 555, MemUsage: 56},
+		Process: process{CPUUsage: 184, RSS: 58, Goroutines: 10},
+	}
+}
+
+// records the body of the last request
+func newRecordServer(t *testing.T, respBody string) (*httptest.Server, *atomic.Value) {
+	body := &atomic.Value{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body.Store(r.URL.RawQuery + "\n" + string(data))
+		_, _ = w.Write([]byte(respBody))
+	}))
+	t.Cleanup(server.Close)
+	return server, body
+}
+
+func TestAlert_Text(t *testing.T) {
+	alert := newTestAlert()
+	alert.Files = []string{"/tmp/cpu.out"}
+	text := alert.Text()
+	assert.Contains(t, text, "[cpu alarm] processes cpu usage(92%) exceeds 80%")
+	assert.Contains(t, text, "service: user")
+	assert.Contains(t, text, "/tmp/cpu.out")
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	server, body := newRecordServer(t, "ok")
+	n := NewWebhookNotifier(server.URL, map[string]string{"X-Token": "123"})
+	assert.Equal(t, "webhook", n.Name())
+
+	err := n.Notify(context.Background(), newTestAlert())
+	require.NoError(t, err)
+	alert := &Alert{}
+	err = json.Unmarshal([]byte(strings.SplitN(body.Load().(string), "\n", 2)[1]), alert)
+	require.NoError(t, err)
+	assert.Equal(t, "cpu", alert.Kind)
+	assert.Equal(t, int32(2), alert.System.CPUCores)
+
+	errServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer errServer.Close()
+	err = NewWebhookNotifier(errServer.URL, nil).Notify(context.Background(), newTestAlert())
+	assert.Error(t, err)
+}
+
+func TestDingTalkNotifier(t *testing.T) {
+	server, body := newRecordServer(t, `{"errcode":0,"errmsg":"ok"}`)
+	n := NewDingTalkNotifier(server.URL+"?access_token=abc", "secret")
+	assert.Equal(t, "dingtalk", n.Name())
+
+	err := n.Notify(context.Background(), newTestAlert())
+	require.NoError(t, err)
+	content := body.Load().(string)
+	assert.Contains(t, content, "access_token=abc&timestamp=")
+	assert.Contains(t, content, "&sign=")
+	assert.Contains(t, content, `"msgtype":"text"`)
+
+	server, _ = newRecordServer(t, `{"errcode":310000,"errmsg":"sign not match"}`)
+	err = NewDingTalkNotifier(server.URL, "").Notify(context.Background(), newTestAlert())
+	assert.Error(t, err)
+}
+
+func TestFeishuNotifier(t *testing.T) {
+	server, body := newRecordServer(t, `{"code":0,"msg":"success"}`)
+	n := NewFeishuNotifier(server.URL, "secret")
+	assert.Equal(t, "feishu", n.Name())
+
+	err := n.Notify(context.Background(), newTestAlert())
+	require.NoError(t, err)
+	content := body.Load().(string)
+	assert.Contains(t, content, `"msg_type":"text"`)
+	assert.Contains(t, content, `"sign":`)
+
+	server, _ = newRecordServer(t, `{"code":19021,"msg":"sign match fail"}`)
+	err = NewFeishuNotifier(server.URL, "").Notify(context.Background(), newTestAlert())
+	assert.Error(t, err)
+}
+
+func TestSlackNotifier(t *testing.T) {
+	server, body := newRecordServer(t, "ok")
+	n := NewSlackNotifier(server.URL)
+	assert.Equal(t, "slack", n.Name())
+
+	err := n.Notify(context.Background(), newTestAlert())
+	require.NoError(t, err)
+	assert.Contains(t, body.Load().(string), `{"text":"[cpu alarm]`)
+}
+
+// a local smtp stand-in that receives one mail
+func runSMTPServer(t *testing.T) (string, chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	mailCh := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 end data with <CR><LF>.<CR><LF>")
+				mail := &strings.Builder{}
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					mail.WriteString(l)
+				}
+				mailCh <- mail.String()
+				reply("250 ok")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	return l.Addr().String(), mailCh
+}
+
+func TestEmailNotifier(t *testing.T) {
+	addr, mailCh := runSMTPServer(t)
+
+	file := filepath.Join(t.TempDir(), "cpu.out")
+	require.NoError(t, os.WriteFile(file, []byte("profile data"), 0666))
+	alert := newTestAlert()
+	alert.Files = []string{file, "not_exist.out"}
+
+	n := NewEmailNotifier(&EmailConfig{
+		Addr: addr,
+		From: "alarm@example.com",
+		To:   []string{"ops@example.com"},
+	})
+	assert.Equal(t, "email", n.Name())
+	err := n.Notify(context.Background(), alert)
+	require.NoError(t, err)
+
+	mail := <-mailCh
+	assert.Contains(t, mail, "To: ops@example.com")
+	assert.Contains(t, mail, "processes cpu usage(92%) exceeds 80%")
+	assert.Contains(t, mail, `filename="cpu.out"`)
+
+	err = NewEmailNotifier(&EmailConfig{}).Notify(context.Background(), alert)
+	assert.Error(t, err)
+}
+
+type fakeNotifier struct {
+	name  string
+	count int32
+	err   error
+}
+
+func (n *fakeNotifier) Name() string {
+	return n.name
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, alert *Alert) error {
+	atomic.AddInt32(&n.count, 1)
+	return n.err
+}
+
+func TestAlarmNotifier(t *testing.T) {
+	fn := &fakeNotifier{name: "fake"}
+	errFn := &fakeNotifier{name: "fake_error", err: errors.New("send failed")}
+	ao := defaultAlarmOptions()
+	ao.apply(
+		WithServiceName("user"),
+		WithNotifiers(fn),
+		WithNotifiers(errFn),
+		WithNotifyCooldown(-1), // invalid value
+		WithNotifyCooldown(time.Minute),
+	)
+	an := newAlarmNotifier(ao)
+
+	sg := newStatGroup()
+	sg.kind, sg.reason = "cpu", "cpu usage exceeds"
+	data := &statData{sys: system{CPUCores: 2}}
+	alert := an.newAlert(sg, data)
+	assert.Equal(t, "user", alert.Service)
+	assert.Equal(t, "cpu", alert.Kind)
+
+	an.notify(alert)
+	an.notify(alert) // in cooldown
+	sg.kind = "memory"
+	an.notify(an.newAlert(sg, data))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fn.count))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&errFn.count))
+}
+
+func TestAlarmNotifier_sameName(t *testing.T) {
+	fn1 := &fakeNotifier{name: "webhook"}
+	fn2 := &fakeNotifier{name: "webhook"}
+	ao := defaultAlarmOptions()
+	ao.apply(WithNotifiers(fn1, fn2), WithNotifyCooldown(time.Minute))
+	an := newAlarmNotifier(ao)
+
+	// the notifiers of the same type do not share the cooldown
+	an.notify(&Alert{Kind: "cpu"})
+	an.notify(&Alert{Kind: "cpu"})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fn1.count))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fn2.count))
+}
+
+func TestAlarmNotifier_AttachProfile(t *testing.T) {
+	fn := &fakeNotifier{name: "fake"}
+	ao := defaultAlarmOptions()
+	ao.apply(WithNotifiers(fn), WithAttachProfile())
+	an := newAlarmNotifier(ao)
+
+	done := make(chan *Alert)
+	go func() {
+		alert := &Alert{Kind: "memory"}
+		an.notify(alert)
+		done <- alert
+	}()
+
+	time.Sleep(time.Millisecond * 100)
+	an.filesCh <- []string{"/tmp/mem.out"}
+	select {
+	case alert := <-done:
+		assert.Equal(t, []string{"/tmp/mem.out"}, alert.Files)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+}
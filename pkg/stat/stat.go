@@ -23,8 +23,9 @@ var (
 type Option func(*options)
 
 type options struct {
-	enableAlarm bool
-	zapFields   []zap.Field
+	enableAlarm  bool
+	alarmOptions *alarmOptions
+	zapFields    []zap.Field
 }
 
 func (o *options) apply(opts ...Option) {
@@ -66,17 +67,23 @@ func WithAlarm(opts ...AlarmOption) Option {
 		if runtime.GOOS == "windows" {
 			return
 		}
-		ao := &alarmOptions{}
+		ao := defaultAlarmOptions()
 		ao.apply(opts...)
 		o.enableAlarm = true
+		o.alarmOptions = ao
 	}
 }
 
-// Init initialize statistical information
+// Init initialize statistical information, the statistics are exported as gauges to the shared registry of pkg/metrics.
 func Init(opts ...Option) {
 	o := &options{}
 	o.apply(opts...)
 
+	var an *alarmNotifier
+	if o.enableAlarm {
+		an = newAlarmNotifier(o.alarmOptions)
+	}
+
 	//nolint
 	go func() {
 		printTick := time.NewTicker(printInfoInterval)
@@ -87,9 +94,15 @@ func Init(opts ...Option) {
 			select {
 			case <-printTick.C:
 				data := printUsageInfo(o.zapFields...)
-				if o.enableAlarm {
+				recordMetrics(data)
+				if o.enableAlarm && data != nil {
 					if sg.check(data) {
+						recordAlarm(sg.kind)
 						sendSystemSignForLinux()
+						if o.alarmOptions.profiler != nil {
+							o.alarmOptions.profiler.Trigger(sg.kind)
+						}
+						go an.notify(an.newAlert(sg, data))
 					}
 				}
 			}
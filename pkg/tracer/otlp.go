@@ -0,0 +1,117 @@
+package tracer
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// OTLPOption set the otlp exporter options.
+type OTLPOption func(*otlpOptions)
+
+type otlpOptions struct {
+	insecure    bool
+	headers     map[string]string
+	timeout     time.Duration
+	compression bool
+	urlPath     string
+}
+
+func defaultOTLPOptions() *otlpOptions {
+	return &otlpOptions{
+		timeout: time.Second * 10,
+	}
+}
+
+func (o *otlpOptions) apply(opts ...OTLPOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithOTLPInsecure disable the client transport security.
+func WithOTLPInsecure() OTLPOption {
+	return func(o *otlpOptions) {
+		o.insecure = true
+	}
+}
+
+// WithOTLPHeaders set the headers sent with each export request, e.g. authentication token.
+func WithOTLPHeaders(headers map[string]string) OTLPOption {
+	return func(o *otlpOptions) {
+		o.headers = headers
+	}
+}
+
+// WithOTLPTimeout set the timeout of each export request, default is 10s.
+func WithOTLPTimeout(timeout time.Duration) OTLPOption {
+	return func(o *otlpOptions) {
+		if timeout > 0 {
+			o.timeout = timeout
+		}
+	}
+}
+
+// WithOTLPCompression enable gzip compression.
+func WithOTLPCompression() OTLPOption {
+	return func(o *otlpOptions) {
+		o.compression = true
+	}
+}
+
+// WithOTLPURLPath set the url path of http exporter, default is /v1/traces.
+func WithOTLPURLPath(urlPath string) OTLPOption {
+	return func(o *otlpOptions) {
+		o.urlPath = urlPath
+	}
+}
+
+// NewOTLPGrpcExporter create an otlp exporter over grpc, endpoint is host:port of collector, e.g. localhost:4317.
+func NewOTLPGrpcExporter(endpoint string, opts ...OTLPOption) (sdkTrace.SpanExporter, error) {
+	o := defaultOTLPOptions()
+	o.apply(opts...)
+
+	clientOpts := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(endpoint),
+		otlptracegrpc.WithTimeout(o.timeout),
+	}
+	if o.insecure {
+		clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+	}
+	if len(o.headers) > 0 {
+		clientOpts = append(clientOpts, otlptracegrpc.WithHeaders(o.headers))
+	}
+	if o.compression {
+		clientOpts = append(clientOpts, otlptracegrpc.WithCompressor("gzip"))
+	}
+
+	return otlptracegrpc.New(context.Background(), clientOpts...)
+}
+
+// NewOTLPHTTPExporter create an otlp exporter over http, endpoint is host:port of collector, e.g. localhost:4318.
+func NewOTLPHTTPExporter(endpoint string, opts ...OTLPOption) (sdkTrace.SpanExporter, error) {
+	o := defaultOTLPOptions()
+	o.apply(opts...)
+
+	clientOpts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(endpoint),
+		otlptracehttp.WithTimeout(o.timeout),
+	}
+	if o.insecure {
+		clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+	}
+	if len(o.headers) > 0 {
+		clientOpts = append(clientOpts, otlptracehttp.WithHeaders(o.headers))
+	}
+	if o.compression {
+		clientOpts = append(clientOpts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+	}
+	if o.urlPath != "" {
+		clientOpts = append(clientOpts, otlptracehttp.WithURLPath(o.urlPath))
+	}
+
+	return otlptracehttp.New(context.Background(), clientOpts...)
+}
@@ -0,0 +1,193 @@
+package tracer
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectorTrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// in-process otlp receiver, records the received span names and headers
+type otlpReceiver struct {
+	collectorTrace.UnimplementedTraceServiceServer
+
+	mu      sync.Mutex
+	spans   []string
+	headers map[string]string
+}
+
+func newOTLPReceiver() *otlpReceiver {
+	return &otlpReceiver{headers: map[string]string{}}
+}
+
+func (r *otlpReceiver) record(req *collectorTrace.ExportTraceServiceRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rs := range req.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			for _, span := range ss.GetSpans() {
+				r.spans = append(r.spans, span.GetName())
+			}
+		}
+	}
+}
+
+func (r *otlpReceiver) setHeader(key string, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.headers[key] = value
+}
+
+func (r *otlpReceiver) getSpans() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.spans...)
+}
+
+func (r *otlpReceiver) getHeader(key string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.headers[key]
+}
+
+func (r *otlpReceiver) Export(ctx context.Context, req *collectorTrace.ExportTraceServiceRequest) (*collectorTrace.ExportTraceServiceResponse, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("x-token"); len(values) > 0 {
+			r.setHeader("x-token", values[0])
+		}
+	}
+	r.record(req)
+	return &collectorTrace.ExportTraceServiceResponse{}, nil
+}
+
+func (r *otlpReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	data := &collectorTrace.ExportTraceServiceRequest{}
+	if err = proto.Unmarshal(body, data); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	r.setHeader("x-token", req.Header.Get("x-token"))
+	r.setHeader("path", req.URL.Path)
+	r.record(data)
+
+	out, _ := proto.Marshal(&collectorTrace.ExportTraceServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(out)
+}
+
+func runOTLPGrpcReceiver(t *testing.T) (*otlpReceiver, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	receiver := newOTLPReceiver()
+	server := grpc.NewServer()
+	collectorTrace.RegisterTraceServiceServer(server, receiver)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+	return receiver, lis.Addr().String()
+}
+
+func TestNewOTLPGrpcExporter(t *testing.T) {
+	receiver, addr := runOTLPGrpcReceiver(t)
+
+	exporter, err := NewOTLPGrpcExporter(addr,
+		WithOTLPInsecure(),
+		WithOTLPHeaders(map[string]string{"x-token": "abc"}),
+		WithOTLPTimeout(time.Second*3),
+		WithOTLPCompression(),
+	)
+	require.NoError(t, err)
+	InitWithOptions(exporter, NewResource(), WithBatchTimeout(time.Millisecond*50))
+
+	_, span := GetProvider().Tracer("test").Start(context.Background(), "grpc-span")
+	span.End()
+	assert.NoError(t, GetProvider().ForceFlush(context.Background()))
+
+	assert.Equal(t, []string{"grpc-span"}, receiver.getSpans())
+	assert.Equal(t, "abc", receiver.getHeader("x-token"))
+	assert.NoError(t, Close(context.Background()))
+}
+
+func TestNewOTLPHTTPExporter(t *testing.T) {
+	receiver := newOTLPReceiver()
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	exporter, err := NewOTLPHTTPExporter(strings.TrimPrefix(server.URL, "http://"),
+		WithOTLPInsecure(),
+		WithOTLPHeaders(map[string]string{"x-token": "abc"}),
+		WithOTLPURLPath("/otlp/v1/traces"),
+	)
+	require.NoError(t, err)
+	InitWithOptions(exporter, NewResource(), WithMaxExportBatchSize(10), WithMaxQueueSize(100))
+
+	_, span := GetProvider().Tracer("test").Start(context.Background(), "http-span")
+	span.End()
+	assert.NoError(t, GetProvider().ForceFlush(context.Background()))
+
+	assert.Equal(t, []string{"http-span"}, receiver.getSpans())
+	assert.Equal(t, "abc", receiver.getHeader("x-token"))
+	assert.Equal(t, "/otlp/v1/traces", receiver.getHeader("path"))
+	assert.NoError(t, Close(context.Background()))
+}
+
+func TestInitWithTracingConfig(t *testing.T) {
+	receiver, addr := runOTLPGrpcReceiver(t)
+
+	err := InitWithTracingConfig("foo", "dev", "v1.0.0", &Config{
+		Endpoint:      addr,
+		Insecure:      true,
+		SamplingRate:  1.0,
+		ParentBased:   true,
+		SamplingRules: []SamplingRule{{Name: "/api/v1/health", Rate: 0}},
+		Propagators:   []string{"w3c", "b3"},
+		BatchTimeout:  time.Millisecond * 50,
+		ExportTimeout: time.Second * 3,
+	})
+	require.NoError(t, err)
+
+	tracer := GetProvider().Tracer("test")
+	_, span := tracer.Start(context.Background(), "/api/v1/health")
+	span.End()
+	_, span = tracer.Start(context.Background(), "/api/v1/user")
+	span.End()
+	assert.NoError(t, GetProvider().ForceFlush(context.Background()))
+	assert.Equal(t, []string{"/api/v1/user"}, receiver.getSpans())
+	assert.NoError(t, Close(context.Background()))
+
+	err = InitWithTracingConfig("foo", "dev", "v1.0.0", &Config{Exporter: "console", Propagators: []string{"jaeger"}})
+	assert.NoError(t, err)
+
+	err = InitWithTracingConfig("foo", "dev", "v1.0.0", &Config{Exporter: "unknown"})
+	assert.Error(t, err)
+	err = InitWithTracingConfig("foo", "dev", "v1.0.0", &Config{Protocol: "unknown"})
+	assert.Error(t, err)
+	err = InitWithTracingConfig("foo", "dev", "v1.0.0", &Config{Exporter: "console", Propagators: []string{"unknown"}})
+	assert.Error(t, err)
+}
+
+func TestNewExporter(t *testing.T) {
+	exporter, err := NewExporter(&Config{Protocol: "http", Endpoint: "127.0.0.1:4318", Insecure: true})
+	assert.NoError(t, err)
+	assert.NotNil(t, exporter)
+
+	exporter, err = NewExporter(&Config{Exporter: "jaeger", Endpoint: "127.0.0.1:6831"})
+	assert.NoError(t, err)
+	assert.NotNil(t, exporter)
+}
@@ -0,0 +1,46 @@
+package tracer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewPropagator(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	testData := []struct {
+		names  []string
+		header string
+	}{
+		{names: nil, header: "traceparent"},
+		{names: []string{"w3c"}, header: "traceparent"},
+		{names: []string{"b3"}, header: "b3"},
+		{names: []string{"b3multi"}, header: "x-b3-traceid"},
+		{names: []string{"jaeger"}, header: "uber-trace-id"},
+	}
+	for _, tt := range testData {
+		p, err := NewPropagator(tt.names...)
+		assert.NoError(t, err)
+
+		carrier := propagation.MapCarrier{}
+		p.Inject(ctx, carrier)
+		assert.NotEmpty(t, carrier.Get(tt.header), tt.header)
+
+		// extract the span context from header
+		sc := trace.SpanContextFromContext(p.Extract(context.Background(), carrier))
+		assert.Equal(t, traceID, sc.TraceID())
+	}
+
+	_, err := NewPropagator("unknown")
+	assert.Error(t, err)
+}
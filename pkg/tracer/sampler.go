@@ -0,0 +1,121 @@
+package tracer
+
+import (
+	"fmt"
+	"strings"
+
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// SamplingRule the sampling rate of spans that match the name, name is the http route or grpc method,
+// e.g. "/api/v1/user/:id", "/api.user.v1.User/GetByID", the suffix * matches any characters, e.g. "/api/v1/user/*".
+type SamplingRule struct {
+	Name string
+	Rate float64
+}
+
+type ruleSampler struct {
+	rules       []*ruleMatcher
+	defaultRule sdkTrace.Sampler
+}
+
+type ruleMatcher struct {
+	name     string
+	isPrefix bool
+	sampler  sdkTrace.Sampler
+}
+
+func (m *ruleMatcher) match(name string) bool {
+	if m.isPrefix {
+		return strings.HasPrefix(name, m.name)
+	}
+	return name == m.name
+}
+
+// NewRuleSampler create a sampler that samples the spans by rules, the first matched rule is used,
+// the span that does not match any rule is sampled by defaultRate, rate >= 1 means all are sampled,
+// rate <= 0 means none are sampled.
+func NewRuleSampler(defaultRate float64, rules ...SamplingRule) sdkTrace.Sampler {
+	s := &ruleSampler{defaultRule: ratioSampler(defaultRate)}
+	for _, rule := range rules {
+		name := normalizeSpanName(rule.Name)
+		m := &ruleMatcher{name: name, sampler: ratioSampler(rule.Rate)}
+		if strings.HasSuffix(name, "*") {
+			m.name = strings.TrimSuffix(name, "*")
+			m.isPrefix = true
+		}
+		s.rules = append(s.rules, m)
+	}
+	return s
+}
+
+// ShouldSample matches the span name, http route and rpc method of span.
+func (s *ruleSampler) ShouldSample(p sdkTrace.SamplingParameters) sdkTrace.SamplingResult {
+	names := spanNames(p)
+	for _, rule := range s.rules {
+		for _, name := range names {
+			if rule.match(name) {
+				return rule.sampler.ShouldSample(p)
+			}
+		}
+	}
+	return s.defaultRule.ShouldSample(p)
+}
+
+func (s *ruleSampler) Description() string {
+	var rules []string
+	for _, rule := range s.rules {
+		rules = append(rules, rule.name+"="+rule.sampler.Description())
+	}
+	return fmt.Sprintf("RuleSampler{rules=[%s],default=%s}", strings.Join(rules, ","), s.defaultRule.Description())
+}
+
+// otelgrpc uses "package.Service/Method" as span name, otelgin uses the route
+func normalizeSpanName(name string) string {
+	return strings.TrimPrefix(name, "/")
+}
+
+func spanNames(p sdkTrace.SamplingParameters) []string {
+	names := []string{normalizeSpanName(p.Name)}
+	var service, method string
+	for _, attr := range p.Attributes {
+		switch attr.Key {
+		case semconv.HTTPRouteKey:
+			names = append(names, normalizeSpanName(attr.Value.AsString()))
+		case semconv.RPCServiceKey:
+			service = attr.Value.AsString()
+		case semconv.RPCMethodKey:
+			method = attr.Value.AsString()
+		}
+	}
+	if service != "" && method != "" {
+		names = append(names, service+"/"+method)
+	}
+	return names
+}
+
+func ratioSampler(rate float64) sdkTrace.Sampler {
+	if rate >= 1 {
+		return sdkTrace.AlwaysSample()
+	}
+	if rate <= 0 {
+		return sdkTrace.NeverSample()
+	}
+	return sdkTrace.TraceIDRatioBased(rate)
+}
+
+// NewSampler create a sampler, if parentBased is true, the sampling decision of parent span is respected,
+// the root span is sampled by rules and defaultRate.
+func NewSampler(parentBased bool, defaultRate float64, rules ...SamplingRule) sdkTrace.Sampler {
+	var sampler sdkTrace.Sampler
+	if len(rules) > 0 {
+		sampler = NewRuleSampler(defaultRate, rules...)
+	} else {
+		sampler = ratioSampler(defaultRate)
+	}
+	if parentBased {
+		return sdkTrace.ParentBased(sampler)
+	}
+	return sampler
+}
@@ -0,0 +1,71 @@
+package tracer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewRuleSampler(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	sampler := NewRuleSampler(1.0,
+		SamplingRule{Name: "/api/v1/health", Rate: 0},
+		SamplingRule{Name: "/api/v1/debug/*", Rate: 0},
+		SamplingRule{Name: "/api.user.v1.User/List", Rate: 0},
+	)
+	t.Log(sampler.Description())
+
+	testData := []struct {
+		name  string
+		attrs []attribute.KeyValue
+		want  sdkTrace.SamplingDecision
+	}{
+		{name: "/api/v1/health", want: sdkTrace.Drop},
+		{name: "GET /api/v1/health", want: sdkTrace.RecordAndSample},
+		{name: "GET", attrs: []attribute.KeyValue{semconv.HTTPRoute("/api/v1/health")}, want: sdkTrace.Drop},
+		{name: "/api/v1/debug/pprof", want: sdkTrace.Drop},
+		{name: "api.user.v1.User/List", want: sdkTrace.Drop},
+		{name: "rpc", attrs: []attribute.KeyValue{semconv.RPCService("api.user.v1.User"), semconv.RPCMethod("List")}, want: sdkTrace.Drop},
+		{name: "api.user.v1.User/GetByID", want: sdkTrace.RecordAndSample},
+		{name: "/api/v1/user", want: sdkTrace.RecordAndSample},
+	}
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			result := sampler.ShouldSample(sdkTrace.SamplingParameters{TraceID: traceID, Name: tt.name, Attributes: tt.attrs})
+			assert.Equal(t, tt.want, result.Decision)
+		})
+	}
+
+	sampler = NewRuleSampler(0, SamplingRule{Name: "/api/v1/order/*", Rate: 1})
+	assert.Equal(t, sdkTrace.RecordAndSample, sampler.ShouldSample(sdkTrace.SamplingParameters{TraceID: traceID, Name: "/api/v1/order/1"}).Decision)
+	assert.Equal(t, sdkTrace.Drop, sampler.ShouldSample(sdkTrace.SamplingParameters{TraceID: traceID, Name: "/api/v1/user/1"}).Decision)
+}
+
+func TestNewSampler(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	sampledParent := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	}))
+
+	// parent based, the sampled parent is respected
+	sampler := NewSampler(true, 0, SamplingRule{Name: "/api/v1/user", Rate: 0})
+	result := sampler.ShouldSample(sdkTrace.SamplingParameters{ParentContext: sampledParent, TraceID: traceID, Name: "/api/v1/user"})
+	assert.Equal(t, sdkTrace.RecordAndSample, result.Decision)
+
+	// not parent based, the rule is used
+	sampler = NewSampler(false, 1, SamplingRule{Name: "/api/v1/user", Rate: 0})
+	result = sampler.ShouldSample(sdkTrace.SamplingParameters{ParentContext: sampledParent, TraceID: traceID, Name: "/api/v1/user"})
+	assert.Equal(t, sdkTrace.Drop, result.Decision)
+
+	sampler = NewSampler(false, 0.5)
+	assert.Contains(t, sampler.Description(), "TraceIDRatioBased")
+}
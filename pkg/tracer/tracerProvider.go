@@ -3,6 +3,9 @@ package tracer
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
@@ -12,6 +15,96 @@ import (
 
 var tp *trace.TracerProvider
 
+// Option set the tracer provider options.
+type Option func(*options)
+
+type options struct {
+	sampler    trace.Sampler
+	propagator propagation.TextMapPropagator
+	batchOpts  []trace.BatchSpanProcessorOption
+}
+
+func defaultOptions() *options {
+	return &options{
+		sampler:    trace.ParentBased(trace.AlwaysSample()),
+		propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	}
+}
+
+func (o *options) apply(opts ...Option) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithSampler set sampler, default is parent based and all links are sampled, see NewSampler.
+func WithSampler(sampler trace.Sampler) Option {
+	return func(o *options) {
+		if sampler != nil {
+			o.sampler = sampler
+		}
+	}
+}
+
+// WithPropagator set propagator, default is tracecontext and baggage, see NewPropagator.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(o *options) {
+		if propagator != nil {
+			o.propagator = propagator
+		}
+	}
+}
+
+// WithBatchTimeout set the maximum delay of sending spans in batch, default is 5s.
+func WithBatchTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout > 0 {
+			o.batchOpts = append(o.batchOpts, trace.WithBatchTimeout(timeout))
+		}
+	}
+}
+
+// WithExportTimeout set the maximum duration of exporting spans, default is 30s.
+func WithExportTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout > 0 {
+			o.batchOpts = append(o.batchOpts, trace.WithExportTimeout(timeout))
+		}
+	}
+}
+
+// WithMaxExportBatchSize set the maximum number of spans sent in a batch, default is 512.
+func WithMaxExportBatchSize(size int) Option {
+	return func(o *options) {
+		if size > 0 {
+			o.batchOpts = append(o.batchOpts, trace.WithMaxExportBatchSize(size))
+		}
+	}
+}
+
+// WithMaxQueueSize set the maximum number of spans buffered, spans are dropped when queue is full, default is 2048.
+func WithMaxQueueSize(size int) Option {
+	return func(o *options) {
+		if size > 0 {
+			o.batchOpts = append(o.batchOpts, trace.WithMaxQueueSize(size))
+		}
+	}
+}
+
+// InitWithOptions Initialize tracer with sampler, propagator and batch processor options.
+func InitWithOptions(exporter trace.SpanExporter, res *resource.Resource, opts ...Option) {
+	o := defaultOptions()
+	o.apply(opts...)
+
+	tp = trace.NewTracerProvider(
+		trace.WithBatcher(exporter, o.batchOpts...),
+		trace.WithResource(res),
+		trace.WithSampler(o.sampler),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(o.propagator)
+}
+
 // Init Initialize tracer, parameter fraction is fraction, default is 1.0, value >= 1.0 means all links are sampled,
 // value <= 0 means all are not sampled, 0 < value < 1 only samples percentage
 func Init(exporter trace.SpanExporter, res *resource.Resource, fractions ...float64) {
@@ -64,6 +157,86 @@ func InitWithConfig(appName string, appEnv string, appVersion string,
 	SetTraceName(appName)
 }
 
+// Config tracing settings
+type Config struct {
+	Exporter string // otlp, jaeger or console, default is otlp
+	Protocol string // grpc or http, only for otlp exporter, default is grpc
+	Endpoint string // otlp collector host:port, e.g. localhost:4317, jaeger agent host:port, e.g. localhost:6831
+	Insecure bool
+	Headers  map[string]string
+
+	SamplingRate  float64 // between 0 and 1, 0 means no sampling, 1 means sampling all links
+	ParentBased   bool    // respect the sampling decision of parent span
+	SamplingRules []SamplingRule
+	Propagators   []string // tracecontext, baggage, b3, b3multi, jaeger
+
+	BatchTimeout       time.Duration
+	ExportTimeout      time.Duration
+	MaxExportBatchSize int
+	MaxQueueSize       int
+}
+
+// NewExporter create a span exporter according to configuration.
+func NewExporter(cfg *Config) (trace.SpanExporter, error) {
+	switch strings.ToLower(cfg.Exporter) {
+	case "", "otlp":
+		var opts []OTLPOption
+		if cfg.Insecure {
+			opts = append(opts, WithOTLPInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, WithOTLPHeaders(cfg.Headers))
+		}
+		if cfg.ExportTimeout > 0 {
+			opts = append(opts, WithOTLPTimeout(cfg.ExportTimeout))
+		}
+		switch strings.ToLower(cfg.Protocol) {
+		case "", "grpc":
+			return NewOTLPGrpcExporter(cfg.Endpoint, opts...)
+		case "http":
+			return NewOTLPHTTPExporter(cfg.Endpoint, opts...)
+		default:
+			return nil, fmt.Errorf("unsupported otlp protocol %q", cfg.Protocol)
+		}
+	case "jaeger":
+		host, port, _ := strings.Cut(cfg.Endpoint, ":")
+		return NewJaegerAgentExporter(host, port)
+	case "console":
+		return NewConsoleExporter()
+	default:
+		return nil, fmt.Errorf("unsupported exporter %q", cfg.Exporter)
+	}
+}
+
+// InitWithTracingConfig Initialize tracer according to tracing configuration.
+func InitWithTracingConfig(appName string, appEnv string, appVersion string, cfg *Config) error {
+	exporter, err := NewExporter(cfg)
+	if err != nil {
+		return err
+	}
+	propagator, err := NewPropagator(cfg.Propagators...)
+	if err != nil {
+		return err
+	}
+
+	res := NewResource(
+		WithServiceName(appName),
+		WithEnvironment(appEnv),
+		WithServiceVersion(appVersion),
+	)
+	InitWithOptions(exporter, res,
+		WithSampler(NewSampler(cfg.ParentBased, cfg.SamplingRate, cfg.SamplingRules...)),
+		WithPropagator(propagator),
+		WithBatchTimeout(cfg.BatchTimeout),
+		WithExportTimeout(cfg.ExportTimeout),
+		WithMaxExportBatchSize(cfg.MaxExportBatchSize),
+		WithMaxQueueSize(cfg.MaxQueueSize),
+	)
+
+	SetTraceName(appName)
+	return nil
+}
+
 // GetProvider get tracer provider
 func GetProvider() *trace.TracerProvider {
 	if tp == nil {
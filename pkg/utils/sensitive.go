@@ -0,0 +1,12 @@
+package utils
+
+// sensitive field names shared by masking the configuration and the logs
+var sensitiveFields = []string{
+	"password", "pwd", "passwd", "secret", "token", "accessToken", "access_token",
+	"refreshToken", "refresh_token", "authorization", "dsn",
+}
+
+// SensitiveFields returns the names of sensitive fields whose values should be masked, e.g. password, token, dsn.
+func SensitiveFields() []string {
+	return append([]string{}, sensitiveFields...)
+}
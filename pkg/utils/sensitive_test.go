@@ -0,0 +1,14 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensitiveFields(t *testing.T) {
+	fields := SensitiveFields()
+	assert.Contains(t, fields, "password")
+	fields[0] = "foo"
+	assert.Equal(t, "password", SensitiveFields()[0])
+}
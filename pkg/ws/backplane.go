@@ -0,0 +1,93 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// message targets
+const (
+	TargetAll     = "all"
+	TargetUser    = "user"
+	TargetRoom    = "room"
+	TargetSession = "session"
+)
+
+// BackplaneMessage is the message exchanged between replicas through the backplane.
+type BackplaneMessage struct {
+	NodeID      string `json:"nodeID"`      // node id of the publisher
+	Target      string `json:"target"`      // all, user, room, session
+	Key         string `json:"key"`         // user id, room or session id, empty when target is all
+	MessageType int    `json:"messageType"` // websocket message type
+	Data        []byte `json:"data"`
+}
+
+// Backplane delivers messages between the hubs of multiple replicas.
+type Backplane interface {
+	Publish(ctx context.Context, msg *BackplaneMessage) error
+	// Subscribe blocks until ctx is canceled or an error occurs
+	Subscribe(ctx context.Context, handler func(msg *BackplaneMessage)) error
+	Close() error
+}
+
+// ---------------------------------- redis backplane ----------------------------------
+
+type redisBackplane struct {
+	client  redis.UniversalClient
+	channel string
+}
+
+// NewRedisBackplane creates a backplane based on redis pub/sub, default channel is "ws:hub".
+func NewRedisBackplane(client redis.UniversalClient, channel ...string) (Backplane, error) {
+	if client == nil {
+		return nil, errors.New("redis client is nil")
+	}
+	ch := "ws:hub"
+	if len(channel) > 0 && channel[0] != "" {
+		ch = channel[0]
+	}
+	return &redisBackplane{client: client, channel: ch}, nil
+}
+
+func (b *redisBackplane) Publish(ctx context.Context, msg *BackplaneMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.channel, data).Err()
+}
+
+func (b *redisBackplane) Subscribe(ctx context.Context, handler func(msg *BackplaneMessage)) error {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	defer pubsub.Close() //nolint
+
+	// wait for confirmation that subscription is created
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case m, ok := <-ch:
+			if !ok {
+				return errors.New("redis pubsub channel closed")
+			}
+			msg := &BackplaneMessage{}
+			if err := json.Unmarshal([]byte(m.Payload), msg); err != nil {
+				continue
+			}
+			handler(msg)
+		}
+	}
+}
+
+// Close no-op, the redis client is managed by the caller.
+func (b *redisBackplane) Close() error {
+	return nil
+}
@@ -2,13 +2,18 @@ package ws
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"log"
+	"math/rand"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/18721889353/sunshine/pkg/krand"
 )
 
 var (
@@ -22,11 +27,19 @@ type clientOptions struct {
 	dialer           *websocket.Dialer
 	requestHeader    http.Header
 	pingDialInterval time.Duration
+
+	reconnect      bool
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
+	onConnect      func(c *Client) error
+	messageHandler func(messageType int, data []byte)
 }
 
 func defaultClientOptions() *clientOptions {
 	return &clientOptions{
-		dialer: websocket.DefaultDialer,
+		dialer:     websocket.DefaultDialer,
+		minBackoff: time.Second,
+		maxBackoff: 30 * time.Second,
 	}
 }
 
@@ -57,6 +70,36 @@ func WithPing(interval time.Duration) ClientOption {
 	}
 }
 
+// WithReconnect enable automatic reconnect when the connection is broken, the interval between
+// attempts grows exponentially from minBackoff to maxBackoff, default 1s and 30s. After reconnecting,
+// the subscribed topics are resubscribed and the OnConnect function is called.
+func WithReconnect(minBackoff time.Duration, maxBackoff time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.reconnect = true
+		if minBackoff > 0 {
+			o.minBackoff = minBackoff
+		}
+		if maxBackoff >= o.minBackoff {
+			o.maxBackoff = maxBackoff
+		}
+	}
+}
+
+// WithOnConnect sets the function called after the connection is established, including reconnecting.
+func WithOnConnect(fn func(c *Client) error) ClientOption {
+	return func(o *clientOptions) {
+		o.onConnect = fn
+	}
+}
+
+// WithMessageHandler sets the function called for each message received from the server,
+// if it is set, messages are read by the client, don't read from GetConn() directly.
+func WithMessageHandler(fn func(messageType int, data []byte)) ClientOption {
+	return func(o *clientOptions) {
+		o.messageHandler = fn
+	}
+}
+
 // ----------------------------------------------------------------------------------
 
 // Client is a wrapper of gorilla/websocket.
@@ -64,13 +107,28 @@ type Client struct {
 	dialer        *websocket.Dialer
 	requestHeader http.Header
 	url           string
-	conn          *websocket.Conn
+
+	connMu  sync.RWMutex
+	conn    *websocket.Conn
+	writeMu sync.Mutex
 
 	pingDialInterval time.Duration
 	ctx              context.Context
 	cancel           context.CancelFunc
 
-	once sync.Once
+	once     sync.Once
+	readOnce sync.Once
+
+	reconnect      bool
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
+	onConnect      func(c *Client) error
+	messageHandler func(messageType int, data []byte)
+
+	mu       sync.Mutex
+	handlers map[string]func(env *Envelope) // message type --> handler
+	pending  map[string]chan *Envelope      // request id --> response
+	topics   map[string]struct{}            // subscribed topics
 }
 
 // NewClient creates a new client.
@@ -87,18 +145,43 @@ func NewClient(url string, opts ...ClientOption) (*Client, error) {
 		pingDialInterval: o.pingDialInterval,
 		ctx:              ctx,
 		cancel:           cancel,
+		reconnect:        o.reconnect,
+		minBackoff:       o.minBackoff,
+		maxBackoff:       o.maxBackoff,
+		onConnect:        o.onConnect,
+		messageHandler:   o.messageHandler,
+		handlers:         make(map[string]func(env *Envelope)),
+		pending:          make(map[string]chan *Envelope),
+		topics:           make(map[string]struct{}),
 	}
 
 	err := c.Reconnect()
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
+	if c.onConnect != nil {
+		if err = c.onConnect(c); err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+	}
+	if c.reconnect || c.messageHandler != nil {
+		c.startReadLoop()
+	}
+
 	return c, nil
 }
 
+func (c *Client) getConn() *websocket.Conn {
+	c.connMu.RLock()
+	defer c.connMu.RUnlock()
+	return c.conn
+}
+
 func (c *Client) GetConn() *websocket.Conn {
-	if c.conn == nil {
+	if c.getConn() == nil {
 		defer func() {
 			if err := recover(); err != nil {
 				log.Printf("get conn panic, %v\n", err)
@@ -110,16 +193,22 @@ func (c *Client) GetConn() *websocket.Conn {
 		}
 	}
 
-	return c.conn
+	return c.getConn()
 }
 
-// Reconnect the websocket server.
+// Reconnect the websocket server, the previous connection is closed.
 func (c *Client) Reconnect() error {
 	conn, _, err := c.dialer.Dial(c.url, c.requestHeader)
 	if err != nil {
 		return err
 	}
+	c.connMu.Lock()
+	oldConn := c.conn
 	c.conn = conn
+	c.connMu.Unlock()
+	if oldConn != nil {
+		_ = oldConn.Close()
+	}
 
 	if c.pingDialInterval > 0 {
 		c.once.Do(func() {
@@ -154,7 +243,7 @@ func (c *Client) ping() {
 		for {
 			select {
 			case <-ticker.C:
-				if err := c.conn.WriteControl(websocket.PingMessage, pingData, time.Now().Add(5*time.Second)); err != nil {
+				if err := c.getConn().WriteControl(websocket.PingMessage, pingData, time.Now().Add(5*time.Second)); err != nil {
 					log.Printf("ping server err, %v\n", err)
 					continue
 				}
@@ -173,13 +262,206 @@ func (c *Client) Close() error {
 	if c.cancel != nil {
 		c.cancel()
 	}
-	if c.conn != nil {
-		return c.conn.Close()
+	if conn := c.getConn(); conn != nil {
+		return conn.Close()
 	}
 
 	return nil
 }
 
+// ----------------------------------------------------------------------------------
+
+// WriteMessage writes the message to the server, it is safe to be called concurrently.
+func (c *Client) WriteMessage(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	conn := c.getConn()
+	if conn == nil {
+		return errors.New("ws: connection is not established")
+	}
+	return conn.WriteMessage(messageType, data)
+}
+
+// Send sends a typed message to the server without waiting for a response.
+func (c *Client) Send(msgType string, payload interface{}) error {
+	return c.sendEnvelope(msgType, "", payload)
+}
+
+func (c *Client) sendEnvelope(msgType string, id string, payload interface{}) error {
+	env, err := NewEnvelope(msgType, id, payload)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(websocket.TextMessage, data)
+}
+
+// Request sends a typed message to the server and waits for the response with the same id,
+// if the response carries an error, it is returned as error.
+func (c *Client) Request(ctx context.Context, msgType string, payload interface{}) (*Envelope, error) {
+	c.startReadLoop()
+
+	id := krand.NewStringID()
+	ch := make(chan *Envelope, 1)
+	c.mu.Lock()
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.sendEnvelope(msgType, id, payload); err != nil {
+		return nil, err
+	}
+
+	select {
+	case env := <-ch:
+		if env.Error != "" {
+			return env, errors.New(env.Error)
+		}
+		return env, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.ctx.Done():
+		return nil, errors.New("ws: client closed")
+	}
+}
+
+// On registers the handler for the typed message pushed by the server.
+func (c *Client) On(msgType string, fn func(env *Envelope)) {
+	c.mu.Lock()
+	c.handlers[msgType] = fn
+	c.mu.Unlock()
+	c.startReadLoop()
+}
+
+// Subscribe the topic, it is resubscribed automatically after reconnecting.
+func (c *Client) Subscribe(topic string) error {
+	c.mu.Lock()
+	c.topics[topic] = struct{}{}
+	c.mu.Unlock()
+	return c.Send(TypeSubscribe, &SubscribePayload{Topic: topic})
+}
+
+// Unsubscribe the topic.
+func (c *Client) Unsubscribe(topic string) error {
+	c.mu.Lock()
+	delete(c.topics, topic)
+	c.mu.Unlock()
+	return c.Send(TypeUnsubscribe, &SubscribePayload{Topic: topic})
+}
+
+func (c *Client) startReadLoop() {
+	c.readOnce.Do(func() {
+		go c.readLoop()
+	})
+}
+
+// readLoop is the only goroutine that reads from the connection after it is started.
+func (c *Client) readLoop() {
+	for {
+		conn := c.getConn()
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			if c.ctx.Err() != nil {
+				return
+			}
+			if c.getConn() != conn { // replaced by Reconnect
+				continue
+			}
+			if !c.reconnect {
+				log.Printf("ws: read message error, %v\n", err)
+				return
+			}
+			if !c.redial() {
+				return
+			}
+			continue
+		}
+		c.handleMessage(messageType, data)
+	}
+}
+
+// redial reconnects the server with exponential backoff until success or the client is closed.
+func (c *Client) redial() bool {
+	backoff := c.minBackoff
+	for {
+		// add jitter to avoid all clients reconnecting at the same time
+		wait := backoff + time.Duration(rand.Int63n(int64(backoff)/2+1))
+		select {
+		case <-c.ctx.Done():
+			return false
+		case <-time.After(wait):
+		}
+
+		err := c.Reconnect()
+		if err == nil {
+			go c.afterReconnect()
+			return true
+		}
+		log.Printf("ws: reconnect error, %v\n", err)
+
+		backoff *= 2
+		if backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
+
+func (c *Client) afterReconnect() {
+	c.mu.Lock()
+	topics := make([]string, 0, len(c.topics))
+	for topic := range c.topics {
+		topics = append(topics, topic)
+	}
+	c.mu.Unlock()
+
+	for _, topic := range topics {
+		if err := c.Send(TypeSubscribe, &SubscribePayload{Topic: topic}); err != nil {
+			log.Printf("ws: resubscribe topic %s error, %v\n", topic, err)
+		}
+	}
+
+	if c.onConnect != nil {
+		if err := c.onConnect(c); err != nil {
+			log.Printf("ws: on connect error, %v\n", err)
+		}
+	}
+}
+
+func (c *Client) handleMessage(messageType int, data []byte) {
+	if c.messageHandler != nil {
+		c.messageHandler(messageType, data)
+	}
+	if messageType != websocket.TextMessage {
+		return
+	}
+
+	env := &Envelope{}
+	if err := json.Unmarshal(data, env); err != nil || env.Type == "" {
+		return
+	}
+
+	c.mu.Lock()
+	if ch, ok := c.pending[env.ID]; ok && env.ID != "" {
+		delete(c.pending, env.ID)
+		c.mu.Unlock()
+		ch <- env
+		return
+	}
+	fn := c.handlers[env.Type]
+	c.mu.Unlock()
+
+	if fn != nil {
+		fn(env)
+	}
+}
+
 // IsServerClose returns true if the error is caused by server close.
 func IsServerClose(err error) bool {
 	return strings.Contains(err.Error(), "use of closed network")
@@ -0,0 +1,61 @@
+package ws
+
+import (
+	"encoding/json"
+
+	"github.com/gorilla/websocket"
+)
+
+// built-in message types
+const (
+	// TypeSubscribe join the topic, payload is SubscribePayload
+	TypeSubscribe = "subscribe"
+	// TypeUnsubscribe leave the topic, payload is SubscribePayload
+	TypeUnsubscribe = "unsubscribe"
+	// TypeError error message sent by server when the message cannot be handled
+	TypeError = "error"
+)
+
+// Envelope is the message format of the typed-message protocol, a request with ID is answered
+// by a response with the same Type and ID.
+type Envelope struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// SubscribePayload payload of subscribe and unsubscribe message
+type SubscribePayload struct {
+	Topic string `json:"topic"`
+}
+
+// NewEnvelope creates an envelope, payload is encoded in json.
+func NewEnvelope(msgType string, id string, payload interface{}) (*Envelope, error) {
+	env := &Envelope{Type: msgType, ID: id}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		env.Payload = data
+	}
+	return env, nil
+}
+
+// Decode decodes the payload into v.
+func (e *Envelope) Decode(v interface{}) error {
+	if len(e.Payload) == 0 {
+		return nil
+	}
+	return json.Unmarshal(e.Payload, v)
+}
+
+// SendEnvelope puts the envelope into the send queue of the session.
+func (s *Session) SendEnvelope(env *Envelope) error {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	return s.Send(websocket.TextMessage, data)
+}
@@ -0,0 +1,495 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/18721889353/sunshine/pkg/krand"
+)
+
+var (
+	// ErrSessionClosed the session has been closed
+	ErrSessionClosed = errors.New("ws: session closed")
+	// ErrSlowConsumer the send queue of the session is full, the session is evicted
+	ErrSlowConsumer = errors.New("ws: slow consumer, send queue is full")
+)
+
+// HubOption is a functional option for the Hub.
+type HubOption func(*hubOptions)
+
+type hubOptions struct {
+	sendQueueSize int
+	writeWait     time.Duration
+	backplane     Backplane
+	nodeID        string
+}
+
+func defaultHubOptions() *hubOptions {
+	return &hubOptions{
+		sendQueueSize: 256,
+		writeWait:     10 * time.Second,
+		nodeID:        krand.NewStringID(),
+	}
+}
+
+func (o *hubOptions) apply(opts ...HubOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithSendQueueSize sets the size of send queue of each connection, if the queue is full,
+// the connection is considered a slow consumer and is evicted, default 256.
+func WithSendQueueSize(size int) HubOption {
+	return func(o *hubOptions) {
+		if size > 0 {
+			o.sendQueueSize = size
+		}
+	}
+}
+
+// WithWriteWait sets the time allowed to write a message to the connection, default 10s.
+func WithWriteWait(d time.Duration) HubOption {
+	return func(o *hubOptions) {
+		if d > 0 {
+			o.writeWait = d
+		}
+	}
+}
+
+// WithBackplane sets the backplane, messages are delivered to the connections held by other replicas.
+func WithBackplane(backplane Backplane) HubOption {
+	return func(o *hubOptions) {
+		o.backplane = backplane
+	}
+}
+
+// WithNodeID sets the node id of the hub, it is used to ignore messages published by itself on the backplane.
+func WithNodeID(nodeID string) HubOption {
+	return func(o *hubOptions) {
+		if nodeID != "" {
+			o.nodeID = nodeID
+		}
+	}
+}
+
+// --------------------------------------------------------------------------------------
+
+// MessageHandler is called for each message received from the session.
+type MessageHandler func(ctx context.Context, s *Session, messageType int, data []byte)
+
+type outMessage struct {
+	messageType int
+	data        []byte
+}
+
+// Session is a connection registered in the hub.
+type Session struct {
+	id     string
+	userID string
+	conn   *Conn
+	hub    *Hub
+
+	send      chan *outMessage
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu    sync.RWMutex
+	rooms map[string]struct{}
+	data  map[string]interface{}
+}
+
+// ID returns the session id.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// UserID returns the user id bound to the session.
+func (s *Session) UserID() string {
+	return s.userID
+}
+
+// Conn returns the underlying connection, don't write to it directly, use Send instead.
+func (s *Session) Conn() *Conn {
+	return s.conn
+}
+
+// Set stores a value in the session.
+func (s *Session) Set(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+}
+
+// Get returns the value stored in the session.
+func (s *Session) Get(key string) (interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.data[key]
+	return value, ok
+}
+
+// Rooms returns the rooms the session has joined.
+func (s *Session) Rooms() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rooms := make([]string, 0, len(s.rooms))
+	for room := range s.rooms {
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
+
+// Join the room.
+func (s *Session) Join(room string) {
+	s.hub.join(s, room)
+}
+
+// Leave the room.
+func (s *Session) Leave(room string) {
+	s.hub.leave(s, room)
+}
+
+// Send puts the message into the send queue without blocking, if the queue is full, the session is evicted.
+func (s *Session) Send(messageType int, data []byte) error {
+	select {
+	case <-s.done:
+		return ErrSessionClosed
+	default:
+	}
+
+	select {
+	case s.send <- &outMessage{messageType: messageType, data: data}:
+		return nil
+	case <-s.done:
+		return ErrSessionClosed
+	default:
+		log.Printf("ws: evict slow consumer, session=%s, user=%s\n", s.id, s.userID)
+		s.Close()
+		return ErrSlowConsumer
+	}
+}
+
+// Close the session and remove it from the hub.
+func (s *Session) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.hub.unregister(s)
+		_ = s.conn.Close()
+	})
+}
+
+// Done returns a channel that is closed when the session is closed.
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// writeLoop is the only goroutine that writes to the connection.
+func (s *Session) writeLoop() {
+	for {
+		select {
+		case msg := <-s.send:
+			_ = s.conn.SetWriteDeadline(time.Now().Add(s.hub.opts.writeWait))
+			if err := s.conn.WriteMessage(msg.messageType, msg.data); err != nil {
+				s.Close()
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// --------------------------------------------------------------------------------------
+
+// Hub is a registry of connections, it supports binding user, rooms, broadcast and targeted sending,
+// if a backplane is set, messages are also delivered to the connections held by other replicas.
+type Hub struct {
+	opts *hubOptions
+
+	mu       sync.RWMutex
+	sessions map[string]*Session            // session id --> session
+	users    map[string]map[string]*Session // user id --> sessions
+	rooms    map[string]map[string]*Session // room --> sessions
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewHub creates a new hub, if a backplane is set, it starts subscribing to the backplane.
+func NewHub(opts ...HubOption) *Hub {
+	o := defaultHubOptions()
+	o.apply(opts...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &Hub{
+		opts:     o,
+		sessions: make(map[string]*Session),
+		users:    make(map[string]map[string]*Session),
+		rooms:    make(map[string]map[string]*Session),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	if o.backplane != nil {
+		go h.subscribeBackplane()
+	}
+
+	return h
+}
+
+// the backoff interval of resubscribing to the backplane
+var (
+	resubscribeMinInterval = 100 * time.Millisecond
+	resubscribeMaxInterval = 10 * time.Second
+)
+
+// subscribe to the backplane until the hub is closed, resubscribe with exponential backoff
+// if the subscription fails or the connection drops.
+func (h *Hub) subscribeBackplane() {
+	interval := resubscribeMinInterval
+	for {
+		startAt := time.Now()
+		err := h.opts.backplane.Subscribe(h.ctx, h.onBackplaneMessage)
+		if h.ctx.Err() != nil {
+			return
+		}
+		if time.Since(startAt) > resubscribeMaxInterval {
+			interval = resubscribeMinInterval // the subscription has been working, retry quickly
+		}
+		log.Printf("ws: subscribe backplane error, resubscribe after %s, %v\n", interval, err)
+
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		interval *= 2
+		if interval > resubscribeMaxInterval {
+			interval = resubscribeMaxInterval
+		}
+	}
+}
+
+// Register the connection to the hub, userID can be empty.
+func (h *Hub) Register(conn *Conn, userID string) *Session {
+	s := &Session{
+		id:     krand.NewStringID(),
+		userID: userID,
+		conn:   conn,
+		hub:    h,
+		send:   make(chan *outMessage, h.opts.sendQueueSize),
+		done:   make(chan struct{}),
+		rooms:  make(map[string]struct{}),
+		data:   make(map[string]interface{}),
+	}
+
+	h.mu.Lock()
+	h.sessions[s.id] = s
+	if userID != "" {
+		if h.users[userID] == nil {
+			h.users[userID] = make(map[string]*Session)
+		}
+		h.users[userID][s.id] = s
+	}
+	h.mu.Unlock()
+
+	go s.writeLoop()
+	return s
+}
+
+// Serve registers the connection, reads messages until the connection is closed, then unregisters it,
+// it is usually called in LoopFn of Server.
+func (h *Hub) Serve(ctx context.Context, conn *Conn, userID string, handler MessageHandler) {
+	s := h.Register(conn, userID)
+	defer s.Close()
+
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			if !IsClientClose(err) {
+				select {
+				case <-s.done:
+				default:
+					log.Printf("ws: read message error, session=%s, %v\n", s.id, err)
+				}
+			}
+			return
+		}
+		if handler != nil {
+			handler(ctx, s, messageType, data)
+		}
+	}
+}
+
+func (h *Hub) unregister(s *Session) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.sessions, s.id)
+	if s.userID != "" {
+		if sessions, ok := h.users[s.userID]; ok {
+			delete(sessions, s.id)
+			if len(sessions) == 0 {
+				delete(h.users, s.userID)
+			}
+		}
+	}
+	for _, room := range s.Rooms() {
+		h.removeFromRoom(s, room)
+	}
+}
+
+func (h *Hub) join(s *Session, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.sessions[s.id]; !ok {
+		return
+	}
+	if h.rooms[room] == nil {
+		h.rooms[room] = make(map[string]*Session)
+	}
+	h.rooms[room][s.id] = s
+
+	s.mu.Lock()
+	s.rooms[room] = struct{}{}
+	s.mu.Unlock()
+}
+
+func (h *Hub) leave(s *Session, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeFromRoom(s, room)
+}
+
+// removeFromRoom must be called with h.mu held
+func (h *Hub) removeFromRoom(s *Session, room string) {
+	if sessions, ok := h.rooms[room]; ok {
+		delete(sessions, s.id)
+		if len(sessions) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+	s.mu.Lock()
+	delete(s.rooms, room)
+	s.mu.Unlock()
+}
+
+// Session returns the local session by id.
+func (h *Hub) Session(id string) (*Session, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	s, ok := h.sessions[id]
+	return s, ok
+}
+
+// Count returns the number of local sessions.
+func (h *Hub) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.sessions)
+}
+
+// RoomMembers returns the local sessions in the room.
+func (h *Hub) RoomMembers(room string) []*Session {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return toList(h.rooms[room])
+}
+
+// UserSessions returns the local sessions bound to the user.
+func (h *Hub) UserSessions(userID string) []*Session {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return toList(h.users[userID])
+}
+
+func toList(m map[string]*Session) []*Session {
+	list := make([]*Session, 0, len(m))
+	for _, s := range m {
+		list = append(list, s)
+	}
+	return list
+}
+
+// Broadcast sends the message to all sessions.
+func (h *Hub) Broadcast(messageType int, data []byte) {
+	h.dispatch(&BackplaneMessage{Target: TargetAll, MessageType: messageType, Data: data})
+}
+
+// SendToUser sends the message to all sessions bound to the user.
+func (h *Hub) SendToUser(userID string, messageType int, data []byte) {
+	h.dispatch(&BackplaneMessage{Target: TargetUser, Key: userID, MessageType: messageType, Data: data})
+}
+
+// SendToRoom sends the message to all sessions in the room.
+func (h *Hub) SendToRoom(room string, messageType int, data []byte) {
+	h.dispatch(&BackplaneMessage{Target: TargetRoom, Key: room, MessageType: messageType, Data: data})
+}
+
+// SendToSession sends the message to the session.
+func (h *Hub) SendToSession(sessionID string, messageType int, data []byte) {
+	h.dispatch(&BackplaneMessage{Target: TargetSession, Key: sessionID, MessageType: messageType, Data: data})
+}
+
+func (h *Hub) dispatch(msg *BackplaneMessage) {
+	h.deliver(msg)
+
+	if h.opts.backplane != nil {
+		msg.NodeID = h.opts.nodeID
+		if err := h.opts.backplane.Publish(h.ctx, msg); err != nil {
+			log.Printf("ws: publish to backplane error, %v\n", err)
+		}
+	}
+}
+
+func (h *Hub) onBackplaneMessage(msg *BackplaneMessage) {
+	if msg.NodeID == h.opts.nodeID {
+		return
+	}
+	h.deliver(msg)
+}
+
+// deliver the message to the local sessions
+func (h *Hub) deliver(msg *BackplaneMessage) {
+	var targets []*Session
+
+	h.mu.RLock()
+	switch msg.Target {
+	case TargetAll:
+		targets = toList(h.sessions)
+	case TargetUser:
+		targets = toList(h.users[msg.Key])
+	case TargetRoom:
+		targets = toList(h.rooms[msg.Key])
+	case TargetSession:
+		if s, ok := h.sessions[msg.Key]; ok {
+			targets = []*Session{s}
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, s := range targets {
+		_ = s.Send(msg.MessageType, msg.Data)
+	}
+}
+
+// Close all sessions and stop subscribing to the backplane.
+func (h *Hub) Close() error {
+	h.cancel()
+
+	h.mu.RLock()
+	sessions := toList(h.sessions)
+	h.mu.RUnlock()
+	for _, s := range sessions {
+		s.Close()
+	}
+
+	if h.opts.backplane != nil {
+		return h.opts.backplane.Close()
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func runHubServer(h *Hub) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := r.URL.Query().Get("uid")
+		room := r.URL.Query().Get("room")
+		s := NewServer(w, r, func(ctx context.Context, conn *Conn) {
+			h.Serve(ctx, conn, userID, func(ctx context.Context, s *Session, messageType int, data []byte) {
+				if room != "" && string(data) == "join" {
+					s.Join(room)
+					_ = s.Send(websocket.TextMessage, []byte("joined"))
+					return
+				}
+				_ = s.Send(messageType, data) // echo
+			})
+		})
+		_ = s.Run(context.Background())
+	}))
+}
+
+func dialHub(t *testing.T, srv *httptest.Server, query string) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws?" + query
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func readText(t *testing.T, conn *websocket.Conn) string {
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second * 2))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func waitCount(h *Hub, n int) {
+	for i := 0; i < 100 && h.Count() != n; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestHub(t *testing.T) {
+	h := NewHub(WithSendQueueSize(16), WithWriteWait(time.Second))
+	defer h.Close()
+	srv := runHubServer(h)
+	defer srv.Close()
+
+	c1 := dialHub(t, srv, "uid=100&room=room1")
+	defer c1.Close()
+	c2 := dialHub(t, srv, "uid=200&room=room1")
+	defer c2.Close()
+	c3 := dialHub(t, srv, "uid=200")
+	defer c3.Close()
+	waitCount(h, 3)
+	assert.Equal(t, 3, h.Count())
+	assert.Len(t, h.UserSessions("200"), 2)
+
+	// echo
+	_ = c3.WriteMessage(websocket.TextMessage, []byte("hello"))
+	assert.Equal(t, "hello", readText(t, c3))
+
+	// join room
+	_ = c1.WriteMessage(websocket.TextMessage, []byte("join"))
+	assert.Equal(t, "joined", readText(t, c1))
+	_ = c2.WriteMessage(websocket.TextMessage, []byte("join"))
+	assert.Equal(t, "joined", readText(t, c2))
+	assert.Len(t, h.RoomMembers("room1"), 2)
+
+	h.SendToRoom("room1", websocket.TextMessage, []byte("to room"))
+	assert.Equal(t, "to room", readText(t, c1))
+	assert.Equal(t, "to room", readText(t, c2))
+
+	h.SendToUser("200", websocket.TextMessage, []byte("to user"))
+	assert.Equal(t, "to user", readText(t, c2))
+	assert.Equal(t, "to user", readText(t, c3))
+
+	h.Broadcast(websocket.TextMessage, []byte("to all"))
+	assert.Equal(t, "to all", readText(t, c1))
+	assert.Equal(t, "to all", readText(t, c2))
+	assert.Equal(t, "to all", readText(t, c3))
+
+	s := h.RoomMembers("room1")[0]
+	s.Set("foo", "bar")
+	v, ok := s.Get("foo")
+	assert.True(t, ok)
+	assert.Equal(t, "bar", v)
+	assert.Equal(t, []string{"room1"}, s.Rooms())
+	s2, ok := h.Session(s.ID())
+	assert.True(t, ok)
+	assert.Equal(t, s.UserID(), s2.UserID())
+	assert.NotNil(t, s.Conn())
+	h.SendToSession(s.ID(), websocket.TextMessage, []byte("to session"))
+
+	s.Leave("room1")
+	assert.Len(t, h.RoomMembers("room1"), 1)
+
+	// client close
+	_ = c1.Close()
+	waitCount(h, 2)
+	assert.Equal(t, 2, h.Count())
+}
+
+func TestSession_slowConsumer(t *testing.T) {
+	h := NewHub(WithSendQueueSize(1))
+	defer h.Close()
+	srv := runHubServer(h)
+	defer srv.Close()
+	c := dialHub(t, srv, "uid=100")
+	defer c.Close()
+	waitCount(h, 1)
+
+	// a session without write loop, the send queue is never consumed
+	conn := h.UserSessions("100")[0].Conn()
+	s := &Session{
+		id:    "slow",
+		conn:  conn,
+		hub:   h,
+		send:  make(chan *outMessage, 1),
+		done:  make(chan struct{}),
+		rooms: make(map[string]struct{}),
+	}
+	assert.NoError(t, s.Send(websocket.TextMessage, []byte("1")))
+	assert.ErrorIs(t, s.Send(websocket.TextMessage, []byte("2")), ErrSlowConsumer)
+	assert.ErrorIs(t, s.Send(websocket.TextMessage, []byte("3")), ErrSessionClosed)
+	<-s.Done()
+}
+
+func TestHub_backplane(t *testing.T) {
+	redisServer, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redisServer.Close()
+	client := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+
+	_, err = NewRedisBackplane(nil)
+	assert.Error(t, err)
+	b1, _ := NewRedisBackplane(client, "test:ws")
+	b2, _ := NewRedisBackplane(client, "test:ws")
+
+	h1 := NewHub(WithBackplane(b1), WithNodeID("node1"))
+	defer h1.Close()
+	h2 := NewHub(WithBackplane(b2), WithNodeID("node2"))
+	defer h2.Close()
+	time.Sleep(time.Millisecond * 100) // wait for subscribing
+
+	srv := runHubServer(h1)
+	defer srv.Close()
+	c := dialHub(t, srv, "uid=100&room=room1")
+	defer c.Close()
+	_ = c.WriteMessage(websocket.TextMessage, []byte("join"))
+	assert.Equal(t, "joined", readText(t, c))
+
+	// sent by another replica
+	h2.SendToRoom("room1", websocket.TextMessage, []byte("from node2"))
+	assert.Equal(t, "from node2", readText(t, c))
+	h2.SendToUser("100", websocket.TextMessage, []byte("to user"))
+	assert.Equal(t, "to user", readText(t, c))
+
+	// sent by itself, received only once
+	h1.Broadcast(websocket.TextMessage, []byte("from node1"))
+	assert.Equal(t, "from node1", readText(t, c))
+	_ = c.SetReadDeadline(time.Now().Add(time.Millisecond * 200))
+	_, _, err = c.ReadMessage()
+	assert.Error(t, err)
+}
+
+type flakyBackplane struct {
+	subscribeCount int32
+	received       chan *BackplaneMessage
+}
+
+func (b *flakyBackplane) Publish(ctx context.Context, msg *BackplaneMessage) error {
+	return nil
+}
+
+// fails twice, then receives messages until ctx is canceled
+func (b *flakyBackplane) Subscribe(ctx context.Context, handler func(msg *BackplaneMessage)) error {
+	if atomic.AddInt32(&b.subscribeCount, 1) <= 2 {
+		return errors.New("connection refused")
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg := <-b.received:
+			handler(msg)
+		}
+	}
+}
+
+func (b *flakyBackplane) Close() error {
+	return nil
+}
+
+func TestHub_resubscribeBackplane(t *testing.T) {
+	minInterval := resubscribeMinInterval
+	resubscribeMinInterval = time.Millisecond * 10
+	defer func() { resubscribeMinInterval = minInterval }()
+
+	b := &flakyBackplane{received: make(chan *BackplaneMessage)}
+	h := NewHub(WithBackplane(b), WithNodeID("node1"))
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&b.subscribeCount) == 3
+	}, time.Second*2, time.Millisecond*10)
+
+	select {
+	case b.received <- &BackplaneMessage{NodeID: "node2"}:
+	case <-time.After(time.Second):
+		t.Fatal("the hub does not resubscribe")
+	}
+
+	_ = h.Close()
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&b.subscribeCount))
+}
@@ -0,0 +1,315 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/time/rate"
+
+	"github.com/18721889353/sunshine/pkg/jwt"
+)
+
+// SessionClaimsKey is the key of jwt claims stored in the session, get it by session.Get(SessionClaimsKey)
+const SessionClaimsKey = "jwt_claims"
+
+// ErrRateLimitExceeded the message rate of the connection exceeds the limit
+var ErrRateLimitExceeded = errors.New("rate limit exceeded")
+
+// Handler handles the message of the specified type, if the message has ID, the returned result
+// or error is sent back to the client with the same type and ID.
+type Handler func(ctx context.Context, s *Session, env *Envelope) (interface{}, error)
+
+// AuthVerifyFn verify the claims after the token is parsed, return an error to reject the connection.
+type AuthVerifyFn func(claims *jwt.Claims, r *http.Request) error
+
+// RouterOption is a functional option for the Router.
+type RouterOption func(*routerOptions)
+
+type routerOptions struct {
+	serverOpts []ServerOption
+	hub        *Hub
+
+	enableAuth bool
+	verify     AuthVerifyFn
+
+	pingInterval time.Duration
+	pongWait     time.Duration
+
+	rateLimit rate.Limit
+	rateBurst int
+}
+
+func defaultRouterOptions() *routerOptions {
+	return &routerOptions{
+		pingInterval: 30 * time.Second,
+		pongWait:     60 * time.Second,
+	}
+}
+
+func (o *routerOptions) apply(opts ...RouterOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithServerOptions sets the options of the underlying Server.
+func WithServerOptions(opts ...ServerOption) RouterOption {
+	return func(o *routerOptions) {
+		o.serverOpts = opts
+	}
+}
+
+// WithHub sets the hub that the connections are registered to, default is a new hub without backplane.
+func WithHub(hub *Hub) RouterOption {
+	return func(o *routerOptions) {
+		o.hub = hub
+	}
+}
+
+// WithJwtAuth enable jwt authentication at upgrade time, the token is read from the Authorization
+// header (Bearer token) or the query parameter token, the uid of claims is bound to the session.
+func WithJwtAuth(verify ...AuthVerifyFn) RouterOption {
+	return func(o *routerOptions) {
+		o.enableAuth = true
+		if len(verify) > 0 {
+			o.verify = verify[0]
+		}
+	}
+}
+
+// WithHeartbeat sets the interval of ping and the maximum time to wait for pong or any message,
+// the connection is considered dead and closed if nothing is received within pongWait.
+func WithHeartbeat(pingInterval time.Duration, pongWait time.Duration) RouterOption {
+	return func(o *routerOptions) {
+		if pingInterval <= 0 || pongWait <= pingInterval {
+			return
+		}
+		o.pingInterval = pingInterval
+		o.pongWait = pongWait
+	}
+}
+
+// WithRateLimit limits the number of messages per second of each connection, messages that exceed
+// the limit are answered with an error and dropped.
+func WithRateLimit(perSecond float64, burst int) RouterOption {
+	return func(o *routerOptions) {
+		if perSecond <= 0 || burst <= 0 {
+			return
+		}
+		o.rateLimit = rate.Limit(perSecond)
+		o.rateBurst = burst
+	}
+}
+
+// --------------------------------------------------------------------------------------
+
+// Router is a typed-message websocket framework, messages are Envelope in json format and dispatched
+// to the handler registered for the message type. Built-in subscribe and unsubscribe messages
+// join and leave topics, which are rooms of the hub.
+type Router struct {
+	opts *routerOptions
+	hub  *Hub
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewRouter creates a new router.
+func NewRouter(opts ...RouterOption) *Router {
+	o := defaultRouterOptions()
+	o.apply(opts...)
+
+	hub := o.hub
+	if hub == nil {
+		hub = NewHub()
+	}
+
+	rt := &Router{
+		opts:     o,
+		hub:      hub,
+		handlers: make(map[string]Handler),
+	}
+	rt.handlers[TypeSubscribe] = handleSubscribe
+	rt.handlers[TypeUnsubscribe] = handleUnsubscribe
+
+	return rt
+}
+
+// Handle registers the handler for the message type, the built-in handler of subscribe and unsubscribe can be overridden.
+func (rt *Router) Handle(msgType string, handler Handler) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.handlers[msgType] = handler
+}
+
+// Hub returns the hub of the router.
+func (rt *Router) Hub() *Hub {
+	return rt.hub
+}
+
+// Publish sends the message to all sessions that subscribe the topic.
+func (rt *Router) Publish(topic string, msgType string, payload interface{}) error {
+	data, err := encodeEnvelope(msgType, payload)
+	if err != nil {
+		return err
+	}
+	rt.hub.SendToRoom(topic, websocket.TextMessage, data)
+	return nil
+}
+
+// SendToUser sends the message to all sessions of the user.
+func (rt *Router) SendToUser(userID string, msgType string, payload interface{}) error {
+	data, err := encodeEnvelope(msgType, payload)
+	if err != nil {
+		return err
+	}
+	rt.hub.SendToUser(userID, websocket.TextMessage, data)
+	return nil
+}
+
+func encodeEnvelope(msgType string, payload interface{}) ([]byte, error) {
+	env, err := NewEnvelope(msgType, "", payload)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(env)
+}
+
+// ServeHTTP authenticates the request, upgrades it to websocket and serves the connection.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var claims *jwt.Claims
+	if rt.opts.enableAuth {
+		var err error
+		claims, err = jwt.ParseToken(getToken(r))
+		if err == nil && rt.opts.verify != nil {
+			err = rt.opts.verify(claims, r)
+		}
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+	}
+
+	s := NewServer(w, r, func(ctx context.Context, conn *Conn) {
+		rt.serve(ctx, conn, claims)
+	}, rt.opts.serverOpts...)
+	_ = s.Run(r.Context())
+}
+
+func getToken(r *http.Request) string {
+	authorization := r.Header.Get("Authorization")
+	if strings.HasPrefix(authorization, "Bearer ") {
+		return authorization[7:]
+	}
+	return r.URL.Query().Get("token")
+}
+
+func (rt *Router) serve(ctx context.Context, conn *Conn, claims *jwt.Claims) {
+	userID := ""
+	if claims != nil {
+		userID = claims.UID
+	}
+	s := rt.hub.Register(conn, userID)
+	defer s.Close()
+	if claims != nil {
+		s.Set(SessionClaimsKey, claims)
+	}
+
+	// heartbeat, the connection is closed if nothing is received within pongWait
+	_ = conn.SetReadDeadline(time.Now().Add(rt.opts.pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(rt.opts.pongWait))
+	})
+	go rt.ping(s)
+
+	var limiter *rate.Limiter
+	if rt.opts.rateLimit > 0 {
+		limiter = rate.NewLimiter(rt.opts.rateLimit, rt.opts.rateBurst)
+	}
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(rt.opts.pongWait))
+
+		env := &Envelope{}
+		if err = json.Unmarshal(data, env); err != nil || env.Type == "" {
+			_ = s.SendEnvelope(&Envelope{Type: TypeError, Error: "invalid message"})
+			continue
+		}
+		if limiter != nil && !limiter.Allow() {
+			_ = s.SendEnvelope(&Envelope{Type: env.Type, ID: env.ID, Error: ErrRateLimitExceeded.Error()})
+			continue
+		}
+
+		rt.dispatch(ctx, s, env)
+	}
+}
+
+func (rt *Router) ping(s *Session) {
+	ticker := time.NewTicker(rt.opts.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(s.hub.opts.writeWait))
+			if err != nil {
+				s.Close()
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (rt *Router) dispatch(ctx context.Context, s *Session, env *Envelope) {
+	rt.mu.RLock()
+	handler, ok := rt.handlers[env.Type]
+	rt.mu.RUnlock()
+	if !ok {
+		_ = s.SendEnvelope(&Envelope{Type: env.Type, ID: env.ID, Error: "unknown message type"})
+		return
+	}
+
+	result, err := handler(ctx, s, env)
+	if env.ID == "" && err == nil {
+		return
+	}
+
+	reply := &Envelope{Type: env.Type, ID: env.ID}
+	if err != nil {
+		reply.Error = err.Error()
+	} else if result != nil {
+		if reply.Payload, err = json.Marshal(result); err != nil {
+			reply.Error = err.Error()
+		}
+	}
+	_ = s.SendEnvelope(reply)
+}
+
+func handleSubscribe(_ context.Context, s *Session, env *Envelope) (interface{}, error) {
+	payload := &SubscribePayload{}
+	if err := env.Decode(payload); err != nil || payload.Topic == "" {
+		return nil, errors.New("invalid topic")
+	}
+	s.Join(payload.Topic)
+	return payload, nil
+}
+
+func handleUnsubscribe(_ context.Context, s *Session, env *Envelope) (interface{}, error) {
+	payload := &SubscribePayload{}
+	if err := env.Decode(payload); err != nil || payload.Topic == "" {
+		return nil, errors.New("invalid topic")
+	}
+	s.Leave(payload.Topic)
+	return payload, nil
+}
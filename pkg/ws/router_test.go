@@ -0,0 +1,253 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/18721889353/sunshine/pkg/jwt"
+)
+
+type echoPayload struct {
+	Text string `json:"text"`
+}
+
+func newTestRouter(opts ...RouterOption) *Router {
+	rt := NewRouter(opts...)
+	rt.Handle("echo", func(ctx context.Context, s *Session, env *Envelope) (interface{}, error) {
+		p := &echoPayload{}
+		if err := env.Decode(p); err != nil {
+			return nil, err
+		}
+		if p.Text == "" {
+			return nil, errors.New("empty text")
+		}
+		return p, nil
+	})
+	rt.Handle("whoami", func(ctx context.Context, s *Session, env *Envelope) (interface{}, error) {
+		return s.UserID(), nil
+	})
+	return rt
+}
+
+func routerURL(srv *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+}
+
+func TestRouter_auth(t *testing.T) {
+	jwt.Init()
+	rt := newTestRouter(WithJwtAuth(func(claims *jwt.Claims, r *http.Request) error {
+		if claims.UID == "blocked" {
+			return errors.New("blocked")
+		}
+		return nil
+	}))
+	defer rt.Hub().Close()
+	srv := httptest.NewServer(rt)
+	defer srv.Close()
+
+	// no token
+	_, resp, err := websocket.DefaultDialer.Dial(routerURL(srv), nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// rejected by verify function
+	token, _ := jwt.GenerateToken("blocked")
+	_, resp, err = websocket.DefaultDialer.Dial(routerURL(srv)+"?token="+token, nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// token in header
+	token, _ = jwt.GenerateToken("100", "foo")
+	c, err := NewClient(routerURL(srv), WithRequestHeader(http.Header{"Authorization": []string{"Bearer " + token}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+	env, err := c.Request(ctx, "whoami", nil)
+	assert.NoError(t, err)
+	var uid string
+	assert.NoError(t, env.Decode(&uid))
+	assert.Equal(t, "100", uid)
+
+	s := rt.Hub().UserSessions("100")[0]
+	v, ok := s.Get(SessionClaimsKey)
+	assert.True(t, ok)
+	assert.Equal(t, "foo", v.(*jwt.Claims).Name)
+}
+
+func TestRouter_message(t *testing.T) {
+	rt := newTestRouter()
+	defer rt.Hub().Close()
+	srv := httptest.NewServer(rt)
+	defer srv.Close()
+
+	c, err := NewClient(routerURL(srv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	// request and response
+	env, err := c.Request(ctx, "echo", &echoPayload{Text: "hello"})
+	assert.NoError(t, err)
+	p := &echoPayload{}
+	assert.NoError(t, env.Decode(p))
+	assert.Equal(t, "hello", p.Text)
+
+	// handler error
+	_, err = c.Request(ctx, "echo", &echoPayload{})
+	assert.EqualError(t, err, "empty text")
+
+	// unknown message type
+	_, err = c.Request(ctx, "unknown", nil)
+	assert.Error(t, err)
+
+	// subscribe and publish
+	received := make(chan *Envelope, 1)
+	c.On("news", func(env *Envelope) {
+		received <- env
+	})
+	_, err = c.Request(ctx, TypeSubscribe, &SubscribePayload{Topic: "topic1"})
+	assert.NoError(t, err)
+	assert.NoError(t, rt.Publish("topic1", "news", &echoPayload{Text: "breaking"}))
+	select {
+	case env = <-received:
+		assert.NoError(t, env.Decode(p))
+		assert.Equal(t, "breaking", p.Text)
+	case <-time.After(time.Second * 2):
+		t.Fatal("timeout waiting for published message")
+	}
+
+	_, err = c.Request(ctx, TypeUnsubscribe, &SubscribePayload{Topic: "topic1"})
+	assert.NoError(t, err)
+	assert.Len(t, rt.Hub().RoomMembers("topic1"), 0)
+}
+
+func TestRouter_rateLimit(t *testing.T) {
+	rt := newTestRouter(WithRateLimit(1, 2))
+	defer rt.Hub().Close()
+	srv := httptest.NewServer(rt)
+	defer srv.Close()
+
+	c, err := NewClient(routerURL(srv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		_, err = c.Request(ctx, "echo", &echoPayload{Text: "hello"})
+		assert.NoError(t, err)
+	}
+	_, err = c.Request(ctx, "echo", &echoPayload{Text: "hello"})
+	assert.EqualError(t, err, ErrRateLimitExceeded.Error())
+}
+
+func TestRouter_heartbeat(t *testing.T) {
+	rt := newTestRouter(WithHeartbeat(time.Millisecond*50, time.Millisecond*200))
+	defer rt.Hub().Close()
+	srv := httptest.NewServer(rt)
+	defer srv.Close()
+
+	// the client answers ping automatically while reading, the connection is kept alive
+	var pings int32
+	conn, _, err := websocket.DefaultDialer.Dial(routerURL(srv), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetPingHandler(func(data string) error {
+		atomic.AddInt32(&pings, 1)
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// the client does not read, pong is never sent, the connection is closed by server
+	deadConn, _, err := websocket.DefaultDialer.Dial(routerURL(srv), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deadConn.Close()
+
+	waitCount(rt.Hub(), 2)
+	time.Sleep(time.Millisecond * 500)
+	assert.Equal(t, 1, rt.Hub().Count())
+	assert.Greater(t, atomic.LoadInt32(&pings), int32(2))
+}
+
+func TestClient_reconnect(t *testing.T) {
+	rt := newTestRouter()
+	defer rt.Hub().Close()
+	srv := httptest.NewServer(rt)
+	defer srv.Close()
+
+	var connects int32
+	var raws int32
+	c, err := NewClient(routerURL(srv),
+		WithReconnect(time.Millisecond*10, time.Millisecond*100),
+		WithOnConnect(func(c *Client) error {
+			atomic.AddInt32(&connects, 1)
+			return nil
+		}),
+		WithMessageHandler(func(messageType int, data []byte) {
+			atomic.AddInt32(&raws, 1)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	assert.NoError(t, c.Subscribe("topic1"))
+	waitRoom := func() {
+		for i := 0; i < 100 && len(rt.Hub().RoomMembers("topic1")) == 0; i++ {
+			time.Sleep(time.Millisecond * 10)
+		}
+	}
+	waitRoom()
+	assert.Len(t, rt.Hub().RoomMembers("topic1"), 1)
+
+	// the server closes the connection, client reconnects and resubscribes
+	for _, s := range rt.Hub().RoomMembers("topic1") {
+		s.Close()
+	}
+	time.Sleep(time.Millisecond * 50)
+	waitRoom()
+	assert.Len(t, rt.Hub().RoomMembers("topic1"), 1)
+	for i := 0; i < 100 && atomic.LoadInt32(&connects) != 2; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&connects))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+	_, err = c.Request(ctx, "echo", &echoPayload{Text: "hello"})
+	assert.NoError(t, err)
+	assert.Greater(t, atomic.LoadInt32(&raws), int32(0))
+
+	assert.NoError(t, c.Unsubscribe("topic1"))
+	assert.NoError(t, c.Send("echo", &echoPayload{Text: "no reply"}))
+}